@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,14 +13,23 @@ import (
 
 	"scootin-aboot/internal/api/middleware"
 	"scootin-aboot/internal/api/routes"
+	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/auth/jwt"
 	"scootin-aboot/internal/config"
 	"scootin-aboot/internal/database"
 	"scootin-aboot/internal/events"
+	"scootin-aboot/internal/geofence"
 	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/pricing"
+	"scootin-aboot/internal/ratelimit"
 	"scootin-aboot/internal/repository"
 	"scootin-aboot/internal/services"
+	"scootin-aboot/internal/stream"
+	"scootin-aboot/internal/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 func main() {
@@ -33,29 +43,60 @@ func main() {
 	}
 	defer logger.Sync()
 
-	dsn := cfg.GetDatabaseDSN()
-	dbURL := cfg.GetDatabaseURL()
-
-	migrationsPath, err := database.GetMigrationsPath()
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.TracingConfig, "scootin-aboot")
 	if err != nil {
-		logger.Fatal("Failed to get migrations path", logger.ErrorField(err))
+		logger.Fatal("Failed to set up tracing", logger.ErrorField(err))
 	}
 
-	if err := database.MigrateUp(dbURL, migrationsPath); err != nil {
-		logger.Fatal("Failed to run database migrations", logger.ErrorField(err))
-	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 
-	sqlDB, err := database.ConnectDatabase(dsn)
-	if err != nil {
-		logger.Fatal("Failed to connect to database", logger.ErrorField(err))
-	}
+	var repo repository.Repository
+	var sqlDB *sql.DB
+	stopHealthCheck := func() {}
 
-	stopHealthCheck := database.StartHealthCheck(sqlDB, 30*time.Second)
+	if cfg.StorageBackend == config.StorageBackendMemory {
+		logger.Warn("Using in-memory storage; all data is lost when the server stops")
+		repo = repository.NewMemoryRepository()
+
+		if cfg.StorageSeedDir != "" {
+			users, scooters, err := database.SeedRepository(context.Background(), repo, cfg.StorageSeedDir)
+			if err != nil {
+				logger.Fatal("Failed to seed in-memory storage", logger.ErrorField(err))
+			}
+			logger.Info("Seeded in-memory storage",
+				logger.String("dir", cfg.StorageSeedDir),
+				logger.Int("users", users),
+				logger.Int("scooters", scooters),
+			)
+		}
+	} else {
+		dsn := cfg.GetDatabaseDSN()
+		dbURL := cfg.GetDatabaseURL()
+
+		migrationsPath, err := database.GetMigrationsPath()
+		if err != nil {
+			logger.Fatal("Failed to get migrations path", logger.ErrorField(err))
+		}
+
+		if err := database.MigrateUp(dbURL, migrationsPath); err != nil {
+			logger.Fatal("Failed to run database migrations", logger.ErrorField(err))
+		}
+
+		sqlDB, err = database.ConnectDatabase(dsn, registry)
+		if err != nil {
+			logger.Fatal("Failed to connect to database", logger.ErrorField(err))
+		}
+
+		stopHealthCheck = database.StartHealthCheck(sqlDB, 30*time.Second)
+		repo = repository.NewRepository(sqlDB)
+	}
 
 	logger.Info("Starting Scootin' Aboot server",
 		logger.String("host", cfg.ServerHost),
 		logger.String("port", cfg.ServerPort),
 		logger.String("log_level", cfg.LogLevel),
+		logger.String("storage_backend", cfg.StorageBackend),
 	)
 
 	if cfg.LogLevel == "debug" {
@@ -64,33 +105,108 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	repo := repository.NewRepository(sqlDB)
+	var zones *geofence.Set
+	if cfg.GeofenceConfig.File != "" {
+		zones, err = geofence.LoadFile(cfg.GeofenceConfig.File)
+		if err != nil {
+			logger.Fatal("Failed to load geofences", logger.ErrorField(err))
+		}
+		logger.Info("Geofences loaded",
+			logger.String("file", cfg.GeofenceConfig.File),
+			logger.Int("zones", len(zones.Zones())),
+		)
+	}
+
+	var fares *pricing.Calculator
+	if cfg.PricingConfig.File != "" {
+		fares, err = pricing.LoadFile(cfg.PricingConfig.File, zones)
+		if err != nil {
+			logger.Fatal("Failed to load tariffs", logger.ErrorField(err))
+		}
+		logger.Info("Tariffs loaded", logger.String("file", cfg.PricingConfig.File))
+	}
+
+	services.RegisterFleetMetrics(registry, repo.Scooter(), repo.Trip())
+
+	unitOfWork := repository.NewTracedUnitOfWork(repo.UnitOfWork())
+
+	scooterChanges := stream.NewHub(cfg.StreamConfig.BufferSize, cfg.StreamConfig.ClientBuffer)
 
 	tripService := services.NewTripService(
 		repo.Trip(),
 		repo.Scooter(),
 		repo.User(),
 		repo.LocationUpdate(),
-		repo.UnitOfWork(),
+		unitOfWork,
+		cfg.BatteryConfig.MinTripLevel,
+		zones,
+		fares,
+		scooterChanges,
 	)
 
 	scooterService := services.NewScooterService(
 		repo.Scooter(),
 		repo.Trip(),
 		repo.LocationUpdate(),
-		repo.UnitOfWork(),
+		unitOfWork,
+		cfg.BatteryConfig.SearchThreshold,
+		scooterChanges,
+	)
+
+	reservationService := services.NewReservationService(
+		repo.Reservation(),
+		unitOfWork,
+		cfg.ReservationConfig.Duration,
+		scooterChanges,
 	)
 
+	scooterStatusService := services.NewScooterStatusService(
+		repo.Scooter(),
+		repo.ScooterStatusHistory(),
+		unitOfWork,
+		scooterChanges,
+	)
+
+	apiKeyService := services.NewAPIKeyService(repo.APIKey())
+	apiKeyValidator := apikey.NewValidator(cfg.APIKey, repo.APIKey())
+
+	var tokenAuthenticator *jwt.Authenticator
+	if cfg.JWTConfig.Enabled() {
+		tokenAuthenticator, err = jwt.NewAuthenticatorFromConfig(&cfg.JWTConfig)
+		if err != nil {
+			logger.Fatal("Failed to configure JWT authentication", logger.ErrorField(err))
+		}
+		logger.Info("JWT authentication enabled",
+			logger.String("issuer", cfg.JWTConfig.Issuer),
+			logger.String("audience", cfg.JWTConfig.Audience),
+		)
+	}
+
+	rateLimiter, err := ratelimit.NewLimiterFromConfig(&cfg.RateLimitConfig)
+	if err != nil {
+		logger.Fatal("Failed to configure rate limiting", logger.ErrorField(err))
+	}
+	if rateLimiter != nil {
+		logger.Info("Rate limiting enabled", logger.String("default", cfg.RateLimitConfig.Default))
+	}
+
 	router := gin.New()
+	// Only the configured proxies may name the client IP that rate limits are charged to
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatal("Invalid trusted proxies", logger.ErrorField(err))
+	}
 
+	router.Use(middleware.RequestIDMiddleware())
 	router.Use(middleware.LoggingMiddleware())
+	router.Use(middleware.MetricsMiddleware(registry))
+	router.Use(middleware.TracingMiddleware())
 	router.Use(middleware.ErrorHandlerMiddleware())
 	router.Use(middleware.ValidateJSON())
 	router.Use(middleware.ValidateContentLength(1024 * 1024))
 
-	routes.SetupRoutes(router, cfg.APIKey, scooterService)
+	routes.SetupRoutes(router, apiKeyValidator, tokenAuthenticator, rateLimiter, scooterService, tripService, apiKeyService, reservationService, scooterStatusService, zones, scooterChanges, cfg.StreamConfig.HeartbeatInterval, registry)
 
-	kafkaConsumer, err := events.NewEventConsumer(&cfg.KafkaConfig, tripService, scooterService)
+	kafkaConsumer, err := events.NewEventConsumer(&cfg.KafkaConfig, tripService, scooterService, registry)
 	if err != nil {
 		logger.Fatal("Failed to create events consumer", logger.ErrorField(err))
 	}
@@ -98,7 +214,18 @@ func main() {
 	if err := kafkaConsumer.Start(); err != nil {
 		logger.Fatal("Failed to start events consumer", logger.ErrorField(err))
 	}
-	logger.Info("Events consumer started")
+	logger.Info("Events consumer started", logger.String("transport", cfg.KafkaConfig.Transport))
+
+	kafkaProducer, err := events.NewKafkaProducer(&cfg.KafkaConfig)
+	if err != nil {
+		logger.Fatal("Failed to create events producer", logger.ErrorField(err))
+	}
+
+	outboxRelay := events.NewOutboxRelay(unitOfWork, kafkaProducer, cfg.OutboxConfig, registry)
+	outboxRelay.Start()
+
+	reservationSweeper := services.NewReservationSweeper(reservationService, cfg.ReservationConfig.SweepInterval)
+	reservationSweeper.Start()
 
 	address := fmt.Sprintf("%s:%s", cfg.ServerHost, cfg.ServerPort)
 	logger.Info("Server starting", logger.String("address", address))
@@ -107,6 +234,9 @@ func main() {
 		Addr:    address,
 		Handler: router,
 	}
+	// Streams and live trip connections never finish on their own, and Shutdown does not track the hijacked
+	// WebSockets, so closing the hub as shutdown starts is what ends them
+	srv.RegisterOnShutdown(scooterChanges.Close)
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -125,14 +255,31 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", logger.ErrorField(err))
 	}
+	if err := scooterChanges.Wait(ctx); err != nil {
+		logger.Error("Live connections did not close in time", logger.ErrorField(err))
+	}
 
 	stopHealthCheck()
 
 	kafkaConsumer.Stop()
 	logger.Info("Events consumer stopped")
 
-	if err := sqlDB.Close(); err != nil {
-		logger.Error("Failed to close database connection", logger.ErrorField(err))
+	reservationSweeper.Stop()
+
+	outboxRelay.Stop()
+
+	if err := kafkaProducer.Close(); err != nil {
+		logger.Error("Failed to close events producer", logger.ErrorField(err))
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Failed to flush traces", logger.ErrorField(err))
+	}
+
+	if sqlDB != nil {
+		if err := sqlDB.Close(); err != nil {
+			logger.Error("Failed to close database connection", logger.ErrorField(err))
+		}
 	}
 
 	logger.Info("Server exited")
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"scootin-aboot/internal/config"
 	"scootin-aboot/internal/logger"
 	"scootin-aboot/internal/simulator"
+	"scootin-aboot/internal/tracing"
 )
 
 func main() {
@@ -35,6 +38,12 @@ func main() {
 		logger.String("log_format", cfg.LogFormat),
 	)
 
+	// Events published by the simulator carry its trace context, so the server's handling joins the same trace
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.TracingConfig, "scootin-simulator")
+	if err != nil {
+		logger.Fatal("Failed to set up tracing", logger.ErrorField(err))
+	}
+
 	logger.Info("Starting Scootin' Aboot simulator")
 
 	sim, err := simulator.NewSimulator(cfg)
@@ -53,4 +62,10 @@ func main() {
 	logger.Info("Received shutdown signal, stopping simulator...")
 
 	sim.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Failed to flush traces", logger.ErrorField(err))
+	}
 }
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse is the only response that includes the key secret
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type APIKeyListResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
+
+func newAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	created, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), services.CreateAPIKeyParams{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		c.Error(h.mapAPIKeyError(c, "Failed to create API key", err))
+		return
+	}
+
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		logger.FromContext(c.Request.Context()).Info("API key created",
+			logger.String("api_key_id", created.Key.ID.String()),
+			logger.String("name", created.Key.Name),
+			logger.Strings("scopes", created.Key.Scopes),
+			logger.String("created_by", principal.Name),
+		)
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		APIKeyResponse: newAPIKeyResponse(created.Key),
+		Key:            created.Secret,
+	})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		c.Error(h.mapAPIKeyError(c, "Failed to list API keys", err))
+		return
+	}
+
+	response := APIKeyListResponse{APIKeys: make([]APIKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.APIKeys = append(response.APIKeys, newAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid API key ID"))
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		c.Error(h.mapAPIKeyError(c, "Failed to revoke API key", err))
+		return
+	}
+
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		logger.FromContext(c.Request.Context()).Info("API key revoked",
+			logger.String("api_key_id", id.String()),
+			logger.String("revoked_by", principal.Name),
+		)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) mapAPIKeyError(c *gin.Context, msg string, err error) *middleware.APIError {
+	switch {
+	case errors.Is(err, services.ErrInvalidAPIKeyRequest):
+		return middleware.NewAPIError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		return middleware.ErrNotFound
+	}
+
+	logger.FromContext(c.Request.Context()).Error(msg, logger.ErrorField(err))
+	return middleware.ErrInternalServer
+}
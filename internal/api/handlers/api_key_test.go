@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	t.Run("returns the secret once", func(t *testing.T) {
+		mockService := &mocks.MockAPIKeyService{}
+		handler := NewAPIKeyHandler(mockService)
+
+		key := &models.APIKey{ID: uuid.New(), Name: "simulator", KeyHash: "hash", Scopes: []string{models.ScopeTripsWrite}, CreatedAt: time.Now()}
+		mockService.On("CreateAPIKey", mock.Anything, services.CreateAPIKeyParams{Name: "simulator", Scopes: []string{models.ScopeTripsWrite}}).
+			Return(&services.CreatedAPIKey{Key: key, Secret: "sk_secret"}, nil)
+
+		router := createTripTestRouter(http.MethodPost, "/admin/api-keys", handler.CreateAPIKey)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(`{"name":"simulator","scopes":["trips:write"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response CreateAPIKeyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, key.ID, response.ID)
+		assert.Equal(t, "sk_secret", response.Key)
+		assert.NotContains(t, w.Body.String(), "hash")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid request maps to 400", func(t *testing.T) {
+		mockService := &mocks.MockAPIKeyService{}
+		handler := NewAPIKeyHandler(mockService)
+
+		mockService.On("CreateAPIKey", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: unknown scope %q", services.ErrInvalidAPIKeyRequest, "everything"))
+
+		router := createTripTestRouter(http.MethodPost, "/admin/api-keys", handler.CreateAPIKey)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(`{"name":"ops","scopes":["everything"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown scope")
+	})
+}
+
+func TestAPIKeyHandler_RevokeAPIKey(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name           string
+		path           string
+		serviceError   error
+		expectedStatus int
+	}{
+		{name: "revoked", path: "/admin/api-keys/" + id.String(), expectedStatus: http.StatusNoContent},
+		{name: "not found", path: "/admin/api-keys/" + id.String(), serviceError: repository.ErrAPIKeyNotFound, expectedStatus: http.StatusNotFound},
+		{name: "invalid id", path: "/admin/api-keys/" + TestData.InvalidUUID, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mocks.MockAPIKeyService{}
+			handler := NewAPIKeyHandler(mockService)
+			mockService.On("RevokeAPIKey", mock.Anything, id).Return(tt.serviceError)
+
+			router := createTripTestRouter(http.MethodDelete, "/admin/api-keys/:id", handler.RevokeAPIKey)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
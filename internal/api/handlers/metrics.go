@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"scootin-aboot/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+type MetricsHandler struct {
+	gatherer prometheus.Gatherer
+}
+
+func NewMetricsHandler(gatherer prometheus.Gatherer) *MetricsHandler {
+	return &MetricsHandler{
+		gatherer: gatherer,
+	}
+}
+
+// GetMetrics writes every metric in the Prometheus exposition format the scraper asks for. A metric that
+// cannot be read, such as the fleet counts while the database is down, is logged and left out rather than
+// failing the scrape.
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	families, err := h.gatherer.Gather()
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("Failed to collect metrics", logger.ErrorField(err))
+	}
+
+	format := expfmt.Negotiate(c.Request.Header)
+	c.Header("Content-Type", string(format))
+	c.Status(http.StatusOK)
+	encoder := expfmt.NewEncoder(c.Writer, format)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			logger.FromContext(c.Request.Context()).Error("Failed to write metrics", logger.ErrorField(err))
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// brokenCollector fails like a metric read from an unreachable database
+type brokenCollector struct {
+	desc *prometheus.Desc
+}
+
+func (b brokenCollector) Describe(ch chan<- *prometheus.Desc) { ch <- b.desc }
+
+func (b brokenCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.NewInvalidMetric(b.desc, errors.New("database is down"))
+}
+
+func TestMetricsHandler_GetMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := prometheus.NewRegistry()
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "scootin_up", Help: "Whether the server is up."})
+	up.Set(1)
+	registry.MustRegister(up, brokenCollector{desc: prometheus.NewDesc("scootin_broken", "Always fails.", nil, nil)})
+	handler := NewMetricsHandler(registry)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+
+	handler.GetMetrics(c)
+
+	assert.Equal(t, http.StatusOK, w.Code, "a failing metric does not fail the scrape")
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), "# TYPE scootin_up gauge\nscootin_up 1\n")
+	assert.NotContains(t, w.Body.String(), "scootin_broken")
+}
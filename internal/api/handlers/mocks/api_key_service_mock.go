@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyService is a mock implementation of APIKeyService
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+// CreateAPIKey mocks the CreateAPIKey method
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, params services.CreateAPIKeyParams) (*services.CreatedAPIKey, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.CreatedAPIKey), args.Error(1)
+}
+
+// ListAPIKeys mocks the ListAPIKeys method
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+// RevokeAPIKey mocks the RevokeAPIKey method
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReservationService is a mock implementation of ReservationService
+type MockReservationService struct {
+	mock.Mock
+}
+
+// ReserveScooter mocks the ReserveScooter method
+func (m *MockReservationService) ReserveScooter(ctx context.Context, scooterID, userID uuid.UUID) (*models.Reservation, error) {
+	args := m.Called(ctx, scooterID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+// CancelReservation mocks the CancelReservation method
+func (m *MockReservationService) CancelReservation(ctx context.Context, scooterID, userID uuid.UUID) (*models.Reservation, error) {
+	args := m.Called(ctx, scooterID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+// ExpireReservations mocks the ExpireReservations method
+func (m *MockReservationService) ExpireReservations(ctx context.Context, at time.Time) (int, error) {
+	args := m.Called(ctx, at)
+	return args.Int(0), args.Error(1)
+}
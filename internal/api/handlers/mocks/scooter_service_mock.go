@@ -37,7 +37,17 @@ func (m *MockScooterService) GetClosestScooters(ctx context.Context, params serv
 	return args.Get(0).(*services.ClosestScootersResult), args.Error(1)
 }
 
-func (m *MockScooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64) error {
-	args := m.Called(ctx, scooterID, lat, lng)
+func (m *MockScooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64, batteryLevel *int) error {
+	args := m.Called(ctx, scooterID, lat, lng, batteryLevel)
 	return args.Error(0)
 }
+
+func (m *MockScooterService) RotateDeviceSecret(ctx context.Context, scooterID uuid.UUID) (string, error) {
+	args := m.Called(ctx, scooterID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockScooterService) GetDevicePublicKey(ctx context.Context, scooterID uuid.UUID) (string, error) {
+	args := m.Called(ctx, scooterID)
+	return args.String(0), args.Error(1)
+}
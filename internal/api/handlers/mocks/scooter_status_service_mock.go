@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockScooterStatusService is a mock implementation of ScooterStatusService
+type MockScooterStatusService struct {
+	mock.Mock
+}
+
+// ChangeStatus mocks the ChangeStatus method
+func (m *MockScooterStatusService) ChangeStatus(ctx context.Context, scooterID uuid.UUID, status models.ScooterStatus, reason, changedBy string) (*models.ScooterStatusChange, error) {
+	args := m.Called(ctx, scooterID, status, reason, changedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ScooterStatusChange), args.Error(1)
+}
+
+// GetStatusHistory mocks the GetStatusHistory method
+func (m *MockScooterStatusService) GetStatusHistory(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error) {
+	args := m.Called(ctx, scooterID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ScooterStatusChange), args.Error(1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/services"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -15,8 +16,8 @@ type MockTripService struct {
 }
 
 // StartTrip mocks the StartTrip method
-func (m *MockTripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error) {
-	args := m.Called(ctx, scooterID, userID, lat, lng)
+func (m *MockTripService) StartTrip(ctx context.Context, tripID, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error) {
+	args := m.Called(ctx, tripID, scooterID, userID, lat, lng)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -24,8 +25,8 @@ func (m *MockTripService) StartTrip(ctx context.Context, scooterID, userID uuid.
 }
 
 // EndTrip mocks the EndTrip method
-func (m *MockTripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error) {
-	args := m.Called(ctx, scooterID, lat, lng)
+func (m *MockTripService) EndTrip(ctx context.Context, tripID, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error) {
+	args := m.Called(ctx, tripID, scooterID, lat, lng)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -33,8 +34,8 @@ func (m *MockTripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat,
 }
 
 // CancelTrip mocks the CancelTrip method
-func (m *MockTripService) CancelTrip(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error) {
-	args := m.Called(ctx, scooterID)
+func (m *MockTripService) CancelTrip(ctx context.Context, tripID, scooterID uuid.UUID) (*models.Trip, error) {
+	args := m.Called(ctx, tripID, scooterID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -73,3 +74,12 @@ func (m *MockTripService) GetTrip(ctx context.Context, tripID uuid.UUID) (*model
 	}
 	return args.Get(0).(*models.Trip), args.Error(1)
 }
+
+// GetTripRoute mocks the GetTripRoute method
+func (m *MockTripService) GetTripRoute(ctx context.Context, tripID uuid.UUID) (*services.TripRoute, error) {
+	args := m.Called(ctx, tripID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TripRoute), args.Error(1)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReservationHandler struct {
+	reservationService services.ReservationService
+}
+
+func NewReservationHandler(reservationService services.ReservationService) *ReservationHandler {
+	return &ReservationHandler{
+		reservationService: reservationService,
+	}
+}
+
+type ReserveScooterRequest struct {
+	// UserID is required for API keys; riders authenticated with a JWT may omit it or must pass their own ID
+	UserID string `json:"user_id" binding:"omitempty,uuid"`
+}
+
+type ReservationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	ScooterID uuid.UUID  `json:"scooter_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Status    string     `json:"status"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (h *ReservationHandler) ReserveScooter(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	// Riders may send no body at all
+	var req ReserveScooterRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	userID, ok := requestedUser(c, req.UserID)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.reservationService.ReserveScooter(c.Request.Context(), scooterID, userID)
+	if err != nil {
+		c.Error(h.mapReservationError(c, "Failed to reserve scooter", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, newReservationResponse(reservation))
+}
+
+// CancelReservation releases the scooter's reservation. Riders can only cancel their own; integrations can
+// cancel any.
+func (h *ReservationHandler) CancelReservation(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	reservation, err := h.reservationService.CancelReservation(c.Request.Context(), scooterID, authenticatedUser(c))
+	if err != nil {
+		c.Error(h.mapReservationError(c, "Failed to cancel reservation", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
+
+// mapReservationError translates reservation service errors into API errors, logging anything unexpected
+func (h *ReservationHandler) mapReservationError(c *gin.Context, msg string, err error) *middleware.APIError {
+	switch {
+	case errors.Is(err, repository.ErrScooterNotFound),
+		errors.Is(err, repository.ErrUserNotFound):
+		return middleware.ErrNotFound
+	case errors.Is(err, services.ErrReservationNotOwned):
+		return errOtherUser
+	case errors.Is(err, services.ErrUserHasActiveTrip),
+		errors.Is(err, services.ErrUserHasReservation),
+		errors.Is(err, services.ErrScooterReserved),
+		errors.Is(err, services.ErrScooterNotAvailable),
+		errors.Is(err, services.ErrNoActiveReservation):
+		return middleware.NewAPIError(http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrActiveReservationExists):
+		// Lost a race with another reservation of the same scooter or by the same user
+		return middleware.NewAPIError(http.StatusConflict, repository.ErrActiveReservationExists.Error())
+	}
+
+	logger.FromContext(c.Request.Context()).Error(msg, logger.ErrorField(err))
+	return middleware.ErrInternalServer
+}
+
+func newReservationResponse(reservation *models.Reservation) ReservationResponse {
+	return ReservationResponse{
+		ID:        reservation.ID,
+		ScooterID: reservation.ScooterID,
+		UserID:    reservation.UserID,
+		Status:    string(reservation.Status),
+		ExpiresAt: reservation.ExpiresAt,
+		EndedAt:   reservation.EndedAt,
+		CreatedAt: reservation.CreatedAt,
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const reservationPath = "/scooters/:id/reservation"
+
+func createValidReservation() *models.Reservation {
+	now := time.Now()
+	return &models.Reservation{
+		ID:        uuid.New(),
+		ScooterID: TestData.ValidScooterID,
+		UserID:    TestData.ValidUserID,
+		Status:    models.ReservationStatusActive,
+		ExpiresAt: now.Add(10 * time.Minute),
+		CreatedAt: now,
+	}
+}
+
+func TestReservationHandler_ReserveScooter(t *testing.T) {
+	tests := []struct {
+		name           string
+		scooterID      string
+		body           string
+		rider          bool
+		serviceErr     error
+		expectReserve  bool
+		expectedStatus int
+	}{
+		{
+			name:           "integration names the user",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           fmt.Sprintf(`{"user_id":"%s"}`, TestData.ValidUserID),
+			expectReserve:  true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "rider without a body",
+			scooterID:      TestData.ValidScooterID.String(),
+			rider:          true,
+			expectReserve:  true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "rider naming another user",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           fmt.Sprintf(`{"user_id":"%s"}`, uuid.New()),
+			rider:          true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "integration without a user",
+			scooterID:      TestData.ValidScooterID.String(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid scooter ID",
+			scooterID:      TestData.InvalidUUID,
+			rider:          true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "scooter held by another rider",
+			scooterID:      TestData.ValidScooterID.String(),
+			rider:          true,
+			serviceErr:     services.ErrScooterReserved,
+			expectReserve:  true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "service failure",
+			scooterID:      TestData.ValidScooterID.String(),
+			rider:          true,
+			serviceErr:     errors.New("connection reset"),
+			expectReserve:  true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReservationService := &mocks.MockReservationService{}
+			handler := NewReservationHandler(mockReservationService)
+			if tt.expectReserve {
+				call := mockReservationService.On("ReserveScooter", mock.Anything, TestData.ValidScooterID, TestData.ValidUserID)
+				if tt.serviceErr != nil {
+					call.Return(nil, tt.serviceErr)
+				} else {
+					call.Return(createValidReservation(), nil)
+				}
+			}
+
+			router := createTripTestRouter(http.MethodPost, reservationPath, handler.ReserveScooter)
+			if tt.rider {
+				router = createRiderTestRouter(http.MethodPost, reservationPath, TestData.ValidUserID, handler.ReserveScooter)
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/scooters/"+tt.scooterID+"/reservation", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.Contains(t, w.Body.String(), `"status":"active"`)
+			}
+			mockReservationService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReservationHandler_CancelReservation(t *testing.T) {
+	tests := []struct {
+		name           string
+		rider          bool
+		serviceErr     error
+		expectedStatus int
+	}{
+		{
+			name:           "integration cancels any reservation",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rider cancels their own reservation",
+			rider:          true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rider cancels another rider's reservation",
+			rider:          true,
+			serviceErr:     services.ErrReservationNotOwned,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no reservation on the scooter",
+			serviceErr:     services.ErrNoActiveReservation,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReservationService := &mocks.MockReservationService{}
+			handler := NewReservationHandler(mockReservationService)
+
+			userID := uuid.Nil
+			router := createTripTestRouter(http.MethodDelete, reservationPath, handler.CancelReservation)
+			if tt.rider {
+				userID = TestData.ValidUserID
+				router = createRiderTestRouter(http.MethodDelete, reservationPath, userID, handler.CancelReservation)
+			}
+
+			call := mockReservationService.On("CancelReservation", mock.Anything, TestData.ValidScooterID, userID)
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				reservation := createValidReservation()
+				reservation.Status = models.ReservationStatusCancelled
+				call.Return(reservation, nil)
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/scooters/"+TestData.ValidScooterID.String()+"/reservation", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockReservationService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"time"
 
+	"scootin-aboot/internal/logger"
 	"scootin-aboot/internal/services"
 
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
@@ -18,21 +20,34 @@ func NewScooterHandler(scooterService services.ScooterService) *ScooterHandler {
 	}
 }
 
+// logScooter names the scooter a request acts on in the request's logs
+func logScooter(c *gin.Context, scooterID uuid.UUID) {
+	c.Request = c.Request.WithContext(logger.WithScooterID(c.Request.Context(), scooterID.String()))
+}
+
 type ScooterQueryParams struct {
-	Status string  `form:"status"`
-	MinLat float64 `form:"min_lat"`
-	MaxLat float64 `form:"max_lat"`
-	MinLng float64 `form:"min_lng"`
-	MaxLng float64 `form:"max_lng"`
-	Limit  int     `form:"limit,default=50"`
-	Offset int     `form:"offset,default=0"`
+	Status         string    `form:"status"`
+	MinLat         float64   `form:"min_lat"`
+	MaxLat         float64   `form:"max_lat"`
+	MinLng         float64   `form:"min_lng"`
+	MaxLng         float64   `form:"max_lng"`
+	LastSeenAfter  time.Time `form:"last_seen_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastSeenBefore time.Time `form:"last_seen_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort           string    `form:"sort"`
+	Order          string    `form:"order"`
+	Latitude       *float64  `form:"lat"`
+	Longitude      *float64  `form:"lng"`
+	Cursor         string    `form:"cursor"`
+	Limit          int       `form:"limit,default=50"`
+	Offset         int       `form:"offset,default=0"`
 }
 
 type ScooterListResponse struct {
-	Scooters []ScooterInfo `json:"scooters"`
-	Total    int64         `json:"total"`
-	Limit    int           `json:"limit"`
-	Offset   int           `json:"offset"`
+	Scooters   []ScooterInfo `json:"scooters"`
+	Total      int64         `json:"total"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 type ScooterInfo struct {
@@ -40,6 +55,7 @@ type ScooterInfo struct {
 	Status           string    `json:"status"`
 	CurrentLatitude  float64   `json:"current_latitude"`
 	CurrentLongitude float64   `json:"current_longitude"`
+	BatteryLevel     *int      `json:"battery_level,omitempty"`
 	LastSeen         time.Time `json:"last_seen"`
 	CreatedAt        time.Time `json:"created_at"`
 }
@@ -49,6 +65,7 @@ type ScooterDetailsResponse struct {
 	Status           string    `json:"status"`
 	CurrentLatitude  float64   `json:"current_latitude"`
 	CurrentLongitude float64   `json:"current_longitude"`
+	BatteryLevel     *int      `json:"battery_level,omitempty"`
 	LastSeen         time.Time `json:"last_seen"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DeviceSecretResponse carries a newly issued device secret; it is not retrievable afterwards
+type DeviceSecretResponse struct {
+	ScooterID uuid.UUID `json:"scooter_id"`
+	Secret    string    `json:"secret"`
+}
+
+func (h *ScooterHandler) RotateDeviceSecret(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	secret, err := h.scooterService.RotateDeviceSecret(c.Request.Context(), scooterID)
+	if err != nil {
+		if errors.Is(err, repository.ErrScooterNotFound) {
+			c.Error(middleware.ErrNotFound)
+			return
+		}
+		logger.FromContext(c.Request.Context()).Error("Failed to rotate device secret", logger.ErrorField(err))
+		c.Error(middleware.ErrInternalServer)
+		return
+	}
+
+	// The request's logs name the scooter and the principal that rotated its secret
+	logger.FromContext(c.Request.Context()).Info("Device secret rotated")
+
+	c.JSON(http.StatusCreated, DeviceSecretResponse{
+		ScooterID: scooterID,
+		Secret:    secret,
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScooterHandler_RotateDeviceSecret(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name           string
+		path           string
+		secret         string
+		serviceError   error
+		expectedStatus int
+	}{
+		{name: "issues a secret", path: "/admin/scooters/" + id.String() + "/device-secret", secret: "dev_secret", expectedStatus: http.StatusCreated},
+		{name: "scooter not found", path: "/admin/scooters/" + id.String() + "/device-secret", serviceError: repository.ErrScooterNotFound, expectedStatus: http.StatusNotFound},
+		{name: "storage failure", path: "/admin/scooters/" + id.String() + "/device-secret", serviceError: errors.New("connection reset"), expectedStatus: http.StatusInternalServerError},
+		{name: "invalid id", path: "/admin/scooters/" + TestData.InvalidUUID + "/device-secret", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mocks.MockScooterService{}
+			handler := createScooterHandler(mockService)
+			mockService.On("RotateDeviceSecret", mock.Anything, id).Return(tt.secret, tt.serviceError)
+
+			router := createTripTestRouter(http.MethodPost, "/admin/scooters/:id/device-secret", handler.RotateDeviceSecret)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var response DeviceSecretResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, id, response.ScooterID)
+				assert.Equal(t, "dev_secret", response.Secret)
+			}
+		})
+	}
+}
@@ -21,27 +21,39 @@ func (h *ScooterHandler) GetScooters(c *gin.Context) {
 	}
 
 	serviceParams := services.ScooterQueryParams{
-		Status: params.Status,
-		MinLat: params.MinLat,
-		MaxLat: params.MaxLat,
-		MinLng: params.MinLng,
-		MaxLng: params.MaxLng,
-		Limit:  params.Limit,
-		Offset: params.Offset,
+		Status:         params.Status,
+		MinLat:         params.MinLat,
+		MaxLat:         params.MaxLat,
+		MinLng:         params.MinLng,
+		MaxLng:         params.MaxLng,
+		LastSeenAfter:  params.LastSeenAfter,
+		LastSeenBefore: params.LastSeenBefore,
+		Sort:           params.Sort,
+		Order:          params.Order,
+		Latitude:       params.Latitude,
+		Longitude:      params.Longitude,
+		Cursor:         params.Cursor,
+		Limit:          params.Limit,
+		Offset:         params.Offset,
 	}
 
 	result, err := h.scooterService.GetScooters(c.Request.Context(), serviceParams)
 	if err != nil {
-		logger.Error("Failed to get scooters", logger.ErrorField(err))
+		if errors.Is(err, services.ErrInvalidQueryParameters) {
+			c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		logger.FromContext(c.Request.Context()).Error("Failed to get scooters", logger.ErrorField(err))
 		c.Error(middleware.ErrInternalServer)
 		return
 	}
 
 	response := ScooterListResponse{
-		Scooters: make([]ScooterInfo, len(result.Scooters)),
-		Total:    result.Total,
-		Limit:    result.Limit,
-		Offset:   result.Offset,
+		Scooters:   make([]ScooterInfo, len(result.Scooters)),
+		Total:      result.Total,
+		Limit:      result.Limit,
+		Offset:     result.Offset,
+		NextCursor: result.NextCursor,
 	}
 
 	for i, scooter := range result.Scooters {
@@ -50,6 +62,7 @@ func (h *ScooterHandler) GetScooters(c *gin.Context) {
 			Status:           scooter.Status,
 			CurrentLatitude:  scooter.CurrentLatitude,
 			CurrentLongitude: scooter.CurrentLongitude,
+			BatteryLevel:     scooter.BatteryLevel,
 			LastSeen:         scooter.LastSeen,
 			CreatedAt:        scooter.CreatedAt,
 		}
@@ -72,7 +85,7 @@ func (h *ScooterHandler) GetScooter(c *gin.Context) {
 			c.Error(middleware.ErrNotFound)
 			return
 		}
-		logger.Error("Failed to get scooter", logger.ErrorField(err))
+		logger.FromContext(c.Request.Context()).Error("Failed to get scooter", logger.ErrorField(err))
 		c.Error(middleware.ErrInternalServer)
 		return
 	}
@@ -82,6 +95,7 @@ func (h *ScooterHandler) GetScooter(c *gin.Context) {
 		Status:           result.Status,
 		CurrentLatitude:  result.CurrentLatitude,
 		CurrentLongitude: result.CurrentLongitude,
+		BatteryLevel:     result.BatteryLevel,
 		LastSeen:         result.LastSeen,
 		CreatedAt:        result.CreatedAt,
 		UpdatedAt:        result.UpdatedAt,
@@ -117,7 +131,11 @@ func (h *ScooterHandler) GetClosestScooters(c *gin.Context) {
 
 	result, err := h.scooterService.GetClosestScooters(c.Request.Context(), serviceParams)
 	if err != nil {
-		logger.Error("Failed to get closest scooters", logger.ErrorField(err))
+		if errors.Is(err, services.ErrInvalidQueryParameters) {
+			c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		logger.FromContext(c.Request.Context()).Error("Failed to get closest scooters", logger.ErrorField(err))
 		c.Error(middleware.ErrInternalServer)
 		return
 	}
@@ -138,6 +156,7 @@ func (h *ScooterHandler) GetClosestScooters(c *gin.Context) {
 				Status:           scooter.Status,
 				CurrentLatitude:  scooter.CurrentLatitude,
 				CurrentLongitude: scooter.CurrentLongitude,
+				BatteryLevel:     scooter.BatteryLevel,
 				LastSeen:         scooter.LastSeen,
 				CreatedAt:        scooter.CreatedAt,
 			},
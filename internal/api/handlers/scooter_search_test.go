@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"scootin-aboot/internal/api/middleware"
 	"scootin-aboot/internal/repository"
@@ -147,6 +149,57 @@ func TestScooterHandler_GetScooters(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockScooterService.AssertExpectations(t)
 	})
+
+	t.Run("maps sorting and cursor parameters", func(t *testing.T) {
+		// Arrange
+		mockScooterService := createMockServices()
+		handler := createScooterHandler(mockScooterService)
+
+		expectedResult := createValidScooterListResult()
+		expectedResult.NextCursor = "next-page"
+		mockScooterService.On("GetScooters", mock.Anything, mock.MatchedBy(func(params services.ScooterQueryParams) bool {
+			return params.Sort == "distance" &&
+				params.Order == "desc" &&
+				params.Latitude != nil && *params.Latitude == 45.5 &&
+				params.Longitude != nil && *params.Longitude == -75.5 &&
+				params.LastSeenAfter.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) &&
+				params.LastSeenBefore.IsZero() &&
+				params.Cursor == "abc"
+		})).Return(expectedResult, nil)
+
+		c, w := setupTestContext("GET", "/api/v1/scooters?sort=distance&order=desc&lat=45.5&lng=-75.5&last_seen_after=2024-01-01T12:00:00Z&cursor=abc", nil)
+
+		// Act
+		handler.GetScooters(c)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response ScooterListResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "next-page", response.NextCursor)
+
+		mockScooterService.AssertExpectations(t)
+	})
+
+	t.Run("service rejects query parameters", func(t *testing.T) {
+		// Arrange
+		mockScooterService := createMockServices()
+		handler := createScooterHandler(mockScooterService)
+
+		mockScooterService.On("GetScooters", mock.Anything, mock.AnythingOfType("services.ScooterQueryParams")).
+			Return(nil, fmt.Errorf("%w: invalid cursor", services.ErrInvalidQueryParameters))
+
+		router := createTestRouter(handler.GetScooters)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test?cursor=bogus", nil)
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusBadRequest, "invalid query parameters: invalid cursor")
+		mockScooterService.AssertExpectations(t)
+	})
 }
 
 func TestScooterHandler_GetScooter(t *testing.T) {
@@ -297,6 +350,9 @@ func TestScooterHandler_GetClosestScooters(t *testing.T) {
 		assert.Len(t, response.Scooters, 1)
 		assert.Equal(t, TestData.ValidScooterID, response.Scooters[0].ID)
 		assert.Equal(t, 500.0, response.Scooters[0].Distance)
+		if assert.NotNil(t, response.Scooters[0].BatteryLevel) {
+			assert.Equal(t, 82, *response.Scooters[0].BatteryLevel)
+		}
 
 		mockScooterService.AssertExpectations(t)
 	})
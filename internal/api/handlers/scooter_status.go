@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScooterStatusHandler struct {
+	statusService services.ScooterStatusService
+}
+
+func NewScooterStatusHandler(statusService services.ScooterStatusService) *ScooterStatusHandler {
+	return &ScooterStatusHandler{
+		statusService: statusService,
+	}
+}
+
+type ChangeScooterStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type StatusHistoryParams struct {
+	Limit  int `form:"limit,default=50"`
+	Offset int `form:"offset,default=0"`
+}
+
+type ScooterStatusChangeResponse struct {
+	ID         uuid.UUID `json:"id"`
+	ScooterID  uuid.UUID `json:"scooter_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedBy  string    `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ScooterStatusHistoryResponse struct {
+	Changes []ScooterStatusChangeResponse `json:"changes"`
+	Limit   int                           `json:"limit"`
+	Offset  int                           `json:"offset"`
+}
+
+// ChangeStatus moves a scooter in or out of service, recording the reason and the API key that made the change
+func (h *ScooterStatusHandler) ChangeStatus(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	var req ChangeScooterStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	var changedBy string
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		changedBy = principal.Name
+	}
+
+	change, err := h.statusService.ChangeStatus(c.Request.Context(), scooterID, models.ScooterStatus(req.Status), req.Reason, changedBy)
+	if err != nil {
+		c.Error(h.mapStatusError(c, "Failed to change scooter status", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newScooterStatusChangeResponse(change))
+}
+
+func (h *ScooterStatusHandler) GetStatusHistory(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	var params StatusHistoryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	changes, err := h.statusService.GetStatusHistory(c.Request.Context(), scooterID, params.Limit, params.Offset)
+	if err != nil {
+		c.Error(h.mapStatusError(c, "Failed to get scooter status history", err))
+		return
+	}
+
+	response := ScooterStatusHistoryResponse{
+		Changes: make([]ScooterStatusChangeResponse, len(changes)),
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+	}
+	for i, change := range changes {
+		response.Changes[i] = newScooterStatusChangeResponse(change)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// mapStatusError translates status service errors into API errors, logging anything unexpected
+func (h *ScooterStatusHandler) mapStatusError(c *gin.Context, msg string, err error) *middleware.APIError {
+	switch {
+	case errors.Is(err, repository.ErrScooterNotFound):
+		return middleware.ErrNotFound
+	case errors.Is(err, services.ErrInvalidStatusChange),
+		errors.Is(err, services.ErrInvalidQueryParameters):
+		return middleware.NewAPIError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrStatusTransitionNotAllowed):
+		return middleware.NewAPIError(http.StatusConflict, err.Error())
+	}
+
+	logger.FromContext(c.Request.Context()).Error(msg, logger.ErrorField(err))
+	return middleware.ErrInternalServer
+}
+
+func newScooterStatusChangeResponse(change *models.ScooterStatusChange) ScooterStatusChangeResponse {
+	return ScooterStatusChangeResponse{
+		ID:         change.ID,
+		ScooterID:  change.ScooterID,
+		FromStatus: string(change.FromStatus),
+		ToStatus:   string(change.ToStatus),
+		Reason:     change.Reason,
+		ChangedBy:  change.ChangedBy,
+		CreatedAt:  change.CreatedAt,
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createStatusTestRouter(method, path string, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandlerMiddleware())
+	router.Use(func(c *gin.Context) {
+		middleware.SetPrincipal(c, &auth.Principal{Name: "fleet-ops", Scopes: []string{models.ScopeAdmin}})
+	})
+	router.Handle(method, path, handler)
+	return router
+}
+
+func TestScooterStatusHandler_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		scooterID      string
+		body           string
+		expectChange   bool
+		serviceErr     error
+		expectedStatus int
+	}{
+		{
+			name:           "scooter sent to maintenance",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           `{"status":"maintenance","reason":"brake check"}`,
+			expectChange:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing reason",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           `{"status":"maintenance"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid scooter ID",
+			scooterID:      TestData.InvalidUUID,
+			body:           `{"status":"maintenance","reason":"brake check"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "status rejected by the service",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           `{"status":"maintenance","reason":"brake check"}`,
+			expectChange:   true,
+			serviceErr:     fmt.Errorf("%w: reason is required", services.ErrInvalidStatusChange),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "illegal transition",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           `{"status":"maintenance","reason":"brake check"}`,
+			expectChange:   true,
+			serviceErr:     fmt.Errorf("%w: %w", services.ErrStatusTransitionNotAllowed, models.ErrInvalidStatusTransition),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "unknown scooter",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           `{"status":"maintenance","reason":"brake check"}`,
+			expectChange:   true,
+			serviceErr:     repository.ErrScooterNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "service failure",
+			scooterID:      TestData.ValidScooterID.String(),
+			body:           `{"status":"maintenance","reason":"brake check"}`,
+			expectChange:   true,
+			serviceErr:     errors.New("connection reset"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStatusService := &mocks.MockScooterStatusService{}
+			handler := NewScooterStatusHandler(mockStatusService)
+			if tt.expectChange {
+				call := mockStatusService.On("ChangeStatus", mock.Anything, TestData.ValidScooterID, models.ScooterStatusMaintenance, "brake check", "fleet-ops")
+				if tt.serviceErr != nil {
+					call.Return(nil, tt.serviceErr)
+				} else {
+					call.Return(&models.ScooterStatusChange{
+						ScooterID:  TestData.ValidScooterID,
+						FromStatus: models.ScooterStatusAvailable,
+						ToStatus:   models.ScooterStatusMaintenance,
+						Reason:     "brake check",
+						ChangedBy:  "fleet-ops",
+						CreatedAt:  time.Now(),
+					}, nil)
+				}
+			}
+
+			router := createStatusTestRouter(http.MethodPost, "/scooters/:id/status", handler.ChangeStatus)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/scooters/"+tt.scooterID+"/status", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"to_status":"maintenance"`)
+				assert.Contains(t, w.Body.String(), `"changed_by":"fleet-ops"`)
+			}
+			mockStatusService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestScooterStatusHandler_GetStatusHistory(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		limit          int
+		serviceErr     error
+		expectedStatus int
+	}{
+		{
+			name:           "default page",
+			limit:          50,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "limit out of range",
+			query:          "?limit=500",
+			limit:          500,
+			serviceErr:     services.ErrInvalidQueryParameters,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown scooter",
+			limit:          50,
+			serviceErr:     repository.ErrScooterNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStatusService := &mocks.MockScooterStatusService{}
+			handler := NewScooterStatusHandler(mockStatusService)
+
+			call := mockStatusService.On("GetStatusHistory", mock.Anything, TestData.ValidScooterID, tt.limit, 0)
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				call.Return([]*models.ScooterStatusChange{{
+					ScooterID:  TestData.ValidScooterID,
+					FromStatus: models.ScooterStatusMaintenance,
+					ToStatus:   models.ScooterStatusAvailable,
+					Reason:     "repaired",
+					ChangedBy:  "fleet-ops",
+				}}, nil)
+			}
+
+			router := createStatusTestRouter(http.MethodGet, "/scooters/:id/status-history", handler.GetStatusHistory)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/scooters/"+TestData.ValidScooterID.String()+"/status-history"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"reason":"repaired"`)
+			}
+			mockStatusService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
+	"scootin-aboot/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxStreamRadius bounds the radius of a scooter stream, in meters, as it does for closest scooter searches
+const maxStreamRadius = 50000
+
+type ScooterStreamHandler struct {
+	hub *stream.Hub
+	// heartbeatInterval is how often an idle stream is sent a comment
+	heartbeatInterval time.Duration
+}
+
+func NewScooterStreamHandler(hub *stream.Hub, heartbeatInterval time.Duration) *ScooterStreamHandler {
+	return &ScooterStreamHandler{
+		hub:               hub,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// ScooterStreamParams select the scooters to stream, by bounding box or by radius around a point
+type ScooterStreamParams struct {
+	MinLat    *float64 `form:"min_lat"`
+	MaxLat    *float64 `form:"max_lat"`
+	MinLng    *float64 `form:"min_lng"`
+	MaxLng    *float64 `form:"max_lng"`
+	Latitude  *float64 `form:"lat"`
+	Longitude *float64 `form:"lng"`
+	Radius    *float64 `form:"radius"` // in meters
+	// LastEventID resumes the stream for clients that cannot set the Last-Event-ID header
+	LastEventID string `form:"last_event_id"`
+}
+
+// ScooterStreamEventResponse is the data of a location or status event
+type ScooterStreamEventResponse struct {
+	ScooterID      uuid.UUID `json:"scooter_id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	BatteryLevel   *int      `json:"battery_level,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// StreamScooters pushes scooter location and status changes as Server-Sent Events until the client goes
+// away. Clients resume with Last-Event-ID; a reset event tells them the changes they missed are gone and
+// they have to reload the scooters. Clients that cannot keep up are disconnected and resume the same way.
+func (h *ScooterStreamHandler) StreamScooters(c *gin.Context) {
+	var params ScooterStreamParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	area, err := newScooterStreamArea(params)
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = params.LastEventID
+	}
+
+	subscription, replay, complete := h.hub.Subscribe(lastEventID)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	w := &scooterStreamWriter{hub: h.hub, c: c, area: area, inside: make(map[uuid.UUID]bool)}
+	if !complete {
+		w.reset()
+	}
+	for _, event := range replay {
+		w.send(event)
+	}
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for w.err == nil {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-subscription.Done():
+			// Dropped for falling behind, or shutting down; either way the client reconnects and resumes
+			return
+		case <-heartbeat.C:
+			w.write(": heartbeat\n\n")
+		case event := <-subscription.Events():
+			w.send(event)
+		}
+	}
+}
+
+// scooterStreamArea is the part of the map a client streams; a nil area is everywhere
+type scooterStreamArea struct {
+	minLat, maxLat, minLng, maxLng float64
+	// radius, in meters, makes the area a circle around lat, lng instead of a box
+	lat, lng, radius float64
+}
+
+func newScooterStreamArea(params ScooterStreamParams) (*scooterStreamArea, error) {
+	box := params.MinLat != nil || params.MaxLat != nil || params.MinLng != nil || params.MaxLng != nil
+	circle := params.Latitude != nil || params.Longitude != nil || params.Radius != nil
+
+	switch {
+	case box && circle:
+		return nil, errors.New("use either a bounding box or a radius, not both")
+	case box:
+		if params.MinLat == nil || params.MaxLat == nil || params.MinLng == nil || params.MaxLng == nil {
+			return nil, errors.New("a bounding box needs min_lat, max_lat, min_lng and max_lng")
+		}
+		if err := validation.ValidateCoordinates(*params.MinLat, *params.MinLng); err != nil {
+			return nil, err
+		}
+		if err := validation.ValidateCoordinates(*params.MaxLat, *params.MaxLng); err != nil {
+			return nil, err
+		}
+		if *params.MinLat > *params.MaxLat || *params.MinLng > *params.MaxLng {
+			return nil, errors.New("min_lat and min_lng cannot exceed max_lat and max_lng")
+		}
+		return &scooterStreamArea{minLat: *params.MinLat, maxLat: *params.MaxLat, minLng: *params.MinLng, maxLng: *params.MaxLng}, nil
+	case circle:
+		if params.Latitude == nil || params.Longitude == nil || params.Radius == nil {
+			return nil, errors.New("a radius needs lat, lng and radius")
+		}
+		if err := validation.ValidateCoordinates(*params.Latitude, *params.Longitude); err != nil {
+			return nil, err
+		}
+		if *params.Radius <= 0 || *params.Radius > maxStreamRadius {
+			return nil, fmt.Errorf("radius must be between 0 and %d meters", maxStreamRadius)
+		}
+		return &scooterStreamArea{lat: *params.Latitude, lng: *params.Longitude, radius: *params.Radius}, nil
+	}
+	return nil, nil
+}
+
+func (a *scooterStreamArea) contains(lat, lng float64) bool {
+	if a == nil {
+		return true
+	}
+	if a.radius > 0 {
+		return repository.HaversineDistance(a.lat, a.lng, lat, lng)*1000 <= a.radius
+	}
+	return lat >= a.minLat && lat <= a.maxLat && lng >= a.minLng && lng <= a.maxLng
+}
+
+// scooterStreamWriter writes events to one client, remembering the first write error
+type scooterStreamWriter struct {
+	hub  *stream.Hub
+	c    *gin.Context
+	area *scooterStreamArea
+	// inside holds the scooters last reported in the area, so that one leaving it is sent once more and
+	// the client can take it off the map
+	inside map[uuid.UUID]bool
+	err    error
+}
+
+func (w *scooterStreamWriter) send(event stream.ScooterEvent) {
+	if w.area.contains(event.Latitude, event.Longitude) {
+		w.inside[event.ScooterID] = true
+	} else if w.inside[event.ScooterID] {
+		delete(w.inside, event.ScooterID)
+	} else {
+		return
+	}
+
+	// Marshalling a struct of plain values cannot fail
+	data, _ := json.Marshal(ScooterStreamEventResponse{
+		ScooterID:      event.ScooterID,
+		Status:         string(event.Status),
+		PreviousStatus: string(event.PreviousStatus),
+		Latitude:       event.Latitude,
+		Longitude:      event.Longitude,
+		BatteryLevel:   event.BatteryLevel,
+		Timestamp:      event.Timestamp,
+	})
+	w.write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", w.hub.EventID(event), event.Type, data))
+}
+
+func (w *scooterStreamWriter) reset() {
+	w.write("event: reset\ndata: {}\n\n")
+}
+
+func (w *scooterStreamWriter) write(message string) {
+	if w.err != nil {
+		return
+	}
+	if _, w.err = w.c.Writer.WriteString(message); w.err == nil {
+		w.c.Writer.Flush()
+	}
+}
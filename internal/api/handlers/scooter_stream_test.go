@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/stream"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nearbyEvent is a location report at the test location, offset east by lngOffset degrees
+func nearbyEvent(scooterID uuid.UUID, lngOffset float64) stream.ScooterEvent {
+	return stream.ScooterEvent{
+		Type:      stream.EventTypeLocation,
+		ScooterID: scooterID,
+		Status:    models.ScooterStatusAvailable,
+		Latitude:  TestData.ValidLatitude,
+		Longitude: TestData.ValidLongitude + lngOffset,
+	}
+}
+
+// publishMarker publishes an event and returns its ID, for resuming the stream right after it
+func publishMarker(t *testing.T, hub *stream.Hub) string {
+	observer, _, _ := hub.Subscribe("")
+	defer observer.Close()
+	hub.Publish(nearbyEvent(uuid.New(), 0))
+	return hub.EventID(<-observer.Events())
+}
+
+// streamOnce serves the stream with the request already cancelled, so it writes what it would on
+// connecting and returns
+func streamOnce(hub *stream.Hub, query, lastEventID string) *httptest.ResponseRecorder {
+	handler := NewScooterStreamHandler(hub, time.Minute)
+	router := createTripTestRouter(http.MethodGet, "/scooters/stream", handler.StreamScooters)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/scooters/stream"+query, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestScooterStreamHandler_StreamScooters(t *testing.T) {
+	t.Run("delivers changes as they happen", func(t *testing.T) {
+		hub := stream.NewHub(10, 10)
+		handler := NewScooterStreamHandler(hub, time.Minute)
+		server := httptest.NewServer(createTripTestRouter(http.MethodGet, "/scooters/stream", handler.StreamScooters))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/scooters/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+		// The headers are sent once the client is subscribed
+		scooterID := uuid.New()
+		battery := 80
+		event := nearbyEvent(scooterID, 0)
+		event.BatteryLevel = &battery
+		hub.Publish(event)
+
+		reader := bufio.NewReader(resp.Body)
+		var lines []string
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		assert.Regexp(t, `^id: \d+-1$`, lines[0])
+		assert.Equal(t, "event: location", lines[1])
+		assert.Contains(t, lines[2], `"scooter_id":"`+scooterID.String()+`"`)
+		assert.Contains(t, lines[2], `"battery_level":80`)
+
+		hub.Close()
+	})
+
+	t.Run("resumes after Last-Event-ID", func(t *testing.T) {
+		hub := stream.NewHub(10, 10)
+		lastEventID := publishMarker(t, hub)
+		hub.Publish(nearbyEvent(uuid.New(), 0))
+		hub.Publish(nearbyEvent(uuid.New(), 0))
+
+		w := streamOnce(hub, "", lastEventID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 2, strings.Count(w.Body.String(), "event: location"))
+		assert.NotContains(t, w.Body.String(), "event: reset")
+	})
+
+	t.Run("resumes from the query string", func(t *testing.T) {
+		hub := stream.NewHub(10, 10)
+		lastEventID := publishMarker(t, hub)
+		hub.Publish(nearbyEvent(uuid.New(), 0))
+
+		w := streamOnce(hub, "?last_event_id="+lastEventID, "")
+
+		assert.Equal(t, 1, strings.Count(w.Body.String(), "event: location"))
+	})
+
+	t.Run("resets when missed changes are gone", func(t *testing.T) {
+		hub := stream.NewHub(1, 10)
+		lastEventID := publishMarker(t, hub)
+		hub.Publish(nearbyEvent(uuid.New(), 0))
+		hub.Publish(nearbyEvent(uuid.New(), 0))
+
+		w := streamOnce(hub, "", lastEventID)
+
+		assert.True(t, strings.HasPrefix(w.Body.String(), "event: reset\n"))
+		assert.NotContains(t, w.Body.String(), "event: location")
+	})
+
+	t.Run("filters by bounding box", func(t *testing.T) {
+		hub := stream.NewHub(10, 10)
+		lastEventID := publishMarker(t, hub)
+		inside := uuid.New()
+		hub.Publish(nearbyEvent(inside, 0))
+		hub.Publish(nearbyEvent(uuid.New(), 1))
+
+		w := streamOnce(hub, "?min_lat=52.5&max_lat=52.6&min_lng=13.3&max_lng=13.5", lastEventID)
+
+		assert.Equal(t, 1, strings.Count(w.Body.String(), "event: location"))
+		assert.Contains(t, w.Body.String(), inside.String())
+	})
+
+	t.Run("filters by radius and sends scooters leaving it once more", func(t *testing.T) {
+		hub := stream.NewHub(10, 10)
+		lastEventID := publishMarker(t, hub)
+		leaving := uuid.New()
+		hub.Publish(nearbyEvent(leaving, 0))
+		hub.Publish(nearbyEvent(leaving, 1))
+		hub.Publish(nearbyEvent(leaving, 2))
+		hub.Publish(nearbyEvent(uuid.New(), 1))
+
+		w := streamOnce(hub, "?lat=52.52&lng=13.405&radius=500", lastEventID)
+
+		assert.Equal(t, 2, strings.Count(w.Body.String(), "event: location"))
+	})
+
+	t.Run("status changes", func(t *testing.T) {
+		hub := stream.NewHub(10, 10)
+		lastEventID := publishMarker(t, hub)
+		event := nearbyEvent(uuid.New(), 0)
+		event.Type = stream.EventTypeStatus
+		event.Status = models.ScooterStatusOccupied
+		event.PreviousStatus = models.ScooterStatusAvailable
+		hub.Publish(event)
+
+		w := streamOnce(hub, "", lastEventID)
+
+		assert.Contains(t, w.Body.String(), "event: status\n")
+		assert.Contains(t, w.Body.String(), `"status":"occupied","previous_status":"available"`)
+	})
+
+	for _, tt := range []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"box and radius", "?min_lat=45&max_lat=46&min_lng=-76&max_lng=-75&lat=45.4&lng=-75.7&radius=100", "use either a bounding box or a radius, not both"},
+		{"incomplete box", "?min_lat=45&max_lat=46", "a bounding box needs min_lat, max_lat, min_lng and max_lng"},
+		{"inverted box", "?min_lat=46&max_lat=45&min_lng=-76&max_lng=-75", "min_lat and min_lng cannot exceed max_lat and max_lng"},
+		{"radius without center", "?radius=100", "a radius needs lat, lng and radius"},
+		{"radius too large", "?lat=45.4&lng=-75.7&radius=60000", "radius must be between 0 and 50000 meters"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := streamOnce(stream.NewHub(10, 10), tt.query, "")
+			assertErrorResponse(t, w, http.StatusBadRequest, tt.message)
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 	"scootin-aboot/internal/api/handlers/mocks"
 	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/models"
 	"scootin-aboot/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -128,6 +130,30 @@ func createScooterHandler(mockScooterService *mocks.MockScooterService) *Scooter
 	return NewScooterHandler(mockScooterService)
 }
 
+func createTripHandler(mockTripService *mocks.MockTripService) *TripHandler {
+	return NewTripHandler(mockTripService)
+}
+
+func createTripTestRouter(method, path string, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandlerMiddleware())
+	router.Handle(method, path, handler)
+	return router
+}
+
+// createRiderTestRouter is createTripTestRouter for requests authenticated as the rider userID
+func createRiderTestRouter(method, path string, userID uuid.UUID, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandlerMiddleware())
+	router.Use(func(c *gin.Context) {
+		middleware.SetPrincipal(c, &auth.Principal{UserID: userID, Scopes: models.UserScopes})
+	})
+	router.Handle(method, path, handler)
+	return router
+}
+
 func assertJSONResponse(t *testing.T, expected interface{}, actual string) {
 	expectedJSON, err := json.Marshal(expected)
 	assert.NoError(t, err)
@@ -203,6 +229,7 @@ func createValidScooterDetailsResultWithTrip() *services.ScooterDetailsResult {
 }
 
 func createValidClosestScootersResult() *services.ClosestScootersResult {
+	batteryLevel := 82
 	return &services.ClosestScootersResult{
 		Scooters: []*services.ScooterWithDistance{
 			{
@@ -211,6 +238,7 @@ func createValidClosestScootersResult() *services.ClosestScootersResult {
 					Status:           TestData.ValidStatus,
 					CurrentLatitude:  TestData.ValidLatitude,
 					CurrentLongitude: TestData.ValidLongitude,
+					BatteryLevel:     &batteryLevel,
 					LastSeen:         time.Now(),
 					CreatedAt:        time.Now().Add(-time.Hour),
 				},
@@ -224,3 +252,36 @@ func createValidClosestScootersResult() *services.ClosestScootersResult {
 		Radius: TestData.ValidRadius,
 	}
 }
+
+func createValidActiveTrip() *models.Trip {
+	return &models.Trip{
+		ID:             TestData.ValidTripID,
+		ScooterID:      TestData.ValidScooterID,
+		UserID:         TestData.ValidUserID,
+		Status:         models.TripStatusActive,
+		StartTime:      time.Now().Add(-10 * time.Minute),
+		StartLatitude:  TestData.ValidLatitude,
+		StartLongitude: TestData.ValidLongitude,
+	}
+}
+
+func createValidCompletedTrip() *models.Trip {
+	trip := createValidActiveTrip()
+	endTime := trip.StartTime.Add(10 * time.Minute)
+	endLat := TestData.ValidLatitude + 0.01
+	endLng := TestData.ValidLongitude + 0.01
+	trip.Status = models.TripStatusCompleted
+	trip.EndTime = &endTime
+	trip.EndLatitude = &endLat
+	trip.EndLongitude = &endLng
+	trip.Fare = &models.Fare{
+		Currency:       "CAD",
+		Tariff:         "default",
+		Minutes:        10,
+		DistanceMeters: 1360,
+		UnlockFee:      100,
+		TimeCharge:     350,
+		Total:          450,
+	}
+	return trip
+}
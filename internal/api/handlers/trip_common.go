@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TripHandler struct {
+	tripService services.TripService
+}
+
+func NewTripHandler(tripService services.TripService) *TripHandler {
+	return &TripHandler{
+		tripService: tripService,
+	}
+}
+
+// logTrip names the trip a request acts on in the request's logs
+func logTrip(c *gin.Context, tripID uuid.UUID) {
+	c.Request = c.Request.WithContext(logger.WithTripID(c.Request.Context(), tripID.String()))
+}
+
+type StartTripRequest struct {
+	TripID    string `json:"trip_id" binding:"omitempty,uuid"`
+	ScooterID string `json:"scooter_id" binding:"required,uuid"`
+	// UserID is required for API keys; riders authenticated with a JWT may omit it or must pass their own ID
+	UserID string `json:"user_id" binding:"omitempty,uuid"`
+	// Coordinates are pointers so that required accepts 0, which is a valid latitude and longitude
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+}
+
+type EndTripRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+}
+
+type TripResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	ScooterID       uuid.UUID  `json:"scooter_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Status          string     `json:"status"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	StartLatitude   float64    `json:"start_latitude"`
+	StartLongitude  float64    `json:"start_longitude"`
+	EndLatitude     *float64   `json:"end_latitude,omitempty"`
+	EndLongitude    *float64   `json:"end_longitude,omitempty"`
+	DurationSeconds *int64     `json:"duration_seconds,omitempty"`
+	// Fare is set on completed trips when pricing is configured
+	Fare *FareResponse `json:"fare,omitempty"`
+}
+
+// FareResponse breaks down a trip's fare; amounts are in minor units of the currency, e.g. cents
+type FareResponse struct {
+	Currency       string  `json:"currency"`
+	Tariff         string  `json:"tariff"`
+	Minutes        int64   `json:"minutes"`
+	DistanceMeters float64 `json:"distance_meters"`
+	UnlockFee      int64   `json:"unlock_fee"`
+	TimeCharge     int64   `json:"time_charge"`
+	DistanceCharge int64   `json:"distance_charge"`
+	Adjustment     int64   `json:"adjustment"`
+	Total          int64   `json:"total"`
+}
+
+// errOtherUser rejects a rider acting on another user's behalf
+var errOtherUser = middleware.NewAPIError(http.StatusForbidden, "Cannot act on behalf of another user")
+
+// authenticatedUser returns the rider a JWT-authenticated request acts for, or uuid.Nil for API keys
+func authenticatedUser(c *gin.Context) uuid.UUID {
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		return principal.UserID
+	}
+	return uuid.Nil
+}
+
+// requestedUser resolves the user a request acts for. Riders always act for themselves; integrations name
+// the user in the body. It reports false after writing an error.
+func requestedUser(c *gin.Context, requestedUserID string) (uuid.UUID, bool) {
+	userID := authenticatedUser(c)
+	switch {
+	case requestedUserID == "" && userID == uuid.Nil:
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "user_id is required"))
+		return uuid.Nil, false
+	case requestedUserID != "":
+		requested, err := uuid.Parse(requestedUserID)
+		if err != nil {
+			c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid user ID"))
+			return uuid.Nil, false
+		}
+		if userID != uuid.Nil && requested != userID {
+			c.Error(errOtherUser)
+			return uuid.Nil, false
+		}
+		userID = requested
+	}
+	return userID, true
+}
+
+func newTripResponse(trip *models.Trip) TripResponse {
+	response := TripResponse{
+		ID:             trip.ID,
+		ScooterID:      trip.ScooterID,
+		UserID:         trip.UserID,
+		Status:         string(trip.Status),
+		StartTime:      trip.StartTime,
+		EndTime:        trip.EndTime,
+		StartLatitude:  trip.StartLatitude,
+		StartLongitude: trip.StartLongitude,
+		EndLatitude:    trip.EndLatitude,
+		EndLongitude:   trip.EndLongitude,
+	}
+
+	if duration := trip.Duration(); duration != nil {
+		seconds := int64(duration.Seconds())
+		response.DurationSeconds = &seconds
+	}
+
+	if fare := trip.Fare; fare != nil {
+		response.Fare = &FareResponse{
+			Currency:       fare.Currency,
+			Tariff:         fare.Tariff,
+			Minutes:        fare.Minutes,
+			DistanceMeters: fare.DistanceMeters,
+			UnlockFee:      fare.UnlockFee,
+			TimeCharge:     fare.TimeCharge,
+			DistanceCharge: fare.DistanceCharge,
+			Adjustment:     fare.Adjustment,
+			Total:          fare.Total,
+		}
+	}
+
+	return response
+}
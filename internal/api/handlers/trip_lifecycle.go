@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+	"scootin-aboot/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *TripHandler) StartTrip(c *gin.Context) {
+	var req StartTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := validation.ValidateCoordinates(*req.Latitude, *req.Longitude); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	scooterID, err := uuid.Parse(req.ScooterID)
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	userID, ok := requestedUser(c, req.UserID)
+	if !ok {
+		return
+	}
+
+	tripID := uuid.Nil
+	if req.TripID != "" {
+		tripID, err = uuid.Parse(req.TripID)
+		if err != nil {
+			c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid trip ID"))
+			return
+		}
+	}
+
+	trip, err := h.tripService.StartTrip(c.Request.Context(), tripID, scooterID, userID, *req.Latitude, *req.Longitude)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to start trip", err))
+		return
+	}
+	logTrip(c, trip.ID)
+
+	c.JSON(http.StatusCreated, newTripResponse(trip))
+}
+
+func (h *TripHandler) EndTrip(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	var req EndTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := validation.ValidateCoordinates(*req.Latitude, *req.Longitude); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	tripID, ok := h.authorizeScooterTrip(c, scooterID)
+	if !ok {
+		return
+	}
+
+	trip, err := h.tripService.EndTrip(c.Request.Context(), tripID, scooterID, *req.Latitude, *req.Longitude)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to end trip", err))
+		return
+	}
+	logTrip(c, trip.ID)
+
+	c.JSON(http.StatusOK, newTripResponse(trip))
+}
+
+func (h *TripHandler) CancelTrip(c *gin.Context) {
+	scooterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid scooter ID"))
+		return
+	}
+	logScooter(c, scooterID)
+
+	tripID, ok := h.authorizeScooterTrip(c, scooterID)
+	if !ok {
+		return
+	}
+
+	trip, err := h.tripService.CancelTrip(c.Request.Context(), tripID, scooterID)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to cancel trip", err))
+		return
+	}
+	logTrip(c, trip.ID)
+
+	c.JSON(http.StatusOK, newTripResponse(trip))
+}
+
+func (h *TripHandler) GetTrip(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid trip ID"))
+		return
+	}
+	logTrip(c, tripID)
+
+	trip, err := h.tripService.GetTrip(c.Request.Context(), tripID)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to get trip", err))
+		return
+	}
+	// Another rider's trip is reported as missing rather than revealing that it exists
+	if userID := authenticatedUser(c); userID != uuid.Nil && trip.UserID != userID {
+		c.Error(middleware.ErrNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTripResponse(trip))
+}
+
+func (h *TripHandler) GetActiveTripByUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid user ID"))
+		return
+	}
+	if authenticated := authenticatedUser(c); authenticated != uuid.Nil && authenticated != userID {
+		c.Error(errOtherUser)
+		return
+	}
+
+	trip, err := h.tripService.GetActiveTripByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to get active trip", err))
+		return
+	}
+	if trip == nil {
+		c.Error(middleware.ErrNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTripResponse(trip))
+}
+
+// authorizeScooterTrip checks that a rider ends or cancels only their own trip. For riders it returns the ID of
+// the scooter's active trip, so the service rejects the request if that trip changes before it runs; for
+// integrations it returns uuid.Nil. It reports false after writing an error.
+func (h *TripHandler) authorizeScooterTrip(c *gin.Context, scooterID uuid.UUID) (uuid.UUID, bool) {
+	userID := authenticatedUser(c)
+	if userID == uuid.Nil {
+		return uuid.Nil, true
+	}
+
+	trip, err := h.tripService.GetActiveTrip(c.Request.Context(), scooterID)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to get active trip", err))
+		return uuid.Nil, false
+	}
+	if trip == nil {
+		c.Error(middleware.NewAPIError(http.StatusConflict, services.ErrNoActiveTripOnScooter.Error()))
+		return uuid.Nil, false
+	}
+	if trip.UserID != userID {
+		c.Error(errOtherUser)
+		return uuid.Nil, false
+	}
+
+	return trip.ID, true
+}
+
+// mapTripError translates trip service errors into API errors, logging anything unexpected
+func (h *TripHandler) mapTripError(c *gin.Context, msg string, err error) *middleware.APIError {
+	switch {
+	case errors.Is(err, services.ErrInvalidCoordinates):
+		return middleware.NewAPIError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrTripNotFound),
+		errors.Is(err, repository.ErrScooterNotFound),
+		errors.Is(err, repository.ErrUserNotFound):
+		return middleware.ErrNotFound
+	case errors.Is(err, services.ErrUserHasActiveTrip),
+		errors.Is(err, services.ErrScooterNotAvailable),
+		errors.Is(err, services.ErrScooterReserved),
+		errors.Is(err, services.ErrScooterBatteryLow),
+		errors.Is(err, services.ErrScooterHasActiveTrip),
+		errors.Is(err, services.ErrNoActiveTripOnScooter),
+		errors.Is(err, services.ErrTripAlreadyExists),
+		errors.Is(err, services.ErrTripMismatch),
+		errors.Is(err, services.ErrParkingNotAllowed):
+		return middleware.NewAPIError(http.StatusConflict, err.Error())
+	}
+
+	logger.FromContext(c.Request.Context()).Error(msg, logger.ErrorField(err))
+	return middleware.ErrInternalServer
+}
@@ -0,0 +1,523 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/geofence"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+)
+
+func TestTripHandler_StartTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validBody := fmt.Sprintf(`{"scooter_id":"%s","user_id":"%s","latitude":%f,"longitude":%f}`,
+		TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude)
+
+	t.Run("successful trip start", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("StartTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude).
+			Return(createValidActiveTrip(), nil)
+
+		router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(validBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response TripResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, TestData.ValidTripID, response.ID)
+		assert.Equal(t, string(models.TripStatusActive), response.Status)
+		assert.Nil(t, response.EndTime)
+		assert.Nil(t, response.DurationSeconds)
+
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("client supplied trip ID", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("StartTrip", mock.Anything, TestData.ValidTripID, TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude).
+			Return(createValidActiveTrip(), nil)
+
+		body := fmt.Sprintf(`{"trip_id":"%s","scooter_id":"%s","user_id":"%s","latitude":%f,"longitude":%f}`,
+			TestData.ValidTripID, TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude)
+
+		router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("missing required fields", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(`{"latitude":45.4215}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockTripService.AssertNotCalled(t, "StartTrip")
+	})
+
+	t.Run("zero coordinates", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("StartTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidUserID, 0.0, 0.0).
+			Return(createValidActiveTrip(), nil)
+
+		body := fmt.Sprintf(`{"scooter_id":"%s","user_id":"%s","latitude":0.0,"longitude":0}`, TestData.ValidScooterID, TestData.ValidUserID)
+
+		router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("invalid coordinates", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		body := fmt.Sprintf(`{"scooter_id":"%s","user_id":"%s","latitude":%f,"longitude":%f}`,
+			TestData.ValidScooterID, TestData.ValidUserID, TestData.InvalidLatitude, TestData.ValidLongitude)
+
+		router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusBadRequest, "invalid latitude: must be between -90 and 90")
+		mockTripService.AssertNotCalled(t, "StartTrip")
+	})
+
+	errorCases := []struct {
+		name            string
+		serviceErr      error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{"user not found", repository.ErrUserNotFound, http.StatusNotFound, "Resource not found"},
+		{"scooter not found", fmt.Errorf("failed to get scooter: %w", repository.ErrScooterNotFound), http.StatusNotFound, "Resource not found"},
+		{"user already has active trip", services.ErrUserHasActiveTrip, http.StatusConflict, "user already has an active trip"},
+		{"scooter not available", services.ErrScooterNotAvailable, http.StatusConflict, "scooter is not available"},
+		{"scooter battery too low", services.ErrScooterBatteryLow, http.StatusConflict, "scooter battery is too low to start a trip"},
+		{"trip ID already exists", services.ErrTripAlreadyExists, http.StatusConflict, "trip already exists"},
+		{"unexpected error", assert.AnError, http.StatusInternalServerError, "Internal server error"},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mockTripService := &mocks.MockTripService{}
+			handler := createTripHandler(mockTripService)
+
+			mockTripService.On("StartTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude).
+				Return(nil, tc.serviceErr)
+
+			router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(validBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assertErrorResponse(t, w, tc.expectedStatus, tc.expectedMessage)
+			mockTripService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTripHandler_LogsUnexpectedErrorsWithRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+
+	mockTripService := &mocks.MockTripService{}
+	handler := createTripHandler(mockTripService)
+	mockTripService.On("StartTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude).
+		Return(nil, assert.AnError)
+
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware(), middleware.ErrorHandlerMiddleware())
+	router.POST("/trips", handler.StartTrip)
+
+	body := fmt.Sprintf(`{"scooter_id":%q,"user_id":%q,"latitude":%f,"longitude":%f}`,
+		TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	entries := logs.FilterMessage("Failed to start trip").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "req-42", entries[0].ContextMap()["request_id"])
+		assert.Equal(t, assert.AnError.Error(), entries[0].ContextMap()["error"])
+	}
+}
+
+func TestTripHandler_EndTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validBody := fmt.Sprintf(`{"latitude":%f,"longitude":%f}`, TestData.ValidLatitude, TestData.ValidLongitude)
+
+	t.Run("successful trip end", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("EndTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).
+			Return(createValidCompletedTrip(), nil)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/end", handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/end", strings.NewReader(validBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response TripResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, string(models.TripStatusCompleted), response.Status)
+		assert.NotNil(t, response.EndTime)
+		assert.NotNil(t, response.DurationSeconds)
+		assert.Equal(t, int64(600), *response.DurationSeconds)
+		require.NotNil(t, response.Fare)
+		assert.Equal(t, "CAD", response.Fare.Currency)
+		assert.Equal(t, int64(450), response.Fare.Total)
+
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("zero coordinates", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("EndTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, 0.0, 0.0).
+			Return(createValidCompletedTrip(), nil)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/end", handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/end", strings.NewReader(`{"latitude":0.0,"longitude":0}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("missing coordinates", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/end", handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/end", strings.NewReader(`{"latitude":45.4215}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockTripService.AssertNotCalled(t, "EndTrip")
+	})
+
+	t.Run("invalid scooter ID", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/end", handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.InvalidUUID+"/trip/end", strings.NewReader(validBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusBadRequest, "Invalid scooter ID")
+		mockTripService.AssertNotCalled(t, "EndTrip")
+	})
+
+	t.Run("no active trip", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("EndTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).
+			Return(nil, services.ErrNoActiveTripOnScooter)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/end", handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/end", strings.NewReader(validBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusConflict, "no active trip found for scooter")
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("parking not allowed", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("EndTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).
+			Return(nil, fmt.Errorf("%w: %w", services.ErrParkingNotAllowed, geofence.ErrOutsideServiceArea))
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/end", handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/end", strings.NewReader(validBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusConflict, "trip cannot end here: location is outside the service area")
+		mockTripService.AssertExpectations(t)
+	})
+}
+
+func TestTripHandler_CancelTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful trip cancel", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		trip := createValidActiveTrip()
+		trip.Status = models.TripStatusCancelled
+		mockTripService.On("CancelTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID).Return(trip, nil)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/cancel", handler.CancelTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/cancel", nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response TripResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, string(models.TripStatusCancelled), response.Status)
+
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("no active trip", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("CancelTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID).Return(nil, services.ErrNoActiveTripOnScooter)
+
+		router := createTripTestRouter(http.MethodPost, "/scooters/:id/trip/cancel", handler.CancelTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/scooters/"+TestData.ValidScooterID.String()+"/trip/cancel", nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusConflict, "no active trip found for scooter")
+		mockTripService.AssertExpectations(t)
+	})
+}
+
+func TestTripHandler_GetTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful request", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidActiveTrip(), nil)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id", handler.GetTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/trips/"+TestData.ValidTripID.String(), nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response TripResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, TestData.ValidTripID, response.ID)
+		assert.Equal(t, TestData.ValidScooterID, response.ScooterID)
+
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("invalid trip ID", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id", handler.GetTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/trips/"+TestData.InvalidUUID, nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusBadRequest, "Invalid trip ID")
+		mockTripService.AssertNotCalled(t, "GetTrip")
+	})
+
+	t.Run("trip not found", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(nil, repository.ErrTripNotFound)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id", handler.GetTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/trips/"+TestData.ValidTripID.String(), nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusNotFound, "Resource not found")
+		mockTripService.AssertExpectations(t)
+	})
+}
+
+func TestTripHandler_GetActiveTripByUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("user has active trip", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("GetActiveTripByUser", mock.Anything, TestData.ValidUserID).Return(createValidActiveTrip(), nil)
+
+		router := createTripTestRouter(http.MethodGet, "/users/:id/active-trip", handler.GetActiveTripByUser)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/users/"+TestData.ValidUserID.String()+"/active-trip", nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response TripResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, TestData.ValidUserID, response.UserID)
+
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("user has no active trip", func(t *testing.T) {
+		// Arrange
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		mockTripService.On("GetActiveTripByUser", mock.Anything, TestData.ValidUserID).Return(nil, nil)
+
+		router := createTripTestRouter(http.MethodGet, "/users/:id/active-trip", handler.GetActiveTripByUser)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/users/"+TestData.ValidUserID.String()+"/active-trip", nil)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assertErrorResponse(t, w, http.StatusNotFound, "Resource not found")
+		mockTripService.AssertExpectations(t)
+	})
+}
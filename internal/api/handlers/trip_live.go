@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+// liveWriteTimeout bounds how long a message may take to reach a live trip client
+const liveWriteTimeout = 10 * time.Second
+
+const (
+	tripLiveMessageLocation  = "location"
+	tripLiveMessageTripEnded = "trip_ended"
+)
+
+type TripLiveHandler struct {
+	trips *TripHandler
+	hub   *stream.Hub
+	// heartbeatInterval is how often an idle connection is pinged
+	heartbeatInterval time.Duration
+}
+
+func NewTripLiveHandler(trips *TripHandler, hub *stream.Hub, heartbeatInterval time.Duration) *TripLiveHandler {
+	return &TripLiveHandler{
+		trips:             trips,
+		hub:               hub,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// TripLiveMessage is a JSON text message sent on a live trip connection. Location is set on location
+// messages and Trip on the trip_ended message, the last one before the connection closes.
+type TripLiveMessage struct {
+	Type     string                    `json:"type"`
+	Location *TripLiveLocationResponse `json:"location,omitempty"`
+	Trip     *TripResponse             `json:"trip,omitempty"`
+}
+
+type TripLiveLocationResponse struct {
+	ScooterID    uuid.UUID `json:"scooter_id"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	BatteryLevel *int      `json:"battery_level,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// TrackTrip upgrades to a WebSocket that sends every location the trip's scooter reports, then a summary
+// of the trip once it ends. A trip that has already ended gets the summary straight away. The connection
+// also closes when the server shuts down or the client falls too far behind; clients reconnect while the
+// trip is active.
+func (h *TripLiveHandler) TrackTrip(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid trip ID"))
+		return
+	}
+	logTrip(c, tripID)
+	if !c.IsWebsocket() {
+		c.Error(middleware.NewAPIError(http.StatusUpgradeRequired, "WebSocket upgrade required"))
+		return
+	}
+
+	trip, err := h.trips.tripService.GetTrip(c.Request.Context(), tripID)
+	if err != nil {
+		c.Error(h.trips.mapTripError(c, "Failed to get trip", err))
+		return
+	}
+	// Another rider's trip is reported as missing rather than revealing that it exists
+	if userID := authenticatedUser(c); userID != uuid.Nil && trip.UserID != userID {
+		c.Error(middleware.ErrNotFound)
+		return
+	}
+
+	subscription, _, _ := h.hub.Subscribe("", trip.ScooterID)
+	defer subscription.Close()
+
+	// Reading an active trip again once subscribed means an end landing in between is not missed
+	if trip.Status == models.TripStatusActive {
+		if trip, err = h.trips.tripService.GetTrip(c.Request.Context(), tripID); err != nil {
+			c.Error(h.trips.mapTripError(c, "Failed to get trip", err))
+			return
+		}
+	}
+
+	server := websocket.Server{
+		// Clients authenticate with API keys or tokens rather than cookies, so any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			h.track(conn, trip, subscription)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *TripLiveHandler) track(conn *websocket.Conn, trip *models.Trip, subscription *stream.Subscription) {
+	// Clients send nothing, but reading answers their pings and notices them leaving
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var message []byte
+		for websocket.Message.Receive(conn, &message) == nil {
+		}
+	}()
+
+	if trip.Status != models.TripStatusActive {
+		h.send(conn, newTripEndedMessage(trip))
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case <-subscription.Done():
+			return
+		case <-heartbeat.C:
+			if err := h.ping(conn); err != nil {
+				return
+			}
+		case event := <-subscription.Events():
+			switch {
+			case event.Type == stream.EventTypeLocation:
+				if err := h.send(conn, TripLiveMessage{
+					Type: tripLiveMessageLocation,
+					Location: &TripLiveLocationResponse{
+						ScooterID:    event.ScooterID,
+						Latitude:     event.Latitude,
+						Longitude:    event.Longitude,
+						BatteryLevel: event.BatteryLevel,
+						Timestamp:    event.Timestamp,
+					},
+				}); err != nil {
+					return
+				}
+			case event.PreviousStatus == models.ScooterStatusOccupied:
+				// The scooter was released, so the trip has been ended or cancelled
+				ended, err := h.trips.tripService.GetTrip(conn.Request().Context(), trip.ID)
+				if err != nil {
+					logger.FromContext(conn.Request().Context()).Error("Failed to get ended trip", logger.ErrorField(err))
+					return
+				}
+				if ended.Status == models.TripStatusActive {
+					continue
+				}
+				h.send(conn, newTripEndedMessage(ended))
+				return
+			}
+		}
+	}
+}
+
+func newTripEndedMessage(trip *models.Trip) TripLiveMessage {
+	response := newTripResponse(trip)
+	return TripLiveMessage{Type: tripLiveMessageTripEnded, Trip: &response}
+}
+
+func (h *TripLiveHandler) send(conn *websocket.Conn, message TripLiveMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+	return websocket.JSON.Send(conn, message)
+}
+
+func (h *TripLiveHandler) ping(conn *websocket.Conn) error {
+	conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+	conn.PayloadType = websocket.PingFrame
+	defer func() { conn.PayloadType = websocket.TextFrame }()
+	_, err := conn.Write(nil)
+	return err
+}
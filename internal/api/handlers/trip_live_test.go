@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// startTripLiveServer serves the live trip endpoint, with router building the test router around it
+func startTripLiveServer(t *testing.T, mockTripService *mocks.MockTripService, hub *stream.Hub, router func(method, path string, handler gin.HandlerFunc) *gin.Engine) *httptest.Server {
+	handler := NewTripLiveHandler(createTripHandler(mockTripService), hub, time.Minute)
+	server := httptest.NewServer(router(http.MethodGet, "/trips/:id/live", handler.TrackTrip))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dialTripLive connects to the trip; the handshake completes only once the connection is subscribed
+func dialTripLive(t *testing.T, server *httptest.Server) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/trips/" + TestData.ValidTripID.String() + "/live"
+	conn, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestTripLiveHandler_TrackTrip(t *testing.T) {
+	t.Run("sends locations, then the summary", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		hub := stream.NewHub(10, 10)
+		active := createValidActiveTrip()
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(active, nil).Twice()
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidCompletedTrip(), nil).Once()
+
+		conn := dialTripLive(t, startTripLiveServer(t, mockTripService, hub, createTripTestRouter))
+
+		battery := 70
+		hub.Publish(stream.ScooterEvent{Type: stream.EventTypeLocation, ScooterID: uuid.New(), Latitude: 1, Longitude: 1})
+		hub.Publish(stream.ScooterEvent{Type: stream.EventTypeLocation, ScooterID: active.ScooterID, Latitude: 52.53, Longitude: 13.41, BatteryLevel: &battery})
+		hub.Publish(stream.ScooterEvent{Type: stream.EventTypeStatus, ScooterID: active.ScooterID, Status: models.ScooterStatusAvailable, PreviousStatus: models.ScooterStatusOccupied})
+
+		var location TripLiveMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &location))
+		assert.Equal(t, "location", location.Type)
+		require.NotNil(t, location.Location)
+		assert.Equal(t, 52.53, location.Location.Latitude)
+		assert.Equal(t, 70, *location.Location.BatteryLevel)
+
+		var summary TripLiveMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &summary))
+		assert.Equal(t, "trip_ended", summary.Type)
+		require.NotNil(t, summary.Trip)
+		assert.Equal(t, string(models.TripStatusCompleted), summary.Trip.Status)
+		require.NotNil(t, summary.Trip.Fare)
+
+		var next TripLiveMessage
+		assert.ErrorIs(t, websocket.JSON.Receive(conn, &next), io.EOF, "the connection closes after the summary")
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("trip already ended", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidCompletedTrip(), nil)
+
+		conn := dialTripLive(t, startTripLiveServer(t, mockTripService, stream.NewHub(10, 10), createTripTestRouter))
+
+		var summary TripLiveMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &summary))
+		assert.Equal(t, "trip_ended", summary.Type)
+		assert.ErrorIs(t, websocket.JSON.Receive(conn, &summary), io.EOF)
+	})
+
+	t.Run("trip ended while subscribing", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidActiveTrip(), nil).Once()
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidCompletedTrip(), nil).Once()
+
+		conn := dialTripLive(t, startTripLiveServer(t, mockTripService, stream.NewHub(10, 10), createTripTestRouter))
+
+		var summary TripLiveMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &summary))
+		assert.Equal(t, "trip_ended", summary.Type)
+		assert.ErrorIs(t, websocket.JSON.Receive(conn, &summary), io.EOF)
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("closes when the hub shuts down", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		hub := stream.NewHub(10, 10)
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidActiveTrip(), nil)
+
+		conn := dialTripLive(t, startTripLiveServer(t, mockTripService, hub, createTripTestRouter))
+		hub.Close()
+
+		var message TripLiveMessage
+		assert.ErrorIs(t, websocket.JSON.Receive(conn, &message), io.EOF)
+		assert.NoError(t, hub.Wait(t.Context()), "the connection's subscription is released")
+	})
+
+	t.Run("another rider's trip", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidActiveTrip(), nil)
+		otherRider := func(method, path string, handler gin.HandlerFunc) *gin.Engine {
+			return createRiderTestRouter(method, path, uuid.New(), handler)
+		}
+		server := startTripLiveServer(t, mockTripService, stream.NewHub(10, 10), otherRider)
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/trips/" + TestData.ValidTripID.String() + "/live"
+		_, err := websocket.Dial(url, "", server.URL)
+		assert.ErrorContains(t, err, "bad status")
+	})
+
+	t.Run("trip not found", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(nil, repository.ErrTripNotFound)
+		handler := NewTripLiveHandler(createTripHandler(mockTripService), stream.NewHub(10, 10), time.Minute)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/live", handler.TrackTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/trips/"+TestData.ValidTripID.String()+"/live", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("not a WebSocket request", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := NewTripLiveHandler(createTripHandler(mockTripService), stream.NewHub(10, 10), time.Minute)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/live", handler.TrackTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/trips/"+TestData.ValidTripID.String()+"/live", nil)
+		router.ServeHTTP(w, req)
+
+		assertErrorResponse(t, w, http.StatusUpgradeRequired, "WebSocket upgrade required")
+		mockTripService.AssertNotCalled(t, "GetTrip")
+	})
+}
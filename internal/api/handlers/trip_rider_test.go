@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Requests authenticated with a rider JWT act for that rider only
+
+func TestTripHandler_RiderStartTrip(t *testing.T) {
+	otherUserID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectStart    bool
+		expectedStatus int
+	}{
+		{
+			name: "user ID taken from the token",
+			body: fmt.Sprintf(`{"scooter_id":"%s","latitude":%f,"longitude":%f}`,
+				TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude),
+			expectStart:    true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "own user ID in the body",
+			body: fmt.Sprintf(`{"scooter_id":"%s","user_id":"%s","latitude":%f,"longitude":%f}`,
+				TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude),
+			expectStart:    true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "another user's ID in the body",
+			body: fmt.Sprintf(`{"scooter_id":"%s","user_id":"%s","latitude":%f,"longitude":%f}`,
+				TestData.ValidScooterID, otherUserID, TestData.ValidLatitude, TestData.ValidLongitude),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTripService := &mocks.MockTripService{}
+			handler := createTripHandler(mockTripService)
+			if tt.expectStart {
+				mockTripService.On("StartTrip", mock.Anything, uuid.Nil, TestData.ValidScooterID, TestData.ValidUserID, TestData.ValidLatitude, TestData.ValidLongitude).
+					Return(createValidActiveTrip(), nil)
+			}
+
+			router := createRiderTestRouter(http.MethodPost, "/trips", TestData.ValidUserID, handler.StartTrip)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockTripService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTripHandler_StartTripRequiresUserForAPIKeys(t *testing.T) {
+	mockTripService := &mocks.MockTripService{}
+	handler := createTripHandler(mockTripService)
+
+	router := createTripTestRouter(http.MethodPost, "/trips", handler.StartTrip)
+	w := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"scooter_id":"%s","latitude":%f,"longitude":%f}`, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude)
+	req, _ := http.NewRequest(http.MethodPost, "/trips", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "user_id is required")
+	mockTripService.AssertNotCalled(t, "StartTrip")
+}
+
+func TestTripHandler_RiderEndTrip(t *testing.T) {
+	path := fmt.Sprintf("/scooters/%s/trip/end", TestData.ValidScooterID)
+	body := fmt.Sprintf(`{"latitude":%f,"longitude":%f}`, TestData.ValidLatitude, TestData.ValidLongitude)
+
+	t.Run("own trip is ended by ID", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetActiveTrip", mock.Anything, TestData.ValidScooterID).Return(createValidActiveTrip(), nil)
+		mockTripService.On("EndTrip", mock.Anything, TestData.ValidTripID, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).
+			Return(createValidCompletedTrip(), nil)
+
+		router := createRiderTestRouter(http.MethodPost, "/scooters/:id/trip/end", TestData.ValidUserID, handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("another rider's trip is forbidden", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetActiveTrip", mock.Anything, TestData.ValidScooterID).Return(createValidActiveTrip(), nil)
+
+		router := createRiderTestRouter(http.MethodPost, "/scooters/:id/trip/end", uuid.New(), handler.EndTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockTripService.AssertNotCalled(t, "EndTrip")
+	})
+}
+
+func TestTripHandler_RiderCancelTripWithoutActiveTrip(t *testing.T) {
+	mockTripService := &mocks.MockTripService{}
+	handler := createTripHandler(mockTripService)
+	mockTripService.On("GetActiveTrip", mock.Anything, TestData.ValidScooterID).Return(nil, nil)
+
+	router := createRiderTestRouter(http.MethodPost, "/scooters/:id/trip/cancel", TestData.ValidUserID, handler.CancelTrip)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/scooters/%s/trip/cancel", TestData.ValidScooterID), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockTripService.AssertNotCalled(t, "CancelTrip")
+}
+
+func TestTripHandler_RiderReadsOnlyOwnTrips(t *testing.T) {
+	otherUserID := uuid.New()
+
+	t.Run("another rider's trip is not found", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetTrip", mock.Anything, TestData.ValidTripID).Return(createValidActiveTrip(), nil)
+
+		router := createRiderTestRouter(http.MethodGet, "/trips/:id", otherUserID, handler.GetTrip)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/trips/"+TestData.ValidTripID.String(), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("another rider's active trip is forbidden", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		router := createRiderTestRouter(http.MethodGet, "/users/:id/active-trip", otherUserID, handler.GetActiveTripByUser)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/users/%s/active-trip", TestData.ValidUserID), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockTripService.AssertNotCalled(t, "GetActiveTripByUser")
+	})
+}
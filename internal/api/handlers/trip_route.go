@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	routeFormatGeoJSON = "geojson"
+	routeFormatGPX     = "gpx"
+)
+
+type TripRouteParams struct {
+	Format string `form:"format"`
+}
+
+// TripRouteResponse is a GeoJSON Feature with the trip's path as a LineString
+type TripRouteResponse struct {
+	Type       string                      `json:"type"`
+	ID         uuid.UUID                   `json:"id"`
+	Geometry   TripRouteGeometryResponse   `json:"geometry"`
+	Properties TripRoutePropertiesResponse `json:"properties"`
+}
+
+type TripRouteGeometryResponse struct {
+	Type string `json:"type"`
+	// Coordinates are longitude, latitude pairs
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+type TripRoutePropertiesResponse struct {
+	TripID         uuid.UUID  `json:"trip_id"`
+	ScooterID      uuid.UUID  `json:"scooter_id"`
+	Status         string     `json:"status"`
+	StartTime      time.Time  `json:"start_time"`
+	EndTime        *time.Time `json:"end_time,omitempty"`
+	DistanceMeters float64    `json:"distance_meters"`
+	// Timestamps holds the time of each coordinate, in order
+	Timestamps []time.Time `json:"timestamps"`
+}
+
+// gpxDocument is a GPX 1.1 file with the trip as a single-segment track
+type gpxDocument struct {
+	XMLName  xml.Name    `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version  string      `xml:"version,attr"`
+	Creator  string      `xml:"creator,attr"`
+	Metadata gpxMetadata `xml:"metadata"`
+	Track    gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string    `xml:"name"`
+	Time time.Time `xml:"time"`
+}
+
+type gpxTrack struct {
+	Name    string     `xml:"name"`
+	Segment []gpxPoint `xml:"trkseg>trkpt"`
+}
+
+type gpxPoint struct {
+	Latitude  float64   `xml:"lat,attr"`
+	Longitude float64   `xml:"lon,attr"`
+	Time      time.Time `xml:"time"`
+}
+
+// GetTripRoute returns the path the trip took, as GeoJSON or, with format=gpx, as a GPX track
+func (h *TripHandler) GetTripRoute(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "Invalid trip ID"))
+		return
+	}
+	logTrip(c, tripID)
+
+	var params TripRouteParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if params.Format == "" {
+		params.Format = routeFormatGeoJSON
+	}
+	if params.Format != routeFormatGeoJSON && params.Format != routeFormatGPX {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, "format must be 'geojson' or 'gpx'"))
+		return
+	}
+
+	route, err := h.tripService.GetTripRoute(c.Request.Context(), tripID)
+	if err != nil {
+		c.Error(h.mapTripError(c, "Failed to get trip route", err))
+		return
+	}
+	// Another rider's trip is reported as missing rather than revealing that it exists
+	if userID := authenticatedUser(c); userID != uuid.Nil && route.Trip.UserID != userID {
+		c.Error(middleware.ErrNotFound)
+		return
+	}
+
+	if params.Format == routeFormatGPX {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="trip-%s.gpx"`, route.Trip.ID))
+		c.Data(http.StatusOK, "application/gpx+xml", newGPXRoute(route))
+		return
+	}
+	c.JSON(http.StatusOK, newTripRouteResponse(route))
+}
+
+func newTripRouteResponse(route *services.TripRoute) TripRouteResponse {
+	trip := route.Trip
+	response := TripRouteResponse{
+		Type: "Feature",
+		ID:   trip.ID,
+		Geometry: TripRouteGeometryResponse{
+			Type:        "LineString",
+			Coordinates: make([][2]float64, 0, len(route.Points)),
+		},
+		Properties: TripRoutePropertiesResponse{
+			TripID:         trip.ID,
+			ScooterID:      trip.ScooterID,
+			Status:         string(trip.Status),
+			StartTime:      trip.StartTime,
+			EndTime:        trip.EndTime,
+			DistanceMeters: route.DistanceMeters,
+			Timestamps:     make([]time.Time, 0, len(route.Points)),
+		},
+	}
+
+	for _, point := range route.Points {
+		response.Geometry.Coordinates = append(response.Geometry.Coordinates, [2]float64{point.Longitude, point.Latitude})
+		response.Properties.Timestamps = append(response.Properties.Timestamps, point.Timestamp)
+	}
+	// A LineString needs two positions; a trip that has only just started has one
+	if len(route.Points) == 1 {
+		response.Geometry.Coordinates = append(response.Geometry.Coordinates, response.Geometry.Coordinates[0])
+		response.Properties.Timestamps = append(response.Properties.Timestamps, response.Properties.Timestamps[0])
+	}
+
+	return response
+}
+
+func newGPXRoute(route *services.TripRoute) []byte {
+	name := "Trip " + route.Trip.ID.String()
+	document := gpxDocument{
+		Version:  "1.1",
+		Creator:  "scootin-aboot",
+		Metadata: gpxMetadata{Name: name, Time: route.Trip.StartTime.UTC()},
+		Track:    gpxTrack{Name: name, Segment: make([]gpxPoint, len(route.Points))},
+	}
+	for i, point := range route.Points {
+		document.Track.Segment[i] = gpxPoint{Latitude: point.Latitude, Longitude: point.Longitude, Time: point.Timestamp.UTC()}
+	}
+
+	// Marshalling fixed structs of numbers and times cannot fail
+	data, _ := xml.MarshalIndent(document, "", "  ")
+	return append([]byte(xml.Header), data...)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/api/handlers/mocks"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestTripRoute() *services.TripRoute {
+	trip := createValidCompletedTrip()
+	return &services.TripRoute{
+		Trip: trip,
+		Points: []services.RoutePoint{
+			{Latitude: trip.StartLatitude, Longitude: trip.StartLongitude, Timestamp: trip.StartTime},
+			{Latitude: TestData.ValidLatitude + 0.005, Longitude: TestData.ValidLongitude, Timestamp: trip.StartTime.Add(5 * time.Minute)},
+			{Latitude: *trip.EndLatitude, Longitude: *trip.EndLongitude, Timestamp: *trip.EndTime},
+		},
+		DistanceMeters: 1360,
+	}
+}
+
+func TestTripHandler_GetTripRoute(t *testing.T) {
+	path := "/trips/" + TestData.ValidTripID.String() + "/route"
+
+	t.Run("GeoJSON by default", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetTripRoute", mock.Anything, TestData.ValidTripID).Return(createTestTripRoute(), nil)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/route", handler.GetTripRoute)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response TripRouteResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Feature", response.Type)
+		assert.Equal(t, "LineString", response.Geometry.Type)
+		require.Len(t, response.Geometry.Coordinates, 3)
+		assert.Equal(t, [2]float64{TestData.ValidLongitude, TestData.ValidLatitude}, response.Geometry.Coordinates[0])
+		assert.Len(t, response.Properties.Timestamps, 3)
+		assert.Equal(t, 1360.0, response.Properties.DistanceMeters)
+		mockTripService.AssertExpectations(t)
+	})
+
+	t.Run("GPX", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetTripRoute", mock.Anything, TestData.ValidTripID).Return(createTestTripRoute(), nil)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/route", handler.GetTripRoute)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path+"?format=gpx", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/gpx+xml", w.Header().Get("Content-Type"))
+
+		var document gpxDocument
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &document))
+		assert.Equal(t, "1.1", document.Version)
+		require.Len(t, document.Track.Segment, 3)
+		assert.Equal(t, TestData.ValidLatitude, document.Track.Segment[0].Latitude)
+	})
+
+	t.Run("only just started", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		trip := createValidActiveTrip()
+		mockTripService.On("GetTripRoute", mock.Anything, TestData.ValidTripID).Return(&services.TripRoute{
+			Trip:   trip,
+			Points: []services.RoutePoint{{Latitude: trip.StartLatitude, Longitude: trip.StartLongitude, Timestamp: trip.StartTime}},
+		}, nil)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/route", handler.GetTripRoute)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+
+		var response TripRouteResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Geometry.Coordinates, 2, "a LineString needs two positions")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/route", handler.GetTripRoute)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path+"?format=kml", nil)
+		router.ServeHTTP(w, req)
+
+		assertErrorResponse(t, w, http.StatusBadRequest, "format must be 'geojson' or 'gpx'")
+		mockTripService.AssertNotCalled(t, "GetTripRoute")
+	})
+
+	t.Run("trip not found", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetTripRoute", mock.Anything, TestData.ValidTripID).Return(nil, repository.ErrTripNotFound)
+
+		router := createTripTestRouter(http.MethodGet, "/trips/:id/route", handler.GetTripRoute)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("another rider's trip", func(t *testing.T) {
+		mockTripService := &mocks.MockTripService{}
+		handler := createTripHandler(mockTripService)
+		mockTripService.On("GetTripRoute", mock.Anything, TestData.ValidTripID).Return(createTestTripRoute(), nil)
+
+		router := createRiderTestRouter(http.MethodGet, "/trips/:id/route", uuid.New(), handler.GetTripRoute)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"scootin-aboot/internal/api/middleware"
+	"scootin-aboot/internal/geofence"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ZoneHandler struct {
+	zones *geofence.Set
+}
+
+func NewZoneHandler(zones *geofence.Set) *ZoneHandler {
+	return &ZoneHandler{
+		zones: zones,
+	}
+}
+
+type ZoneQueryParams struct {
+	Type string `form:"type"`
+}
+
+// ZoneCollectionResponse is a GeoJSON FeatureCollection with one feature per zone
+type ZoneCollectionResponse struct {
+	Type     string         `json:"type"`
+	Features []ZoneResponse `json:"features"`
+}
+
+type ZoneResponse struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   ZoneGeometryResponse   `json:"geometry"`
+	Properties ZonePropertiesResponse `json:"properties"`
+}
+
+type ZoneGeometryResponse struct {
+	Type        string             `json:"type"`
+	Coordinates []geofence.Polygon `json:"coordinates"`
+}
+
+type ZonePropertiesResponse struct {
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`
+	SpeedLimitKmh float64 `json:"speed_limit_kmh,omitempty"`
+}
+
+// GetZones returns the service areas, no-parking and slow zones as GeoJSON so apps can draw them
+func (h *ZoneHandler) GetZones(c *gin.Context) {
+	var params ZoneQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	var types []geofence.ZoneType
+	if params.Type != "" {
+		zoneType := geofence.ZoneType(params.Type)
+		if !zoneType.IsValid() {
+			c.Error(middleware.NewAPIError(http.StatusBadRequest, "type must be 'service_area', 'no_parking' or 'slow_zone'"))
+			return
+		}
+		types = append(types, zoneType)
+	}
+
+	zones := h.zones.Zones(types...)
+	response := ZoneCollectionResponse{
+		Type:     "FeatureCollection",
+		Features: make([]ZoneResponse, len(zones)),
+	}
+	for i, zone := range zones {
+		response.Features[i] = ZoneResponse{
+			Type: "Feature",
+			ID:   zone.ID,
+			Geometry: ZoneGeometryResponse{
+				Type:        "MultiPolygon",
+				Coordinates: zone.Polygons,
+			},
+			Properties: ZonePropertiesResponse{
+				Name:          zone.Name,
+				Type:          string(zone.Type),
+				SpeedLimitKmh: zone.SpeedLimitKmh,
+			},
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
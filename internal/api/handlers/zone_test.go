@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"scootin-aboot/internal/geofence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestZones(t *testing.T) *geofence.Set {
+	square := []geofence.Polygon{{{{-76, 45}, {-75, 45}, {-75, 46}, {-76, 46}, {-76, 45}}}}
+	zones, err := geofence.NewSet([]*geofence.Zone{
+		{ID: "ottawa", Name: "Ottawa", Type: geofence.ZoneTypeServiceArea, Polygons: square},
+		{ID: "market", Name: "Market", Type: geofence.ZoneTypeSlow, SpeedLimitKmh: 10, Polygons: square},
+	})
+	require.NoError(t, err)
+	return zones
+}
+
+func TestZoneHandler_GetZones(t *testing.T) {
+	tests := []struct {
+		name           string
+		zones          *geofence.Set
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:           "all zones",
+			zones:          createTestZones(t),
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"ottawa", "market"},
+		},
+		{
+			name:           "filtered by type",
+			zones:          createTestZones(t),
+			query:          "?type=slow_zone",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"market"},
+		},
+		{
+			name:           "no zones configured",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{},
+		},
+		{
+			name:           "unknown type",
+			zones:          createTestZones(t),
+			query:          "?type=park",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewZoneHandler(tt.zones)
+			router := createTripTestRouter(http.MethodGet, "/zones", handler.GetZones)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/zones"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response ZoneCollectionResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "FeatureCollection", response.Type)
+
+			ids := []string{}
+			for _, feature := range response.Features {
+				assert.Equal(t, "MultiPolygon", feature.Geometry.Type)
+				ids = append(ids, feature.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
@@ -3,23 +3,56 @@ package middleware
 import (
 	"net/http"
 
+	"scootin-aboot/internal/auth"
 	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/auth/jwt"
+	"scootin-aboot/internal/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
+// principalKey is the gin context key holding the *auth.Principal of an authenticated request
+const principalKey = "principal"
+
+// APIKeyMiddleware authenticates requests with API keys only
 func APIKeyMiddleware(validator *apikey.Validator) gin.HandlerFunc {
+	return AuthMiddleware(validator, nil)
+}
+
+// AuthMiddleware authenticates a request with an API key, or with a rider JWT when the bearer credential
+// is one and tokens is not nil, and places the resolved principal on the gin context
+func AuthMiddleware(validator *apikey.Validator, tokens *jwt.Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var apiKey string
+		var credential string
+		fromHeader := false
 
 		if apiKeyHeader := c.GetHeader("X-API-Key"); apiKeyHeader != "" {
-			apiKey = apiKeyHeader
+			credential = apiKeyHeader
 		} else {
 			authHeader := c.GetHeader("Authorization")
-			apiKey = apikey.ExtractAPIKey(authHeader)
+			credential = apikey.ExtractAPIKey(authHeader)
+			fromHeader = true
 		}
 
-		if err := validator.ValidateAPIKey(apiKey); err != nil {
+		var principal *auth.Principal
+		var err error
+		if fromHeader && tokens != nil && jwt.LooksLikeJWT(credential) {
+			principal, err = tokens.Authenticate(credential)
+		} else {
+			principal, err = validator.Authenticate(c.Request.Context(), credential)
+		}
+
+		if err != nil && !isAuthFailure(err) {
+			logger.FromContext(c.Request.Context()).Error("Failed to verify API key", logger.ErrorField(err))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Service Unavailable",
+				"message": "Unable to verify API key",
+				"code":    http.StatusServiceUnavailable,
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
 				"message": "Authentication failed",
@@ -32,6 +65,66 @@ func APIKeyMiddleware(validator *apikey.Validator) gin.HandlerFunc {
 			return
 		}
 
+		SetPrincipal(c, principal)
 		c.Next()
 	}
 }
+
+// RequireScope rejects requests whose principal was not granted scope. It must run after APIKeyMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"message": "API key is missing a required scope",
+				"code":    http.StatusForbidden,
+				"details": map[string]string{
+					"required_scope": scope,
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SetPrincipal records the principal a request is authenticated as, and names it in the request's logs:
+// API keys by name and riders by user ID
+func SetPrincipal(c *gin.Context, principal *auth.Principal) {
+	c.Set(principalKey, principal)
+
+	if c.Request != nil {
+		name := principal.Name
+		if principal.IsUser() {
+			name = "user:" + principal.UserID.String()
+		}
+		c.Request = c.Request.WithContext(logger.WithPrincipal(c.Request.Context(), name))
+	}
+}
+
+// GetPrincipal returns the principal APIKeyMiddleware resolved for the request
+func GetPrincipal(c *gin.Context) (*auth.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
+
+// isAuthFailure reports whether err rejects the credentials, as opposed to a failed key lookup.
+// Token verification never depends on another service, so every JWT error is a rejection.
+func isAuthFailure(err error) bool {
+	switch err {
+	case apikey.ErrMissingAPIKey, apikey.ErrInvalidAPIKey, apikey.ErrRevokedAPIKey, apikey.ErrExpiredAPIKey:
+		return true
+	case jwt.ErrMalformedToken, jwt.ErrUnsupportedAlgorithm, jwt.ErrUnknownSigningKey, jwt.ErrInvalidSignature,
+		jwt.ErrTokenExpired, jwt.ErrTokenNotYetValid, jwt.ErrInvalidIssuer, jwt.ErrInvalidAudience, jwt.ErrInvalidSubject:
+		return true
+	default:
+		return false
+	}
+}
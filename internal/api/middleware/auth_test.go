@@ -1,21 +1,32 @@
 package middleware
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/auth/jwt"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAPIKeyMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	validKey := "test-api-key-12345"
-	validator := apikey.NewValidator(validKey)
+	validator := apikey.NewValidator(validKey, nil)
 
 	tests := []struct {
 		name           string
@@ -82,3 +93,170 @@ func TestAPIKeyMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	readerKey := "sk_scooter-reader"
+	require.NoError(t, repo.APIKey().Create(ctx, &models.APIKey{
+		Name:    "reader",
+		KeyHash: apikey.HashKey(readerKey),
+		Scopes:  []string{models.ScopeScootersRead},
+	}))
+	validator := apikey.NewValidator("test-api-key-12345", repo.APIKey())
+
+	router := gin.New()
+	router.Use(APIKeyMiddleware(validator))
+	router.GET("/scooters", RequireScope(models.ScopeScootersRead), func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		require.True(t, ok)
+		c.JSON(http.StatusOK, gin.H{"principal": principal.Name})
+	})
+	router.POST("/trips", RequireScope(models.ScopeTripsWrite), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		apiKey         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "granted scope",
+			method:         "GET",
+			path:           "/scooters",
+			apiKey:         readerKey,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"principal":"reader"`,
+		},
+		{
+			name:           "missing scope",
+			method:         "POST",
+			path:           "/trips",
+			apiKey:         readerKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   models.ScopeTripsWrite,
+		},
+		{
+			name:           "admin has every scope",
+			method:         "POST",
+			path:           "/trips",
+			apiKey:         "test-api-key-12345",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "success",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-API-Key", tt.apiKey)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestAPIKeyMiddleware_RevokedKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	key := &models.APIKey{Name: "simulator", KeyHash: apikey.HashKey("sk_simulator"), Scopes: []string{models.ScopeTripsWrite}}
+	require.NoError(t, repo.APIKey().Create(ctx, key))
+	require.NoError(t, repo.APIKey().Revoke(ctx, key.ID))
+
+	router := gin.New()
+	router.Use(APIKeyMiddleware(apikey.NewValidator("", repo.APIKey())))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer sk_simulator")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), apikey.ErrRevokedAPIKey.Error())
+}
+
+func TestAuthMiddleware_JWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	tokens, err := jwt.NewAuthenticator(jwt.Config{
+		HMACSecret: secret,
+		Issuer:     "https://auth.scootin.example",
+		Audience:   "scootin-api",
+		ClockSkew:  time.Minute,
+	})
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	sign := func(claims string) string {
+		input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(claims))
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(input))
+		return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	}
+	claims := `{"iss":"https://auth.scootin.example","aud":"scootin-api","sub":%q,"exp":%d}`
+	exp := time.Now().Add(time.Hour).Unix()
+
+	router := gin.New()
+	router.Use(AuthMiddleware(apikey.NewValidator("test-api-key-12345", nil), tokens))
+	router.GET("/test", func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		require.True(t, ok)
+		c.JSON(http.StatusOK, gin.H{"user_id": principal.UserID, "name": principal.Name})
+	})
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "valid token",
+			authHeader:     "Bearer " + sign(fmt.Sprintf(claims, userID, exp)),
+			expectedStatus: http.StatusOK,
+			expectedBody:   userID.String(),
+		},
+		{
+			name:           "expired token",
+			authHeader:     "Bearer " + sign(fmt.Sprintf(claims, userID, time.Now().Add(-time.Hour).Unix())),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   jwt.ErrTokenExpired.Error(),
+		},
+		{
+			name:           "API keys still work",
+			authHeader:     "Bearer test-api-key-12345",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "bootstrap",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", tt.authHeader)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -13,6 +13,8 @@ type ErrorResponse struct {
 	Message string            `json:"message"`
 	Code    int               `json:"code"`
 	Details map[string]string `json:"details,omitempty"`
+	// RequestID is the request's X-Request-ID, for quoting in support requests
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func ErrorHandlerMiddleware() gin.HandlerFunc {
@@ -22,7 +24,7 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
 
-			logger.Error("Request error",
+			logger.FromContext(c.Request.Context()).Error("Request error",
 				logger.String("method", c.Request.Method),
 				logger.String("path", c.Request.URL.Path),
 				logger.String("client_ip", c.ClientIP()),
@@ -31,10 +33,12 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 
 			statusCode := http.StatusInternalServerError
 			message := "Internal server error"
+			var details map[string]string
 
 			if customErr, ok := err.Err.(*APIError); ok {
 				statusCode = customErr.Code
 				message = customErr.Message
+				details = customErr.Details
 			} else {
 				switch err.Type {
 				case gin.ErrorTypeBind:
@@ -50,9 +54,11 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 			}
 
 			c.JSON(statusCode, ErrorResponse{
-				Error:   http.StatusText(statusCode),
-				Message: message,
-				Code:    statusCode,
+				Error:     http.StatusText(statusCode),
+				Message:   message,
+				Code:      statusCode,
+				Details:   details,
+				RequestID: GetRequestID(c),
 			})
 		}
 	}
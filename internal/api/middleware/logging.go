@@ -8,7 +8,7 @@ import (
 
 func LoggingMiddleware() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		logger.Info("HTTP Request",
+		logger.FromContext(param.Request.Context()).Info("HTTP Request",
 			logger.String("method", param.Method),
 			logger.String("path", param.Path),
 			logger.Int("status", param.StatusCode),
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown paths cannot grow the series without bound
+const unmatchedRoute = "unmatched"
+
+// streamingKey is the gin context key marking requests that stay open for as long as the client listens
+const streamingKey = "streaming"
+
+// MetricsMiddleware counts requests and records their latency per route template, such as /api/v1/trips/:id.
+// Streaming requests are counted but left out of the latency histogram, where their connection time would
+// swamp the latency of ordinary requests.
+func MetricsMiddleware(registerer prometheus.Registerer) gin.HandlerFunc {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scootin_http_requests_total",
+		Help: "HTTP requests served, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scootin_http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests other than streams, by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	registerer.MustRegister(requests, latency)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		if !c.GetBool(streamingKey) {
+			latency.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+		}
+	}
+}
+
+// Streaming marks a route whose responses stream for as long as the client listens, such as server-sent
+// events or a WebSocket, so MetricsMiddleware leaves it out of request latency
+func Streaming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(streamingKey, true)
+		c.Next()
+	}
+}
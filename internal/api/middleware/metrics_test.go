@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	router := gin.New()
+	router.Use(MetricsMiddleware(registry))
+	router.Use(ErrorHandlerMiddleware())
+
+	router.GET("/trips/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.Error(ErrNotFound)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	router.GET("/scooters/stream", Streaming(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/trips/1", "/trips/2", "/trips/missing", "/nowhere", "/scooters/stream"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	requests := func(route, status string) float64 {
+		for _, family := range families {
+			if family.GetName() != "scootin_http_requests_total" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if labelValue(metric.GetLabel(), "route") == route && labelValue(metric.GetLabel(), "status") == status {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+	assert.Equal(t, 2.0, requests("/trips/:id", "200"))
+	assert.Equal(t, 1.0, requests("/trips/:id", "404"))
+	assert.Equal(t, 1.0, requests(unmatchedRoute, "404"))
+	assert.Equal(t, 1.0, requests("/scooters/stream", "200"), "streams are counted")
+
+	histograms := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "scootin_http_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			histograms[labelValue(metric.GetLabel(), "route")] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, uint64(3), histograms["/trips/:id"])
+	assert.NotContains(t, histograms, "/scooters/stream", "streams are left out of request latency")
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "scootin_http_request_duration_seconds"), "matched and unmatched routes")
+}
+
+func labelValue(labels []*dto.LabelPair, name string) string {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Rate limit response headers. Reset and Retry-After are in seconds from now.
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimitMiddleware charges each request to its client's quota and rejects it with 429 when the quota is
+// used up. Authenticated requests are charged to their principal, so it must run after AuthMiddleware on
+// protected routes; other requests are charged to the client IP. A nil limiter disables rate limiting.
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		result, limited, err := limiter.Take(c.Request.Context(), rateLimitClient(c), c.Request.Method+" "+c.FullPath())
+		if err != nil {
+			// An unavailable store must not take the API down with it
+			logger.FromContext(c.Request.Context()).Warn("Rate limit check failed; allowing request", logger.ErrorField(err))
+			c.Next()
+			return
+		}
+		if !limited {
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, result)
+		if !result.Allowed {
+			rejectRateLimited(c, result, "Rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuthFailureLimitMiddleware throttles clients that keep failing authentication, such as one guessing API
+// keys or tokens. It counts the 401 responses to each client IP and, once the IP has used up its quota of
+// failures, rejects its requests with 429 before their credentials are checked, so it must run before
+// AuthMiddleware. A nil limiter disables it.
+func AuthFailureLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		client := ipClient(c)
+		result, limited, err := limiter.CheckAuthFailures(ctx, client)
+		if err != nil {
+			logger.FromContext(ctx).Warn("Authentication failure limit check failed; allowing request", logger.ErrorField(err))
+			c.Next()
+			return
+		}
+		if !limited {
+			c.Next()
+			return
+		}
+		if !result.Allowed {
+			setRateLimitHeaders(c, result)
+			rejectRateLimited(c, result, "Too many failed authentication attempts")
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			if err := limiter.ChargeAuthFailure(ctx, client); err != nil {
+				logger.FromContext(ctx).Warn("Failed to count authentication failure", logger.ErrorField(err))
+			}
+		}
+	}
+}
+
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+	c.Header(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	c.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func rejectRateLimited(c *gin.Context, result ratelimit.Result, message string) {
+	retryAfter := strconv.Itoa(ceilSeconds(result.RetryAfter))
+	c.Header(HeaderRetryAfter, retryAfter)
+	c.Error(NewAPIErrorWithDetails(http.StatusTooManyRequests, message, map[string]string{
+		"retry_after": retryAfter,
+	}))
+	c.Abort()
+}
+
+// rateLimitClient identifies the principal of the request, or its IP when it is not authenticated
+func rateLimitClient(c *gin.Context) ratelimit.Client {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return ipClient(c)
+	}
+	return principalClient(principal)
+}
+
+func ipClient(c *gin.Context) ratelimit.Client {
+	return ratelimit.Client{ID: "ip:" + c.ClientIP()}
+}
+
+func principalClient(principal *auth.Principal) ratelimit.Client {
+	switch {
+	case principal.IsUser():
+		return ratelimit.Client{ID: "user:" + principal.UserID.String()}
+	case principal.KeyID != uuid.Nil:
+		return ratelimit.Client{
+			ID:    "key:" + principal.KeyID.String(),
+			Names: []string{principal.KeyID.String(), principal.Name},
+		}
+	default:
+		return ratelimit.Client{ID: "key:" + principal.Name, Names: []string{principal.Name}}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitRouter(limiter *ratelimit.Limiter, principal *auth.Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandlerMiddleware())
+	if principal != nil {
+		router.Use(func(c *gin.Context) { SetPrincipal(c, principal) })
+	}
+	router.Use(RateLimitMiddleware(limiter))
+	router.GET("/scooters/closest", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/scooters", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policies{
+		Default: ratelimit.Policy{Limit: 2, Period: time.Minute},
+		Routes: map[string]ratelimit.Policy{
+			"GET /scooters/closest": {Limit: 1, Period: time.Minute},
+		},
+	})
+	router := newRateLimitRouter(limiter, nil)
+
+	get := func(path, ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/scooters/closest", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "0", w.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "60", w.Header().Get(HeaderRateLimitReset))
+	assert.Empty(t, w.Header().Get(HeaderRetryAfter))
+
+	w = get("/scooters/closest", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get(HeaderRetryAfter))
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Too Many Requests", response.Error)
+	assert.Equal(t, "Rate limit exceeded", response.Message)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	assert.Equal(t, "60", response.Details["retry_after"])
+
+	// The overall quota still has room for other routes
+	w = get("/scooters", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderRateLimitLimit))
+
+	// Unauthenticated clients are told apart by IP
+	w = get("/scooters/closest", "10.0.0.2")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitMiddleware_ForwardedFor(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policies{
+		Default: ratelimit.Policy{Limit: 1, Period: time.Minute},
+	})
+	router := newRateLimitRouter(limiter, nil)
+
+	get := func(remoteIP, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/scooters", nil)
+		req.RemoteAddr = remoteIP + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.NoError(t, router.SetTrustedProxies(nil))
+	assert.Equal(t, http.StatusOK, get("10.0.0.1", "203.0.113.1"))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.1", "203.0.113.2"), "a spoofed header does not change the bucket")
+
+	require.NoError(t, router.SetTrustedProxies([]string{"10.0.0.9"}))
+	assert.Equal(t, http.StatusOK, get("10.0.0.9", "203.0.113.3"), "a trusted proxy names the client")
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.9", "203.0.113.3"))
+	assert.Equal(t, http.StatusOK, get("10.0.0.9", "203.0.113.4"))
+}
+
+func TestRateLimitMiddleware_KeysByPrincipal(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limiter := ratelimit.NewLimiter(store, ratelimit.Policies{
+		Default: ratelimit.Policy{Limit: 1, Period: time.Minute},
+		Keys: map[string]ratelimit.Policy{
+			"simulator": {Limit: 50, Period: time.Minute},
+		},
+	})
+
+	serve := func(principal *auth.Principal, ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/scooters", nil)
+		req.RemoteAddr = ip + ":1234"
+		newRateLimitRouter(limiter, principal).ServeHTTP(w, req)
+		return w
+	}
+
+	rider := &auth.Principal{UserID: uuid.New(), Name: "user:1"}
+	assert.Equal(t, http.StatusOK, serve(rider, "10.0.0.1").Code)
+	// The same rider is limited from another address
+	assert.Equal(t, http.StatusTooManyRequests, serve(rider, "10.0.0.2").Code)
+
+	simulator := &auth.Principal{KeyID: uuid.New(), Name: "simulator"}
+	w := serve(simulator, "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "50", w.Header().Get(HeaderRateLimitLimit))
+}
+
+func TestAuthFailureLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policies{
+		Default:      ratelimit.Policy{Limit: 100, Period: time.Minute},
+		AuthFailures: ratelimit.Policy{Limit: 3, Period: time.Minute},
+	})
+	router := gin.New()
+	router.Use(ErrorHandlerMiddleware(), AuthFailureLimitMiddleware(limiter), APIKeyMiddleware(apikey.NewValidator("test-api-key-12345", nil)))
+	router.GET("/scooters", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(key, ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/scooters", nil)
+		req.Header.Set("X-API-Key", key)
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, get(fmt.Sprintf("sk_guess-%d", i), "10.0.0.1").Code)
+	}
+
+	w := get("sk_guess-3", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "repeated invalid keys are throttled")
+	assert.Equal(t, "20", w.Header().Get(HeaderRetryAfter))
+
+	assert.Equal(t, http.StatusTooManyRequests, get("test-api-key-12345", "10.0.0.1").Code,
+		"the IP is throttled before its credentials are checked")
+	assert.Equal(t, http.StatusOK, get("test-api-key-12345", "10.0.0.2").Code, "other clients are unaffected")
+	assert.Equal(t, http.StatusOK, get("test-api-key-12345", "10.0.0.2").Code, "successful requests are not counted")
+}
+
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	router := newRateLimitRouter(nil, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/scooters", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderRateLimitLimit))
+}
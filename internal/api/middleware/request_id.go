@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"scootin-aboot/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID correlating a request's logs, its error response and the events it produces
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds a caller's request ID; longer ones are replaced
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware keeps the caller's X-Request-ID, or generates one when it is missing or unusable,
+// echoes it in the response and attaches it to the request context for logger.FromContext
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID RequestIDMiddleware assigned to the request
+func GetRequestID(c *gin.Context) string {
+	if c.Request == nil {
+		return ""
+	}
+	return logger.RequestID(c.Request.Context())
+}
+
+// validRequestID accepts printable ASCII without spaces, so a caller's ID cannot break log lines or headers
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "keeps the caller's ID", header: "checkout-7f3a", expected: "checkout-7f3a"},
+		{name: "generates a missing ID"},
+		{name: "replaces an ID with spaces", header: "two words"},
+		{name: "replaces an overlong ID", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			router := gin.New()
+			router.Use(RequestIDMiddleware())
+			router.GET("/test", func(c *gin.Context) {
+				seen = logger.RequestID(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, requestID)
+			} else {
+				assert.NoError(t, uuid.Validate(requestID), "a UUID is generated")
+			}
+			assert.Equal(t, requestID, seen, "handlers see the echoed ID")
+		})
+	}
+}
+
+func TestErrorHandlerMiddleware_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), ErrorHandlerMiddleware())
+	router.GET("/test-error", func(c *gin.Context) {
+		c.Error(ErrNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/test-error", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(w, req)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "req-42", response.RequestID)
+}
+
+func TestSetPrincipal_NamesRequestLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+	SetPrincipal(c, &auth.Principal{UserID: userID})
+
+	principal, ok := GetPrincipal(c)
+	require.True(t, ok)
+	assert.Equal(t, userID, principal.UserID)
+
+	assert.Equal(t, logger.FromContext(logger.WithPrincipal(t.Context(), "user:"+userID.String())),
+		logger.FromContext(c.Request.Context()))
+}
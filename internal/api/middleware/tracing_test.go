@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"scootin-aboot/internal/tracing/tracingtest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracingtest.Record(t)
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(TracingMiddleware())
+	router.Use(ErrorHandlerMiddleware())
+	router.GET("/trips/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		if c.Param("id") == "broken" {
+			c.Error(NewAPIError(http.StatusInternalServerError, "Internal server error"))
+			return
+		}
+		c.Error(ErrNotFound)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/trips/missing", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest(http.MethodGet, "/trips/broken", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	continued := spans[0]
+	assert.Equal(t, "GET /trips/:id", continued.Name())
+	assert.Equal(t, trace.SpanKindServer, continued.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", continued.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", continued.Parent().SpanID().String())
+	assert.Contains(t, continued.Attributes(), attribute.Int("http.response.status_code", http.StatusNotFound))
+	assert.Equal(t, codes.Unset, continued.Status().Code, "client errors do not fail the span")
+
+	failed := spans[1]
+	assert.False(t, failed.Parent().IsValid())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, failed.SpanContext(), handlerSpan, "handlers run inside the request span")
+}
@@ -4,20 +4,53 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"scootin-aboot/internal/api/handlers"
 	"scootin-aboot/internal/api/middleware"
 	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/auth/jwt"
+	"scootin-aboot/internal/geofence"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/ratelimit"
 	"scootin-aboot/internal/services"
+	"scootin-aboot/internal/stream"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func SetupRoutes(router *gin.Engine, apiKey string, scooterService services.ScooterService) {
+func SetupRoutes(
+	router *gin.Engine,
+	apiKeyValidator *apikey.Validator,
+	tokenAuthenticator *jwt.Authenticator,
+	rateLimiter *ratelimit.Limiter,
+	scooterService services.ScooterService,
+	tripService services.TripService,
+	apiKeyService services.APIKeyService,
+	reservationService services.ReservationService,
+	scooterStatusService services.ScooterStatusService,
+	zones *geofence.Set,
+	scooterChanges *stream.Hub,
+	streamHeartbeat time.Duration,
+	metricsGatherer prometheus.Gatherer,
+) {
 	healthHandler := handlers.NewHealthHandler()
+	metricsHandler := handlers.NewMetricsHandler(metricsGatherer)
 	scooterHandler := handlers.NewScooterHandler(scooterService)
+	tripHandler := handlers.NewTripHandler(tripService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
+	scooterStatusHandler := handlers.NewScooterStatusHandler(scooterStatusService)
+	zoneHandler := handlers.NewZoneHandler(zones)
+	scooterStreamHandler := handlers.NewScooterStreamHandler(scooterChanges, streamHeartbeat)
+	tripLiveHandler := handlers.NewTripLiveHandler(tripHandler, scooterChanges, streamHeartbeat)
 
-	apiKeyValidator := apikey.NewValidator(apiKey)
+	scootersRead := middleware.RequireScope(models.ScopeScootersRead)
+	tripsRead := middleware.RequireScope(models.ScopeTripsRead)
+	tripsWrite := middleware.RequireScope(models.ScopeTripsWrite)
+	rateLimit := middleware.RateLimitMiddleware(rateLimiter)
+	authFailureLimit := middleware.AuthFailureLimitMiddleware(rateLimiter)
 
 	router.GET("/docs", func(c *gin.Context) {
 		swaggerUIPath := filepath.Join(".", "docs", "swagger-ui.html")
@@ -37,20 +70,45 @@ func SetupRoutes(router *gin.Engine, apiKey string, scooterService services.Scoo
 		c.File(apiDocsPath)
 	})
 
+	router.GET("/metrics", authFailureLimit, middleware.APIKeyMiddleware(apiKeyValidator), middleware.RequireScope(models.ScopeAdmin), metricsHandler.GetMetrics)
+
 	router.Static("/paths", "./docs/api/paths")
 	router.Static("/components", "./docs/api/components")
 	router.Static("/info", "./docs/api/info")
 
 	v1 := router.Group("/api/v1")
 	{
-		v1.GET("/health", healthHandler.HealthCheck)
+		v1.GET("/health", rateLimit, healthHandler.HealthCheck)
 
 		protected := v1.Group("")
-		protected.Use(middleware.APIKeyMiddleware(apiKeyValidator))
+		protected.Use(authFailureLimit, middleware.AuthMiddleware(apiKeyValidator, tokenAuthenticator), rateLimit)
 		{
-			protected.GET("/scooters", scooterHandler.GetScooters)
-			protected.GET("/scooters/:id", scooterHandler.GetScooter)
-			protected.GET("/scooters/closest", scooterHandler.GetClosestScooters)
+			protected.GET("/scooters", scootersRead, scooterHandler.GetScooters)
+			protected.GET("/scooters/:id", scootersRead, scooterHandler.GetScooter)
+			protected.GET("/scooters/closest", scootersRead, scooterHandler.GetClosestScooters)
+			protected.GET("/scooters/stream", middleware.Streaming(), scootersRead, scooterStreamHandler.StreamScooters)
+			protected.GET("/zones", scootersRead, zoneHandler.GetZones)
+			protected.POST("/scooters/:id/trip/end", tripsWrite, tripHandler.EndTrip)
+			protected.POST("/scooters/:id/trip/cancel", tripsWrite, tripHandler.CancelTrip)
+			protected.POST("/scooters/:id/reservation", tripsWrite, reservationHandler.ReserveScooter)
+			protected.DELETE("/scooters/:id/reservation", tripsWrite, reservationHandler.CancelReservation)
+
+			protected.POST("/trips", tripsWrite, tripHandler.StartTrip)
+			protected.GET("/trips/:id", tripsRead, tripHandler.GetTrip)
+			protected.GET("/trips/:id/route", tripsRead, tripHandler.GetTripRoute)
+			protected.GET("/trips/:id/live", middleware.Streaming(), tripsRead, tripLiveHandler.TrackTrip)
+			protected.GET("/users/:id/active-trip", tripsRead, tripHandler.GetActiveTripByUser)
+
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireScope(models.ScopeAdmin))
+			{
+				admin.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+				admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+				admin.POST("/scooters/:id/device-secret", scooterHandler.RotateDeviceSecret)
+				admin.POST("/scooters/:id/status", scooterStatusHandler.ChangeStatus)
+				admin.GET("/scooters/:id/status-history", scooterStatusHandler.GetStatusHistory)
+			}
 		}
 	}
 }
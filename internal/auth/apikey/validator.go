@@ -1,32 +1,106 @@
 package apikey
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"strings"
+	"time"
+
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+)
+
+var (
+	ErrMissingAPIKey = errors.New("API key is required")
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrRevokedAPIKey = errors.New("API key has been revoked")
+	ErrExpiredAPIKey = errors.New("API key has expired")
 )
 
+// keyPrefix marks generated keys so they are recognisable in logs and secret scanners
+const keyPrefix = "sk_"
+
+// lastUsedInterval limits how often last_used_at is written for a busy key
+const lastUsedInterval = time.Minute
+
+// Validator resolves API keys to principals. Keys are looked up in the api_keys table; the bootstrap key
+// from the configuration, when set, is accepted as an admin so the first database keys can be issued.
 type Validator struct {
-	validKey string
+	bootstrapKey string
+	keys         repository.APIKeyRepository
+	now          func() time.Time
 }
 
-func NewValidator(apiKey string) *Validator {
+func NewValidator(bootstrapKey string, keys repository.APIKeyRepository) *Validator {
 	return &Validator{
-		validKey: apiKey,
+		bootstrapKey: bootstrapKey,
+		keys:         keys,
+		now:          time.Now,
 	}
 }
 
-func (v *Validator) ValidateAPIKey(providedKey string) error {
+func (v *Validator) Authenticate(ctx context.Context, providedKey string) (*auth.Principal, error) {
 	if providedKey == "" {
-		return errors.New("API key is required")
+		return nil, ErrMissingAPIKey
 	}
 
 	// Use constant time comparison to prevent timing attacks
-	if subtle.ConstantTimeCompare([]byte(providedKey), []byte(v.validKey)) != 1 {
-		return errors.New("invalid API key")
+	if v.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(providedKey), []byte(v.bootstrapKey)) == 1 {
+		return &auth.Principal{Name: "bootstrap", Scopes: []string{models.ScopeAdmin}}, nil
+	}
+
+	if v.keys == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := v.keys.GetByHash(ctx, HashKey(providedKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrInvalidAPIKey
 	}
 
-	return nil
+	now := v.now()
+	if key.RevokedAt != nil {
+		return nil, ErrRevokedAPIKey
+	}
+	if !key.IsActive(now) {
+		return nil, ErrExpiredAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		if err := v.keys.TouchLastUsed(ctx, key.ID, now); err != nil {
+			logger.Warn("Failed to record API key usage",
+				logger.String("api_key_id", key.ID.String()),
+				logger.ErrorField(err),
+			)
+		}
+	}
+
+	return &auth.Principal{KeyID: key.ID, Name: key.Name, Scopes: key.Scopes}, nil
+}
+
+// GenerateKey returns a new random API key secret
+func GenerateKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashKey returns the hex SHA-256 of a key secret, as stored in the api_keys table
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
 }
 
 func ExtractAPIKey(authHeader string) string {
@@ -1,58 +1,153 @@
 package apikey
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
 )
 
-func TestValidator_ValidateAPIKey(t *testing.T) {
-	validKey := "test-api-key-12345"
-	validator := NewValidator(validKey)
+func TestValidator_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	createKey := func(name string, key *models.APIKey) string {
+		secret, err := GenerateKey()
+		if err != nil {
+			t.Fatalf("GenerateKey: %v", err)
+		}
+		key.Name = name
+		key.KeyHash = HashKey(secret)
+		if err := repo.APIKey().Create(ctx, key); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return secret
+	}
+
+	readKey := createKey("mobile", &models.APIKey{Scopes: []string{models.ScopeScootersRead}})
+	futureKey := createKey("ops", &models.APIKey{Scopes: []string{models.ScopeTripsRead}, ExpiresAt: &future})
+	expiredKey := createKey("expired", &models.APIKey{Scopes: []string{models.ScopeScootersRead}, ExpiresAt: &past})
+	revokedKey := createKey("revoked", &models.APIKey{Scopes: []string{models.ScopeScootersRead}, RevokedAt: &past})
+
+	validator := NewValidator("test-api-key-12345", repo.APIKey())
 
 	tests := []struct {
-		name        string
-		providedKey string
-		expectError bool
+		name          string
+		providedKey   string
+		expectedError error
+		expectedName  string
 	}{
 		{
-			name:        "valid API key",
-			providedKey: "test-api-key-12345",
-			expectError: false,
+			name:         "bootstrap API key",
+			providedKey:  "test-api-key-12345",
+			expectedName: "bootstrap",
+		},
+		{
+			name:          "invalid API key",
+			providedKey:   "wrong-key",
+			expectedError: ErrInvalidAPIKey,
 		},
 		{
-			name:        "invalid API key",
-			providedKey: "wrong-key",
-			expectError: true,
+			name:          "empty API key",
+			providedKey:   "",
+			expectedError: ErrMissingAPIKey,
 		},
 		{
-			name:        "empty API key",
-			providedKey: "",
-			expectError: true,
+			name:          "case sensitive",
+			providedKey:   "TEST-API-KEY-12345",
+			expectedError: ErrInvalidAPIKey,
 		},
 		{
-			name:        "case sensitive",
-			providedKey: "TEST-API-KEY-12345",
-			expectError: true,
+			name:          "partial match",
+			providedKey:   "test-api-key-1234",
+			expectedError: ErrInvalidAPIKey,
 		},
 		{
-			name:        "partial match",
-			providedKey: "test-api-key-1234",
-			expectError: true,
+			name:         "stored key",
+			providedKey:  readKey,
+			expectedName: "mobile",
+		},
+		{
+			name:         "stored key before expiry",
+			providedKey:  futureKey,
+			expectedName: "ops",
+		},
+		{
+			name:          "expired key",
+			providedKey:   expiredKey,
+			expectedError: ErrExpiredAPIKey,
+		},
+		{
+			name:          "revoked key",
+			providedKey:   revokedKey,
+			expectedError: ErrRevokedAPIKey,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validator.ValidateAPIKey(tt.providedKey)
-			if tt.expectError && err == nil {
-				t.Errorf("Expected error but got none")
+			principal, err := validator.Authenticate(ctx, tt.providedKey)
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("Expected error %v but got: %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
 			}
-			if !tt.expectError && err != nil {
-				t.Errorf("Expected no error but got: %v", err)
+			if principal.Name != tt.expectedName {
+				t.Errorf("Expected principal %q, got %q", tt.expectedName, principal.Name)
 			}
 		})
 	}
 }
 
+func TestValidator_AuthenticateRecordsUsage(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	secret, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	key := &models.APIKey{Name: "simulator", KeyHash: HashKey(secret), Scopes: []string{models.ScopeTripsWrite}}
+	if err := repo.APIKey().Create(ctx, key); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	validator := NewValidator("", repo.APIKey())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validator.now = func() time.Time { return now }
+
+	principal, err := validator.Authenticate(ctx, secret)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.KeyID != key.ID || !principal.HasScope(models.ScopeTripsWrite) || principal.HasScope(models.ScopeAdmin) {
+		t.Errorf("Unexpected principal %+v", principal)
+	}
+
+	stored, err := repo.APIKey().GetByID(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(now) {
+		t.Errorf("Expected last_used_at %v, got %v", now, stored.LastUsedAt)
+	}
+
+	// The bootstrap key is disabled when no key is configured
+	if _, err := validator.Authenticate(ctx, "test-api-key-12345"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey, got %v", err)
+	}
+}
+
 func TestExtractAPIKey(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,65 @@
+package device
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, secretPrefix))
+
+	privateKey, err := parseSecret(secret)
+	require.NoError(t, err)
+	publicKey, err := PublicKey(secret)
+	require.NoError(t, err)
+	scooterID := "550e8400-e29b-41d4-a716-446655440000"
+	timestamp := "2025-06-01T12:00:00Z"
+	value := []byte(`{"eventType":"location.updated"}`)
+
+	signature := Sign(privateKey, scooterID, timestamp, value)
+	assert.True(t, Verify(publicKey, scooterID, timestamp, value, signature))
+
+	otherSecret, err := GenerateSecret()
+	require.NoError(t, err)
+	otherPublicKey, err := PublicKey(otherSecret)
+	require.NoError(t, err)
+
+	assert.False(t, Verify(otherPublicKey, scooterID, timestamp, value, signature), "other key")
+	assert.False(t, Verify(publicKey, "550e8400-e29b-41d4-a716-446655440001", timestamp, value, signature), "other scooter")
+	assert.False(t, Verify(publicKey, scooterID, "2025-06-01T12:00:01Z", value, signature), "other timestamp")
+	assert.False(t, Verify(publicKey, scooterID, timestamp, []byte(`{}`), signature), "other payload")
+	assert.False(t, Verify(publicKey, scooterID, timestamp, value, strings.TrimPrefix(signature, signatureVersion)), "missing version")
+	assert.False(t, Verify(publicKey, scooterID, timestamp, value, ""), "unsigned")
+	assert.False(t, Verify("", scooterID, timestamp, value, signature), "no key")
+}
+
+func TestPublicKey_RejectsInvalidSecrets(t *testing.T) {
+	for _, secret := range []string{"", "dev_", "dev_not-base64!", "dev_c2hvcnQ", "sk_" + strings.Repeat("A", 43)} {
+		_, err := PublicKey(secret)
+		assert.ErrorIs(t, err, ErrInvalidSecret, secret)
+	}
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner()
+
+	_, err := signer.Sign("scooter-1", "ts", []byte("payload"))
+	assert.ErrorIs(t, err, ErrNoSecret)
+
+	assert.ErrorIs(t, signer.SetSecret("scooter-1", "dev_secret"), ErrInvalidSecret)
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.NoError(t, signer.SetSecret("scooter-1", secret))
+	signature, err := signer.Sign("scooter-1", "ts", []byte("payload"))
+	require.NoError(t, err)
+
+	publicKey, err := PublicKey(secret)
+	require.NoError(t, err)
+	assert.True(t, Verify(publicKey, "scooter-1", "ts", []byte("payload"), signature))
+}
@@ -0,0 +1,196 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"scootin-aboot/internal/auth"
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/models"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	algHS256 = "HS256"
+	algRS256 = "RS256"
+)
+
+var (
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownSigningKey    = errors.New("unknown signing key")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrTokenExpired         = errors.New("token has expired")
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+	ErrInvalidSubject       = errors.New("token subject is not a user ID")
+)
+
+// Config selects the accepted signing keys and the claims every token must carry
+type Config struct {
+	// HMACSecret enables HS256 tokens
+	HMACSecret []byte
+	// RSAKeys enables RS256 tokens, keyed by the kid of their JWKS entry
+	RSAKeys map[string]*rsa.PublicKey
+
+	// Issuer and Audience are required; every token must name both
+	Issuer   string
+	Audience string
+	// ClockSkew is how far exp, nbf and iat may be off from the local clock
+	ClockSkew time.Duration
+}
+
+// Authenticator verifies rider JWTs and resolves them to user principals
+type Authenticator struct {
+	config  Config
+	methods []string
+	now     func() time.Time
+}
+
+func NewAuthenticator(config Config) (*Authenticator, error) {
+	if len(config.HMACSecret) == 0 && len(config.RSAKeys) == 0 {
+		return nil, errors.New("JWT authentication needs an HMAC secret or RSA keys")
+	}
+	if len(config.HMACSecret) > 0 && len(config.HMACSecret) < 32 {
+		return nil, errors.New("JWT HMAC secret must be at least 32 bytes")
+	}
+	if config.Issuer == "" {
+		return nil, errors.New("JWT authentication needs an issuer")
+	}
+	if config.Audience == "" {
+		return nil, errors.New("JWT authentication needs an audience")
+	}
+
+	// Only the algorithms a key was configured for are accepted, so an HS256 token can never be checked
+	// against an RSA public key and unsigned tokens are always rejected
+	var methods []string
+	if len(config.HMACSecret) > 0 {
+		methods = append(methods, algHS256)
+	}
+	if len(config.RSAKeys) > 0 {
+		methods = append(methods, algRS256)
+	}
+
+	return &Authenticator{config: config, methods: methods, now: time.Now}, nil
+}
+
+// Authenticate verifies the token's signature and claims and returns the rider it was issued to
+func (a *Authenticator) Authenticate(token string) (*auth.Principal, error) {
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil || userID == uuid.Nil {
+		return nil, ErrInvalidSubject
+	}
+
+	return &auth.Principal{
+		UserID: userID,
+		Name:   "user:" + userID.String(),
+		Scopes: slices.Clone(models.UserScopes),
+	}, nil
+}
+
+func (a *Authenticator) verify(token string) (*gojwt.RegisteredClaims, error) {
+	parser := gojwt.NewParser(
+		gojwt.WithValidMethods(a.methods),
+		gojwt.WithIssuer(a.config.Issuer),
+		gojwt.WithAudience(a.config.Audience),
+		gojwt.WithLeeway(a.config.ClockSkew),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithIssuedAt(),
+		gojwt.WithTimeFunc(a.now),
+	)
+
+	var claims gojwt.RegisteredClaims
+	parsed, err := parser.ParseWithClaims(token, &claims, a.key)
+	if err != nil {
+		return nil, a.mapError(parsed, &claims, err)
+	}
+	return &claims, nil
+}
+
+// key returns the key that verifies the token's signature
+func (a *Authenticator) key(token *gojwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case algHS256:
+		return a.config.HMACSecret, nil
+	case algRS256:
+		kid, _ := token.Header["kid"].(string)
+		return a.rsaKey(kid)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+// rsaKey returns the JWKS key named by kid; a token without kid is accepted only when there is a single key
+func (a *Authenticator) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if key, ok := a.config.RSAKeys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(a.config.RSAKeys) == 1 {
+		for _, key := range a.config.RSAKeys {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// mapError translates a parser error into this package's errors, which the auth middleware reports to
+// clients. A missing required claim is reported as the check it fails.
+func (a *Authenticator) mapError(token *gojwt.Token, claims *gojwt.RegisteredClaims, err error) error {
+	switch {
+	case errors.Is(err, gojwt.ErrTokenMalformed):
+		return ErrMalformedToken
+	case errors.Is(err, ErrUnknownSigningKey):
+		return ErrUnknownSigningKey
+	case token != nil && (token.Method == nil || !slices.Contains(a.methods, token.Method.Alg())):
+		return ErrUnsupportedAlgorithm
+	case errors.Is(err, gojwt.ErrTokenSignatureInvalid):
+		return ErrInvalidSignature
+	case errors.Is(err, gojwt.ErrTokenExpired), claims.ExpiresAt == nil:
+		return ErrTokenExpired
+	case errors.Is(err, gojwt.ErrTokenNotValidYet), errors.Is(err, gojwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotYetValid
+	case errors.Is(err, gojwt.ErrTokenInvalidIssuer), claims.Issuer == "":
+		return ErrInvalidIssuer
+	case errors.Is(err, gojwt.ErrTokenInvalidAudience), len(claims.Audience) == 0:
+		return ErrInvalidAudience
+	default:
+		return ErrMalformedToken
+	}
+}
+
+// LooksLikeJWT reports whether a bearer credential has the three dot-separated segments of a JWS,
+// which generated API keys never contain
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// NewAuthenticatorFromConfig builds an authenticator from the server configuration, loading the JWKS file
+func NewAuthenticatorFromConfig(cfg *config.JWTConfig) (*Authenticator, error) {
+	authConfig := Config{
+		HMACSecret: []byte(cfg.HMACSecret),
+		Issuer:     cfg.Issuer,
+		Audience:   cfg.Audience,
+		ClockSkew:  cfg.ClockSkew,
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		authConfig.RSAKeys = keys
+	}
+
+	return NewAuthenticator(authConfig)
+}
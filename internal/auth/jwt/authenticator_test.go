@@ -0,0 +1,281 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testUserID = uuid.MustParse("987fcdeb-51a2-43d7-8f9e-123456789abc")
+	testNow    = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret []byte, header, claims map[string]any) string {
+	t.Helper()
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]any) string {
+	t.Helper()
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss": "https://auth.scootin.example",
+		"sub": testUserID.String(),
+		"aud": "scootin-api",
+		"iat": testNow.Add(-time.Minute).Unix(),
+		"exp": testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func withClaim(name string, value any) map[string]any {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func newTestAuthenticator(t *testing.T, cfg Config) *Authenticator {
+	t.Helper()
+	cfg.Issuer = "https://auth.scootin.example"
+	cfg.Audience = "scootin-api"
+	cfg.ClockSkew = 30 * time.Second
+
+	authenticator, err := NewAuthenticator(cfg)
+	require.NoError(t, err)
+	authenticator.now = func() time.Time { return testNow }
+	return authenticator
+}
+
+func TestAuthenticator_HS256(t *testing.T) {
+	authenticator := newTestAuthenticator(t, Config{HMACSecret: testSecret})
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+
+	tests := []struct {
+		name          string
+		token         string
+		expectedError error
+	}{
+		{name: "valid token", token: signHS256(t, testSecret, hs256, validClaims())},
+		{name: "audience array", token: signHS256(t, testSecret, hs256, withClaim("aud", []string{"other", "scootin-api"}))},
+		{name: "expired within clock skew", token: signHS256(t, testSecret, hs256, withClaim("exp", testNow.Add(-10*time.Second).Unix()))},
+		{name: "not before within clock skew", token: signHS256(t, testSecret, hs256, withClaim("nbf", testNow.Add(10*time.Second).Unix()))},
+		{
+			name:          "wrong secret",
+			token:         signHS256(t, []byte("another-secret-another-secret-!!"), hs256, validClaims()),
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "expired beyond clock skew",
+			token:         signHS256(t, testSecret, hs256, withClaim("exp", testNow.Add(-time.Minute).Unix())),
+			expectedError: ErrTokenExpired,
+		},
+		{
+			name:          "missing expiry",
+			token:         signHS256(t, testSecret, hs256, withClaim("exp", nil)),
+			expectedError: ErrTokenExpired,
+		},
+		{
+			name:          "not before beyond clock skew",
+			token:         signHS256(t, testSecret, hs256, withClaim("nbf", testNow.Add(time.Minute).Unix())),
+			expectedError: ErrTokenNotYetValid,
+		},
+		{
+			name:          "wrong issuer",
+			token:         signHS256(t, testSecret, hs256, withClaim("iss", "https://evil.example")),
+			expectedError: ErrInvalidIssuer,
+		},
+		{
+			name:          "missing issuer",
+			token:         signHS256(t, testSecret, hs256, withClaim("iss", nil)),
+			expectedError: ErrInvalidIssuer,
+		},
+		{
+			name:          "missing audience",
+			token:         signHS256(t, testSecret, hs256, withClaim("aud", nil)),
+			expectedError: ErrInvalidAudience,
+		},
+		{
+			name:          "issued in the future",
+			token:         signHS256(t, testSecret, hs256, withClaim("iat", testNow.Add(time.Minute).Unix())),
+			expectedError: ErrTokenNotYetValid,
+		},
+		{
+			name:          "wrong audience",
+			token:         signHS256(t, testSecret, hs256, withClaim("aud", "another-api")),
+			expectedError: ErrInvalidAudience,
+		},
+		{
+			name:          "subject is not a user ID",
+			token:         signHS256(t, testSecret, hs256, withClaim("sub", "rider@example.com")),
+			expectedError: ErrInvalidSubject,
+		},
+		{
+			name:          "unsigned token",
+			token:         encodeSegment(t, map[string]any{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + ".",
+			expectedError: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:          "RS256 token without RSA keys",
+			token:         signHS256(t, testSecret, map[string]any{"alg": "RS256"}, validClaims()),
+			expectedError: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:          "malformed token",
+			token:         "not.a-token",
+			expectedError: ErrMalformedToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(tt.token)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, principal)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testUserID, principal.UserID)
+			assert.True(t, principal.IsUser())
+			assert.ElementsMatch(t, models.UserScopes, principal.Scopes)
+			assert.False(t, principal.HasScope(models.ScopeAdmin))
+		})
+	}
+}
+
+func writeJWKS(t *testing.T, keys map[string]*rsa.PublicKey) string {
+	t.Helper()
+	var set jwks
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	// Encryption keys and other key types are ignored
+	set.Keys = append(set.Keys, jwk{Kty: "EC", Kid: "ec"}, jwk{Kty: "RSA", Kid: "enc", Use: "enc"})
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestAuthenticator_RS256WithJWKS(t *testing.T) {
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	previous, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := writeJWKS(t, map[string]*rsa.PublicKey{"current": &current.PublicKey, "previous": &previous.PublicKey})
+	keys, err := LoadJWKS(path)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	authenticator := newTestAuthenticator(t, Config{RSAKeys: keys, HMACSecret: testSecret})
+
+	principal, err := authenticator.Authenticate(signRS256(t, current, map[string]any{"alg": "RS256", "kid": "current"}, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, testUserID, principal.UserID)
+
+	_, err = authenticator.Authenticate(signRS256(t, previous, map[string]any{"alg": "RS256", "kid": "previous"}, validClaims()))
+	assert.NoError(t, err, "rotated keys stay valid while they are in the JWKS")
+
+	_, err = authenticator.Authenticate(signRS256(t, previous, map[string]any{"alg": "RS256", "kid": "current"}, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = authenticator.Authenticate(signRS256(t, unknown, map[string]any{"alg": "RS256", "kid": "unknown"}, validClaims()))
+	assert.ErrorIs(t, err, ErrUnknownSigningKey)
+
+	_, err = authenticator.Authenticate(signRS256(t, current, map[string]any{"alg": "RS256"}, validClaims()))
+	assert.ErrorIs(t, err, ErrUnknownSigningKey, "kid is required when the JWKS has several keys")
+}
+
+func TestLoadJWKS_RejectsUnusableSets(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = LoadJWKS(writeJWKS(t, map[string]*rsa.PublicKey{"small": &small.PublicKey}))
+	assert.ErrorContains(t, err, "at least 2048 bits")
+
+	_, err = LoadJWKS(writeJWKS(t, nil))
+	assert.ErrorContains(t, err, "no RSA signing keys")
+
+	_, err = LoadJWKS(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestNewAuthenticatorFromConfig(t *testing.T) {
+	_, err := NewAuthenticatorFromConfig(&config.JWTConfig{})
+	assert.Error(t, err)
+
+	_, err = NewAuthenticatorFromConfig(&config.JWTConfig{HMACSecret: "too-short"})
+	assert.ErrorContains(t, err, "at least 32 bytes")
+
+	_, err = NewAuthenticatorFromConfig(&config.JWTConfig{HMACSecret: string(testSecret), Audience: "scootin-api"})
+	assert.ErrorContains(t, err, "needs an issuer")
+
+	_, err = NewAuthenticatorFromConfig(&config.JWTConfig{HMACSecret: string(testSecret), Issuer: "https://auth.scootin.example"})
+	assert.ErrorContains(t, err, "needs an audience")
+
+	authenticator, err := NewAuthenticatorFromConfig(&config.JWTConfig{
+		HMACSecret: string(testSecret),
+		Issuer:     "https://auth.scootin.example",
+		Audience:   "scootin-api",
+		ClockSkew:  time.Minute,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, authenticator.config.ClockSkew)
+}
+
+func TestLooksLikeJWT(t *testing.T) {
+	assert.True(t, LooksLikeJWT("eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln"))
+	assert.False(t, LooksLikeJWT("sk_9c1Zr2m0x3B0p9Vt4uKq7Yh6Ls5Nw8Dj2Fa1Ge3Ho4I"))
+	assert.False(t, LooksLikeJWT("test-api-key-12345"))
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"slices"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Principal is the identity an authenticated request acts as: an integration holding an API key,
+// or a rider holding a JWT
+type Principal struct {
+	// KeyID identifies the API key; uuid.Nil for the bootstrap key and for riders
+	KeyID uuid.UUID
+	// UserID is the rider a JWT was issued to; uuid.Nil for API keys
+	UserID uuid.UUID
+	Name   string
+	Scopes []string
+}
+
+// HasScope reports whether the principal was granted scope; admin grants every scope
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, models.ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// IsUser reports whether the principal acts on behalf of a single rider, who may only touch their own trips
+func (p *Principal) IsUser() bool {
+	return p.UserID != uuid.Nil
+}
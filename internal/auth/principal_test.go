@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestPrincipal_HasScope(t *testing.T) {
+	admin := &Principal{Scopes: []string{models.ScopeAdmin}}
+	reader := &Principal{Scopes: []string{models.ScopeScootersRead}}
+
+	if !admin.HasScope(models.ScopeTripsWrite) {
+		t.Errorf("Expected admin to have every scope")
+	}
+	if !reader.HasScope(models.ScopeScootersRead) {
+		t.Errorf("Expected reader to have %s", models.ScopeScootersRead)
+	}
+	if reader.HasScope(models.ScopeTripsWrite) || reader.HasScope(models.ScopeAdmin) {
+		t.Errorf("Expected reader to lack write and admin scopes")
+	}
+}
+
+func TestPrincipal_IsUser(t *testing.T) {
+	if (&Principal{KeyID: uuid.New()}).IsUser() {
+		t.Errorf("Expected an API key principal not to be a user")
+	}
+	if !(&Principal{UserID: uuid.New()}).IsUser() {
+		t.Errorf("Expected a JWT principal to be a user")
+	}
+}
@@ -1,11 +1,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +16,15 @@ type Config struct {
 	APIKey     string
 	ServerPort string
 	ServerHost string
+	// TrustedProxies are the addresses or CIDRs of the proxies whose X-Forwarded-For and X-Real-IP headers
+	// are believed; with none, the client IP is the address of the connection
+	TrustedProxies []string
+
+	// StorageBackend selects the repository implementation: StorageBackendPostgres or StorageBackendMemory
+	StorageBackend string
+	// StorageSeedDir holds the users.sql and scooters.sql fixtures loaded into the memory backend on start;
+	// empty starts it without data
+	StorageSeedDir string
 
 	DBHost     string
 	DBPort     string
@@ -33,24 +44,197 @@ type Config struct {
 
 	KafkaConfig KafkaConfig
 
+	OutboxConfig OutboxConfig
+
+	JWTConfig JWTConfig
+
+	RateLimitConfig RateLimitConfig
+
+	ReservationConfig ReservationConfig
+	BatteryConfig     BatteryConfig
+	GeofenceConfig    GeofenceConfig
+	PricingConfig     PricingConfig
+	StreamConfig      StreamConfig
+
+	TracingConfig TracingConfig
+
 	LogLevel  string
 	LogFormat string
 }
 
 type KafkaConfig struct {
+	// Transport selects the message bus: KafkaTransportKafka or KafkaTransportLocal
+	Transport string
+	// LocalDir is where the local transport keeps topic logs and consumer offsets
+	LocalDir string
+
 	Brokers          []string
 	ClientID         string
 	SecurityProtocol string
 	Topics           KafkaTopics
+	Retry            KafkaRetryConfig
+
+	// UseLifecycleTopic publishes all scooter events on Topics.Lifecycle instead of one topic per event type
+	UseLifecycleTopic bool
+
+	// RequireSignatures makes the consumer verify each event's device signature and divert unsigned or
+	// invalid messages to Topics.Rejected instead of handling them
+	RequireSignatures bool
+	// SignatureMaxSkew is how far a signed event's timestamp may be from the consumer's clock; older
+	// events are rejected as possible replays
+	SignatureMaxSkew time.Duration
 }
 
 type KafkaTopics struct {
 	TripStarted     string
 	TripEnded       string
 	LocationUpdated string
+
+	// Dead-letter topics receive messages that could not be processed from the matching source topic
+	TripStartedDLQ     string
+	TripEndedDLQ       string
+	LocationUpdatedDLQ string
+
+	// Lifecycle carries trip and location events together, keyed by scooter ID, when UseLifecycleTopic is set
+	Lifecycle    string
+	LifecycleDLQ string
+
+	// ServerEvents carries the server's own state changes relayed from the outbox, keyed by scooter ID
+	ServerEvents string
+
+	// Rejected receives messages whose device signature is missing or invalid when signatures are required
+	Rejected string
 }
 
-// City configuration constants
+// DeadLetterTopics maps each source topic to its dead-letter topic
+func (t KafkaTopics) DeadLetterTopics() map[string]string {
+	topics := map[string]string{
+		t.TripStarted:     t.TripStartedDLQ,
+		t.TripEnded:       t.TripEndedDLQ,
+		t.LocationUpdated: t.LocationUpdatedDLQ,
+	}
+	if t.Lifecycle != "" {
+		topics[t.Lifecycle] = t.LifecycleDLQ
+	}
+	return topics
+}
+
+// KafkaRetryConfig controls how the consumer retries transient processing failures
+type KafkaRetryConfig struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// OutboxConfig controls the relay that publishes outbox events to Kafka
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is how many times an event is tried before it is marked failed and skipped
+	MaxAttempts int
+}
+
+// JWTConfig enables rider authentication with JWTs; it is disabled when neither HMACSecret nor JWKSFile is set
+type JWTConfig struct {
+	// HMACSecret verifies HS256 tokens
+	HMACSecret string
+	// JWKSFile holds the RSA public keys that verify RS256 tokens
+	JWKSFile string
+
+	Issuer    string
+	Audience  string
+	ClockSkew time.Duration
+}
+
+// Enabled reports whether any signing key is configured
+func (c JWTConfig) Enabled() bool {
+	return c.HMACSecret != "" || c.JWKSFile != ""
+}
+
+// ReservationConfig sets how long riders may hold a scooter and how often expired holds are released
+type ReservationConfig struct {
+	Duration      time.Duration
+	SweepInterval time.Duration
+}
+
+// BatteryConfig sets the charge, as a percentage, below which scooters are kept out of dispatch
+type BatteryConfig struct {
+	// SearchThreshold hides scooters reporting a lower charge from closest scooter searches
+	SearchThreshold int
+	// MinTripLevel is the lowest charge a trip can be started with
+	MinTripLevel int
+}
+
+// GeofenceConfig points at the GeoJSON file of service areas, no-parking and slow zones
+type GeofenceConfig struct {
+	// File is read at startup; when empty no zones apply and trips can end anywhere
+	File string
+}
+
+// PricingConfig points at the JSON file of tariffs trips are priced by
+type PricingConfig struct {
+	// File is read at startup; when empty trips end without a fare
+	File string
+}
+
+// StreamConfig sizes the live scooter stream
+type StreamConfig struct {
+	// BufferSize is how many of the latest changes are kept for clients resuming with Last-Event-ID
+	BufferSize int
+	// ClientBuffer is how many changes may queue for a client before it is disconnected as too slow
+	ClientBuffer int
+	// HeartbeatInterval is how often an idle stream is sent a comment to keep proxies from closing it
+	HeartbeatInterval time.Duration
+}
+
+// TracingConfig selects where spans are sent
+type TracingConfig struct {
+	// Exporter is TracingExporterNone, TracingExporterOTLP or TracingExporterStdout. Trace context is
+	// propagated whichever is chosen, so a service that exports nothing does not break its callers' traces.
+	Exporter string
+	// OTLPEndpoint is the URL of the OTLP/HTTP collector, such as http://localhost:4318
+	OTLPEndpoint string
+	// SampleRatio is the share of new traces recorded; traces started upstream follow the caller's decision
+	SampleRatio float64
+}
+
+// RateLimitConfig sets the request quotas. Policies are written "<requests>/<period>", for example "600/1m".
+type RateLimitConfig struct {
+	Enabled bool
+	// Default is the quota of each API key, rider or, for unauthenticated requests, client IP
+	Default string
+	// Routes adds quotas for single routes as comma-separated "<METHOD> <path>=<policy>" entries,
+	// with the path as registered with the router, e.g. "GET /api/v1/scooters/:id=60/1m"
+	Routes string
+	// Keys replaces Default for the listed API keys as comma-separated "<key name or ID>=<policy>" entries
+	Keys string
+	// AuthFailures is the quota of failed authentications per client IP; once it is used up the IP gets 429
+	// before its credentials are checked
+	AuthFailures string
+}
+
+// Storage backends
+const (
+	StorageBackendPostgres = "postgres"
+	StorageBackendMemory   = "memory"
+)
+
+// Trace exporters
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// Event transports
+const (
+	KafkaTransportKafka = "kafka"
+	KafkaTransportLocal = "local"
+)
+
+// City configuration constants. The simulator places scooters in these circles when no service areas
+// are configured.
 const (
 	OttawaCenterLat   = 45.4215
 	OttawaCenterLng   = -75.6972
@@ -69,6 +253,11 @@ func Load() (*Config, error) {
 		ServerPort: getEnv("SERVER_PORT", "8080"),
 		ServerHost: getEnv("SERVER_HOST", "localhost"),
 
+		TrustedProxies: getEnvAsStringSlice("TRUSTED_PROXIES", nil),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", StorageBackendPostgres),
+		StorageSeedDir: getEnvAllowEmpty("STORAGE_SEED_DIR", "seeds"),
+
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBName:     getEnv("DB_NAME", "scootin_aboot"),
@@ -86,6 +275,8 @@ func Load() (*Config, error) {
 		SimulatorRestMax:         getEnvAsInt("SIMULATOR_REST_MAX", 5),
 
 		KafkaConfig: KafkaConfig{
+			Transport:        getEnv("KAFKA_TRANSPORT", KafkaTransportKafka),
+			LocalDir:         getEnv("KAFKA_LOCAL_DIR", "data/events"),
 			Brokers:          getEnvAsStringSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
 			ClientID:         getEnv("KAFKA_CLIENT_ID", "scooter-simulator"),
 			SecurityProtocol: getEnv("KAFKA_SECURITY_PROTOCOL", "PLAINTEXT"),
@@ -93,13 +284,123 @@ func Load() (*Config, error) {
 				TripStarted:     getEnv("KAFKA_TOPIC_TRIP_STARTED", "scooter.trip.started"),
 				TripEnded:       getEnv("KAFKA_TOPIC_TRIP_ENDED", "scooter.trip.ended"),
 				LocationUpdated: getEnv("KAFKA_TOPIC_LOCATION_UPDATED", "scooter.location.updated"),
+
+				TripStartedDLQ:     getEnv("KAFKA_TOPIC_TRIP_STARTED_DLQ", "scooter.trip.started.dlq"),
+				TripEndedDLQ:       getEnv("KAFKA_TOPIC_TRIP_ENDED_DLQ", "scooter.trip.ended.dlq"),
+				LocationUpdatedDLQ: getEnv("KAFKA_TOPIC_LOCATION_UPDATED_DLQ", "scooter.location.updated.dlq"),
+
+				Lifecycle:    getEnv("KAFKA_TOPIC_LIFECYCLE", "scooter.lifecycle"),
+				LifecycleDLQ: getEnv("KAFKA_TOPIC_LIFECYCLE_DLQ", "scooter.lifecycle.dlq"),
+
+				ServerEvents: getEnv("KAFKA_TOPIC_SERVER_EVENTS", "scootin.server.events"),
+
+				Rejected: getEnv("KAFKA_TOPIC_REJECTED", "scooter.events.rejected"),
+			},
+			Retry: KafkaRetryConfig{
+				MaxAttempts:       getEnvAsInt("KAFKA_RETRY_MAX_ATTEMPTS", 5),
+				InitialBackoff:    getEnvAsDuration("KAFKA_RETRY_INITIAL_BACKOFF", 100*time.Millisecond),
+				MaxBackoff:        getEnvAsDuration("KAFKA_RETRY_MAX_BACKOFF", 5*time.Second),
+				BackoffMultiplier: getEnvAsFloat64("KAFKA_RETRY_BACKOFF_MULTIPLIER", 2.0),
 			},
+			UseLifecycleTopic: getEnvAsBool("KAFKA_USE_LIFECYCLE_TOPIC", false),
+			RequireSignatures: getEnvAsBool("KAFKA_REQUIRE_SIGNATURES", true),
+			SignatureMaxSkew:  getEnvAsDuration("KAFKA_SIGNATURE_MAX_SKEW", 5*time.Minute),
+		},
+
+		OutboxConfig: OutboxConfig{
+			PollInterval: getEnvAsDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+		},
+
+		JWTConfig: JWTConfig{
+			HMACSecret: getEnv("JWT_HMAC_SECRET", ""),
+			JWKSFile:   getEnv("JWT_JWKS_FILE", ""),
+			Issuer:     getEnv("JWT_ISSUER", ""),
+			Audience:   getEnv("JWT_AUDIENCE", ""),
+			ClockSkew:  getEnvAsDuration("JWT_CLOCK_SKEW", time.Minute),
+		},
+
+		RateLimitConfig: RateLimitConfig{
+			Enabled:      getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Default:      getEnv("RATE_LIMIT_DEFAULT", "600/1m"),
+			Routes:       getEnv("RATE_LIMIT_ROUTES", "GET /api/v1/scooters/closest=120/1m"),
+			Keys:         getEnv("RATE_LIMIT_KEYS", ""),
+			AuthFailures: getEnv("RATE_LIMIT_AUTH_FAILURES", "20/1m"),
+		},
+
+		ReservationConfig: ReservationConfig{
+			Duration:      getEnvAsDuration("RESERVATION_DURATION", 10*time.Minute),
+			SweepInterval: getEnvAsDuration("RESERVATION_SWEEP_INTERVAL", 15*time.Second),
+		},
+
+		BatteryConfig: BatteryConfig{
+			SearchThreshold: getEnvAsInt("BATTERY_SEARCH_THRESHOLD", 20),
+			MinTripLevel:    getEnvAsInt("BATTERY_MIN_TRIP_LEVEL", 15),
+		},
+
+		GeofenceConfig: GeofenceConfig{
+			File: getEnvAllowEmpty("GEOFENCE_FILE", "geofences/zones.geojson"),
+		},
+
+		PricingConfig: PricingConfig{
+			File: getEnvAllowEmpty("PRICING_FILE", "pricing/tariffs.json"),
+		},
+
+		StreamConfig: StreamConfig{
+			BufferSize:        getEnvAsInt("STREAM_BUFFER_SIZE", 1000),
+			ClientBuffer:      getEnvAsInt("STREAM_CLIENT_BUFFER", 64),
+			HeartbeatInterval: getEnvAsDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second),
+		},
+
+		TracingConfig: TracingConfig{
+			Exporter:     getEnv("TRACING_EXPORTER", TracingExporterNone),
+			OTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", "http://localhost:4318"),
+			SampleRatio:  getEnvAsFloat64("TRACING_SAMPLE_RATIO", 1.0),
 		},
 
 		LogLevel:  getEnv("LOG_LEVEL", "info"),
 		LogFormat: getEnv("LOG_FORMAT", "json"),
 	}
 
+	if config.StorageBackend != StorageBackendPostgres && config.StorageBackend != StorageBackendMemory {
+		return nil, fmt.Errorf("invalid STORAGE_BACKEND %q: must be %q or %q", config.StorageBackend, StorageBackendPostgres, StorageBackendMemory)
+	}
+
+	if config.KafkaConfig.Transport != KafkaTransportKafka && config.KafkaConfig.Transport != KafkaTransportLocal {
+		return nil, fmt.Errorf("invalid KAFKA_TRANSPORT %q: must be %q or %q", config.KafkaConfig.Transport, KafkaTransportKafka, KafkaTransportLocal)
+	}
+
+	if level := config.BatteryConfig.SearchThreshold; level < 0 || level > 100 {
+		return nil, fmt.Errorf("invalid BATTERY_SEARCH_THRESHOLD %d: must be between 0 and 100", level)
+	}
+	if level := config.BatteryConfig.MinTripLevel; level < 0 || level > 100 {
+		return nil, fmt.Errorf("invalid BATTERY_MIN_TRIP_LEVEL %d: must be between 0 and 100", level)
+	}
+
+	if size := config.StreamConfig.BufferSize; size < 0 {
+		return nil, fmt.Errorf("invalid STREAM_BUFFER_SIZE %d: must not be negative", size)
+	}
+	if size := config.StreamConfig.ClientBuffer; size < 1 {
+		return nil, fmt.Errorf("invalid STREAM_CLIENT_BUFFER %d: must be at least 1", size)
+	}
+	if interval := config.StreamConfig.HeartbeatInterval; interval <= 0 {
+		return nil, fmt.Errorf("invalid STREAM_HEARTBEAT_INTERVAL %s: must be positive", interval)
+	}
+
+	if config.JWTConfig.Enabled() && (config.JWTConfig.Issuer == "" || config.JWTConfig.Audience == "") {
+		return nil, errors.New("JWT_ISSUER and JWT_AUDIENCE are required when JWT authentication is enabled")
+	}
+
+	switch config.TracingConfig.Exporter {
+	case TracingExporterNone, TracingExporterOTLP, TracingExporterStdout:
+	default:
+		return nil, fmt.Errorf("invalid TRACING_EXPORTER %q: must be %q, %q or %q", config.TracingConfig.Exporter, TracingExporterNone, TracingExporterOTLP, TracingExporterStdout)
+	}
+	if ratio := config.TracingConfig.SampleRatio; ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO %g: must be between 0 and 1", ratio)
+	}
+
 	return config, nil
 }
 
@@ -110,6 +411,14 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvAllowEmpty is getEnv for settings that are turned off by setting them to an empty value
+func getEnvAllowEmpty(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
 func getEnvAsInt(key string, fallback int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -119,6 +428,33 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvAsBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+func getEnvAsFloat64(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return fallback
+}
+
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return fallback
+}
+
 func getEnvAsStringSlice(key string, fallback []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Split by comma and trim whitespace
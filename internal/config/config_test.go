@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
@@ -44,3 +45,223 @@ func TestDatabaseURL(t *testing.T) {
 	actual := config.GetDatabaseURL()
 	assert.Equal(t, expected, actual)
 }
+
+func TestKafkaTopicsDeadLetterTopics(t *testing.T) {
+	topics := KafkaTopics{
+		TripStarted:        "trip-started",
+		TripEnded:          "trip-ended",
+		LocationUpdated:    "location-updated",
+		TripStartedDLQ:     "trip-started.dlq",
+		TripEndedDLQ:       "trip-ended.dlq",
+		LocationUpdatedDLQ: "location-updated.dlq",
+	}
+
+	expected := map[string]string{
+		"trip-started":     "trip-started.dlq",
+		"trip-ended":       "trip-ended.dlq",
+		"location-updated": "location-updated.dlq",
+	}
+	assert.Equal(t, expected, topics.DeadLetterTopics())
+
+	topics.Lifecycle = "lifecycle"
+	topics.LifecycleDLQ = "lifecycle.dlq"
+	assert.Equal(t, "lifecycle.dlq", topics.DeadLetterTopics()["lifecycle"])
+}
+
+func TestConfigLoadStorageBackend(t *testing.T) {
+	t.Setenv("STORAGE_BACKEND", StorageBackendMemory)
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, StorageBackendMemory, config.StorageBackend)
+	assert.Equal(t, "seeds", config.StorageSeedDir)
+
+	t.Setenv("STORAGE_SEED_DIR", "")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.StorageSeedDir)
+
+	t.Setenv("STORAGE_BACKEND", "sqlite")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestConfigLoadKafkaTransport(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, KafkaTransportKafka, config.KafkaConfig.Transport)
+
+	t.Setenv("KAFKA_TRANSPORT", KafkaTransportLocal)
+	t.Setenv("KAFKA_LOCAL_DIR", "/tmp/scootin-events")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, KafkaTransportLocal, config.KafkaConfig.Transport)
+	assert.Equal(t, "/tmp/scootin-events", config.KafkaConfig.LocalDir)
+
+	t.Setenv("KAFKA_TRANSPORT", "rabbitmq")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestConfigLoadJWT(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.JWTConfig.Enabled())
+	assert.Equal(t, time.Minute, config.JWTConfig.ClockSkew)
+
+	t.Setenv("JWT_JWKS_FILE", "/etc/scootin/jwks.json")
+	t.Setenv("JWT_ISSUER", "https://auth.scootin.example")
+	t.Setenv("JWT_AUDIENCE", "scootin-api")
+	t.Setenv("JWT_CLOCK_SKEW", "15s")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.JWTConfig.Enabled())
+	assert.Equal(t, "https://auth.scootin.example", config.JWTConfig.Issuer)
+	assert.Equal(t, "scootin-api", config.JWTConfig.Audience)
+	assert.Equal(t, 15*time.Second, config.JWTConfig.ClockSkew)
+
+	t.Setenv("JWT_AUDIENCE", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "JWT_AUDIENCE")
+
+	t.Setenv("JWT_AUDIENCE", "scootin-api")
+	t.Setenv("JWT_ISSUER", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "JWT_ISSUER")
+}
+
+func TestConfigLoadSignatures(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.KafkaConfig.RequireSignatures)
+	assert.Equal(t, "scooter.events.rejected", config.KafkaConfig.Topics.Rejected)
+	assert.Equal(t, 5*time.Minute, config.KafkaConfig.SignatureMaxSkew)
+
+	t.Setenv("KAFKA_REQUIRE_SIGNATURES", "false")
+	t.Setenv("KAFKA_TOPIC_REJECTED", "rejected")
+	t.Setenv("KAFKA_SIGNATURE_MAX_SKEW", "30s")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.False(t, config.KafkaConfig.RequireSignatures)
+	assert.Equal(t, "rejected", config.KafkaConfig.Topics.Rejected)
+	assert.Equal(t, 30*time.Second, config.KafkaConfig.SignatureMaxSkew)
+}
+
+func TestConfigLoadRateLimit(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.RateLimitConfig.Enabled)
+	assert.Equal(t, "600/1m", config.RateLimitConfig.Default)
+	assert.Equal(t, "GET /api/v1/scooters/closest=120/1m", config.RateLimitConfig.Routes)
+	assert.Equal(t, "20/1m", config.RateLimitConfig.AuthFailures)
+	assert.Empty(t, config.TrustedProxies)
+
+	t.Setenv("RATE_LIMIT_ENABLED", "false")
+	t.Setenv("RATE_LIMIT_KEYS", "simulator=6000/1m")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.False(t, config.RateLimitConfig.Enabled)
+	assert.Equal(t, "simulator=6000/1m", config.RateLimitConfig.Keys)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, config.TrustedProxies)
+}
+
+func TestConfigLoadReservations(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, config.ReservationConfig.Duration)
+	assert.Equal(t, 15*time.Second, config.ReservationConfig.SweepInterval)
+
+	t.Setenv("RESERVATION_DURATION", "5m")
+	t.Setenv("RESERVATION_SWEEP_INTERVAL", "1m")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, config.ReservationConfig.Duration)
+	assert.Equal(t, time.Minute, config.ReservationConfig.SweepInterval)
+}
+
+func TestConfigLoadBattery(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 20, config.BatteryConfig.SearchThreshold)
+	assert.Equal(t, 15, config.BatteryConfig.MinTripLevel)
+
+	t.Setenv("BATTERY_SEARCH_THRESHOLD", "30")
+	t.Setenv("BATTERY_MIN_TRIP_LEVEL", "10")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 30, config.BatteryConfig.SearchThreshold)
+	assert.Equal(t, 10, config.BatteryConfig.MinTripLevel)
+
+	t.Setenv("BATTERY_MIN_TRIP_LEVEL", "101")
+	_, err = Load()
+	assert.ErrorContains(t, err, "BATTERY_MIN_TRIP_LEVEL")
+}
+
+func TestConfigLoadGeofences(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "geofences/zones.geojson", config.GeofenceConfig.File)
+
+	t.Setenv("GEOFENCE_FILE", "")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.GeofenceConfig.File)
+}
+
+func TestConfigLoadPricing(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "pricing/tariffs.json", config.PricingConfig.File)
+
+	t.Setenv("PRICING_FILE", "")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.PricingConfig.File)
+}
+
+func TestConfigLoadStream(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1000, config.StreamConfig.BufferSize)
+	assert.Equal(t, 64, config.StreamConfig.ClientBuffer)
+	assert.Equal(t, 15*time.Second, config.StreamConfig.HeartbeatInterval)
+
+	t.Setenv("STREAM_BUFFER_SIZE", "50")
+	t.Setenv("STREAM_CLIENT_BUFFER", "8")
+	t.Setenv("STREAM_HEARTBEAT_INTERVAL", "30s")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 50, config.StreamConfig.BufferSize)
+	assert.Equal(t, 8, config.StreamConfig.ClientBuffer)
+	assert.Equal(t, 30*time.Second, config.StreamConfig.HeartbeatInterval)
+
+	t.Setenv("STREAM_CLIENT_BUFFER", "0")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestConfigLoadTracing(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, TracingExporterNone, config.TracingConfig.Exporter)
+	assert.Equal(t, "http://localhost:4318", config.TracingConfig.OTLPEndpoint)
+	assert.Equal(t, 1.0, config.TracingConfig.SampleRatio)
+
+	t.Setenv("TRACING_EXPORTER", "otlp")
+	t.Setenv("TRACING_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, TracingExporterOTLP, config.TracingConfig.Exporter)
+	assert.Equal(t, "http://collector:4318", config.TracingConfig.OTLPEndpoint)
+	assert.Equal(t, 0.25, config.TracingConfig.SampleRatio)
+
+	t.Setenv("TRACING_SAMPLE_RATIO", "2")
+	_, err = Load()
+	assert.Error(t, err)
+
+	t.Setenv("TRACING_SAMPLE_RATIO", "1")
+	t.Setenv("TRACING_EXPORTER", "jaeger")
+	_, err = Load()
+	assert.Error(t, err)
+}
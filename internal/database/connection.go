@@ -8,9 +8,11 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func ConnectDatabase(dsn string) (*sql.DB, error) {
+// ConnectDatabase opens the connection pool and registers its statistics with registerer, which may be nil
+func ConnectDatabase(dsn string, registerer prometheus.Registerer) (*sql.DB, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -26,6 +28,10 @@ func ConnectDatabase(dsn string) (*sql.DB, error) {
 	db.SetConnMaxLifetime(0)
 	db.SetConnMaxIdleTime(0)
 
+	if registerer != nil {
+		registerPoolMetrics(db, registerer)
+	}
+
 	log.Println("Successfully connected to database")
 	return db, nil
 }
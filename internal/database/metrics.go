@@ -0,0 +1,35 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registerPoolMetrics exposes the connection pool statistics of db, read on every scrape
+func registerPoolMetrics(db *sql.DB, registerer prometheus.Registerer) {
+	registerer.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "scootin_db_open_connections",
+			Help:        "Open database connections, by whether they are in use or idle.",
+			ConstLabels: prometheus.Labels{"state": "in_use"},
+		}, func() float64 { return float64(db.Stats().InUse) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "scootin_db_open_connections",
+			Help:        "Open database connections, by whether they are in use or idle.",
+			ConstLabels: prometheus.Labels{"state": "idle"},
+		}, func() float64 { return float64(db.Stats().Idle) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "scootin_db_max_open_connections",
+			Help: "Maximum number of open database connections.",
+		}, func() float64 { return float64(db.Stats().MaxOpenConnections) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "scootin_db_wait_count_total",
+			Help: "Times a query waited for a free database connection.",
+		}, func() float64 { return float64(db.Stats().WaitCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "scootin_db_wait_duration_seconds_total",
+			Help: "Time spent waiting for a free database connection.",
+		}, func() float64 { return db.Stats().WaitDuration.Seconds() }),
+	)
+}
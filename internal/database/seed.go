@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Rows of the INSERT statements in seeds/users.sql and seeds/scooters.sql. Only the IDs, statuses and
+// locations are read; timestamps are set when the rows are created.
+var (
+	seedUserRow    = regexp.MustCompile(`(?m)^\('([0-9a-f-]{36})',`)
+	seedScooterRow = regexp.MustCompile(`(?m)^\('([0-9a-f-]{36})', '([a-z]+)', (-?[0-9.]+), (-?[0-9.]+),`)
+)
+
+// SeedRepository loads the users and scooters of the SQL fixtures in dir into repo, so a server on the
+// memory backend starts with the same fleet as a seeded database. It returns how many of each it created.
+func SeedRepository(ctx context.Context, repo repository.Repository, dir string) (users, scooters int, err error) {
+	usersSQL, err := os.ReadFile(filepath.Join(dir, "users.sql"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read user seeds: %w", err)
+	}
+	scootersSQL, err := os.ReadFile(filepath.Join(dir, "scooters.sql"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read scooter seeds: %w", err)
+	}
+
+	for _, row := range seedUserRow.FindAllStringSubmatch(string(usersSQL), -1) {
+		id, err := uuid.Parse(row[1])
+		if err != nil {
+			return users, scooters, fmt.Errorf("invalid seeded user ID %q: %w", row[1], err)
+		}
+		if err := repo.User().Create(ctx, &models.User{ID: id}); err != nil {
+			return users, scooters, fmt.Errorf("failed to seed user %s: %w", id, err)
+		}
+		users++
+	}
+
+	for _, row := range seedScooterRow.FindAllStringSubmatch(string(scootersSQL), -1) {
+		scooter, err := parseSeedScooter(row[1:])
+		if err != nil {
+			return users, scooters, err
+		}
+		if err := repo.Scooter().Create(ctx, scooter); err != nil {
+			return users, scooters, fmt.Errorf("failed to seed scooter %s: %w", scooter.ID, err)
+		}
+		scooters++
+	}
+
+	return users, scooters, nil
+}
+
+func parseSeedScooter(fields []string) (*models.Scooter, error) {
+	id, err := uuid.Parse(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid seeded scooter ID %q: %w", fields[0], err)
+	}
+	status := models.ScooterStatus(fields[1])
+	if !status.IsValid() {
+		return nil, fmt.Errorf("invalid status %q for seeded scooter %s", fields[1], id)
+	}
+	latitude, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude for seeded scooter %s: %w", id, err)
+	}
+	longitude, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude for seeded scooter %s: %w", id, err)
+	}
+
+	return &models.Scooter{
+		ID:               id,
+		Status:           status,
+		CurrentLatitude:  latitude,
+		CurrentLongitude: longitude,
+	}, nil
+}
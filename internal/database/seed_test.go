@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	users, scooters, err := SeedRepository(ctx, repo, filepath.Join("..", "..", "seeds"))
+	require.NoError(t, err)
+	assert.Equal(t, 10, users)
+	assert.Equal(t, 20, scooters)
+
+	_, err = repo.User().GetByID(ctx, uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"))
+	assert.NoError(t, err)
+
+	scooter, err := repo.Scooter().GetByID(ctx, uuid.MustParse("a1b2c3d4-e5f6-7890-abcd-ef1234567890"))
+	require.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusAvailable, scooter.Status)
+	assert.Equal(t, 45.4215, scooter.CurrentLatitude)
+	assert.Equal(t, -75.6972, scooter.CurrentLongitude)
+}
+
+func TestSeedRepository_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, _, err := SeedRepository(ctx, repository.NewMemoryRepository(), t.TempDir())
+	assert.ErrorContains(t, err, "failed to read user seeds")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "users.sql"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scooters.sql"),
+		[]byte("('a1b2c3d4-e5f6-7890-abcd-ef1234567890', 'parked', 45.4215, -75.6972, NOW(), NOW(), NOW()),\n"), 0o600))
+	_, _, err = SeedRepository(ctx, repository.NewMemoryRepository(), dir)
+	assert.ErrorContains(t, err, `invalid status "parked"`)
+}
@@ -3,38 +3,92 @@ package events
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	"scootin-aboot/internal/config"
 	"scootin-aboot/internal/logger"
 	"scootin-aboot/internal/services"
+	"scootin-aboot/internal/tracing"
 
 	"github.com/Shopify/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Headers attached to dead-lettered messages; the message value is the original payload
+const (
+	HeaderDLQSourceTopic     = "dlq-source-topic"
+	HeaderDLQSourcePartition = "dlq-source-partition"
+	HeaderDLQSourceOffset    = "dlq-source-offset"
+	HeaderDLQError           = "dlq-error"
+	HeaderDLQAttempts        = "dlq-attempts"
+	HeaderDLQPermanent       = "dlq-permanent"
+	HeaderDLQFailedAt        = "dlq-failed-at"
+)
+
+// messageSender is the subset of sarama.SyncProducer used to publish dead-lettered messages
+type messageSender interface {
+	SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error)
+	Close() error
+}
+
 type EventConsumer struct {
-	consumerGroup sarama.ConsumerGroup
-	config        *config.KafkaConfig
-	handlers      map[string]EventHandler
-	ctx           context.Context
-	cancel        context.CancelFunc
-	wg            sync.WaitGroup
+	consumerGroup    sarama.ConsumerGroup
+	config           *config.KafkaConfig
+	handlers         map[string]EventHandler
+	retryPolicy      RetryPolicy
+	deadLetterTopics map[string]string
+	deadLetter       messageSender
+	// verifier checks device signatures before dispatch; nil disables verification
+	verifier     *signatureVerifier
+	rejectionsMu sync.Mutex
+	rejections   map[string]int64
+	metrics      *consumerMetrics
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// attemptProcessingTime is the time allowed for one attempt at handling a message, or for dead-lettering it
+const attemptProcessingTime = 500 * time.Millisecond
+
+// maxProcessingTime bounds how long a message may take before sarama stops fetching for its partition. Retries
+// back off inside the handler, so it covers every attempt of the retry policy, the waits between them and the
+// dead-letter publish.
+func maxProcessingTime(policy RetryPolicy) time.Duration {
+	return policy.TotalBackoff() + time.Duration(policy.MaxAttempts+1)*attemptProcessingTime
 }
 
-func NewEventConsumer(cfg *config.KafkaConfig, tripService services.TripService, scooterService services.ScooterService) (*EventConsumer, error) {
+// NewEventConsumer creates a consumer for the event topics; registerer, which may be nil, receives its metrics
+func NewEventConsumer(cfg *config.KafkaConfig, tripService services.TripService, scooterService services.ScooterService, registerer prometheus.Registerer) (*EventConsumer, error) {
+	retryPolicy := NewRetryPolicy(cfg.Retry)
+
 	saramaConfig := sarama.NewConfig()
 	saramaConfig.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
 	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
 	saramaConfig.Consumer.Group.Session.Timeout = 10 * time.Second
 	saramaConfig.Consumer.Group.Heartbeat.Interval = 3 * time.Second
-	saramaConfig.Consumer.MaxProcessingTime = 500 * time.Millisecond
+	saramaConfig.Consumer.MaxProcessingTime = maxProcessingTime(retryPolicy)
 
-	consumerGroup, err := sarama.NewConsumerGroup(cfg.Brokers, "scooter-service", saramaConfig)
+	consumerGroup, err := newConsumerGroup(cfg, "scooter-service", saramaConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create consumer group: %w", err)
 	}
 
+	producerConfig := sarama.NewConfig()
+	producerConfig.Producer.RequiredAcks = sarama.WaitForAll
+	producerConfig.Producer.Retry.Max = 3
+	producerConfig.Producer.Return.Successes = true
+
+	deadLetter, err := newSyncProducer(cfg, producerConfig)
+	if err != nil {
+		consumerGroup.Close()
+		return nil, fmt.Errorf("failed to create dead-letter producer: %w", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	deps := HandlerDependencies{
@@ -47,14 +101,26 @@ func NewEventConsumer(cfg *config.KafkaConfig, tripService services.TripService,
 		cfg.Topics.TripEnded:       NewTripEndedHandler(deps),
 		cfg.Topics.LocationUpdated: NewLocationUpdatedHandler(deps),
 	}
+	if cfg.UseLifecycleTopic {
+		handlers[cfg.Topics.Lifecycle] = NewLifecycleHandler(deps)
+	}
+
+	consumer := &EventConsumer{
+		consumerGroup:    consumerGroup,
+		config:           cfg,
+		handlers:         handlers,
+		retryPolicy:      retryPolicy,
+		deadLetterTopics: cfg.Topics.DeadLetterTopics(),
+		deadLetter:       deadLetter,
+		metrics:          newConsumerMetrics(registerer),
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+	if cfg.RequireSignatures {
+		consumer.verifier = newSignatureVerifier(scooterService.GetDevicePublicKey, cfg.SignatureMaxSkew)
+	}
 
-	return &EventConsumer{
-		consumerGroup: consumerGroup,
-		config:        cfg,
-		handlers:      handlers,
-		ctx:           ctx,
-		cancel:        cancel,
-	}, nil
+	return consumer, nil
 }
 
 func (c *EventConsumer) Start() error {
@@ -63,6 +129,10 @@ func (c *EventConsumer) Start() error {
 		c.config.Topics.TripEnded,
 		c.config.Topics.LocationUpdated,
 	}
+	// Per-type topics stay subscribed so messages published before switching to the lifecycle topic are drained
+	if c.config.UseLifecycleTopic {
+		topics = append(topics, c.config.Topics.Lifecycle)
+	}
 
 	c.wg.Add(1)
 	go func() {
@@ -93,6 +163,12 @@ func (c *EventConsumer) Stop() {
 		logger.Error("Error closing consumer group", logger.ErrorField(err))
 	}
 
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			logger.Error("Error closing dead-letter producer", logger.ErrorField(err))
+		}
+	}
+
 	logger.Info("Kafka consumer stopped")
 }
 
@@ -112,16 +188,46 @@ func (c *EventConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim
 				return nil
 			}
 
-			if err := c.processMessage(message); err != nil {
-				logger.Error("Error processing message",
+			ctx := messageContext(c.ctx, message)
+			start := time.Now()
+			attempts, err := c.retryPolicy.Do(session.Context(), func() error {
+				return c.processMessage(ctx, message)
+			})
+			c.metrics.observeProcessing(message, time.Since(start))
+			if err != nil {
+				if session.Context().Err() != nil && !IsPermanentError(err) {
+					// Shutting down mid-retry: leave the offset uncommitted so the message is redelivered
+					return nil
+				}
+
+				if reason, ok := rejectionReason(err); ok {
+					if rejectErr := c.sendToRejected(ctx, message, reason); rejectErr != nil {
+						return fmt.Errorf("failed to reject message from %s at offset %d: %w", message.Topic, message.Offset, rejectErr)
+					}
+					c.metrics.countError(message, outcomeRejected)
+					session.MarkMessage(message, "")
+					c.metrics.observeLag(claim, message)
+					continue
+				}
+
+				logger.FromContext(ctx).Error("Error processing message",
 					logger.String("topic", message.Topic),
 					logger.String("partition", fmt.Sprintf("%d", message.Partition)),
 					logger.String("offset", fmt.Sprintf("%d", message.Offset)),
+					logger.Int("attempts", attempts),
+					logger.Bool("permanent", IsPermanentError(err)),
 					logger.ErrorField(err),
 				)
+
+				if dlqErr := c.sendToDeadLetter(ctx, message, err, attempts); dlqErr != nil {
+					// Committing past the message would lose it, so end the session and let it be redelivered
+					return fmt.Errorf("failed to dead-letter message from %s at offset %d: %w", message.Topic, message.Offset, dlqErr)
+				}
+				c.metrics.countError(message, outcomeDeadLettered)
 			}
 
 			session.MarkMessage(message, "")
+			c.metrics.observeLag(claim, message)
 
 		case <-session.Context().Done():
 			return nil
@@ -129,8 +235,22 @@ func (c *EventConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim
 	}
 }
 
-func (c *EventConsumer) processMessage(message *sarama.ConsumerMessage) error {
-	logger.Debug("Processing Kafka message",
+// messageContext derives the context a message is handled and logged in, carrying the request ID of its
+// publisher when it has one
+func messageContext(parent context.Context, message *sarama.ConsumerMessage) context.Context {
+	if requestID := consumerHeaders(message.Headers).Get(HeaderRequestID); requestID != "" {
+		return logger.WithRequestID(parent, requestID)
+	}
+	return parent
+}
+
+// processMessage handles the message in a span continuing the trace of its publisher, when it carries one
+func (c *EventConsumer) processMessage(ctx context.Context, message *sarama.ConsumerMessage) (err error) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, consumerHeaders(message.Headers))
+	ctx, span := tracer().Start(ctx, message.Topic+" process", trace.WithSpanKind(trace.SpanKindConsumer), messagingAttributes(message.Topic))
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).Debug("Processing Kafka message",
 		logger.String("topic", message.Topic),
 		logger.String("partition", fmt.Sprintf("%d", message.Partition)),
 		logger.String("offset", fmt.Sprintf("%d", message.Offset)),
@@ -138,8 +258,131 @@ func (c *EventConsumer) processMessage(message *sarama.ConsumerMessage) error {
 
 	handler, exists := c.handlers[message.Topic]
 	if !exists {
-		return fmt.Errorf("unknown topic: %s", message.Topic)
+		return NewPermanentError(fmt.Errorf("unknown topic: %s", message.Topic))
+	}
+
+	if c.verifier != nil {
+		if err := c.verifier.verify(ctx, message); err != nil {
+			return err
+		}
+	}
+
+	return handler.Handle(ctx, message.Value)
+}
+
+// Rejections returns how many messages have been rejected for each reason since the consumer started
+func (c *EventConsumer) Rejections() map[string]int64 {
+	c.rejectionsMu.Lock()
+	defer c.rejectionsMu.Unlock()
+
+	counts := make(map[string]int64, len(c.rejections))
+	for reason, count := range c.rejections {
+		counts[reason] = count
+	}
+	return counts
+}
+
+// sendToRejected counts a message that failed signature verification and diverts it to the rejected topic
+func (c *EventConsumer) sendToRejected(ctx context.Context, message *sarama.ConsumerMessage, reason string) error {
+	if topic := c.config.Topics.Rejected; topic != "" {
+		if err := c.publishRejected(topic, message, reason); err != nil {
+			return err
+		}
+	}
+
+	c.rejectionsMu.Lock()
+	if c.rejections == nil {
+		c.rejections = make(map[string]int64)
+	}
+	c.rejections[reason]++
+	c.rejectionsMu.Unlock()
+
+	logger.FromContext(ctx).Warn("Message rejected",
+		logger.String("topic", message.Topic),
+		logger.String("key", string(message.Key)),
+		logger.String("offset", fmt.Sprintf("%d", message.Offset)),
+		logger.String("reason", reason),
+	)
+
+	return nil
+}
+
+func (c *EventConsumer) publishRejected(topic string, message *sarama.ConsumerMessage, reason string) error {
+	if c.deadLetter == nil {
+		return fmt.Errorf("dead-letter producer is not configured")
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+4)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
 	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderDLQSourceTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDLQSourcePartition), Value: []byte(strconv.FormatInt(int64(message.Partition), 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQSourceOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(HeaderRejectedReason), Value: []byte(reason)},
+	)
 
-	return handler.Handle(c.ctx, message.Value)
+	rejectedMessage := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+	if message.Key != nil {
+		rejectedMessage.Key = sarama.ByteEncoder(message.Key)
+	}
+
+	_, _, err := c.deadLetter.SendMessage(rejectedMessage)
+	return err
+}
+
+// sendToDeadLetter publishes the failed message to the dead-letter topic of its source topic
+func (c *EventConsumer) sendToDeadLetter(ctx context.Context, message *sarama.ConsumerMessage, processErr error, attempts int) error {
+	topic, exists := c.deadLetterTopics[message.Topic]
+	if !exists || topic == "" {
+		return fmt.Errorf("no dead-letter topic configured for %s", message.Topic)
+	}
+	if c.deadLetter == nil {
+		return fmt.Errorf("dead-letter producer is not configured")
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+7)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderDLQSourceTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDLQSourcePartition), Value: []byte(strconv.FormatInt(int64(message.Partition), 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQSourceOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQError), Value: []byte(processErr.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderDLQAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQPermanent), Value: []byte(strconv.FormatBool(IsPermanentError(processErr)))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQFailedAt), Value: []byte(time.Now().Format(time.RFC3339))},
+	)
+
+	dlqMessage := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+	if message.Key != nil {
+		dlqMessage.Key = sarama.ByteEncoder(message.Key)
+	}
+
+	if _, _, err := c.deadLetter.SendMessage(dlqMessage); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Warn("Message sent to dead-letter topic",
+		logger.String("source_topic", message.Topic),
+		logger.String("dlq_topic", topic),
+		logger.String("offset", fmt.Sprintf("%d", message.Offset)),
+		logger.Int("attempts", attempts),
+	)
+
+	return nil
 }
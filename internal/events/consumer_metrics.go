@@ -0,0 +1,72 @@
+package events
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes counted by scootin_kafka_consumer_errors_total
+const (
+	outcomeRejected     = "rejected"
+	outcomeDeadLettered = "dead_lettered"
+)
+
+// consumerMetrics instruments message processing per topic; a nil *consumerMetrics records nothing
+type consumerMetrics struct {
+	lag      *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func newConsumerMetrics(registerer prometheus.Registerer) *consumerMetrics {
+	if registerer == nil {
+		return nil
+	}
+	m := &consumerMetrics{
+		lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "scootin_kafka_consumer_lag",
+			Help: "Messages behind the end of the partition after the last one processed.",
+		}, []string{"topic", "partition"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "scootin_kafka_consumer_processing_duration_seconds",
+			Help:    "Time taken to process a message, retries included, by topic.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"topic"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scootin_kafka_consumer_errors_total",
+			Help: "Messages that could not be processed, by topic and whether they were rejected or dead-lettered.",
+		}, []string{"topic", "outcome"}),
+	}
+	registerer.MustRegister(m.lag, m.duration, m.errors)
+	return m
+}
+
+func (m *consumerMetrics) observeProcessing(message *sarama.ConsumerMessage, took time.Duration) {
+	if m == nil {
+		return
+	}
+	m.duration.WithLabelValues(message.Topic).Observe(took.Seconds())
+}
+
+func (m *consumerMetrics) countError(message *sarama.ConsumerMessage, outcome string) {
+	if m == nil {
+		return
+	}
+	m.errors.WithLabelValues(message.Topic, outcome).Inc()
+}
+
+// observeLag records how far the claim is behind once message is done with. Transports that do not
+// track the high water mark report a negative one, which is left unrecorded.
+func (m *consumerMetrics) observeLag(claim sarama.ConsumerGroupClaim, message *sarama.ConsumerMessage) {
+	if m == nil {
+		return
+	}
+	highWaterMark := claim.HighWaterMarkOffset()
+	if highWaterMark < 0 {
+		return
+	}
+	m.lag.WithLabelValues(message.Topic, strconv.FormatInt(int64(message.Partition), 10)).Set(float64(max(highWaterMark-message.Offset-1, 0)))
+}
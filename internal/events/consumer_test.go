@@ -10,8 +10,11 @@ import (
 	"scootin-aboot/internal/models"
 
 	"github.com/Shopify/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewEventConsumer(t *testing.T) {
@@ -35,6 +38,19 @@ func TestEventConsumer_Cleanup(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMaxProcessingTime(t *testing.T) {
+	policy := NewRetryPolicy(config.KafkaRetryConfig{
+		MaxAttempts:       5,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        5 * time.Second,
+		BackoffMultiplier: 2,
+	})
+
+	// 1.5s of backoff plus half a second for each of the five attempts and the dead-letter publish
+	assert.Equal(t, 4500*time.Millisecond, maxProcessingTime(policy))
+	assert.Greater(t, maxProcessingTime(policy), policy.TotalBackoff())
+}
+
 func TestEventConsumer_processMessage(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -50,7 +66,7 @@ func TestEventConsumer_processMessage(t *testing.T) {
 				Value: []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`),
 			},
 			setupMocks: func(tripService *MockTripService, scooterService *MockScooterService) {
-				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
+				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
 			},
 			expectError: false,
 		},
@@ -58,10 +74,10 @@ func TestEventConsumer_processMessage(t *testing.T) {
 			name: "trip ended message",
 			message: &sarama.ConsumerMessage{
 				Topic: "trip-ended",
-				Value: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"trip-123","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
+				Value: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
 			},
 			setupMocks: func(tripService *MockTripService, scooterService *MockScooterService) {
-				tripService.On("EndTrip", mock.Anything, mock.Anything, 45.4216, -75.6973).Return(&models.Trip{}, nil)
+				tripService.On("EndTrip", mock.Anything, mock.Anything, mock.Anything, 45.4216, -75.6973).Return(&models.Trip{}, nil)
 			},
 			expectError: false,
 		},
@@ -72,7 +88,7 @@ func TestEventConsumer_processMessage(t *testing.T) {
 				Value: []byte(`{"eventType":"location.updated","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","tripId":"trip-123","latitude":45.4216,"longitude":-75.6973,"heading":90.0,"speed":15.5}}`),
 			},
 			setupMocks: func(tripService *MockTripService, scooterService *MockScooterService) {
-				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973).Return(nil)
+				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973, (*int)(nil)).Return(nil)
 			},
 			expectError: false,
 		},
@@ -118,7 +134,7 @@ func TestEventConsumer_processMessage(t *testing.T) {
 				ctx:      context.Background(),
 			}
 
-			err := consumer.processMessage(tt.message)
+			err := consumer.processMessage(context.Background(), tt.message)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -190,7 +206,7 @@ func TestEventConsumer_ConsumeClaim(t *testing.T) {
 			claim := NewMockConsumerGroupClaim()
 
 			if tt.name == "successful message processing" {
-				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
+				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
 			}
 
 			tt.setupMocks(session, claim)
@@ -335,3 +351,186 @@ func TestEventConsumer_Stop(t *testing.T) {
 		})
 	}
 }
+
+func headerValue(headers []sarama.RecordHeader, key string) string {
+	for _, header := range headers {
+		if string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func TestEventConsumer_ConsumeClaim_RetryAndDeadLetter(t *testing.T) {
+	validPayload := []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`)
+
+	tests := []struct {
+		name        string
+		payload     []byte
+		setupMocks  func(*MockTripService, *MockMessageSender, *MockConsumerGroupSession)
+		expectError bool
+		checkDLQ    func(*testing.T, *sarama.ProducerMessage)
+	}{
+		{
+			name:    "transient error is retried until success",
+			payload: validPayload,
+			setupMocks: func(tripService *MockTripService, sender *MockMessageSender, session *MockConsumerGroupSession) {
+				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return((*models.Trip)(nil), errors.New("connection reset")).Twice()
+				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil).Once()
+				session.On("MarkMessage", mock.Anything, "").Return()
+			},
+		},
+		{
+			name:    "transient error is dead-lettered after max attempts",
+			payload: validPayload,
+			setupMocks: func(tripService *MockTripService, sender *MockMessageSender, session *MockConsumerGroupSession) {
+				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return((*models.Trip)(nil), errors.New("connection reset")).Times(3)
+				sender.On("SendMessage", mock.Anything).Return(int32(0), int64(0), nil).Once()
+				session.On("MarkMessage", mock.Anything, "").Return()
+			},
+			checkDLQ: func(t *testing.T, msg *sarama.ProducerMessage) {
+				assert.Equal(t, "trip-started.dlq", msg.Topic)
+				assert.Equal(t, "3", headerValue(msg.Headers, HeaderDLQAttempts))
+				assert.Equal(t, "false", headerValue(msg.Headers, HeaderDLQPermanent))
+				assert.Contains(t, headerValue(msg.Headers, HeaderDLQError), "connection reset")
+			},
+		},
+		{
+			name:    "permanent error is dead-lettered without retry",
+			payload: []byte(`invalid json`),
+			setupMocks: func(tripService *MockTripService, sender *MockMessageSender, session *MockConsumerGroupSession) {
+				sender.On("SendMessage", mock.Anything).Return(int32(0), int64(0), nil).Once()
+				session.On("MarkMessage", mock.Anything, "").Return()
+			},
+			checkDLQ: func(t *testing.T, msg *sarama.ProducerMessage) {
+				value, err := msg.Value.Encode()
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid json", string(value))
+				assert.Equal(t, "trip-started", headerValue(msg.Headers, HeaderDLQSourceTopic))
+				assert.Equal(t, "2", headerValue(msg.Headers, HeaderDLQSourcePartition))
+				assert.Equal(t, "42", headerValue(msg.Headers, HeaderDLQSourceOffset))
+				assert.Equal(t, "1", headerValue(msg.Headers, HeaderDLQAttempts))
+				assert.Equal(t, "true", headerValue(msg.Headers, HeaderDLQPermanent))
+				assert.Contains(t, headerValue(msg.Headers, HeaderDLQError), "failed to unmarshal")
+			},
+		},
+		{
+			name:    "dead-letter failure leaves message uncommitted",
+			payload: []byte(`invalid json`),
+			setupMocks: func(tripService *MockTripService, sender *MockMessageSender, session *MockConsumerGroupSession) {
+				sender.On("SendMessage", mock.Anything).Return(int32(0), int64(0), errors.New("broker unavailable")).Once()
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tripService := &MockTripService{}
+			scooterService := &MockScooterService{}
+			sender := &MockMessageSender{}
+			session := &MockConsumerGroupSession{}
+			claim := NewMockConsumerGroupClaim()
+
+			session.On("Context").Return(context.Background())
+			claim.On("Messages").Return(nil)
+			tt.setupMocks(tripService, sender, session)
+
+			deps := HandlerDependencies{
+				TripService:    tripService,
+				ScooterService: scooterService,
+			}
+
+			topics := config.KafkaTopics{
+				TripStarted:    "trip-started",
+				TripStartedDLQ: "trip-started.dlq",
+			}
+
+			consumer := &EventConsumer{
+				config:   &config.KafkaConfig{Topics: topics},
+				handlers: map[string]EventHandler{"trip-started": NewTripStartedHandler(deps)},
+				retryPolicy: RetryPolicy{
+					MaxAttempts:       3,
+					InitialBackoff:    time.Millisecond,
+					MaxBackoff:        time.Millisecond,
+					BackoffMultiplier: 2,
+				},
+				deadLetterTopics: topics.DeadLetterTopics(),
+				deadLetter:       sender,
+				ctx:              context.Background(),
+			}
+
+			go func() {
+				claim.SendMessage(&sarama.ConsumerMessage{
+					Topic:     "trip-started",
+					Partition: 2,
+					Offset:    42,
+					Value:     tt.payload,
+				})
+				claim.Close()
+			}()
+
+			err := consumer.ConsumeClaim(session, claim)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				session.AssertNotCalled(t, "MarkMessage", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				session.AssertExpectations(t)
+			}
+
+			if tt.checkDLQ != nil {
+				sender.AssertNumberOfCalls(t, "SendMessage", 1)
+				tt.checkDLQ(t, sender.Calls[0].Arguments.Get(0).(*sarama.ProducerMessage))
+			}
+
+			tripService.AssertExpectations(t)
+			sender.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventConsumer_ConsumeClaim_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	sender := &MockMessageSender{}
+	session := &MockConsumerGroupSession{}
+	claim := NewMockConsumerGroupClaim()
+
+	session.On("Context").Return(context.Background())
+	session.On("MarkMessage", mock.Anything, "").Return()
+	claim.On("Messages").Return(nil)
+	claim.On("HighWaterMarkOffset").Return(int64(50))
+	sender.On("SendMessage", mock.Anything).Return(int32(0), int64(0), nil)
+
+	topics := config.KafkaTopics{
+		TripStarted:    "trip-started",
+		TripStartedDLQ: "trip-started.dlq",
+	}
+	deps := HandlerDependencies{TripService: &MockTripService{}, ScooterService: &MockScooterService{}}
+	consumer := &EventConsumer{
+		config:           &config.KafkaConfig{Topics: topics},
+		handlers:         map[string]EventHandler{"trip-started": NewTripStartedHandler(deps)},
+		retryPolicy:      RetryPolicy{MaxAttempts: 1},
+		deadLetterTopics: topics.DeadLetterTopics(),
+		deadLetter:       sender,
+		metrics:          newConsumerMetrics(registry),
+		ctx:              context.Background(),
+	}
+
+	go func() {
+		claim.SendMessage(&sarama.ConsumerMessage{Topic: "trip-started", Partition: 2, Offset: 42, Value: []byte(`invalid json`)})
+		claim.Close()
+	}()
+	assert.NoError(t, consumer.ConsumeClaim(session, claim))
+
+	assert.Equal(t, 7.0, testutil.ToFloat64(consumer.metrics.lag.WithLabelValues("trip-started", "2")))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "scootin_kafka_consumer_processing_duration_seconds" {
+			assert.Equal(t, uint64(1), family.GetMetric()[0].GetHistogram().GetSampleCount())
+		}
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.errors.WithLabelValues("trip-started", outcomeDeadLettered)))
+}
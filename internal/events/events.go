@@ -1,11 +1,20 @@
 package events
 
 import (
+	"encoding/json"
 	"time"
 
+	"scootin-aboot/internal/models"
+
 	"github.com/google/uuid"
 )
 
+const (
+	EventTypeTripStarted     = "trip.started"
+	EventTypeTripEnded       = "trip.ended"
+	EventTypeLocationUpdated = "location.updated"
+)
+
 type BaseEvent struct {
 	EventType string    `json:"eventType"`
 	EventID   string    `json:"eventId"`
@@ -27,6 +36,9 @@ type TripStartedData struct {
 	StartTime      string  `json:"startTime"`
 }
 
+// TripEndedEvent is a scooter's report that its trip ended, which asks the server to end the trip. It has no
+// fare: trips are priced when the server ends them, and the fare is published in the trip.ended server event
+// of the outbox (services.TripEventPayload).
 type TripEndedEvent struct {
 	BaseEvent
 	Data TripEndedData `json:"data"`
@@ -54,13 +66,15 @@ type LocationUpdatedData struct {
 	Longitude float64 `json:"longitude"`
 	Heading   float64 `json:"heading"`
 	Speed     float64 `json:"speed"`
+	// BatteryLevel is the charge as a percentage; omitted by devices that do not report it
+	BatteryLevel *int `json:"batteryLevel,omitempty"`
 }
 
 func NewTripStartedEvent(tripID, scooterID, userID string, startLat, startLng float64) *TripStartedEvent {
 	now := time.Now()
 	return &TripStartedEvent{
 		BaseEvent: BaseEvent{
-			EventType: "trip.started",
+			EventType: EventTypeTripStarted,
 			EventID:   uuid.New().String(),
 			Timestamp: now,
 			Version:   "1.0",
@@ -82,7 +96,7 @@ func NewTripEndedEvent(tripID, scooterID, userID string, endLat, endLng float64,
 
 	return &TripEndedEvent{
 		BaseEvent: BaseEvent{
-			EventType: "trip.ended",
+			EventType: EventTypeTripEnded,
 			EventID:   uuid.New().String(),
 			Timestamp: now,
 			Version:   "1.0",
@@ -99,21 +113,43 @@ func NewTripEndedEvent(tripID, scooterID, userID string, endLat, endLng float64,
 	}
 }
 
-func NewLocationUpdatedEvent(scooterID, tripID string, lat, lng, heading, speed float64) *LocationUpdatedEvent {
+func NewLocationUpdatedEvent(scooterID, tripID string, lat, lng, heading, speed float64, batteryLevel *int) *LocationUpdatedEvent {
 	return &LocationUpdatedEvent{
 		BaseEvent: BaseEvent{
-			EventType: "location.updated",
+			EventType: EventTypeLocationUpdated,
 			EventID:   uuid.New().String(),
 			Timestamp: time.Now(),
 			Version:   "1.0",
 		},
 		Data: LocationUpdatedData{
-			ScooterID: scooterID,
-			TripID:    tripID,
-			Latitude:  lat,
-			Longitude: lng,
-			Heading:   heading,
-			Speed:     speed,
+			ScooterID:    scooterID,
+			TripID:       tripID,
+			Latitude:     lat,
+			Longitude:    lng,
+			Heading:      heading,
+			Speed:        speed,
+			BatteryLevel: batteryLevel,
+		},
+	}
+}
+
+// ServerEvent is an authoritative state change published by the server from the outbox.
+// EventID is the outbox row ID, so subscribers can deduplicate redelivered events.
+type ServerEvent struct {
+	BaseEvent
+	ScooterID string          `json:"scooterId"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func NewServerEvent(outboxEvent *models.OutboxEvent) *ServerEvent {
+	return &ServerEvent{
+		BaseEvent: BaseEvent{
+			EventType: outboxEvent.EventType,
+			EventID:   outboxEvent.ID.String(),
+			Timestamp: outboxEvent.CreatedAt,
+			Version:   "1.0",
 		},
+		ScooterID: outboxEvent.AggregateID.String(),
+		Data:      outboxEvent.Payload,
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"scootin-aboot/internal/services"
+
+	"github.com/google/uuid"
 )
 
 type EventHandler interface {
@@ -14,3 +16,11 @@ type HandlerDependencies struct {
 	TripService    services.TripService
 	ScooterService services.ScooterService
 }
+
+// parseOptionalTripID returns uuid.Nil for events that carry no trip ID
+func parseOptionalTripID(tripID string) (uuid.UUID, error) {
+	if tripID == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(tripID)
+}
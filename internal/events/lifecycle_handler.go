@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// LifecycleHandler consumes the shared scooter lifecycle topic and dispatches each message on its event type
+type LifecycleHandler struct {
+	handlers map[string]EventHandler
+}
+
+func NewLifecycleHandler(deps HandlerDependencies) *LifecycleHandler {
+	return &LifecycleHandler{
+		handlers: map[string]EventHandler{
+			EventTypeTripStarted:     NewTripStartedHandler(deps),
+			EventTypeTripEnded:       NewTripEndedHandler(deps),
+			EventTypeLocationUpdated: NewLocationUpdatedHandler(deps),
+		},
+	}
+}
+
+func (h *LifecycleHandler) Handle(ctx context.Context, data []byte) error {
+	var event BaseEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return NewPermanentError(fmt.Errorf("failed to unmarshal lifecycle event: %w", err))
+	}
+
+	handler, exists := h.handlers[event.EventType]
+	if !exists {
+		return NewPermanentError(fmt.Errorf("unknown event type: %s", event.EventType))
+	}
+
+	return handler.Handle(ctx, data)
+}
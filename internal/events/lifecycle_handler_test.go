@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLifecycleHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            []byte
+		setupMocks      func(*MockScooterService)
+		expectError     bool
+		expectPermanent bool
+		errorMsg        string
+	}{
+		{
+			name: "dispatches location updated event",
+			data: []byte(`{"eventType":"location.updated","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","tripId":"trip-123","latitude":45.4216,"longitude":-75.6973,"heading":90.0,"speed":15.5}}`),
+			setupMocks: func(scooterService *MockScooterService) {
+				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973, (*int)(nil)).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name:            "unknown event type",
+			data:            []byte(`{"eventType":"scooter.exploded","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{}}`),
+			setupMocks:      func(scooterService *MockScooterService) {},
+			expectError:     true,
+			expectPermanent: true,
+			errorMsg:        "unknown event type: scooter.exploded",
+		},
+		{
+			name:            "invalid JSON",
+			data:            []byte(`invalid json`),
+			setupMocks:      func(scooterService *MockScooterService) {},
+			expectError:     true,
+			expectPermanent: true,
+			errorMsg:        "failed to unmarshal lifecycle event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scooterService := &MockScooterService{}
+			tt.setupMocks(scooterService)
+
+			handler := NewLifecycleHandler(HandlerDependencies{
+				TripService:    &MockTripService{},
+				ScooterService: scooterService,
+			})
+
+			err := handler.Handle(context.Background(), tt.data)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				assert.Equal(t, tt.expectPermanent, IsPermanentError(err))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			scooterService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,497 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/logger"
+
+	"github.com/Shopify/sarama"
+)
+
+// The local transport stands in for a Kafka broker during development and tests. Each topic is an
+// append-only file of JSON lines in a shared directory, so a server and a simulator on the same machine
+// can exchange events through it. Topics have a single partition, which keeps messages in the order they
+// were produced, and consumer groups store their offsets next to the logs. The producer and consumer group
+// implement the sarama interfaces, so KafkaProducer and EventConsumer run unchanged on top of it.
+
+// localPollInterval is how often a consumer checks a topic file for new messages
+const localPollInterval = 100 * time.Millisecond
+
+var errLocalTxnUnsupported = errors.New("transactions are not supported by the local transport")
+
+// newSyncProducer creates a producer for the transport selected in cfg
+func newSyncProducer(cfg *config.KafkaConfig, saramaConfig *sarama.Config) (sarama.SyncProducer, error) {
+	if cfg.Transport == config.KafkaTransportLocal {
+		return newLocalSyncProducer(cfg.LocalDir)
+	}
+	return sarama.NewSyncProducer(cfg.Brokers, saramaConfig)
+}
+
+// newConsumerGroup creates a consumer group for the transport selected in cfg
+func newConsumerGroup(cfg *config.KafkaConfig, group string, saramaConfig *sarama.Config) (sarama.ConsumerGroup, error) {
+	if cfg.Transport == config.KafkaTransportLocal {
+		return newLocalConsumerGroup(cfg.LocalDir, group)
+	}
+	return sarama.NewConsumerGroup(cfg.Brokers, group, saramaConfig)
+}
+
+type localRecord struct {
+	Key       []byte        `json:"key,omitempty"`
+	Value     []byte        `json:"value"`
+	Headers   []localHeader `json:"headers,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+type localHeader struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+func localTopicPath(dir, topic string) (string, error) {
+	if topic == "" || strings.ContainsAny(topic, `/\`) || topic == "." || topic == ".." {
+		return "", fmt.Errorf("invalid topic name for local transport: %q", topic)
+	}
+	return filepath.Join(dir, topic+".log"), nil
+}
+
+// localSyncProducer appends messages to topic files and implements sarama.SyncProducer
+type localSyncProducer struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newLocalSyncProducer(dir string) (*localSyncProducer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local transport directory: %w", err)
+	}
+	return &localSyncProducer{dir: dir}, nil
+}
+
+// SendMessage appends msg to its topic file. The offset is not tracked on the producer side and is returned as -1.
+func (p *localSyncProducer) SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	path, err := localTopicPath(p.dir, msg.Topic)
+	if err != nil {
+		return 0, -1, err
+	}
+
+	record := localRecord{Timestamp: time.Now()}
+	if msg.Key != nil {
+		if record.Key, err = msg.Key.Encode(); err != nil {
+			return 0, -1, fmt.Errorf("failed to encode message key: %w", err)
+		}
+	}
+	if msg.Value != nil {
+		if record.Value, err = msg.Value.Encode(); err != nil {
+			return 0, -1, fmt.Errorf("failed to encode message value: %w", err)
+		}
+	}
+	for _, header := range msg.Headers {
+		record.Headers = append(record.Headers, localHeader{Key: header.Key, Value: header.Value})
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return 0, -1, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A single O_APPEND write keeps lines from concurrent processes from interleaving
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, -1, err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return 0, -1, err
+	}
+
+	return 0, -1, nil
+}
+
+func (p *localSyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	for _, msg := range msgs {
+		if _, _, err := p.SendMessage(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *localSyncProducer) Close() error {
+	return nil
+}
+
+func (p *localSyncProducer) TxnStatus() sarama.ProducerTxnStatusFlag {
+	return sarama.ProducerTxnFlagReady
+}
+
+func (p *localSyncProducer) IsTransactional() bool {
+	return false
+}
+
+func (p *localSyncProducer) BeginTxn() error {
+	return errLocalTxnUnsupported
+}
+
+func (p *localSyncProducer) CommitTxn() error {
+	return errLocalTxnUnsupported
+}
+
+func (p *localSyncProducer) AbortTxn() error {
+	return errLocalTxnUnsupported
+}
+
+func (p *localSyncProducer) AddOffsetsToTxn(offsets map[string][]*sarama.PartitionOffsetMetadata, groupId string) error {
+	return errLocalTxnUnsupported
+}
+
+func (p *localSyncProducer) AddMessageToTxn(msg *sarama.ConsumerMessage, groupId string, metadata *string) error {
+	return errLocalTxnUnsupported
+}
+
+// localConsumerGroup tails topic files and implements sarama.ConsumerGroup.
+// A group with no stored offset for a topic starts from the beginning of its file.
+type localConsumerGroup struct {
+	dir       string
+	group     string
+	errors    chan error
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newLocalConsumerGroup(dir, group string) (*localConsumerGroup, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "offsets", group), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local transport directory: %w", err)
+	}
+	return &localConsumerGroup{
+		dir:    dir,
+		group:  group,
+		errors: make(chan error),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+// Consume runs one session over all topics, like a sarama group generation. It returns when ctx is done
+// or as soon as any ConsumeClaim returns, after which uncommitted messages are delivered again.
+func (g *localConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	select {
+	case <-g.closed:
+		return sarama.ErrClosedConsumerGroup
+	default:
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	session := &localConsumerGroupSession{
+		group:   g,
+		ctx:     sessionCtx,
+		offsets: make(map[string]int64, len(topics)),
+	}
+
+	claims := make([]*localConsumerGroupClaim, 0, len(topics))
+	for _, topic := range topics {
+		path, err := localTopicPath(g.dir, topic)
+		if err != nil {
+			return err
+		}
+		offset, err := g.loadOffset(topic)
+		if err != nil {
+			return err
+		}
+		session.offsets[topic] = offset
+		claims = append(claims, &localConsumerGroupClaim{
+			topic:    topic,
+			path:     path,
+			offset:   offset,
+			messages: make(chan *sarama.ConsumerMessage),
+		})
+	}
+
+	if err := handler.Setup(session); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	claimErrors := make(chan error, len(claims))
+	for _, claim := range claims {
+		wg.Add(2)
+		go func(claim *localConsumerGroupClaim) {
+			defer wg.Done()
+			claim.feed(sessionCtx)
+		}(claim)
+		go func(claim *localConsumerGroupClaim) {
+			defer wg.Done()
+			if err := handler.ConsumeClaim(session, claim); err != nil {
+				claimErrors <- err
+			}
+			cancel()
+		}(claim)
+	}
+
+	wg.Wait()
+	close(claimErrors)
+
+	if err := handler.Cleanup(session); err != nil {
+		return err
+	}
+
+	return <-claimErrors
+}
+
+func (g *localConsumerGroup) Errors() <-chan error {
+	return g.errors
+}
+
+func (g *localConsumerGroup) Close() error {
+	g.closeOnce.Do(func() {
+		close(g.closed)
+		close(g.errors)
+	})
+	return nil
+}
+
+// Pausing is not supported by the local transport; the methods exist to satisfy sarama.ConsumerGroup
+func (g *localConsumerGroup) Pause(partitions map[string][]int32)  {}
+func (g *localConsumerGroup) Resume(partitions map[string][]int32) {}
+func (g *localConsumerGroup) PauseAll()                            {}
+func (g *localConsumerGroup) ResumeAll()                           {}
+
+func (g *localConsumerGroup) offsetPath(topic string) string {
+	return filepath.Join(g.dir, "offsets", g.group, topic)
+}
+
+func (g *localConsumerGroup) loadOffset(topic string) (int64, error) {
+	data, err := os.ReadFile(g.offsetPath(topic))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read offset for %s: %w", topic, err)
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse offset for %s: %w", topic, err)
+	}
+	return offset, nil
+}
+
+// storeOffset writes the offset through a rename so a crash never leaves a truncated file
+func (g *localConsumerGroup) storeOffset(topic string, offset int64) error {
+	path := g.offsetPath(topic)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// localConsumerGroupSession implements sarama.ConsumerGroupSession; marked offsets are committed immediately
+type localConsumerGroupSession struct {
+	group   *localConsumerGroup
+	ctx     context.Context
+	mu      sync.Mutex
+	offsets map[string]int64
+}
+
+func (s *localConsumerGroupSession) Claims() map[string][]int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claims := make(map[string][]int32, len(s.offsets))
+	for topic := range s.offsets {
+		claims[topic] = []int32{0}
+	}
+	return claims
+}
+
+func (s *localConsumerGroupSession) MemberID() string {
+	return s.group.group
+}
+
+func (s *localConsumerGroupSession) GenerationID() int32 {
+	return 1
+}
+
+func (s *localConsumerGroupSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offset <= s.offsets[topic] {
+		return
+	}
+	s.offsets[topic] = offset
+
+	if err := s.group.storeOffset(topic, offset); err != nil {
+		logger.Error("Failed to store local consumer offset",
+			logger.String("topic", topic),
+			logger.Int64("offset", offset),
+			logger.ErrorField(err),
+		)
+	}
+}
+
+func (s *localConsumerGroupSession) Commit() {}
+
+func (s *localConsumerGroupSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offsets[topic] = offset
+	if err := s.group.storeOffset(topic, offset); err != nil {
+		logger.Error("Failed to store local consumer offset",
+			logger.String("topic", topic),
+			logger.Int64("offset", offset),
+			logger.ErrorField(err),
+		)
+	}
+}
+
+func (s *localConsumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+func (s *localConsumerGroupSession) Context() context.Context {
+	return s.ctx
+}
+
+// localConsumerGroupClaim implements sarama.ConsumerGroupClaim; offsets are line numbers in the topic file
+type localConsumerGroupClaim struct {
+	topic    string
+	path     string
+	offset   int64
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *localConsumerGroupClaim) Topic() string {
+	return c.topic
+}
+
+func (c *localConsumerGroupClaim) Partition() int32 {
+	return 0
+}
+
+func (c *localConsumerGroupClaim) InitialOffset() int64 {
+	return c.offset
+}
+
+// HighWaterMarkOffset is not tracked by the local transport
+func (c *localConsumerGroupClaim) HighWaterMarkOffset() int64 {
+	return -1
+}
+
+func (c *localConsumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+// feed tails the topic file from the claim's offset until ctx is done, then closes the message channel
+func (c *localConsumerGroupClaim) feed(ctx context.Context) {
+	defer close(c.messages)
+
+	var file *os.File
+	for file == nil {
+		var err error
+		file, err = os.Open(c.path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				logger.Error("Failed to open local topic", logger.String("topic", c.topic), logger.ErrorField(err))
+			}
+			if !sleepContext(ctx, localPollInterval) {
+				return
+			}
+		}
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var pending []byte
+	var offset int64
+
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		pending = append(pending, chunk...)
+		if err == io.EOF {
+			// Wait for the rest of a partially written line, or for new messages
+			if !sleepContext(ctx, localPollInterval) {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			logger.Error("Failed to read local topic", logger.String("topic", c.topic), logger.ErrorField(err))
+			return
+		}
+
+		line := pending
+		pending = nil
+		offset++
+		if offset <= c.offset {
+			continue
+		}
+
+		message, err := decodeLocalRecord(c.topic, offset-1, line)
+		if err != nil {
+			logger.Error("Skipping unreadable local message",
+				logger.String("topic", c.topic),
+				logger.Int64("offset", offset-1),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+
+		select {
+		case c.messages <- message:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func decodeLocalRecord(topic string, offset int64, line []byte) (*sarama.ConsumerMessage, error) {
+	var record localRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		return nil, err
+	}
+
+	headers := make([]*sarama.RecordHeader, 0, len(record.Headers))
+	for _, header := range record.Headers {
+		headers = append(headers, &sarama.RecordHeader{Key: header.Key, Value: header.Value})
+	}
+
+	return &sarama.ConsumerMessage{
+		Topic:     topic,
+		Partition: 0,
+		Offset:    offset,
+		Key:       record.Key,
+		Value:     record.Value,
+		Headers:   headers,
+		Timestamp: record.Timestamp,
+	}, nil
+}
+
+// sleepContext waits for d and reports false if ctx ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
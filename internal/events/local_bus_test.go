@@ -0,0 +1,208 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/models"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHandler marks and forwards every message it receives
+type recordingHandler struct {
+	received chan *sarama.ConsumerMessage
+}
+
+func (h *recordingHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *recordingHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *recordingHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for message := range claim.Messages() {
+		session.MarkMessage(message, "")
+		h.received <- message
+	}
+	return nil
+}
+
+func sendLocal(t *testing.T, producer sarama.SyncProducer, topic, key, value string) {
+	t.Helper()
+	_, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.StringEncoder(key),
+		Value:   sarama.StringEncoder(value),
+		Headers: []sarama.RecordHeader{{Key: []byte("event-type"), Value: []byte("test")}},
+	})
+	require.NoError(t, err)
+}
+
+func receiveLocal(t *testing.T, received <-chan *sarama.ConsumerMessage) *sarama.ConsumerMessage {
+	t.Helper()
+	select {
+	case message := <-received:
+		return message
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestLocalBus_DeliversInOrderAndResumesFromCommittedOffset(t *testing.T) {
+	dir := t.TempDir()
+	producer, err := newLocalSyncProducer(dir)
+	require.NoError(t, err)
+
+	sendLocal(t, producer, "trips", "scooter-1", "first")
+	sendLocal(t, producer, "trips", "scooter-2", "second")
+
+	consume := func() (context.CancelFunc, chan *sarama.ConsumerMessage, chan error) {
+		group, err := newLocalConsumerGroup(dir, "test-group")
+		require.NoError(t, err)
+
+		handler := &recordingHandler{received: make(chan *sarama.ConsumerMessage, 10)}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- group.Consume(ctx, []string{"trips"}, handler) }()
+		return cancel, handler.received, done
+	}
+
+	cancel, received, done := consume()
+
+	first := receiveLocal(t, received)
+	assert.Equal(t, "first", string(first.Value))
+	assert.Equal(t, "scooter-1", string(first.Key))
+	assert.Equal(t, int64(0), first.Offset)
+	require.Len(t, first.Headers, 1)
+	assert.Equal(t, "event-type", string(first.Headers[0].Key))
+
+	second := receiveLocal(t, received)
+	assert.Equal(t, "second", string(second.Value))
+	assert.Equal(t, int64(1), second.Offset)
+
+	// Messages produced while the group is running are picked up by the tail
+	sendLocal(t, producer, "trips", "scooter-1", "third")
+	assert.Equal(t, "third", string(receiveLocal(t, received).Value))
+
+	cancel()
+	require.NoError(t, <-done)
+
+	sendLocal(t, producer, "trips", "scooter-3", "fourth")
+
+	cancel, received, done = consume()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	resumed := receiveLocal(t, received)
+	assert.Equal(t, "fourth", string(resumed.Value))
+	assert.Equal(t, int64(3), resumed.Offset)
+}
+
+func TestLocalBus_RejectsInvalidTopic(t *testing.T) {
+	producer, err := newLocalSyncProducer(t.TempDir())
+	require.NoError(t, err)
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "../escape", Value: sarama.StringEncoder("x")})
+	assert.Error(t, err)
+}
+
+func TestLocalConsumerGroup_Closed(t *testing.T) {
+	group, err := newLocalConsumerGroup(t.TempDir(), "test-group")
+	require.NoError(t, err)
+	require.NoError(t, group.Close())
+
+	err = group.Consume(context.Background(), []string{"trips"}, &recordingHandler{})
+	assert.ErrorIs(t, err, sarama.ErrClosedConsumerGroup)
+}
+
+func readLocalTopic(t *testing.T, dir, topic string) []localRecord {
+	t.Helper()
+	file, err := os.Open(filepath.Join(dir, topic+".log"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []localRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record localRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestEventConsumer_LocalTransport(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.KafkaConfig{
+		Transport: config.KafkaTransportLocal,
+		LocalDir:  dir,
+		Topics: config.KafkaTopics{
+			TripStarted:        "trip-started",
+			TripEnded:          "trip-ended",
+			LocationUpdated:    "location-updated",
+			TripStartedDLQ:     "trip-started.dlq",
+			TripEndedDLQ:       "trip-ended.dlq",
+			LocationUpdatedDLQ: "location-updated.dlq",
+		},
+		Retry: config.KafkaRetryConfig{
+			MaxAttempts:       1,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        time.Millisecond,
+			BackoffMultiplier: 1,
+		},
+	}
+
+	tripService := &MockTripService{}
+	scooterService := &MockScooterService{}
+	started := make(chan struct{}, 1)
+	tripService.On("StartTrip", mock.Anything, testTripID, mock.Anything, mock.Anything, 45.4215, -75.6972).
+		Return(&models.Trip{}, nil).
+		Run(func(mock.Arguments) { started <- struct{}{} })
+
+	consumer, err := NewEventConsumer(cfg, tripService, scooterService, nil)
+	require.NoError(t, err)
+	require.NoError(t, consumer.Start())
+	defer consumer.Stop()
+
+	producer, err := NewKafkaProducer(cfg)
+	require.NoError(t, err)
+	defer producer.Close()
+
+	// An undecodable payload is dead-lettered and does not block the event behind it
+	deadLetter, err := newLocalSyncProducer(dir)
+	require.NoError(t, err)
+	sendLocal(t, deadLetter, cfg.Topics.TripStarted, "550e8400-e29b-41d4-a716-446655440001", "not json")
+
+	event := NewTripStartedEvent(testTripID.String(), "550e8400-e29b-41d4-a716-446655440001", "550e8400-e29b-41d4-a716-446655440002", 45.4215, -75.6972)
+	require.NoError(t, producer.PublishTripStarted(context.Background(), event))
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("trip started event was not handled")
+	}
+	tripService.AssertExpectations(t)
+
+	assert.Eventually(t, func() bool {
+		return len(readLocalTopic(t, dir, cfg.Topics.TripStartedDLQ)) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+
+	dlq := readLocalTopic(t, dir, cfg.Topics.TripStartedDLQ)
+	require.Len(t, dlq, 1)
+	assert.Equal(t, "not json", string(dlq[0].Value))
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440001", string(dlq[0].Key))
+}
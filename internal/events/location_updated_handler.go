@@ -3,9 +3,12 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
 
 	"github.com/google/uuid"
 )
@@ -23,28 +26,35 @@ func NewLocationUpdatedHandler(deps HandlerDependencies) *LocationUpdatedHandler
 func (h *LocationUpdatedHandler) Handle(ctx context.Context, data []byte) error {
 	var event LocationUpdatedEvent
 	if err := json.Unmarshal(data, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal location updated event: %w", err)
+		return NewPermanentError(fmt.Errorf("failed to unmarshal location updated event: %w", err))
 	}
 
-	logger.Debug("Processing location updated event",
-		logger.String("scooter_id", event.Data.ScooterID),
-		logger.String("trip_id", event.Data.TripID),
+	ctx = logger.WithScooterID(ctx, event.Data.ScooterID)
+	ctx = logger.WithTripID(ctx, event.Data.TripID)
+	logger.FromContext(ctx).Debug("Processing location updated event",
 		logger.Float64("lat", event.Data.Latitude),
 		logger.Float64("lng", event.Data.Longitude),
 	)
 
 	scooterID, err := uuid.Parse(event.Data.ScooterID)
 	if err != nil {
-		return fmt.Errorf("invalid scooter ID: %w", err)
+		return NewPermanentError(fmt.Errorf("invalid scooter ID: %w", err))
 	}
 
-	if err := h.deps.ScooterService.UpdateLocation(ctx, scooterID, event.Data.Latitude, event.Data.Longitude); err != nil {
+	ctx = services.WithProcessedEvent(ctx, event.EventID, event.EventType)
+	err = h.deps.ScooterService.UpdateLocation(ctx, scooterID, event.Data.Latitude, event.Data.Longitude, event.Data.BatteryLevel)
+	if errors.Is(err, repository.ErrEventAlreadyProcessed) {
+		logger.FromContext(ctx).Debug("Skipping already processed event",
+			logger.String("event_id", event.EventID),
+			logger.String("event_type", event.EventType),
+		)
+		return nil
+	}
+	if err != nil {
 		return fmt.Errorf("failed to update scooter location: %w", err)
 	}
 
-	logger.Debug("Location updated event processed successfully",
-		logger.String("scooter_id", event.Data.ScooterID),
-	)
+	logger.FromContext(ctx).Debug("Location updated event processed successfully")
 
 	return nil
 }
@@ -3,8 +3,11 @@ package events
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
+	"scootin-aboot/internal/repository"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,7 +24,17 @@ func TestLocationUpdatedHandler_Handle(t *testing.T) {
 			name: "valid location updated event",
 			data: []byte(`{"eventType":"location.updated","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","tripId":"trip-123","latitude":45.4216,"longitude":-75.6973,"heading":90.0,"speed":15.5}}`),
 			setupMocks: func(scooterService *MockScooterService) {
-				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973).Return(nil)
+				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973, (*int)(nil)).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name: "location updated event with battery level",
+			data: []byte(`{"eventType":"location.updated","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","tripId":"trip-123","latitude":45.4216,"longitude":-75.6973,"heading":90.0,"speed":15.5,"batteryLevel":42}}`),
+			setupMocks: func(scooterService *MockScooterService) {
+				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973, mock.MatchedBy(func(level *int) bool {
+					return level != nil && *level == 42
+				})).Return(nil)
 			},
 			expectError: false,
 		},
@@ -41,11 +54,19 @@ func TestLocationUpdatedHandler_Handle(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid scooter ID",
 		},
+		{
+			name: "already processed event is skipped",
+			data: []byte(`{"eventType":"location.updated","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","tripId":"trip-123","latitude":45.4216,"longitude":-75.6973,"heading":90.0,"speed":15.5}}`),
+			setupMocks: func(scooterService *MockScooterService) {
+				scooterService.On("UpdateLocation", mock.MatchedBy(isProcessingEvent), mock.Anything, 45.4216, -75.6973, (*int)(nil)).Return(fmt.Errorf("failed to record processed event test-id: %w", repository.ErrEventAlreadyProcessed))
+			},
+			expectError: false,
+		},
 		{
 			name: "scooter service error",
 			data: []byte(`{"eventType":"location.updated","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","tripId":"trip-123","latitude":45.4216,"longitude":-75.6973,"heading":90.0,"speed":15.5}}`),
 			setupMocks: func(scooterService *MockScooterService) {
-				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973).Return(errors.New("service error"))
+				scooterService.On("UpdateLocation", mock.Anything, mock.Anything, 45.4216, -75.6973, (*int)(nil)).Return(errors.New("service error"))
 			},
 			expectError: true,
 			errorMsg:    "failed to update scooter location",
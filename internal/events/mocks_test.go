@@ -11,22 +11,31 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// testTripID matches the tripId carried by the trip event fixtures
+var testTripID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+
+// isProcessingEvent matches contexts that carry the "test-id" event used by the fixtures
+func isProcessingEvent(ctx context.Context) bool {
+	event, ok := services.ProcessedEventFromContext(ctx)
+	return ok && event.EventID == "test-id"
+}
+
 type MockTripService struct {
 	mock.Mock
 }
 
-func (m *MockTripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error) {
-	args := m.Called(ctx, scooterID, userID, lat, lng)
+func (m *MockTripService) StartTrip(ctx context.Context, tripID, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error) {
+	args := m.Called(ctx, tripID, scooterID, userID, lat, lng)
 	return args.Get(0).(*models.Trip), args.Error(1)
 }
 
-func (m *MockTripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error) {
-	args := m.Called(ctx, scooterID, lat, lng)
+func (m *MockTripService) EndTrip(ctx context.Context, tripID, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error) {
+	args := m.Called(ctx, tripID, scooterID, lat, lng)
 	return args.Get(0).(*models.Trip), args.Error(1)
 }
 
-func (m *MockTripService) CancelTrip(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error) {
-	args := m.Called(ctx, scooterID)
+func (m *MockTripService) CancelTrip(ctx context.Context, tripID, scooterID uuid.UUID) (*models.Trip, error) {
+	args := m.Called(ctx, tripID, scooterID)
 	return args.Get(0).(*models.Trip), args.Error(1)
 }
 
@@ -50,6 +59,11 @@ func (m *MockTripService) GetTrip(ctx context.Context, tripID uuid.UUID) (*model
 	return args.Get(0).(*models.Trip), args.Error(1)
 }
 
+func (m *MockTripService) GetTripRoute(ctx context.Context, tripID uuid.UUID) (*services.TripRoute, error) {
+	args := m.Called(ctx, tripID)
+	return args.Get(0).(*services.TripRoute), args.Error(1)
+}
+
 type MockScooterService struct {
 	mock.Mock
 }
@@ -69,11 +83,21 @@ func (m *MockScooterService) GetClosestScooters(ctx context.Context, params serv
 	return args.Get(0).(*services.ClosestScootersResult), args.Error(1)
 }
 
-func (m *MockScooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64) error {
-	args := m.Called(ctx, scooterID, lat, lng)
+func (m *MockScooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64, batteryLevel *int) error {
+	args := m.Called(ctx, scooterID, lat, lng, batteryLevel)
 	return args.Error(0)
 }
 
+func (m *MockScooterService) RotateDeviceSecret(ctx context.Context, scooterID uuid.UUID) (string, error) {
+	args := m.Called(ctx, scooterID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockScooterService) GetDevicePublicKey(ctx context.Context, scooterID uuid.UUID) (string, error) {
+	args := m.Called(ctx, scooterID)
+	return args.String(0), args.Error(1)
+}
+
 type MockConsumerGroupSession struct {
 	mock.Mock
 }
@@ -195,3 +219,46 @@ func (m *MockConsumerGroup) PauseAll() {
 func (m *MockConsumerGroup) ResumeAll() {
 	m.Called()
 }
+
+type MockMessageSender struct {
+	mock.Mock
+}
+
+func (m *MockMessageSender) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	args := m.Called(msg)
+	return args.Get(0).(int32), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMessageSender) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockEventProducer struct {
+	mock.Mock
+}
+
+func (m *MockEventProducer) PublishTripStarted(ctx context.Context, event *TripStartedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventProducer) PublishTripEnded(ctx context.Context, event *TripEndedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventProducer) PublishLocationUpdated(ctx context.Context, event *LocationUpdatedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventProducer) PublishServerEvent(ctx context.Context, event *ServerEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventProducer) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
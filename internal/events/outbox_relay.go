@@ -0,0 +1,201 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OutboxRelay publishes events written to the outbox by the services. Delivery is at least once:
+// an event published just before its batch fails to commit is published again on the next poll.
+type OutboxRelay struct {
+	unitOfWork   repository.UnitOfWork
+	producer     EventProducer
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	parked       prometheus.Counter
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// NewOutboxRelay creates a relay for the outbox; registerer, which may be nil, receives its metrics
+func NewOutboxRelay(unitOfWork repository.UnitOfWork, producer EventProducer, cfg config.OutboxConfig, registerer prometheus.Registerer) *OutboxRelay {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	relay := &OutboxRelay{
+		unitOfWork:   unitOfWork,
+		producer:     producer,
+		pollInterval: cfg.PollInterval,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		parked: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "scootin_outbox_failed_events_total",
+			Help: "Outbox events marked failed after too many publish attempts.",
+		}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if relay.pollInterval <= 0 {
+		relay.pollInterval = time.Second
+	}
+	if relay.batchSize < 1 {
+		relay.batchSize = 1
+	}
+	if relay.maxAttempts < 1 {
+		relay.maxAttempts = 1
+	}
+	if registerer != nil {
+		registerer.MustRegister(relay.parked)
+	}
+
+	return relay
+}
+
+func (r *OutboxRelay) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+				// Keep draining while batches come back full so a backlog clears without waiting for the next tick
+				for {
+					published, err := r.RelayBatch(r.ctx)
+					if err != nil {
+						logger.Error("Failed to relay outbox events", logger.ErrorField(err))
+						break
+					}
+					if published < r.batchSize || r.ctx.Err() != nil {
+						break
+					}
+				}
+			}
+		}
+	}()
+
+	logger.Info("Outbox relay started", logger.String("poll_interval", r.pollInterval.String()))
+}
+
+func (r *OutboxRelay) Stop() {
+	logger.Info("Stopping outbox relay...")
+	r.cancel()
+	r.wg.Wait()
+	logger.Info("Outbox relay stopped")
+}
+
+// RelayBatch publishes the oldest unpublished events and returns how many were published, each with the ID of
+// the request that caused it. Events for a scooter are never published out of order: after a failure, the
+// scooter's later events in the batch wait for the next poll, while other scooters' events go ahead.
+//
+// An event that has failed maxAttempts times is marked failed and no longer holds up its scooter's later
+// events. Events are only marked failed in a batch that published something else, so a broker outage,
+// which fails every event, marks none of them.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := r.unitOfWork.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var committed bool
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	outboxRepo := tx.OutboxRepository()
+
+	outboxEvents, err := outboxRepo.GetUnpublished(ctx, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unpublished outbox events: %w", err)
+	}
+
+	published := 0
+	var failures []outboxFailure
+	blocked := make(map[uuid.UUID]bool)
+	for _, outboxEvent := range outboxEvents {
+		if blocked[outboxEvent.AggregateID] {
+			continue
+		}
+
+		publishCtx := ctx
+		if outboxEvent.RequestID != nil {
+			publishCtx = logger.WithRequestID(ctx, *outboxEvent.RequestID)
+		}
+
+		if err := r.producer.PublishServerEvent(publishCtx, NewServerEvent(outboxEvent)); err != nil {
+			failures = append(failures, outboxFailure{
+				event: outboxEvent,
+				err:   fmt.Errorf("failed to publish outbox event %s: %w", outboxEvent.ID, err),
+			})
+			blocked[outboxEvent.AggregateID] = true
+			// Two scooters failing before anything was published looks like the broker is down
+			if published == 0 && len(failures) > 1 {
+				break
+			}
+			continue
+		}
+
+		if err := outboxRepo.MarkPublished(ctx, outboxEvent.ID); err != nil {
+			return 0, fmt.Errorf("failed to mark outbox event %s published: %w", outboxEvent.ID, err)
+		}
+		published++
+	}
+
+	var publishErrs []error
+	var parked []outboxFailure
+	for _, failure := range failures {
+		if published > 0 && failure.event.Attempts+1 >= r.maxAttempts {
+			if err := outboxRepo.MarkFailed(ctx, failure.event.ID, failure.err.Error()); err != nil {
+				return 0, fmt.Errorf("failed to mark outbox event %s failed: %w", failure.event.ID, err)
+			}
+			parked = append(parked, failure)
+			continue
+		}
+		if err := outboxRepo.RecordFailure(ctx, failure.event.ID, failure.err.Error()); err != nil {
+			return 0, fmt.Errorf("failed to record outbox failure for %s: %w", failure.event.ID, err)
+		}
+		publishErrs = append(publishErrs, failure.err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	for _, failure := range parked {
+		r.parked.Inc()
+		logger.Error("Outbox event marked failed after repeated publish failures",
+			logger.String("outbox_event_id", failure.event.ID.String()),
+			logger.String("event_type", failure.event.EventType),
+			logger.String("scooter_id", failure.event.AggregateID.String()),
+			logger.Int("attempts", failure.event.Attempts+1),
+			logger.ErrorField(failure.err))
+	}
+
+	return published, errors.Join(publishErrs...)
+}
+
+// outboxFailure is an event that could not be published in a batch
+type outboxFailure struct {
+	event *models.OutboxEvent
+	err   error
+}
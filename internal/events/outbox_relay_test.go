@@ -0,0 +1,223 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository/mocks"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOutboxEvent(eventType string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:          uuid.New(),
+		AggregateID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
+		EventType:   eventType,
+		Payload:     json.RawMessage(`{"tripId":"550e8400-e29b-41d4-a716-446655440000"}`),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	first := newTestOutboxEvent(models.OutboxEventTripStarted)
+	second := newTestOutboxEvent(models.OutboxEventScooterStatusChanged)
+	requested := newTestOutboxEvent(models.OutboxEventTripEnded)
+	requestID := "req-42"
+	requested.RequestID = &requestID
+
+	// poison can never be published; other is an event for another scooter
+	poison := newTestOutboxEvent(models.OutboxEventTripEnded)
+	poison.AggregateID = uuid.New()
+	poison.Attempts = 4
+	retried := *poison
+	retried.Attempts = 1
+	other := newTestOutboxEvent(models.OutboxEventTripStarted)
+	other.AggregateID = uuid.New()
+	isEvent := func(event *models.OutboxEvent) interface{} {
+		return mock.MatchedBy(func(published *ServerEvent) bool { return published.EventID == event.ID.String() })
+	}
+
+	tests := []struct {
+		name              string
+		setupMocks        func(*mocks.MockOutboxRepository, *MockEventProducer, *mocks.MockUnitOfWorkTx)
+		expectedPublished int
+		expectError       bool
+		errorMsg          string
+	}{
+		{
+			name: "publishes and marks every event",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{first, second}, nil)
+				producer.On("PublishServerEvent", mock.Anything, mock.MatchedBy(func(event *ServerEvent) bool {
+					return event.EventID == first.ID.String() && event.EventType == models.OutboxEventTripStarted &&
+						event.ScooterID == first.AggregateID.String()
+				})).Return(nil)
+				producer.On("PublishServerEvent", mock.Anything, mock.MatchedBy(func(event *ServerEvent) bool {
+					return event.EventID == second.ID.String()
+				})).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, first.ID).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, second.ID).Return(nil)
+				tx.On("Commit").Return(nil)
+			},
+			expectedPublished: 2,
+		},
+		{
+			name: "publishes with the ID of the request that caused the event",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{requested, first}, nil)
+				producer.On("PublishServerEvent", mock.MatchedBy(func(ctx context.Context) bool {
+					return logger.RequestID(ctx) == requestID
+				}), mock.MatchedBy(func(event *ServerEvent) bool {
+					return event.EventID == requested.ID.String()
+				})).Return(nil)
+				producer.On("PublishServerEvent", mock.MatchedBy(func(ctx context.Context) bool {
+					return logger.RequestID(ctx) == ""
+				}), mock.MatchedBy(func(event *ServerEvent) bool {
+					return event.EventID == first.ID.String()
+				})).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, requested.ID).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, first.ID).Return(nil)
+				tx.On("Commit").Return(nil)
+			},
+			expectedPublished: 2,
+		},
+		{
+			name: "marks an event failed after too many attempts and publishes the events behind it",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{poison, first, second}, nil)
+				producer.On("PublishServerEvent", mock.Anything, isEvent(poison)).Return(errors.New("message too large"))
+				producer.On("PublishServerEvent", mock.Anything, isEvent(first)).Return(nil)
+				producer.On("PublishServerEvent", mock.Anything, isEvent(second)).Return(nil)
+				outboxRepo.On("MarkFailed", mock.Anything, poison.ID, mock.MatchedBy(func(errMsg string) bool {
+					return strings.Contains(errMsg, "message too large")
+				})).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, first.ID).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, second.ID).Return(nil)
+				tx.On("Commit").Return(nil)
+			},
+			expectedPublished: 2,
+		},
+		{
+			name: "retries a failed event later and publishes other scooters' events meanwhile",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{&retried, first}, nil)
+				producer.On("PublishServerEvent", mock.Anything, isEvent(&retried)).Return(errors.New("message too large"))
+				producer.On("PublishServerEvent", mock.Anything, isEvent(first)).Return(nil)
+				outboxRepo.On("RecordFailure", mock.Anything, retried.ID, mock.AnythingOfType("string")).Return(nil)
+				outboxRepo.On("MarkPublished", mock.Anything, first.ID).Return(nil)
+				tx.On("Commit").Return(nil)
+			},
+			expectedPublished: 1,
+			expectError:       true,
+			errorMsg:          "message too large",
+		},
+		{
+			name: "marks nothing failed when the broker is down",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{poison, other, first}, nil)
+				producer.On("PublishServerEvent", mock.Anything, isEvent(poison)).Return(errors.New("broker unavailable"))
+				producer.On("PublishServerEvent", mock.Anything, isEvent(other)).Return(errors.New("broker unavailable"))
+				outboxRepo.On("RecordFailure", mock.Anything, poison.ID, mock.AnythingOfType("string")).Return(nil)
+				outboxRepo.On("RecordFailure", mock.Anything, other.ID, mock.AnythingOfType("string")).Return(nil)
+				tx.On("Commit").Return(nil)
+			},
+			expectedPublished: 0,
+			expectError:       true,
+			errorMsg:          "broker unavailable",
+		},
+		{
+			name: "holds back a scooter's later events after a publish failure",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{first, second}, nil)
+				producer.On("PublishServerEvent", mock.Anything, mock.MatchedBy(func(event *ServerEvent) bool {
+					return event.EventID == first.ID.String()
+				})).Return(errors.New("broker unavailable"))
+				outboxRepo.On("RecordFailure", mock.Anything, first.ID, mock.AnythingOfType("string")).Return(nil)
+				tx.On("Commit").Return(nil)
+			},
+			expectedPublished: 0,
+			expectError:       true,
+			errorMsg:          "broker unavailable",
+		},
+		{
+			name: "query failure rolls back",
+			setupMocks: func(outboxRepo *mocks.MockOutboxRepository, producer *MockEventProducer, tx *mocks.MockUnitOfWorkTx) {
+				outboxRepo.On("GetUnpublished", mock.Anything, 10).Return(nil, errors.New("database error"))
+				tx.On("Rollback").Return(nil)
+			},
+			expectError: true,
+			errorMsg:    "failed to get unpublished outbox events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unitOfWork := &mocks.MockUnitOfWork{}
+			tx := &mocks.MockUnitOfWorkTx{}
+			outboxRepo := &mocks.MockOutboxRepository{}
+			producer := &MockEventProducer{}
+			unitOfWork.On("Begin", mock.Anything).Return(tx, nil)
+			tx.On("OutboxRepository").Return(outboxRepo)
+			tt.setupMocks(outboxRepo, producer, tx)
+
+			relay := NewOutboxRelay(unitOfWork, producer, config.OutboxConfig{PollInterval: time.Second, BatchSize: 10, MaxAttempts: 5}, nil)
+
+			published, err := relay.RelayBatch(context.Background())
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPublished, published)
+
+			outboxRepo.AssertExpectations(t)
+			producer.AssertExpectations(t)
+			tx.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOutboxRelay_CountsFailedEvents(t *testing.T) {
+	poison := newTestOutboxEvent(models.OutboxEventTripEnded)
+	poison.Attempts = 2
+	healthy := newTestOutboxEvent(models.OutboxEventTripStarted)
+	healthy.AggregateID = uuid.New()
+
+	unitOfWork := &mocks.MockUnitOfWork{}
+	tx := &mocks.MockUnitOfWorkTx{}
+	outboxRepo := &mocks.MockOutboxRepository{}
+	producer := &MockEventProducer{}
+	unitOfWork.On("Begin", mock.Anything).Return(tx, nil)
+	tx.On("OutboxRepository").Return(outboxRepo)
+	tx.On("Commit").Return(nil)
+	outboxRepo.On("GetUnpublished", mock.Anything, 10).Return([]*models.OutboxEvent{poison, healthy}, nil)
+	producer.On("PublishServerEvent", mock.Anything, mock.MatchedBy(func(event *ServerEvent) bool {
+		return event.EventID == poison.ID.String()
+	})).Return(errors.New("unknown topic"))
+	producer.On("PublishServerEvent", mock.Anything, mock.Anything).Return(nil)
+	outboxRepo.On("MarkFailed", mock.Anything, poison.ID, mock.AnythingOfType("string")).Return(nil)
+	outboxRepo.On("MarkPublished", mock.Anything, healthy.ID).Return(nil)
+
+	registry := prometheus.NewRegistry()
+	relay := NewOutboxRelay(unitOfWork, producer, config.OutboxConfig{BatchSize: 10, MaxAttempts: 3}, registry)
+
+	published, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, 1.0, testutil.ToFloat64(relay.parked))
+}
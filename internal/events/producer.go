@@ -8,20 +8,34 @@ import (
 
 	"scootin-aboot/internal/config"
 	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/tracing"
 
 	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// HeaderRequestID carries the ID of the request an event originated from, so the consumer's logs for the
+// event carry it too
+const HeaderRequestID = "request-id"
+
 type EventProducer interface {
 	PublishTripStarted(ctx context.Context, event *TripStartedEvent) error
 	PublishTripEnded(ctx context.Context, event *TripEndedEvent) error
 	PublishLocationUpdated(ctx context.Context, event *LocationUpdatedEvent) error
+	PublishServerEvent(ctx context.Context, event *ServerEvent) error
 	Close() error
 }
 
+// MessageSigner signs an event on behalf of the scooter it is keyed by
+type MessageSigner interface {
+	Sign(scooterID, timestamp string, value []byte) (string, error)
+}
+
 type KafkaProducer struct {
 	producer sarama.SyncProducer
 	config   *config.KafkaConfig
+	signer   MessageSigner
 }
 
 func NewKafkaProducer(cfg *config.KafkaConfig) (*KafkaProducer, error) {
@@ -33,12 +47,13 @@ func NewKafkaProducer(cfg *config.KafkaConfig) (*KafkaProducer, error) {
 	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
 	saramaConfig.Producer.Retry.Max = 3
 	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Partitioner = sarama.NewHashPartitioner
 	saramaConfig.Producer.Timeout = 10 * time.Second
 	saramaConfig.Net.DialTimeout = 5 * time.Second
 	saramaConfig.Net.ReadTimeout = 5 * time.Second
 	saramaConfig.Net.WriteTimeout = 5 * time.Second
 
-	producer, err := sarama.NewSyncProducer(cfg.Brokers, saramaConfig)
+	producer, err := newSyncProducer(cfg, saramaConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
 	}
@@ -49,27 +64,51 @@ func NewKafkaProducer(cfg *config.KafkaConfig) (*KafkaProducer, error) {
 	}, nil
 }
 
+// SetSigner adds a device signature header to every message published afterwards
+func (p *KafkaProducer) SetSigner(signer MessageSigner) {
+	p.signer = signer
+}
+
 func (p *KafkaProducer) PublishTripStarted(ctx context.Context, event *TripStartedEvent) error {
-	return p.publishEvent(ctx, p.config.Topics.TripStarted, event)
+	return p.publishEvent(ctx, p.topicFor(p.config.Topics.TripStarted), event.Data.ScooterID, event)
 }
 
 func (p *KafkaProducer) PublishTripEnded(ctx context.Context, event *TripEndedEvent) error {
-	return p.publishEvent(ctx, p.config.Topics.TripEnded, event)
+	return p.publishEvent(ctx, p.topicFor(p.config.Topics.TripEnded), event.Data.ScooterID, event)
 }
 
 func (p *KafkaProducer) PublishLocationUpdated(ctx context.Context, event *LocationUpdatedEvent) error {
-	return p.publishEvent(ctx, p.config.Topics.LocationUpdated, event)
+	return p.publishEvent(ctx, p.topicFor(p.config.Topics.LocationUpdated), event.Data.ScooterID, event)
+}
+
+func (p *KafkaProducer) PublishServerEvent(ctx context.Context, event *ServerEvent) error {
+	return p.publishEvent(ctx, p.config.Topics.ServerEvents, event.ScooterID, event)
+}
+
+// topicFor routes an event to the shared lifecycle topic when enabled, otherwise to its own topic
+func (p *KafkaProducer) topicFor(topic string) string {
+	if p.config.UseLifecycleTopic {
+		return p.config.Topics.Lifecycle
+	}
+	return topic
 }
 
-func (p *KafkaProducer) publishEvent(ctx context.Context, topic string, event interface{}) error {
+// publishEvent keys messages by scooter ID so all events for a scooter land on the same partition in order.
+// The message carries the trace context of its publish span in a traceparent header, and the request ID
+// attached to ctx, if any, in a request-id header.
+func (p *KafkaProducer) publishEvent(ctx context.Context, topic, scooterID string, event interface{}) (err error) {
+	ctx, span := tracer().Start(ctx, topic+" publish", trace.WithSpanKind(trace.SpanKindProducer), messagingAttributes(topic))
+	defer func() { tracing.End(span, err) }()
+
 	eventJSON, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	timestamp := time.Now().Format(time.RFC3339)
 	message := &sarama.ProducerMessage{
 		Topic: topic,
-		Key:   sarama.StringEncoder(fmt.Sprintf("%s-%d", topic, time.Now().UnixNano())),
+		Key:   sarama.StringEncoder(scooterID),
 		Value: sarama.ByteEncoder(eventJSON),
 		Headers: []sarama.RecordHeader{
 			{
@@ -77,18 +116,37 @@ func (p *KafkaProducer) publishEvent(ctx context.Context, topic string, event in
 				Value: []byte(fmt.Sprintf("%T", event)),
 			},
 			{
-				Key:   []byte("timestamp"),
-				Value: []byte(time.Now().Format(time.RFC3339)),
+				Key:   []byte(HeaderTimestamp),
+				Value: []byte(timestamp),
 			},
 		},
 	}
 
+	otel.GetTextMapPropagator().Inject(ctx, producerHeaders{message})
+	if requestID := logger.RequestID(ctx); requestID != "" {
+		message.Headers = append(message.Headers, sarama.RecordHeader{
+			Key:   []byte(HeaderRequestID),
+			Value: []byte(requestID),
+		})
+	}
+
+	if p.signer != nil {
+		signature, err := p.signer.Sign(scooterID, timestamp, eventJSON)
+		if err != nil {
+			return fmt.Errorf("failed to sign event: %w", err)
+		}
+		message.Headers = append(message.Headers, sarama.RecordHeader{
+			Key:   []byte(HeaderSignature),
+			Value: []byte(signature),
+		})
+	}
+
 	partition, offset, err := p.producer.SendMessage(message)
 	if err != nil {
 		return fmt.Errorf("failed to send message to Kafka: %w", err)
 	}
 
-	logger.Debug("Event published to Kafka",
+	logger.FromContext(ctx).Debug("Event published to Kafka",
 		logger.String("topic", topic),
 		logger.String("partition", fmt.Sprintf("%d", partition)),
 		logger.String("offset", fmt.Sprintf("%d", offset)),
@@ -133,6 +191,12 @@ func (m *MockProducer) PublishLocationUpdated(ctx context.Context, event *Locati
 	return nil
 }
 
+func (m *MockProducer) PublishServerEvent(ctx context.Context, event *ServerEvent) error {
+	m.Events = append(m.Events, event)
+	logger.Debug("Mock: Server event published", logger.String("event_type", event.EventType))
+	return nil
+}
+
 func (m *MockProducer) Close() error {
 	return nil
 }
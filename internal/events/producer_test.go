@@ -6,12 +6,14 @@ import (
 	"testing"
 	"time"
 
+	"scootin-aboot/internal/auth/device"
 	"scootin-aboot/internal/config"
 
 	"github.com/Shopify/sarama"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockSyncProducer is a mock implementation of sarama.SyncProducer
@@ -185,6 +187,93 @@ func TestKafkaProducer_PublishTripStarted(t *testing.T) {
 	}
 }
 
+func TestKafkaProducer_PublishRoutingAndKey(t *testing.T) {
+	event := NewLocationUpdatedEvent("scooter-123", "trip-123", 45.4216, -75.6973, 90.0, 15.5, nil)
+
+	tests := []struct {
+		name              string
+		useLifecycleTopic bool
+		expectedTopic     string
+	}{
+		{
+			name:              "per-type topic",
+			useLifecycleTopic: false,
+			expectedTopic:     "location-updated",
+		},
+		{
+			name:              "lifecycle topic",
+			useLifecycleTopic: true,
+			expectedTopic:     "lifecycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProducer := &MockSyncProducer{}
+			mockProducer.On("SendMessage", mock.MatchedBy(func(msg *sarama.ProducerMessage) bool {
+				key, err := msg.Key.Encode()
+				return err == nil && msg.Topic == tt.expectedTopic && string(key) == "scooter-123"
+			})).Return(int32(0), int64(1), nil)
+
+			producer := &KafkaProducer{
+				producer: mockProducer,
+				config: &config.KafkaConfig{
+					Topics: config.KafkaTopics{
+						LocationUpdated: "location-updated",
+						Lifecycle:       "lifecycle",
+					},
+					UseLifecycleTopic: tt.useLifecycleTopic,
+				},
+			}
+
+			err := producer.PublishLocationUpdated(context.Background(), event)
+
+			assert.NoError(t, err)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestKafkaProducer_SignsMessages(t *testing.T) {
+	scooterID := "550e8400-e29b-41d4-a716-446655440001"
+	secret, err := device.GenerateSecret()
+	require.NoError(t, err)
+
+	signer := device.NewSigner()
+	require.NoError(t, signer.SetSecret(scooterID, secret))
+
+	var sent *sarama.ProducerMessage
+	mockProducer := &MockSyncProducer{}
+	mockProducer.On("SendMessage", mock.Anything).
+		Run(func(args mock.Arguments) { sent = args.Get(0).(*sarama.ProducerMessage) }).
+		Return(int32(0), int64(1), nil)
+
+	producer := &KafkaProducer{
+		producer: mockProducer,
+		config:   &config.KafkaConfig{Topics: config.KafkaTopics{LocationUpdated: "location-updated"}},
+	}
+	producer.SetSigner(signer)
+
+	event := NewLocationUpdatedEvent(scooterID, "trip-123", 45.4216, -75.6973, 90.0, 15.5, nil)
+	require.NoError(t, producer.PublishLocationUpdated(context.Background(), event))
+	require.NotNil(t, sent)
+
+	value, err := sent.Value.Encode()
+	require.NoError(t, err)
+	timestamp := headerValue(sent.Headers, HeaderTimestamp)
+	signature := headerValue(sent.Headers, HeaderSignature)
+	assert.NotEmpty(t, timestamp)
+	publicKey, err := device.PublicKey(secret)
+	require.NoError(t, err)
+	assert.True(t, device.Verify(publicKey, scooterID, timestamp, value, signature))
+
+	// A scooter without a secret cannot publish once signing is enabled
+	other := NewLocationUpdatedEvent("550e8400-e29b-41d4-a716-446655440002", "trip-123", 45.4216, -75.6973, 90.0, 15.5, nil)
+	err = producer.PublishLocationUpdated(context.Background(), other)
+	assert.ErrorIs(t, err, device.ErrNoSecret)
+	mockProducer.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
 func TestKafkaProducer_Close(t *testing.T) {
 	tests := []struct {
 		name        string
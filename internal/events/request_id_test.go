@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/logger"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// publishServerEvent publishes an event with ctx and returns the headers of the message sent
+func publishServerEvent(t *testing.T, ctx context.Context) []*sarama.RecordHeader {
+	sent := make(chan *sarama.ProducerMessage, 1)
+	mockProducer := &MockSyncProducer{}
+	mockProducer.On("SendMessage", mock.AnythingOfType("*sarama.ProducerMessage")).
+		Run(func(args mock.Arguments) { sent <- args.Get(0).(*sarama.ProducerMessage) }).
+		Return(int32(0), int64(1), nil)
+	producer := &KafkaProducer{
+		producer: mockProducer,
+		config:   &config.KafkaConfig{Topics: config.KafkaTopics{ServerEvents: "server-events"}},
+	}
+
+	require.NoError(t, producer.PublishServerEvent(ctx, &ServerEvent{ScooterID: "550e8400-e29b-41d4-a716-446655440001"}))
+
+	published := <-sent
+	headers := make([]*sarama.RecordHeader, 0, len(published.Headers))
+	for i := range published.Headers {
+		headers = append(headers, &published.Headers[i])
+	}
+	return headers
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	headers := publishServerEvent(t, logger.WithRequestID(context.Background(), "req-42"))
+	assert.Equal(t, "req-42", consumerHeaders(headers).Get(HeaderRequestID))
+
+	handler := &contextHandler{}
+	consumer := &EventConsumer{
+		handlers: map[string]EventHandler{"server-events": handler},
+		ctx:      context.Background(),
+	}
+	message := &sarama.ConsumerMessage{Topic: "server-events", Headers: headers}
+	require.NoError(t, consumer.processMessage(messageContext(consumer.ctx, message), message))
+
+	assert.Equal(t, "req-42", logger.RequestID(handler.ctx), "the handler logs with the publisher's request ID")
+}
+
+func TestRequestIDPropagation_WithoutRequestID(t *testing.T) {
+	headers := publishServerEvent(t, context.Background())
+	assert.NotContains(t, consumerHeaders(headers).Keys(), HeaderRequestID)
+
+	handler := &contextHandler{}
+	consumer := &EventConsumer{
+		handlers: map[string]EventHandler{"server-events": handler},
+		ctx:      context.Background(),
+	}
+	message := &sarama.ConsumerMessage{Topic: "server-events", Headers: headers}
+	require.NoError(t, consumer.processMessage(messageContext(consumer.ctx, message), message))
+
+	assert.Empty(t, logger.RequestID(handler.ctx))
+}
+
+func TestConsumeClaim_LogsDeadLetteringWithRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+
+	sender := &MockMessageSender{}
+	sender.On("SendMessage", mock.Anything).Return(int32(0), int64(0), nil).Once()
+	session := &MockConsumerGroupSession{}
+	session.On("Context").Return(context.Background())
+	session.On("MarkMessage", mock.Anything, "").Return()
+	claim := NewMockConsumerGroupClaim()
+	claim.On("Messages").Return(nil)
+
+	topics := config.KafkaTopics{TripStarted: "trip-started", TripStartedDLQ: "trip-started.dlq"}
+	consumer := &EventConsumer{
+		config:           &config.KafkaConfig{Topics: topics},
+		handlers:         map[string]EventHandler{"trip-started": NewTripStartedHandler(HandlerDependencies{})},
+		retryPolicy:      RetryPolicy{MaxAttempts: 1},
+		deadLetterTopics: topics.DeadLetterTopics(),
+		deadLetter:       sender,
+		ctx:              context.Background(),
+	}
+
+	go func() {
+		claim.SendMessage(&sarama.ConsumerMessage{
+			Topic:   "trip-started",
+			Value:   []byte(`invalid json`),
+			Headers: []*sarama.RecordHeader{{Key: []byte(HeaderRequestID), Value: []byte("req-42")}},
+		})
+		claim.Close()
+	}()
+	require.NoError(t, consumer.ConsumeClaim(session, claim))
+
+	for _, message := range []string{"Error processing message", "Message sent to dead-letter topic"} {
+		entries := logs.FilterMessage(message).All()
+		if assert.Len(t, entries, 1, message) {
+			assert.Equal(t, "req-42", entries[0].ContextMap()["request_id"], message)
+		}
+	}
+}
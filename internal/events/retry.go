@@ -0,0 +1,133 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+)
+
+// PermanentError marks a processing failure that cannot succeed on retry, such as a malformed payload
+type PermanentError struct {
+	Err error
+}
+
+func NewPermanentError(err error) error {
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// permanentServiceErrors are business rule violations that a redelivery will hit again
+var permanentServiceErrors = []error{
+	services.ErrInvalidCoordinates,
+	services.ErrInvalidBatteryLevel,
+	services.ErrUserHasActiveTrip,
+	services.ErrScooterNotAvailable,
+	services.ErrScooterReserved,
+	services.ErrScooterBatteryLow,
+	services.ErrScooterHasActiveTrip,
+	services.ErrNoActiveTripOnScooter,
+	services.ErrTripAlreadyExists,
+	services.ErrTripMismatch,
+	services.ErrParkingNotAllowed,
+	repository.ErrScooterNotFound,
+	repository.ErrTripNotFound,
+	repository.ErrUserNotFound,
+}
+
+// IsPermanentError reports whether err should skip retries and go straight to the dead-letter topic.
+// Anything not recognised as permanent, such as a database failure, is treated as transient.
+func IsPermanentError(err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+
+	for _, target := range permanentServiceErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type RetryPolicy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+func NewRetryPolicy(cfg config.KafkaRetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts:       cfg.MaxAttempts,
+		InitialBackoff:    cfg.InitialBackoff,
+		MaxBackoff:        cfg.MaxBackoff,
+		BackoffMultiplier: cfg.BackoffMultiplier,
+	}
+
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.BackoffMultiplier < 1 {
+		policy.BackoffMultiplier = 1
+	}
+
+	return policy
+}
+
+// Backoff returns the delay to wait after the given failed attempt, starting at 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= p.BackoffMultiplier
+		if p.MaxBackoff > 0 && delay >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// TotalBackoff returns the longest time Do can spend waiting between attempts
+func (p RetryPolicy) TotalBackoff() time.Duration {
+	var total time.Duration
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		total += p.Backoff(attempt)
+	}
+	return total
+}
+
+// Do runs fn until it succeeds, fails permanently or runs out of attempts, returning the number of attempts made
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) (int, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return attempt, nil
+		}
+
+		if IsPermanentError(err) || attempt >= p.MaxAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(p.Backoff(attempt)):
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	policy := NewRetryPolicy(config.KafkaRetryConfig{})
+
+	assert.Equal(t, 1, policy.MaxAttempts)
+	assert.Equal(t, 1.0, policy.BackoffMultiplier)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        500 * time.Millisecond,
+		BackoffMultiplier: 2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 400*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(4))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(10))
+}
+
+func TestRetryPolicy_TotalBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        500 * time.Millisecond,
+		BackoffMultiplier: 2,
+	}
+
+	// Waits follow the first four attempts: 100ms + 200ms + 400ms + 500ms
+	assert.Equal(t, 1200*time.Millisecond, policy.TotalBackoff())
+	assert.Zero(t, RetryPolicy{MaxAttempts: 1, InitialBackoff: time.Second}.TotalBackoff())
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        time.Millisecond,
+		BackoffMultiplier: 2,
+	}
+
+	tests := []struct {
+		name             string
+		errs             []error
+		expectError      bool
+		expectedAttempts int
+	}{
+		{
+			name:             "succeeds on first attempt",
+			errs:             []error{nil},
+			expectError:      false,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retries transient errors until success",
+			errs:             []error{errors.New("connection refused"), errors.New("connection refused"), nil},
+			expectError:      false,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "gives up after max attempts",
+			errs:             []error{errors.New("connection refused"), errors.New("connection refused"), errors.New("connection refused")},
+			expectError:      true,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "does not retry permanent errors",
+			errs:             []error{NewPermanentError(errors.New("failed to unmarshal"))},
+			expectError:      true,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			attempts, err := policy.Do(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAttempts, attempts)
+			assert.Equal(t, tt.expectedAttempts, calls)
+		})
+	}
+}
+
+func TestRetryPolicy_Do_ContextCancelled(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, BackoffMultiplier: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts, err := policy.Do(ctx, func() error {
+		return errors.New("connection refused")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestIsPermanentError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"permanent error", NewPermanentError(errors.New("invalid scooter ID")), true},
+		{"wrapped permanent error", fmt.Errorf("outer: %w", NewPermanentError(errors.New("bad"))), true},
+		{"business rule violation", fmt.Errorf("failed to start trip: %w", services.ErrUserHasActiveTrip), true},
+		{"missing entity", fmt.Errorf("failed to end trip: %w", repository.ErrScooterNotFound), true},
+		{"database error", fmt.Errorf("failed to start trip: %w", errors.New("connection reset by peer")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsPermanentError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/auth/device"
+	"scootin-aboot/internal/repository"
+
+	"github.com/Shopify/sarama"
+	"github.com/google/uuid"
+)
+
+// Headers carrying the device signature of an event. The signature covers the message key, the
+// timestamp header and the value, so none of them can be altered without invalidating it.
+const (
+	HeaderTimestamp = "timestamp"
+	HeaderSignature = "signature"
+
+	// HeaderRejectedReason is attached to messages diverted to the rejected topic
+	HeaderRejectedReason = "rejected-reason"
+)
+
+// Reasons a message fails signature verification
+const (
+	RejectUnsigned         = "unsigned"
+	RejectUnknownDevice    = "unknown_device"
+	RejectInvalidSignature = "invalid_signature"
+	RejectScooterMismatch  = "scooter_mismatch"
+	// RejectInvalidTimestamp is for a timestamp header that is missing or not RFC 3339
+	RejectInvalidTimestamp = "invalid_timestamp"
+	// RejectStaleTimestamp is for a message signed further from now than the allowed skew, such as a replay
+	RejectStaleTimestamp = "stale_timestamp"
+	// RejectMissingEventID is for a signed event without an eventId, which replays could not be told apart by
+	RejectMissingEventID = "missing_event_id"
+)
+
+// RejectedError is returned for a message that is not signed by the scooter it claims to come from
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return "message rejected: " + e.Reason
+}
+
+// DeviceKeyLookup returns the public key that verifies a scooter's signatures, or an empty string when
+// the scooter has no device secret
+type DeviceKeyLookup func(ctx context.Context, scooterID uuid.UUID) (string, error)
+
+type signatureVerifier struct {
+	keys    DeviceKeyLookup
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+// newSignatureVerifier creates a verifier accepting messages signed at most maxSkew before or after now
+func newSignatureVerifier(keys DeviceKeyLookup, maxSkew time.Duration) *signatureVerifier {
+	return &signatureVerifier{keys: keys, maxSkew: maxSkew, now: time.Now}
+}
+
+// verify checks that the message was signed with the device secret of the scooter it is keyed by, recently
+// enough not to be a replay, and that the payload is about that scooter and carries an event ID. Failures
+// are permanent RejectedErrors, except for errors looking up the key, which are returned as-is so the
+// message is retried.
+func (v *signatureVerifier) verify(ctx context.Context, message *sarama.ConsumerMessage) error {
+	signature := messageHeader(message, HeaderSignature)
+	if signature == "" {
+		return rejected(RejectUnsigned)
+	}
+
+	scooterID, err := uuid.Parse(string(message.Key))
+	if err != nil {
+		return rejected(RejectUnknownDevice)
+	}
+
+	publicKey, err := v.keys(ctx, scooterID)
+	if err != nil {
+		if errors.Is(err, repository.ErrScooterNotFound) {
+			return rejected(RejectUnknownDevice)
+		}
+		return fmt.Errorf("failed to look up device key: %w", err)
+	}
+	if publicKey == "" {
+		return rejected(RejectUnknownDevice)
+	}
+
+	timestamp := messageHeader(message, HeaderTimestamp)
+	if !device.Verify(publicKey, string(message.Key), timestamp, message.Value, signature) {
+		return rejected(RejectInvalidSignature)
+	}
+
+	signedAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return rejected(RejectInvalidTimestamp)
+	}
+	if skew := v.now().Sub(signedAt); skew > v.maxSkew || skew < -v.maxSkew {
+		return rejected(RejectStaleTimestamp)
+	}
+
+	// A device may only report about itself, whatever key it publishes under
+	var payload struct {
+		EventID string `json:"eventId"`
+		Data    struct {
+			ScooterID string `json:"scooterId"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message.Value, &payload); err != nil {
+		// Undecodable payloads are left to the handler, which dead-letters them
+		return nil
+	}
+	if claimed, err := uuid.Parse(payload.Data.ScooterID); err != nil || claimed != scooterID {
+		return rejected(RejectScooterMismatch)
+	}
+	// The processed events ledger drops a replay within the skew window by its event ID
+	if payload.EventID == "" {
+		return rejected(RejectMissingEventID)
+	}
+
+	return nil
+}
+
+func rejected(reason string) error {
+	return NewPermanentError(&RejectedError{Reason: reason})
+}
+
+// rejectionReason returns the reason a message was rejected, if err is a rejection
+func rejectionReason(err error) (string, bool) {
+	var rejectedErr *RejectedError
+	if errors.As(err, &rejectedErr) {
+		return rejectedErr.Reason, true
+	}
+	return "", false
+}
+
+func messageHeader(message *sarama.ConsumerMessage, key string) string {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
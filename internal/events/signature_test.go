@@ -0,0 +1,284 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/auth/device"
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/Shopify/sarama"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const signedScooterID = "550e8400-e29b-41d4-a716-446655440001"
+
+var signedPayload = []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`)
+
+// newDeviceSecret returns a device secret and the public key the server stores for it
+func newDeviceSecret(t *testing.T) (secret, publicKey string) {
+	t.Helper()
+	secret, err := device.GenerateSecret()
+	require.NoError(t, err)
+	publicKey, err = device.PublicKey(secret)
+	require.NoError(t, err)
+	return secret, publicKey
+}
+
+// signedMessage returns a message signed with secret just now
+func signedMessage(t *testing.T, secret, key string, value []byte) *sarama.ConsumerMessage {
+	t.Helper()
+	return signedMessageAt(t, secret, key, value, time.Now().UTC().Format(time.RFC3339))
+}
+
+func signedMessageAt(t *testing.T, secret, key string, value []byte, timestamp string) *sarama.ConsumerMessage {
+	t.Helper()
+	signer := device.NewSigner()
+	require.NoError(t, signer.SetSecret(key, secret))
+	signature, err := signer.Sign(key, timestamp, value)
+	require.NoError(t, err)
+
+	return &sarama.ConsumerMessage{
+		Topic: "trip-started",
+		Key:   []byte(key),
+		Value: value,
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(HeaderTimestamp), Value: []byte(timestamp)},
+			{Key: []byte(HeaderSignature), Value: []byte(signature)},
+		},
+	}
+}
+
+func TestSignatureVerifier_verify(t *testing.T) {
+	secret, publicKey := newDeviceSecret(t)
+	otherSecret, _ := newDeviceSecret(t)
+	otherID := "550e8400-e29b-41d4-a716-446655440009"
+
+	lookup := func(ctx context.Context, scooterID uuid.UUID) (string, error) {
+		switch scooterID.String() {
+		case signedScooterID:
+			return publicKey, nil
+		case otherID:
+			return "", nil
+		case "550e8400-e29b-41d4-a716-4466554400ee":
+			return "", errors.New("connection reset")
+		}
+		return "", repository.ErrScooterNotFound
+	}
+
+	tampered := signedMessage(t, secret, signedScooterID, signedPayload)
+	tampered.Value = append([]byte{}, signedPayload...)
+	tampered.Value[len(tampered.Value)-3] = '1'
+
+	wrongTimestamp := signedMessage(t, secret, signedScooterID, signedPayload)
+	wrongTimestamp.Headers[0].Value = []byte(time.Now().Add(time.Second).UTC().Format(time.RFC3339Nano))
+
+	missingTimestamp := signedMessageAt(t, secret, signedScooterID, signedPayload, "")
+	missingTimestamp.Headers = missingTimestamp.Headers[1:]
+
+	withoutEventID := []byte(strings.Replace(string(signedPayload), `"eventId":"test-id"`, `"eventId":""`, 1))
+
+	tests := []struct {
+		name      string
+		message   *sarama.ConsumerMessage
+		reason    string
+		transient bool
+	}{
+		{
+			name:    "valid signature",
+			message: signedMessage(t, secret, signedScooterID, signedPayload),
+		},
+		{
+			name:    "unsigned",
+			message: &sarama.ConsumerMessage{Key: []byte(signedScooterID), Value: signedPayload},
+			reason:  RejectUnsigned,
+		},
+		{
+			name:    "key is not a scooter ID",
+			message: signedMessage(t, secret, "scooter-1", signedPayload),
+			reason:  RejectUnknownDevice,
+		},
+		{
+			name:    "unknown scooter",
+			message: signedMessage(t, secret, "550e8400-e29b-41d4-a716-446655440077", signedPayload),
+			reason:  RejectUnknownDevice,
+		},
+		{
+			name:    "scooter without a device secret",
+			message: signedMessage(t, secret, otherID, signedPayload),
+			reason:  RejectUnknownDevice,
+		},
+		{
+			name:    "tampered payload",
+			message: tampered,
+			reason:  RejectInvalidSignature,
+		},
+		{
+			name:    "tampered timestamp",
+			message: wrongTimestamp,
+			reason:  RejectInvalidSignature,
+		},
+		{
+			name:    "missing timestamp",
+			message: missingTimestamp,
+			reason:  RejectInvalidTimestamp,
+		},
+		{
+			name:    "malformed timestamp",
+			message: signedMessageAt(t, secret, signedScooterID, signedPayload, "yesterday"),
+			reason:  RejectInvalidTimestamp,
+		},
+		{
+			name:    "replayed long after it was signed",
+			message: signedMessageAt(t, secret, signedScooterID, signedPayload, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)),
+			reason:  RejectStaleTimestamp,
+		},
+		{
+			name:    "signed in the future",
+			message: signedMessageAt(t, secret, signedScooterID, signedPayload, time.Now().Add(time.Hour).UTC().Format(time.RFC3339)),
+			reason:  RejectStaleTimestamp,
+		},
+		{
+			name:    "within the allowed skew",
+			message: signedMessageAt(t, secret, signedScooterID, signedPayload, time.Now().Add(-4*time.Minute).UTC().Format(time.RFC3339)),
+		},
+		{
+			name:    "missing event ID",
+			message: signedMessage(t, secret, signedScooterID, withoutEventID),
+			reason:  RejectMissingEventID,
+		},
+		{
+			name:    "signed with another key",
+			message: signedMessage(t, otherSecret, signedScooterID, signedPayload),
+			reason:  RejectInvalidSignature,
+		},
+		{
+			name:      "key lookup failure is transient",
+			message:   signedMessage(t, secret, "550e8400-e29b-41d4-a716-4466554400ee", signedPayload),
+			transient: true,
+		},
+	}
+
+	verifier := newSignatureVerifier(lookup, 5*time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.verify(context.Background(), tt.message)
+
+			switch {
+			case tt.transient:
+				require.Error(t, err)
+				assert.False(t, IsPermanentError(err))
+			case tt.reason != "":
+				require.Error(t, err)
+				assert.True(t, IsPermanentError(err))
+				reason, ok := rejectionReason(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.reason, reason)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifier_RejectsPayloadForAnotherScooter(t *testing.T) {
+	// A device signing with its own key must not report events about a different scooter
+	attackerID := "550e8400-e29b-41d4-a716-446655440009"
+	secret, publicKey := newDeviceSecret(t)
+
+	verifier := newSignatureVerifier(func(ctx context.Context, scooterID uuid.UUID) (string, error) {
+		return publicKey, nil
+	}, 5*time.Minute)
+
+	err := verifier.verify(context.Background(), signedMessage(t, secret, attackerID, signedPayload))
+
+	reason, ok := rejectionReason(err)
+	assert.True(t, ok)
+	assert.Equal(t, RejectScooterMismatch, reason)
+}
+
+func TestEventConsumer_ConsumeClaim_RejectsUnsignedMessages(t *testing.T) {
+	secret, publicKey := newDeviceSecret(t)
+	forgerSecret, _ := newDeviceSecret(t)
+
+	tripService := &MockTripService{}
+	scooterService := &MockScooterService{}
+	sender := &MockMessageSender{}
+	session := &MockConsumerGroupSession{}
+	claim := NewMockConsumerGroupClaim()
+
+	scooterService.On("GetDevicePublicKey", mock.Anything, uuid.MustParse(signedScooterID)).Return(publicKey, nil)
+	tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil).Once()
+
+	var rejected []*sarama.ProducerMessage
+	sender.On("SendMessage", mock.Anything).
+		Run(func(args mock.Arguments) { rejected = append(rejected, args.Get(0).(*sarama.ProducerMessage)) }).
+		Return(int32(0), int64(0), nil)
+
+	session.On("Context").Return(context.Background())
+	session.On("MarkMessage", mock.Anything, "").Return()
+	claim.On("Messages").Return(nil)
+
+	topics := config.KafkaTopics{
+		TripStarted:    "trip-started",
+		TripStartedDLQ: "trip-started.dlq",
+		Rejected:       "rejected",
+	}
+
+	consumer := &EventConsumer{
+		config: &config.KafkaConfig{Topics: topics},
+		handlers: map[string]EventHandler{"trip-started": NewTripStartedHandler(HandlerDependencies{
+			TripService:    tripService,
+			ScooterService: scooterService,
+		})},
+		retryPolicy: RetryPolicy{
+			MaxAttempts:       3,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        time.Millisecond,
+			BackoffMultiplier: 2,
+		},
+		deadLetterTopics: topics.DeadLetterTopics(),
+		deadLetter:       sender,
+		verifier:         newSignatureVerifier(scooterService.GetDevicePublicKey, 5*time.Minute),
+		ctx:              context.Background(),
+	}
+
+	forged := signedMessage(t, forgerSecret, signedScooterID, signedPayload)
+	forged.Offset = 2
+	valid := signedMessage(t, secret, signedScooterID, signedPayload)
+	valid.Offset = 3
+	replayed := signedMessageAt(t, secret, signedScooterID, signedPayload, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	replayed.Offset = 4
+
+	go func() {
+		claim.SendMessage(&sarama.ConsumerMessage{Topic: "trip-started", Offset: 1, Key: []byte(signedScooterID), Value: signedPayload})
+		claim.SendMessage(forged)
+		claim.SendMessage(valid)
+		claim.SendMessage(replayed)
+		claim.Close()
+	}()
+
+	require.NoError(t, consumer.ConsumeClaim(session, claim))
+
+	// Rejected messages are not retried, are diverted to the rejected topic, and do not reach the handler
+	tripService.AssertExpectations(t)
+	session.AssertNumberOfCalls(t, "MarkMessage", 4)
+	require.Len(t, rejected, 3)
+	for _, msg := range rejected {
+		assert.Equal(t, "rejected", msg.Topic)
+		assert.Equal(t, "trip-started", headerValue(msg.Headers, HeaderDLQSourceTopic))
+	}
+	assert.Equal(t, RejectUnsigned, headerValue(rejected[0].Headers, HeaderRejectedReason))
+	assert.Equal(t, RejectInvalidSignature, headerValue(rejected[1].Headers, HeaderRejectedReason))
+	assert.Equal(t, RejectStaleTimestamp, headerValue(rejected[2].Headers, HeaderRejectedReason))
+
+	assert.Equal(t, map[string]int64{RejectUnsigned: 1, RejectInvalidSignature: 1, RejectStaleTimestamp: 1}, consumer.Rejections())
+}
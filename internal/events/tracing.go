@@ -0,0 +1,78 @@
+package events
+
+import (
+	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "scootin-aboot/internal/events"
+
+// messagingAttributes describe a message on topic for spans
+func messagingAttributes(topic string) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", topic),
+	)
+}
+
+// producerHeaders carries trace context into the headers of a message being published
+type producerHeaders struct {
+	message *sarama.ProducerMessage
+}
+
+var _ propagation.TextMapCarrier = producerHeaders{}
+
+func (h producerHeaders) Get(key string) string {
+	for _, header := range h.message.Headers {
+		if string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h producerHeaders) Set(key, value string) {
+	h.message.Headers = append(h.message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (h producerHeaders) Keys() []string {
+	keys := make([]string, 0, len(h.message.Headers))
+	for _, header := range h.message.Headers {
+		keys = append(keys, string(header.Key))
+	}
+	return keys
+}
+
+// consumerHeaders reads trace context from the headers of a consumed message
+type consumerHeaders []*sarama.RecordHeader
+
+var _ propagation.TextMapCarrier = consumerHeaders{}
+
+func (h consumerHeaders) Get(key string) string {
+	for _, header := range h {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set is not used: consumed messages are only read
+func (h consumerHeaders) Set(string, string) {}
+
+func (h consumerHeaders) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for _, header := range h {
+		if header != nil {
+			keys = append(keys, string(header.Key))
+		}
+	}
+	return keys
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/tracing/tracingtest"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextHandler keeps the context it handled a message with
+type contextHandler struct {
+	ctx context.Context
+}
+
+func (h *contextHandler) Handle(ctx context.Context, data []byte) error {
+	h.ctx = ctx
+	return nil
+}
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	sent := make(chan *sarama.ProducerMessage, 1)
+	mockProducer := &MockSyncProducer{}
+	mockProducer.On("SendMessage", mock.AnythingOfType("*sarama.ProducerMessage")).
+		Run(func(args mock.Arguments) { sent <- args.Get(0).(*sarama.ProducerMessage) }).
+		Return(int32(0), int64(1), nil)
+	producer := &KafkaProducer{
+		producer: mockProducer,
+		config:   &config.KafkaConfig{Topics: config.KafkaTopics{LocationUpdated: "location-updated"}},
+	}
+
+	ctx, simulator := otel.Tracer("test").Start(context.Background(), "simulate")
+	require.NoError(t, producer.PublishLocationUpdated(ctx, NewLocationUpdatedEvent("550e8400-e29b-41d4-a716-446655440001", "", 45.4216, -75.6973, 90, 15, nil)))
+	simulator.End()
+
+	published := <-sent
+	var headers []*sarama.RecordHeader
+	for i := range published.Headers {
+		headers = append(headers, &published.Headers[i])
+	}
+	assert.NotEmpty(t, consumerHeaders(headers).Get("traceparent"))
+
+	handler := &contextHandler{}
+	consumer := &EventConsumer{
+		handlers: map[string]EventHandler{"location-updated": handler},
+		ctx:      context.Background(),
+	}
+	require.NoError(t, consumer.processMessage(context.Background(), &sarama.ConsumerMessage{Topic: "location-updated", Headers: headers}))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	publish, process := spans[0], spans[2]
+	assert.Equal(t, "location-updated publish", publish.Name())
+	assert.Equal(t, trace.SpanKindProducer, publish.SpanKind())
+	assert.Equal(t, simulator.SpanContext().SpanID(), publish.Parent().SpanID())
+
+	assert.Equal(t, "location-updated process", process.Name())
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind())
+	assert.Equal(t, publish.SpanContext().TraceID(), process.SpanContext().TraceID())
+	assert.Equal(t, publish.SpanContext().SpanID(), process.Parent().SpanID())
+	assert.True(t, process.Parent().IsRemote())
+
+	assert.Equal(t, process.SpanContext(), trace.SpanContextFromContext(handler.ctx), "the handler runs inside the process span")
+}
+
+func TestProcessMessage_WithoutTraceContext(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	consumer := &EventConsumer{
+		handlers: map[string]EventHandler{"location-updated": &contextHandler{}},
+		ctx:      context.Background(),
+	}
+	require.NoError(t, consumer.processMessage(context.Background(), &sarama.ConsumerMessage{Topic: "location-updated"}))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent().IsValid(), "a message without a traceparent starts a new trace")
+}
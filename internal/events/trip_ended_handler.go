@@ -3,9 +3,12 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
 
 	"github.com/google/uuid"
 )
@@ -23,29 +26,41 @@ func NewTripEndedHandler(deps HandlerDependencies) *TripEndedHandler {
 func (h *TripEndedHandler) Handle(ctx context.Context, data []byte) error {
 	var event TripEndedEvent
 	if err := json.Unmarshal(data, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal trip ended event: %w", err)
+		return NewPermanentError(fmt.Errorf("failed to unmarshal trip ended event: %w", err))
 	}
 
-	logger.Info("Processing trip ended event",
-		logger.String("trip_id", event.Data.TripID),
-		logger.String("scooter_id", event.Data.ScooterID),
+	ctx = logger.WithScooterID(ctx, event.Data.ScooterID)
+	ctx = logger.WithTripID(ctx, event.Data.TripID)
+	logger.FromContext(ctx).Info("Processing trip ended event",
 		logger.String("user_id", event.Data.UserID),
 		logger.Int("duration_seconds", event.Data.DurationSeconds),
 	)
 
+	tripID, err := parseOptionalTripID(event.Data.TripID)
+	if err != nil {
+		return NewPermanentError(fmt.Errorf("invalid trip ID: %w", err))
+	}
+
 	scooterID, err := uuid.Parse(event.Data.ScooterID)
 	if err != nil {
-		return fmt.Errorf("invalid scooter ID: %w", err)
+		return NewPermanentError(fmt.Errorf("invalid scooter ID: %w", err))
 	}
 
-	trip, err := h.deps.TripService.EndTrip(ctx, scooterID, event.Data.EndLatitude, event.Data.EndLongitude)
+	ctx = services.WithProcessedEvent(ctx, event.EventID, event.EventType)
+	trip, err := h.deps.TripService.EndTrip(ctx, tripID, scooterID, event.Data.EndLatitude, event.Data.EndLongitude)
+	if errors.Is(err, repository.ErrEventAlreadyProcessed) {
+		logger.FromContext(ctx).Info("Skipping already processed event",
+			logger.String("event_id", event.EventID),
+			logger.String("event_type", event.EventType),
+		)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to end trip: %w", err)
 	}
 
-	logger.Info("Trip ended event processed successfully",
-		logger.String("trip_id", trip.ID.String()),
-		logger.String("scooter_id", event.Data.ScooterID),
+	// The event may leave the trip to be found from the scooter
+	logger.FromContext(logger.WithTripID(ctx, trip.ID.String())).Info("Trip ended event processed successfully",
 		logger.String("user_id", event.Data.UserID),
 	)
 
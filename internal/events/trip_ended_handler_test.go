@@ -3,9 +3,12 @@ package events
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,9 +24,9 @@ func TestTripEndedHandler_Handle(t *testing.T) {
 	}{
 		{
 			name: "valid trip ended event",
-			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"trip-123","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
+			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
 			setupMocks: func(tripService *MockTripService) {
-				tripService.On("EndTrip", mock.Anything, mock.Anything, 45.4216, -75.6973).Return(&models.Trip{}, nil)
+				tripService.On("EndTrip", mock.Anything, testTripID, mock.Anything, 45.4216, -75.6973).Return(&models.Trip{}, nil)
 			},
 			expectError: false,
 		},
@@ -37,17 +40,42 @@ func TestTripEndedHandler_Handle(t *testing.T) {
 		},
 		{
 			name: "invalid scooter ID",
-			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"trip-123","scooterId":"invalid-uuid","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
+			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"invalid-uuid","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
 			setupMocks: func(tripService *MockTripService) {
 			},
 			expectError: true,
 			errorMsg:    "invalid scooter ID",
 		},
 		{
-			name: "trip service error",
+			name: "invalid trip ID",
 			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"trip-123","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
 			setupMocks: func(tripService *MockTripService) {
-				tripService.On("EndTrip", mock.Anything, mock.Anything, 45.4216, -75.6973).Return((*models.Trip)(nil), errors.New("service error"))
+			},
+			expectError: true,
+			errorMsg:    "invalid trip ID",
+		},
+		{
+			name: "trip ID mismatch",
+			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
+			setupMocks: func(tripService *MockTripService) {
+				tripService.On("EndTrip", mock.Anything, testTripID, mock.Anything, 45.4216, -75.6973).Return((*models.Trip)(nil), services.ErrTripMismatch)
+			},
+			expectError: true,
+			errorMsg:    "trip ID does not match active trip",
+		},
+		{
+			name: "already processed event is skipped",
+			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
+			setupMocks: func(tripService *MockTripService) {
+				tripService.On("EndTrip", mock.MatchedBy(isProcessingEvent), testTripID, mock.Anything, 45.4216, -75.6973).Return((*models.Trip)(nil), fmt.Errorf("failed to record processed event test-id: %w", repository.ErrEventAlreadyProcessed))
+			},
+			expectError: false,
+		},
+		{
+			name: "trip service error",
+			data: []byte(`{"eventType":"trip.ended","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"user-123","endLatitude":45.4216,"endLongitude":-75.6973,"endTime":"2023-01-01T00:30:00Z","durationSeconds":1800}}`),
+			setupMocks: func(tripService *MockTripService) {
+				tripService.On("EndTrip", mock.Anything, testTripID, mock.Anything, 45.4216, -75.6973).Return((*models.Trip)(nil), errors.New("service error"))
 			},
 			expectError: true,
 			errorMsg:    "failed to end trip",
@@ -3,9 +3,12 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/services"
 
 	"github.com/google/uuid"
 )
@@ -23,33 +26,45 @@ func NewTripStartedHandler(deps HandlerDependencies) *TripStartedHandler {
 func (h *TripStartedHandler) Handle(ctx context.Context, data []byte) error {
 	var event TripStartedEvent
 	if err := json.Unmarshal(data, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal trip started event: %w", err)
+		return NewPermanentError(fmt.Errorf("failed to unmarshal trip started event: %w", err))
 	}
 
-	logger.Info("Processing trip started event",
-		logger.String("trip_id", event.Data.TripID),
-		logger.String("scooter_id", event.Data.ScooterID),
+	ctx = logger.WithScooterID(ctx, event.Data.ScooterID)
+	ctx = logger.WithTripID(ctx, event.Data.TripID)
+	logger.FromContext(ctx).Info("Processing trip started event",
 		logger.String("user_id", event.Data.UserID),
 	)
 
+	tripID, err := parseOptionalTripID(event.Data.TripID)
+	if err != nil {
+		return NewPermanentError(fmt.Errorf("invalid trip ID: %w", err))
+	}
+
 	scooterID, err := uuid.Parse(event.Data.ScooterID)
 	if err != nil {
-		return fmt.Errorf("invalid scooter ID: %w", err)
+		return NewPermanentError(fmt.Errorf("invalid scooter ID: %w", err))
 	}
 
 	userID, err := uuid.Parse(event.Data.UserID)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return NewPermanentError(fmt.Errorf("invalid user ID: %w", err))
 	}
 
-	trip, err := h.deps.TripService.StartTrip(ctx, scooterID, userID, event.Data.StartLatitude, event.Data.StartLongitude)
+	ctx = services.WithProcessedEvent(ctx, event.EventID, event.EventType)
+	trip, err := h.deps.TripService.StartTrip(ctx, tripID, scooterID, userID, event.Data.StartLatitude, event.Data.StartLongitude)
+	if errors.Is(err, repository.ErrEventAlreadyProcessed) {
+		logger.FromContext(ctx).Info("Skipping already processed event",
+			logger.String("event_id", event.EventID),
+			logger.String("event_type", event.EventType),
+		)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to start trip: %w", err)
 	}
 
-	logger.Info("Trip started event processed successfully",
-		logger.String("trip_id", trip.ID.String()),
-		logger.String("scooter_id", event.Data.ScooterID),
+	// The event may leave the trip ID to the server
+	logger.FromContext(logger.WithTripID(ctx, trip.ID.String())).Info("Trip started event processed successfully",
 		logger.String("user_id", event.Data.UserID),
 	)
 
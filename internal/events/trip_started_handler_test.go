@@ -3,10 +3,13 @@ package events
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,7 +26,7 @@ func TestTripStartedHandler_Handle(t *testing.T) {
 			name: "valid trip started event",
 			data: []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`),
 			setupMocks: func(tripService *MockTripService) {
-				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
+				tripService.On("StartTrip", mock.Anything, testTripID, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
 			},
 			expectError: false,
 		},
@@ -51,11 +54,35 @@ func TestTripStartedHandler_Handle(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid user ID",
 		},
+		{
+			name: "missing trip ID lets the service generate one",
+			data: []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`),
+			setupMocks: func(tripService *MockTripService) {
+				tripService.On("StartTrip", mock.Anything, uuid.Nil, mock.Anything, mock.Anything, 45.4215, -75.6972).Return(&models.Trip{}, nil)
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid trip ID",
+			data: []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"trip-123","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`),
+			setupMocks: func(tripService *MockTripService) {
+			},
+			expectError: true,
+			errorMsg:    "invalid trip ID",
+		},
+		{
+			name: "already processed event is skipped",
+			data: []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`),
+			setupMocks: func(tripService *MockTripService) {
+				tripService.On("StartTrip", mock.MatchedBy(isProcessingEvent), testTripID, mock.Anything, mock.Anything, 45.4215, -75.6972).Return((*models.Trip)(nil), fmt.Errorf("failed to record processed event test-id: %w", repository.ErrEventAlreadyProcessed))
+			},
+			expectError: false,
+		},
 		{
 			name: "trip service error",
 			data: []byte(`{"eventType":"trip.started","eventId":"test-id","timestamp":"2023-01-01T00:00:00Z","version":"1.0","data":{"tripId":"550e8400-e29b-41d4-a716-446655440000","scooterId":"550e8400-e29b-41d4-a716-446655440001","userId":"550e8400-e29b-41d4-a716-446655440002","startLatitude":45.4215,"startLongitude":-75.6972,"startTime":"2023-01-01T00:00:00Z"}}`),
 			setupMocks: func(tripService *MockTripService) {
-				tripService.On("StartTrip", mock.Anything, mock.Anything, mock.Anything, 45.4215, -75.6972).Return((*models.Trip)(nil), errors.New("service error"))
+				tripService.On("StartTrip", mock.Anything, testTripID, mock.Anything, mock.Anything, 45.4215, -75.6972).Return((*models.Trip)(nil), errors.New("service error"))
 			},
 			expectError: true,
 			errorMsg:    "failed to start trip",
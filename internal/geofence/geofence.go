@@ -0,0 +1,243 @@
+package geofence
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ZoneType says what a zone restricts
+type ZoneType string
+
+const (
+	// ZoneTypeServiceArea is where scooters operate; trips can only end inside one
+	ZoneTypeServiceArea ZoneType = "service_area"
+	// ZoneTypeNoParking is an area inside a service area where trips cannot end
+	ZoneTypeNoParking ZoneType = "no_parking"
+	// ZoneTypeSlow is an area with a lower speed limit
+	ZoneTypeSlow ZoneType = "slow_zone"
+)
+
+func (t ZoneType) IsValid() bool {
+	switch t {
+	case ZoneTypeServiceArea, ZoneTypeNoParking, ZoneTypeSlow:
+		return true
+	}
+	return false
+}
+
+var (
+	ErrOutsideServiceArea = errors.New("location is outside the service area")
+	ErrNoParkingZone      = errors.New("location is in a no-parking zone")
+)
+
+// Position is a GeoJSON position: longitude first, then latitude
+type Position [2]float64
+
+// Polygon is a list of closed rings; the first is the outer boundary and any others are holes in it
+type Polygon [][]Position
+
+type Zone struct {
+	ID   string
+	Name string
+	Type ZoneType
+	// SpeedLimitKmh is the speed limit inside slow zones
+	SpeedLimitKmh float64
+	// Polygons make up the zone's area, as a GeoJSON MultiPolygon
+	Polygons []Polygon
+
+	minLat, maxLat, minLng, maxLng float64
+}
+
+// Contains reports whether the point lies inside the zone. Edges are treated as planar, which is
+// accurate enough at city scale.
+func (z *Zone) Contains(lat, lng float64) bool {
+	if lat < z.minLat || lat > z.maxLat || lng < z.minLng || lng > z.maxLng {
+		return false
+	}
+
+	for _, polygon := range z.Polygons {
+		if polygon.contains(lat, lng) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Polygon) contains(lat, lng float64) bool {
+	if len(p) == 0 || !ringContains(p[0], lat, lng) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, lat, lng) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains casts a ray from the point towards increasing longitude and counts the edges it crosses
+func ringContains(ring []Position, lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		lngI, latI := ring[i][0], ring[i][1]
+		lngJ, latJ := ring[j][0], ring[j][1]
+		if (latI > lat) != (latJ > lat) && lng < (lngJ-lngI)*(lat-latI)/(latJ-latI)+lngI {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Set holds the zones in force. A nil or empty Set places no restrictions.
+type Set struct {
+	zones []*Zone
+}
+
+// NewSet validates zones and computes their bounds
+func NewSet(zones []*Zone) (*Set, error) {
+	ids := make(map[string]bool, len(zones))
+	for i, zone := range zones {
+		if err := zone.validate(); err != nil {
+			return nil, fmt.Errorf("zone %d: %w", i, err)
+		}
+		if ids[zone.ID] {
+			return nil, fmt.Errorf("zone %d: duplicate id %q", i, zone.ID)
+		}
+		ids[zone.ID] = true
+		zone.computeBounds()
+	}
+	return &Set{zones: zones}, nil
+}
+
+func (z *Zone) validate() error {
+	if z.ID == "" {
+		return errors.New("id is required")
+	}
+	if z.Name == "" {
+		return errors.New("name is required")
+	}
+	if !z.Type.IsValid() {
+		return fmt.Errorf("type must be %q, %q or %q", ZoneTypeServiceArea, ZoneTypeNoParking, ZoneTypeSlow)
+	}
+	if z.Type == ZoneTypeSlow && z.SpeedLimitKmh <= 0 {
+		return errors.New("slow zones need a positive speed_limit_kmh")
+	}
+	if len(z.Polygons) == 0 {
+		return errors.New("geometry has no polygons")
+	}
+
+	for _, polygon := range z.Polygons {
+		if len(polygon) == 0 {
+			return errors.New("polygon has no rings")
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return errors.New("rings need at least four positions")
+			}
+			if ring[0] != ring[len(ring)-1] {
+				return errors.New("rings must be closed")
+			}
+			for _, position := range ring {
+				if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+					return fmt.Errorf("position %v is out of range", position)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Bounds returns the zone's bounding box
+func (z *Zone) Bounds() (minLat, minLng, maxLat, maxLng float64) {
+	return z.minLat, z.minLng, z.maxLat, z.maxLng
+}
+
+func (z *Zone) computeBounds() {
+	z.minLat, z.maxLat, z.minLng, z.maxLng = 90, -90, 180, -180
+	for _, polygon := range z.Polygons {
+		// Holes lie inside the outer ring, so it alone bounds the polygon
+		for _, position := range polygon[0] {
+			z.minLng = min(z.minLng, position[0])
+			z.maxLng = max(z.maxLng, position[0])
+			z.minLat = min(z.minLat, position[1])
+			z.maxLat = max(z.maxLat, position[1])
+		}
+	}
+}
+
+// Zones returns every zone, or those of the given types
+func (s *Set) Zones(types ...ZoneType) []*Zone {
+	if s == nil {
+		return nil
+	}
+	if len(types) == 0 {
+		return s.zones
+	}
+
+	var zones []*Zone
+	for _, zone := range s.zones {
+		for _, t := range types {
+			if zone.Type == t {
+				zones = append(zones, zone)
+				break
+			}
+		}
+	}
+	return zones
+}
+
+// ZonesAt returns the zones containing the point
+func (s *Set) ZonesAt(lat, lng float64) []*Zone {
+	if s == nil {
+		return nil
+	}
+
+	var zones []*Zone
+	for _, zone := range s.zones {
+		if zone.Contains(lat, lng) {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// CheckParking returns ErrOutsideServiceArea or ErrNoParkingZone when a trip cannot end at the point.
+// Without any service areas configured, parking is allowed anywhere outside no-parking zones.
+func (s *Set) CheckParking(lat, lng float64) error {
+	if s == nil {
+		return nil
+	}
+
+	var hasServiceArea, inServiceArea bool
+	for _, zone := range s.zones {
+		switch zone.Type {
+		case ZoneTypeServiceArea:
+			hasServiceArea = true
+			if zone.Contains(lat, lng) {
+				inServiceArea = true
+			}
+		case ZoneTypeNoParking:
+			if zone.Contains(lat, lng) {
+				return fmt.Errorf("%w: %s", ErrNoParkingZone, zone.Name)
+			}
+		}
+	}
+
+	if hasServiceArea && !inServiceArea {
+		return ErrOutsideServiceArea
+	}
+	return nil
+}
+
+// SpeedLimitAt returns the lowest speed limit of the slow zones containing the point
+func (s *Set) SpeedLimitAt(lat, lng float64) (float64, bool) {
+	var limit float64
+	var found bool
+	for _, zone := range s.ZonesAt(lat, lng) {
+		if zone.Type == ZoneTypeSlow && (!found || zone.SpeedLimitKmh < limit) {
+			limit = zone.SpeedLimitKmh
+			found = true
+		}
+	}
+	return limit, found
+}
@@ -0,0 +1,152 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testZones = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "id": "city",
+      "properties": {"name": "City", "type": "service_area"},
+      "geometry": {
+        "type": "Polygon",
+        "coordinates": [
+          [[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]],
+          [[4, 4], [6, 4], [6, 6], [4, 6], [4, 4]]
+        ]
+      }
+    },
+    {
+      "type": "Feature",
+      "id": 7,
+      "properties": {"name": "Plaza", "type": "no_parking"},
+      "geometry": {
+        "type": "MultiPolygon",
+        "coordinates": [
+          [[[1, 1], [2, 1], [2, 2], [1, 2], [1, 1]]],
+          [[[8, 8], [9, 8], [9, 9], [8, 9], [8, 8]]]
+        ]
+      }
+    },
+    {
+      "type": "Feature",
+      "id": "old-town",
+      "properties": {"name": "Old Town", "type": "slow_zone", "speed_limit_kmh": 10},
+      "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [3, 0], [3, 3], [0, 3], [0, 0]]]}
+    },
+    {
+      "type": "Feature",
+      "id": "school",
+      "properties": {"name": "School", "type": "slow_zone", "speed_limit_kmh": 6},
+      "geometry": {"type": "Polygon", "coordinates": [[[2, 2], [3, 2], [3, 3], [2, 3], [2, 2]]]}
+    }
+  ]
+}`
+
+func TestSet_CheckParking(t *testing.T) {
+	zones, err := Parse([]byte(testZones))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		wantErr  error
+	}{
+		{name: "inside the service area", lat: 3, lng: 7},
+		{name: "outside the service area", lat: 11, lng: 5, wantErr: ErrOutsideServiceArea},
+		{name: "in a hole of the service area", lat: 5, lng: 5, wantErr: ErrOutsideServiceArea},
+		{name: "in a no-parking zone", lat: 1.5, lng: 1.5, wantErr: ErrNoParkingZone},
+		{name: "in the second polygon of a no-parking zone", lat: 8.5, lng: 8.5, wantErr: ErrNoParkingZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := zones.CheckParking(tt.lat, tt.lng)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSet_NoZones(t *testing.T) {
+	var zones *Set
+	assert.NoError(t, zones.CheckParking(45, -75))
+	assert.Empty(t, zones.Zones())
+
+	_, ok := zones.SpeedLimitAt(45, -75)
+	assert.False(t, ok)
+}
+
+func TestSet_SpeedLimitAt(t *testing.T) {
+	zones, err := Parse([]byte(testZones))
+	require.NoError(t, err)
+
+	limit, ok := zones.SpeedLimitAt(1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, limit)
+
+	limit, ok = zones.SpeedLimitAt(2.5, 2.5)
+	assert.True(t, ok)
+	assert.Equal(t, 6.0, limit, "the lowest limit applies where slow zones overlap")
+
+	_, ok = zones.SpeedLimitAt(7, 7)
+	assert.False(t, ok)
+}
+
+func TestSet_Zones(t *testing.T) {
+	zones, err := Parse([]byte(testZones))
+	require.NoError(t, err)
+
+	assert.Len(t, zones.Zones(), 4)
+
+	noParking := zones.Zones(ZoneTypeNoParking)
+	require.Len(t, noParking, 1)
+	assert.Equal(t, "7", noParking[0].ID)
+	assert.Len(t, noParking[0].Polygons, 2)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	square := `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1], [0, 0]]]}`
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not JSON", data: `zones`},
+		{name: "not a FeatureCollection", data: `{"type": "Feature"}`},
+		{name: "missing id", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {"name": "A", "type": "service_area"}, "geometry": ` + square + `}]}`},
+		{name: "unknown zone type", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "a", "properties": {"name": "A", "type": "park"}, "geometry": ` + square + `}]}`},
+		{name: "slow zone without a limit", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "a", "properties": {"name": "A", "type": "slow_zone"}, "geometry": ` + square + `}]}`},
+		{name: "point geometry", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "a", "properties": {"name": "A", "type": "service_area"}, "geometry": {"type": "Point", "coordinates": [0, 0]}}]}`},
+		{name: "open ring", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "a", "properties": {"name": "A", "type": "service_area"}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}}]}`},
+		{name: "duplicate id", data: `{"type": "FeatureCollection", "features": [` +
+			`{"type": "Feature", "id": "a", "properties": {"name": "A", "type": "service_area"}, "geometry": ` + square + `},` +
+			`{"type": "Feature", "id": "a", "properties": {"name": "B", "type": "no_parking"}, "geometry": ` + square + `}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFile_DefaultZones(t *testing.T) {
+	zones, err := LoadFile("../../geofences/zones.geojson")
+	require.NoError(t, err)
+
+	// City centres, where the seeded scooters are
+	assert.NoError(t, zones.CheckParking(45.4215, -75.6972))
+	assert.NoError(t, zones.CheckParking(45.5017, -73.5673))
+	assert.ErrorIs(t, zones.CheckParking(43.6532, -79.3832), ErrOutsideServiceArea)
+}
@@ -0,0 +1,100 @@
+package geofence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// featureCollection is the GeoJSON document zones are loaded from. Each feature carries its zone's id and
+// a Polygon or MultiPolygon geometry, with the zone's name, type and speed_limit_kmh in its properties.
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type     string `json:"type"`
+	ID       any    `json:"id"`
+	Geometry *struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		Name          string   `json:"name"`
+		Type          ZoneType `json:"type"`
+		SpeedLimitKmh float64  `json:"speed_limit_kmh"`
+	} `json:"properties"`
+}
+
+// Parse reads zones from a GeoJSON FeatureCollection
+func Parse(data []byte) (*Set, error) {
+	var collection featureCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("invalid GeoJSON: expected a FeatureCollection, got %q", collection.Type)
+	}
+
+	zones := make([]*Zone, 0, len(collection.Features))
+	for i, f := range collection.Features {
+		zone, err := f.zone()
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		zones = append(zones, zone)
+	}
+
+	return NewSet(zones)
+}
+
+// LoadFile reads zones from a GeoJSON file
+func LoadFile(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geofence file: %w", err)
+	}
+
+	zones, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load geofences from %s: %w", path, err)
+	}
+	return zones, nil
+}
+
+func (f feature) zone() (*Zone, error) {
+	if f.Type != "Feature" {
+		return nil, fmt.Errorf("expected a Feature, got %q", f.Type)
+	}
+	if f.Geometry == nil {
+		return nil, fmt.Errorf("geometry is required")
+	}
+
+	zone := &Zone{
+		Name:          f.Properties.Name,
+		Type:          f.Properties.Type,
+		SpeedLimitKmh: f.Properties.SpeedLimitKmh,
+	}
+	// GeoJSON allows numeric and string ids
+	if f.ID != nil {
+		zone.ID = fmt.Sprint(f.ID)
+	}
+
+	switch f.Geometry.Type {
+	case "Polygon":
+		var polygon Polygon
+		if err := json.Unmarshal(f.Geometry.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		zone.Polygons = []Polygon{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(f.Geometry.Coordinates, &zone.Polygons); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("geometry must be a Polygon or MultiPolygon, got %q", f.Geometry.Type)
+	}
+
+	return zone, nil
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+	"slices"
+)
+
+type contextKey struct{}
+
+// contextFields identify what a request or event is about; empty fields are left out of log entries
+type contextFields struct {
+	requestID string
+	principal string
+	scooterID string
+	tripID    string
+}
+
+func fieldsFrom(ctx context.Context) contextFields {
+	fields, _ := ctx.Value(contextKey{}).(contextFields)
+	return fields
+}
+
+func withFields(ctx context.Context, set func(*contextFields)) context.Context {
+	fields := fieldsFrom(ctx)
+	set(&fields)
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// WithRequestID attaches the ID correlating everything done for one request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return withFields(ctx, func(f *contextFields) { f.requestID = requestID })
+}
+
+// WithPrincipal attaches the API key name or rider the request is authenticated as
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return withFields(ctx, func(f *contextFields) { f.principal = principal })
+}
+
+// WithScooterID attaches the scooter a request or event acts on
+func WithScooterID(ctx context.Context, scooterID string) context.Context {
+	return withFields(ctx, func(f *contextFields) { f.scooterID = scooterID })
+}
+
+// WithTripID attaches the trip a request or event acts on
+func WithTripID(ctx context.Context, tripID string) context.Context {
+	return withFields(ctx, func(f *contextFields) { f.tripID = tripID })
+}
+
+// RequestID returns the request ID attached by WithRequestID, or "" if there is none
+func RequestID(ctx context.Context) string {
+	return fieldsFrom(ctx).requestID
+}
+
+// ContextLogger logs through the global logger, adding the request ID, principal, scooter ID and trip ID
+// carried by a context to every entry
+type ContextLogger struct {
+	fields []LogField
+}
+
+// FromContext returns a logger for the fields attached to ctx
+func FromContext(ctx context.Context) *ContextLogger {
+	f := fieldsFrom(ctx)
+
+	var fields []LogField
+	for _, field := range []LogField{
+		String("request_id", f.requestID),
+		String("principal", f.principal),
+		String("scooter_id", f.scooterID),
+		String("trip_id", f.tripID),
+	} {
+		if field.Value != "" {
+			fields = append(fields, field)
+		}
+	}
+	return &ContextLogger{fields: fields}
+}
+
+func (l *ContextLogger) Info(msg string, fields ...LogField) {
+	Info(msg, slices.Concat(l.fields, fields)...)
+}
+
+func (l *ContextLogger) Debug(msg string, fields ...LogField) {
+	Debug(msg, slices.Concat(l.fields, fields)...)
+}
+
+func (l *ContextLogger) Warn(msg string, fields ...LogField) {
+	Warn(msg, slices.Concat(l.fields, fields)...)
+}
+
+func (l *ContextLogger) Error(msg string, fields ...LogField) {
+	Error(msg, slices.Concat(l.fields, fields)...)
+}
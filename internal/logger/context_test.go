@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observe routes the global logger to an in-memory recorder until the test ends
+func observe(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	previous := Logger
+	Logger = zap.New(core)
+	t.Cleanup(func() { Logger = previous })
+	return logs
+}
+
+func TestFromContext(t *testing.T) {
+	logs := observe(t)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithPrincipal(ctx, "billing")
+	ctx = WithScooterID(ctx, "scooter-1")
+	ctx = WithTripID(ctx, "trip-1")
+	FromContext(ctx).Info("Trip ended", String("user_id", "user-1"))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "Trip ended", entries[0].Message)
+		assert.Equal(t, map[string]interface{}{
+			"request_id": "req-1",
+			"principal":  "billing",
+			"scooter_id": "scooter-1",
+			"trip_id":    "trip-1",
+			"user_id":    "user-1",
+		}, entries[0].ContextMap())
+	}
+	assert.Equal(t, "req-1", RequestID(ctx))
+}
+
+func TestFromContext_LeavesOutMissingFields(t *testing.T) {
+	logs := observe(t)
+
+	parent := WithRequestID(context.Background(), "req-1")
+	child := WithTripID(parent, "trip-1")
+	FromContext(parent).Warn("Parent")
+	FromContext(context.Background()).Error("Bare")
+
+	entries := logs.All()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, map[string]interface{}{"request_id": "req-1"}, entries[0].ContextMap(), "a derived context does not change its parent")
+		assert.Empty(t, entries[1].ContextMap())
+	}
+	assert.Equal(t, "req-1", RequestID(child))
+	assert.Empty(t, RequestID(context.Background()))
+}
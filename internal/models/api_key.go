@@ -0,0 +1,67 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes granted to integrations
+const (
+	ScopeScootersRead = "scooters:read"
+	ScopeTripsRead    = "trips:read"
+	ScopeTripsWrite   = "trips:write"
+	// ScopeAdmin grants every other scope and access to key management
+	ScopeAdmin = "admin"
+)
+
+// APIKeyScopes lists every scope an API key can be granted
+var APIKeyScopes = []string{ScopeScootersRead, ScopeTripsRead, ScopeTripsWrite, ScopeAdmin}
+
+// UserScopes are granted to riders authenticated with a JWT, who may only act on their own trips
+var UserScopes = []string{ScopeScootersRead, ScopeTripsRead, ScopeTripsWrite}
+
+// APIKey is a credential issued to one integration. Only the SHA-256 hash of the secret is stored.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// SetID sets the ID if not already set
+func (k *APIKey) SetID() {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+}
+
+// SetTimestamps sets the created_at timestamp if not already set
+func (k *APIKey) SetTimestamps() {
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now()
+	}
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given time
+func (k *APIKey) IsActive(at time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
+
+// IsValidScope reports whether scope is one of APIKeyScopes
+func IsValidScope(scope string) bool {
+	return slices.Contains(APIKeyScopes, scope)
+}
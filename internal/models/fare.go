@@ -0,0 +1,16 @@
+package models
+
+// Fare is what a completed trip cost. Amounts are in the currency's minor units, e.g. cents.
+type Fare struct {
+	Currency string `json:"currency"`
+	// Tariff names the tariff applied: the zone ID it is configured for, or "default"
+	Tariff         string  `json:"tariff"`
+	Minutes        int64   `json:"minutes"`
+	DistanceMeters float64 `json:"distance_meters"`
+	UnlockFee      int64   `json:"unlock_fee"`
+	TimeCharge     int64   `json:"time_charge"`
+	DistanceCharge int64   `json:"distance_charge"`
+	// Adjustment raises the fare to the tariff's minimum or, when negative, lowers it to its cap
+	Adjustment int64 `json:"adjustment"`
+	Total      int64 `json:"total"`
+}
@@ -9,13 +9,15 @@ import (
 )
 
 type LocationUpdate struct {
-	ID        uuid.UUID  `json:"id" db:"id"`
-	ScooterID uuid.UUID  `json:"scooter_id" db:"scooter_id"`
-	Latitude  float64    `json:"latitude" db:"latitude"`
-	Longitude float64    `json:"longitude" db:"longitude"`
-	Timestamp time.Time  `json:"timestamp" db:"timestamp"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	ScooterID uuid.UUID `json:"scooter_id" db:"scooter_id"`
+	Latitude  float64   `json:"latitude" db:"latitude"`
+	Longitude float64   `json:"longitude" db:"longitude"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	// BatteryLevel is the charge reported with the update as a percentage, if any
+	BatteryLevel *int       `json:"battery_level,omitempty" db:"battery_level"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 
 	Scooter Scooter `json:"scooter,omitempty"`
 }
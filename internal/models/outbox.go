@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Server-side event types written to the outbox
+const (
+	OutboxEventTripStarted          = "trip.started"
+	OutboxEventTripEnded            = "trip.ended"
+	OutboxEventTripCancelled        = "trip.cancelled"
+	OutboxEventScooterStatusChanged = "scooter.status_changed"
+)
+
+// OutboxEvent is a state change recorded in the same transaction that made it, waiting to be published
+type OutboxEvent struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	AggregateID uuid.UUID       `json:"aggregate_id" db:"aggregate_id"`
+	EventType   string          `json:"event_type" db:"event_type"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	PublishedAt *time.Time      `json:"published_at,omitempty" db:"published_at"`
+	// FailedAt is when the relay gave up publishing the event; it is then skipped until an operator clears it
+	FailedAt *time.Time `json:"failed_at,omitempty" db:"failed_at"`
+	// RequestID is the ID of the API request that made the change; nil for changes made by events or workers
+	RequestID *string `json:"request_id,omitempty" db:"request_id"`
+}
+
+// TableName returns the table name for the OutboxEvent model
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// SetID sets the ID if not already set
+func (e *OutboxEvent) SetID() {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+}
+
+// SetTimestamps sets the created_at timestamp if not already set
+func (e *OutboxEvent) SetTimestamps() {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+}
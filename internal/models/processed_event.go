@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcessedEvent records an event that has already been applied, keyed by its event ID
+type ProcessedEvent struct {
+	EventID     string    `json:"event_id" db:"event_id"`
+	EventType   string    `json:"event_type" db:"event_type"`
+	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
+}
+
+// TableName returns the table name for the ProcessedEvent model
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
+
+// SetTimestamps sets the processed_at timestamp if not already set
+func (e *ProcessedEvent) SetTimestamps() {
+	if e.ProcessedAt.IsZero() {
+		e.ProcessedAt = time.Now()
+	}
+}
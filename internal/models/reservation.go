@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive ReservationStatus = "active"
+	// ReservationStatusFulfilled marks a reservation whose rider started a trip on the scooter
+	ReservationStatusFulfilled ReservationStatus = "fulfilled"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+	ReservationStatusExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds a scooter for one rider until ExpiresAt
+type Reservation struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	ScooterID uuid.UUID         `json:"scooter_id" db:"scooter_id"`
+	UserID    uuid.UUID         `json:"user_id" db:"user_id"`
+	Status    ReservationStatus `json:"status" db:"status"`
+	ExpiresAt time.Time         `json:"expires_at" db:"expires_at"`
+	EndedAt   *time.Time        `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
+
+func (Reservation) TableName() string {
+	return "reservations"
+}
+
+// SetID sets the ID if not already set
+func (r *Reservation) SetID() {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+}
+
+// SetTimestamps sets the created_at timestamp if not already set
+func (r *Reservation) SetTimestamps() {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+}
+
+func (r *Reservation) IsActive() bool {
+	return r.Status == ReservationStatusActive
+}
+
+// IsExpired reports whether an active reservation has run out at the given time
+func (r *Reservation) IsExpired(at time.Time) bool {
+	return r.IsActive() && !at.Before(r.ExpiresAt)
+}
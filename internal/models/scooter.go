@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"scootin-aboot/internal/validation"
@@ -14,8 +16,44 @@ type ScooterStatus string
 const (
 	ScooterStatusAvailable ScooterStatus = "available"
 	ScooterStatusOccupied  ScooterStatus = "occupied"
+	// ScooterStatusReserved holds the scooter for the rider with an active reservation on it
+	ScooterStatusReserved ScooterStatus = "reserved"
+	// ScooterStatusMaintenance takes a broken scooter out of service until it is repaired
+	ScooterStatusMaintenance ScooterStatus = "maintenance"
+	// ScooterStatusCharging takes a scooter out of service while its battery is charged or swapped
+	ScooterStatusCharging ScooterStatus = "charging"
+	// ScooterStatusRetired removes a scooter from service for good
+	ScooterStatusRetired ScooterStatus = "retired"
 )
 
+var (
+	ErrInvalidScooterStatus    = errors.New("invalid scooter status")
+	ErrInvalidStatusTransition = errors.New("invalid scooter status transition")
+)
+
+// scooterTransitions lists the statuses a scooter can move to from each status. Trips and reservations
+// move scooters between available, occupied and reserved; only an available scooter can be taken out
+// of service, and a retired scooter never returns.
+var scooterTransitions = map[ScooterStatus][]ScooterStatus{
+	ScooterStatusAvailable:   {ScooterStatusOccupied, ScooterStatusReserved, ScooterStatusMaintenance, ScooterStatusCharging, ScooterStatusRetired},
+	ScooterStatusOccupied:    {ScooterStatusAvailable},
+	ScooterStatusReserved:    {ScooterStatusAvailable, ScooterStatusOccupied},
+	ScooterStatusMaintenance: {ScooterStatusAvailable, ScooterStatusCharging, ScooterStatusRetired},
+	ScooterStatusCharging:    {ScooterStatusAvailable, ScooterStatusMaintenance, ScooterStatusRetired},
+	ScooterStatusRetired:     {},
+}
+
+// IsValid reports whether status is a known scooter status
+func (status ScooterStatus) IsValid() bool {
+	_, ok := scooterTransitions[status]
+	return ok
+}
+
+// InService reports whether a scooter in status can be ridden or is being ridden
+func (status ScooterStatus) InService() bool {
+	return status == ScooterStatusAvailable || status == ScooterStatusOccupied || status == ScooterStatusReserved
+}
+
 type Scooter struct {
 	ID               uuid.UUID     `json:"id" db:"id"`
 	Status           ScooterStatus `json:"status" db:"status"`
@@ -24,7 +62,9 @@ type Scooter struct {
 	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time     `json:"updated_at" db:"updated_at"`
 	LastSeen         time.Time     `json:"last_seen" db:"last_seen"`
-	DeletedAt        *time.Time    `json:"deleted_at,omitempty" db:"deleted_at"`
+	// BatteryLevel is the charge last reported by the scooter as a percentage; nil until it reports one
+	BatteryLevel *int       `json:"battery_level,omitempty" db:"battery_level"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 
 	// Relationships
 	Trips []Trip `json:"trips,omitempty"`
@@ -70,6 +110,16 @@ func (s *Scooter) IsOccupied() bool {
 	return s.Status == ScooterStatusOccupied
 }
 
+func (s *Scooter) IsReserved() bool {
+	return s.Status == ScooterStatusReserved
+}
+
+// HasBatteryBelow reports whether the scooter has reported a charge below level; a scooter that has
+// never reported one is not considered low
+func (s *Scooter) HasBatteryBelow(level int) bool {
+	return s.BatteryLevel != nil && *s.BatteryLevel < level
+}
+
 func (s *Scooter) ValidateCoordinates() error {
 	return validation.ValidateCoordinates(s.CurrentLatitude, s.CurrentLongitude)
 }
@@ -82,15 +132,38 @@ func (s *Scooter) UpdateLocation(latitude, longitude float64) error {
 	return validation.ValidateCoordinates(latitude, longitude)
 }
 
+// ValidateStatusTransition returns ErrInvalidStatusTransition unless a scooter can move from status from to
+// status to. Every status change goes through it, whether made by an admin, a trip or a reservation.
+func ValidateStatusTransition(from, to ScooterStatus) error {
+	if !to.IsValid() {
+		return ErrInvalidScooterStatus
+	}
+	if !slices.Contains(scooterTransitions[from], to) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, from, to)
+	}
+	return nil
+}
+
+// CanTransitionTo reports whether the scooter can move from its current status to status
+func (s *Scooter) CanTransitionTo(status ScooterStatus) bool {
+	return ValidateStatusTransition(s.Status, status) == nil
+}
+
+// SetStatus moves the scooter to status, allowing only the transitions in scooterTransitions.
+// A scooter without a status yet can start in any status.
 func (s *Scooter) SetStatus(status ScooterStatus) error {
-	switch status {
-	case ScooterStatusAvailable, ScooterStatusOccupied:
-		s.Status = status
-		s.UpdatedAt = time.Now()
-		return nil
-	default:
-		return errors.New("invalid scooter status")
+	if !status.IsValid() {
+		return ErrInvalidScooterStatus
+	}
+	if s.Status != "" {
+		if err := ValidateStatusTransition(s.Status, status); err != nil {
+			return err
+		}
 	}
+
+	s.Status = status
+	s.UpdatedAt = time.Now()
+	return nil
 }
 
 func (s *Scooter) GetLatitude() float64 {
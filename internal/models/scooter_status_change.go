@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScooterStatusChange records a manual change of a scooter's status and why it was made
+type ScooterStatusChange struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	ScooterID  uuid.UUID     `json:"scooter_id" db:"scooter_id"`
+	FromStatus ScooterStatus `json:"from_status" db:"from_status"`
+	ToStatus   ScooterStatus `json:"to_status" db:"to_status"`
+	Reason     string        `json:"reason" db:"reason"`
+	// ChangedBy names the API key that made the change
+	ChangedBy string    `json:"changed_by" db:"changed_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (ScooterStatusChange) TableName() string {
+	return "scooter_status_history"
+}
+
+// SetID sets the ID if not already set
+func (c *ScooterStatusChange) SetID() {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+}
+
+// SetTimestamps sets the created_at timestamp
+func (c *ScooterStatusChange) SetTimestamps() {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+}
@@ -65,12 +65,66 @@ func TestScooterModel(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, ScooterStatusOccupied, scooter.Status)
 
+		err = scooter.SetStatus(ScooterStatusAvailable)
+		assert.NoError(t, err)
+
+		err = scooter.SetStatus(ScooterStatusReserved)
+		assert.NoError(t, err)
+		assert.True(t, scooter.IsReserved())
+		assert.False(t, scooter.IsAvailable())
+
 		// Test invalid status
 		err = scooter.SetStatus("invalid_status")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid scooter status")
 	})
 
+	t.Run("SetStatusTransitions", func(t *testing.T) {
+		tests := []struct {
+			from  ScooterStatus
+			to    ScooterStatus
+			legal bool
+		}{
+			{ScooterStatusAvailable, ScooterStatusMaintenance, true},
+			{ScooterStatusAvailable, ScooterStatusCharging, true},
+			{ScooterStatusAvailable, ScooterStatusRetired, true},
+			{ScooterStatusMaintenance, ScooterStatusAvailable, true},
+			{ScooterStatusMaintenance, ScooterStatusCharging, true},
+			{ScooterStatusCharging, ScooterStatusAvailable, true},
+			{ScooterStatusReserved, ScooterStatusOccupied, true},
+			{ScooterStatusOccupied, ScooterStatusMaintenance, false},
+			{ScooterStatusOccupied, ScooterStatusReserved, false},
+			{ScooterStatusReserved, ScooterStatusMaintenance, false},
+			{ScooterStatusMaintenance, ScooterStatusOccupied, false},
+			{ScooterStatusCharging, ScooterStatusReserved, false},
+			{ScooterStatusRetired, ScooterStatusAvailable, false},
+			{ScooterStatusAvailable, ScooterStatusAvailable, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+				scooter := &Scooter{Status: tt.from}
+
+				err := scooter.SetStatus(tt.to)
+				if tt.legal {
+					assert.NoError(t, err)
+					assert.Equal(t, tt.to, scooter.Status)
+				} else {
+					assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+					assert.Equal(t, tt.from, scooter.Status)
+				}
+			})
+		}
+	})
+
+	t.Run("InService", func(t *testing.T) {
+		assert.True(t, ScooterStatusAvailable.InService())
+		assert.True(t, ScooterStatusReserved.InService())
+		assert.False(t, ScooterStatusMaintenance.InService())
+		assert.False(t, ScooterStatusCharging.InService())
+		assert.False(t, ScooterStatusRetired.InService())
+	})
+
 	t.Run("UpdateLocation", func(t *testing.T) {
 		scooter := &Scooter{
 			CurrentLatitude:  45.4215,
@@ -89,4 +143,16 @@ func TestScooterModel(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid latitude")
 	})
+
+	t.Run("HasBatteryBelow", func(t *testing.T) {
+		scooter := &Scooter{Status: ScooterStatusAvailable}
+
+		// A scooter that has never reported its charge is not considered low
+		assert.False(t, scooter.HasBatteryBelow(20))
+
+		level := 19
+		scooter.BatteryLevel = &level
+		assert.True(t, scooter.HasBatteryBelow(20))
+		assert.False(t, scooter.HasBatteryBelow(19))
+	})
 }
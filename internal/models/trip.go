@@ -31,6 +31,8 @@ type Trip struct {
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Fare is priced when the trip ends, if pricing is configured
+	Fare *Fare `json:"fare,omitempty" db:"fare_breakdown"`
 
 	Scooter Scooter `json:"scooter,omitempty"`
 	User    User    `json:"user,omitempty"`
@@ -0,0 +1,150 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"scootin-aboot/internal/geofence"
+	"scootin-aboot/internal/models"
+)
+
+// DefaultTariff names the tariff used where no zone has its own
+const DefaultTariff = "default"
+
+// Tariff sets what trips cost, in the currency's minor units
+type Tariff struct {
+	UnlockFee int64 `json:"unlock_fee"`
+	// PerMinute is charged for every started minute
+	PerMinute   int64 `json:"per_minute"`
+	PerKm       int64 `json:"per_km"`
+	MinimumFare int64 `json:"minimum_fare"`
+	// MaximumFare caps the fare; zero means no cap
+	MaximumFare int64 `json:"maximum_fare"`
+}
+
+func (t Tariff) validate() error {
+	if t.UnlockFee < 0 || t.PerMinute < 0 || t.PerKm < 0 || t.MinimumFare < 0 || t.MaximumFare < 0 {
+		return errors.New("amounts cannot be negative")
+	}
+	if t.MaximumFare > 0 && t.MaximumFare < t.MinimumFare {
+		return errors.New("maximum_fare cannot be below minimum_fare")
+	}
+	return nil
+}
+
+// Config is the tariff file. Zone tariffs are keyed by the ID of a zone in the geofence file.
+type Config struct {
+	Currency string            `json:"currency"`
+	Default  Tariff            `json:"default"`
+	Zones    map[string]Tariff `json:"zones"`
+}
+
+// Calculator prices trips by the tariff in force where they started
+type Calculator struct {
+	currency string
+	tariffs  map[string]Tariff
+	zones    *geofence.Set
+}
+
+// NewCalculator validates the tariffs. Zone tariffs must name a zone in zones; without zones only the
+// default tariff applies.
+func NewCalculator(config Config, zones *geofence.Set) (*Calculator, error) {
+	if len(config.Currency) != 3 {
+		return nil, fmt.Errorf("currency must be a three-letter ISO 4217 code, got %q", config.Currency)
+	}
+	if err := config.Default.validate(); err != nil {
+		return nil, fmt.Errorf("default tariff: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, zone := range zones.Zones() {
+		known[zone.ID] = true
+	}
+
+	tariffs := map[string]Tariff{DefaultTariff: config.Default}
+	for id, tariff := range config.Zones {
+		if zones != nil && !known[id] {
+			return nil, fmt.Errorf("tariff for unknown zone %q", id)
+		}
+		if err := tariff.validate(); err != nil {
+			return nil, fmt.Errorf("tariff for zone %q: %w", id, err)
+		}
+		tariffs[id] = tariff
+	}
+
+	return &Calculator{
+		currency: config.Currency,
+		tariffs:  tariffs,
+		zones:    zones,
+	}, nil
+}
+
+// LoadFile reads tariffs from a JSON file
+func LoadFile(path string, zones *geofence.Set) (*Calculator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing file: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to load tariffs from %s: %w", path, err)
+	}
+
+	calculator, err := NewCalculator(config, zones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tariffs from %s: %w", path, err)
+	}
+	return calculator, nil
+}
+
+// TariffAt returns the name and tariff in force at the point. A tariff for a no-parking or slow zone
+// takes precedence over that of the service area around it.
+func (c *Calculator) TariffAt(lat, lng float64) (string, Tariff) {
+	name := DefaultTariff
+	for _, zone := range c.zones.ZonesAt(lat, lng) {
+		if _, ok := c.tariffs[zone.ID]; !ok {
+			continue
+		}
+		name = zone.ID
+		if zone.Type != geofence.ZoneTypeServiceArea {
+			break
+		}
+	}
+	return name, c.tariffs[name]
+}
+
+// Fare prices a trip that started at the point and covered distanceMeters in duration. A nil Calculator
+// prices nothing and returns nil.
+func (c *Calculator) Fare(startLat, startLng float64, duration time.Duration, distanceMeters float64) *models.Fare {
+	if c == nil {
+		return nil
+	}
+
+	name, tariff := c.TariffAt(startLat, startLng)
+	minutes := int64(math.Ceil(max(duration, 0).Minutes()))
+
+	fare := &models.Fare{
+		Currency:       c.currency,
+		Tariff:         name,
+		Minutes:        minutes,
+		DistanceMeters: math.Round(distanceMeters),
+		UnlockFee:      tariff.UnlockFee,
+		TimeCharge:     minutes * tariff.PerMinute,
+		DistanceCharge: int64(math.Round(float64(tariff.PerKm) * distanceMeters / 1000)),
+	}
+
+	subtotal := fare.UnlockFee + fare.TimeCharge + fare.DistanceCharge
+	total := max(subtotal, tariff.MinimumFare)
+	if tariff.MaximumFare > 0 {
+		total = min(total, tariff.MaximumFare)
+	}
+	fare.Adjustment = total - subtotal
+	fare.Total = total
+
+	return fare
+}
@@ -0,0 +1,162 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/geofence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testZones(t *testing.T) *geofence.Set {
+	city := []geofence.Polygon{{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}}
+	centre := []geofence.Polygon{{{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}}}
+	zones, err := geofence.NewSet([]*geofence.Zone{
+		{ID: "city", Name: "City", Type: geofence.ZoneTypeServiceArea, Polygons: city},
+		{ID: "centre", Name: "Centre", Type: geofence.ZoneTypeSlow, SpeedLimitKmh: 10, Polygons: centre},
+	})
+	require.NoError(t, err)
+	return zones
+}
+
+func testConfig() Config {
+	return Config{
+		Currency: "CAD",
+		Default:  Tariff{UnlockFee: 100, PerMinute: 35, MinimumFare: 150, MaximumFare: 3000},
+		Zones: map[string]Tariff{
+			"city":   {UnlockFee: 100, PerMinute: 30, PerKm: 10},
+			"centre": {UnlockFee: 200, PerMinute: 50},
+		},
+	}
+}
+
+func TestCalculator_Fare(t *testing.T) {
+	calculator, err := NewCalculator(testConfig(), testZones(t))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		lat, lng       float64
+		duration       time.Duration
+		distanceMeters float64
+		wantTariff     string
+		wantTime       int64
+		wantDistance   int64
+		wantAdjustment int64
+		wantTotal      int64
+	}{
+		{
+			name:       "started outside every zone",
+			lat:        20,
+			lng:        20,
+			duration:   10 * time.Minute,
+			wantTariff: DefaultTariff,
+			wantTime:   350,
+			wantTotal:  450,
+		},
+		{
+			name:           "started in the service area, partial minutes round up",
+			lat:            2,
+			lng:            2,
+			duration:       4*time.Minute + time.Second,
+			distanceMeters: 1550,
+			wantTariff:     "city",
+			wantTime:       150,
+			wantDistance:   16,
+			wantTotal:      266,
+		},
+		{
+			name:       "a zone inside the service area takes precedence",
+			lat:        5,
+			lng:        5,
+			duration:   time.Minute,
+			wantTariff: "centre",
+			wantTime:   50,
+			wantTotal:  250,
+		},
+		{
+			name:           "raised to the minimum fare",
+			lat:            20,
+			lng:            20,
+			duration:       30 * time.Second,
+			wantTariff:     DefaultTariff,
+			wantTime:       35,
+			wantAdjustment: 15,
+			wantTotal:      150,
+		},
+		{
+			name:           "capped at the maximum fare",
+			lat:            20,
+			lng:            20,
+			duration:       2 * time.Hour,
+			wantTariff:     DefaultTariff,
+			wantTime:       4200,
+			wantAdjustment: -1300,
+			wantTotal:      3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fare := calculator.Fare(tt.lat, tt.lng, tt.duration, tt.distanceMeters)
+			require.NotNil(t, fare)
+
+			assert.Equal(t, "CAD", fare.Currency)
+			assert.Equal(t, tt.wantTariff, fare.Tariff)
+			assert.Equal(t, tt.wantTime, fare.TimeCharge)
+			assert.Equal(t, tt.wantDistance, fare.DistanceCharge)
+			assert.Equal(t, tt.wantAdjustment, fare.Adjustment)
+			assert.Equal(t, tt.wantTotal, fare.Total)
+			assert.Equal(t, fare.Total, fare.UnlockFee+fare.TimeCharge+fare.DistanceCharge+fare.Adjustment)
+		})
+	}
+}
+
+func TestCalculator_NilPricesNothing(t *testing.T) {
+	var calculator *Calculator
+	assert.Nil(t, calculator.Fare(45, -75, time.Minute, 100))
+}
+
+func TestCalculator_WithoutZonesUsesDefault(t *testing.T) {
+	calculator, err := NewCalculator(testConfig(), nil)
+	require.NoError(t, err)
+
+	fare := calculator.Fare(5, 5, time.Minute, 0)
+	assert.Equal(t, DefaultTariff, fare.Tariff)
+}
+
+func TestNewCalculator_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(config *Config)
+	}{
+		{name: "missing currency", modify: func(config *Config) { config.Currency = "" }},
+		{name: "negative rate", modify: func(config *Config) { config.Default.PerMinute = -1 }},
+		{name: "cap below minimum", modify: func(config *Config) { config.Default.MaximumFare = 100 }},
+		{name: "unknown zone", modify: func(config *Config) { config.Zones["elsewhere"] = Tariff{} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			tt.modify(&config)
+			_, err := NewCalculator(config, testZones(t))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFile_DefaultTariffs(t *testing.T) {
+	zones, err := geofence.LoadFile("../../geofences/zones.geojson")
+	require.NoError(t, err)
+
+	calculator, err := LoadFile("../../pricing/tariffs.json", zones)
+	require.NoError(t, err)
+
+	name, _ := calculator.TariffAt(45.5017, -73.5673)
+	assert.Equal(t, "montreal", name)
+	name, _ = calculator.TariffAt(45.4215, -75.6972)
+	assert.Equal(t, "ottawa", name)
+}
@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"scootin-aboot/internal/config"
+)
+
+// Policies select the quotas a request is charged against
+type Policies struct {
+	// Default is the quota of each client across all routes
+	Default Policy
+	// Keys replaces Default for the API keys they name, by key name or ID
+	Keys map[string]Policy
+	// Routes add a separate quota per client for single routes, keyed by "<METHOD> <path>" with the
+	// path as registered with the router
+	Routes map[string]Policy
+	// AuthFailures is the quota of failed authentications per client IP, which throttles credential guessing
+	AuthFailures Policy
+}
+
+// Limiter charges requests to per-client token buckets held in a Store
+type Limiter struct {
+	store    Store
+	policies Policies
+}
+
+func NewLimiter(store Store, policies Policies) *Limiter {
+	return &Limiter{
+		store:    store,
+		policies: policies,
+	}
+}
+
+// NewLimiterFromConfig builds an in-memory limiter from cfg; it returns nil when rate limiting is disabled
+func NewLimiterFromConfig(cfg *config.RateLimitConfig) (*Limiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	policies := Policies{}
+
+	var err error
+	if policies.Default, err = ParsePolicy(cfg.Default); err != nil {
+		return nil, err
+	}
+	if policies.Keys, err = parsePolicyList(cfg.Keys); err != nil {
+		return nil, fmt.Errorf("invalid key rate limits: %w", err)
+	}
+	if policies.Routes, err = parsePolicyList(cfg.Routes); err != nil {
+		return nil, fmt.Errorf("invalid route rate limits: %w", err)
+	}
+	if cfg.AuthFailures != "" {
+		if policies.AuthFailures, err = ParsePolicy(cfg.AuthFailures); err != nil {
+			return nil, fmt.Errorf("invalid authentication failure rate limit: %w", err)
+		}
+	}
+
+	return NewLimiter(NewMemoryStore(), policies), nil
+}
+
+// parsePolicyList parses comma-separated "<name>=<policy>" entries
+func parsePolicyList(value string) (map[string]Policy, error) {
+	policies := make(map[string]Policy)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid entry %q: expected <name>=<requests>/<period>", entry)
+		}
+
+		policy, err := ParsePolicy(spec)
+		if err != nil {
+			return nil, err
+		}
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
+// Client identifies who a request is charged to
+type Client struct {
+	// ID is unique per client, such as "key:<id>" or "ip:<address>"
+	ID string
+	// Names are matched against the per-key policies, most specific first
+	Names []string
+}
+
+// Take charges a request by client to route. When route has a policy the request is charged to the
+// client's bucket for that route first, then to the client's overall bucket; a request refused by the
+// route bucket is not charged to the overall one. The result of the more restrictive bucket is returned.
+// ok is false when no policy limits the request.
+func (l *Limiter) Take(ctx context.Context, client Client, route string) (result Result, ok bool, err error) {
+	buckets := make([]bucketRef, 0, 2)
+	if policy, exists := l.policies.Routes[route]; exists && !policy.Unlimited() {
+		buckets = append(buckets, bucketRef{key: client.ID + "|" + route, policy: policy})
+	}
+	if policy := l.policyFor(client); !policy.Unlimited() {
+		buckets = append(buckets, bucketRef{key: client.ID, policy: policy})
+	}
+
+	for _, bucket := range buckets {
+		taken, err := l.store.Take(ctx, bucket.key, bucket.policy)
+		if err != nil {
+			return Result{}, false, err
+		}
+		if !ok || moreRestrictive(taken, result) {
+			result = taken
+		}
+		ok = true
+
+		if !taken.Allowed {
+			break
+		}
+	}
+
+	return result, ok, nil
+}
+
+// CheckAuthFailures reports whether client, identified by its IP, has failed authentication too often to
+// try again, without charging it. ok is false when failures are not limited.
+func (l *Limiter) CheckAuthFailures(ctx context.Context, client Client) (result Result, ok bool, err error) {
+	if l.policies.AuthFailures.Unlimited() {
+		return Result{}, false, nil
+	}
+	result, err = l.store.Peek(ctx, authFailureKey(client), l.policies.AuthFailures)
+	return result, err == nil, err
+}
+
+// ChargeAuthFailure charges a failed authentication to client
+func (l *Limiter) ChargeAuthFailure(ctx context.Context, client Client) error {
+	if l.policies.AuthFailures.Unlimited() {
+		return nil
+	}
+	_, err := l.store.Take(ctx, authFailureKey(client), l.policies.AuthFailures)
+	return err
+}
+
+// authFailureKey keeps the failure bucket apart from the client's request quota
+func authFailureKey(client Client) string {
+	return "auth-failures|" + client.ID
+}
+
+type bucketRef struct {
+	key    string
+	policy Policy
+}
+
+func (l *Limiter) policyFor(client Client) Policy {
+	for _, name := range client.Names {
+		if policy, exists := l.policies.Keys[name]; exists {
+			return policy
+		}
+	}
+	return l.policies.Default
+}
+
+// moreRestrictive orders results by whether they were refused, then by how many tokens remain
+func moreRestrictive(a, b Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	return a.Remaining < b.Remaining
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often buckets that have refilled completely are dropped
+const memorySweepInterval = time.Minute
+
+type memoryBucket struct {
+	state  bucketState
+	policy Policy
+}
+
+// MemoryStore keeps token buckets in process memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	return s.bucket(now, key, policy).take(now, policy), nil
+}
+
+func (s *MemoryStore) Peek(ctx context.Context, key string, policy Policy) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	return s.bucket(now, key, policy).peek(now, policy), nil
+}
+
+// bucket returns the bucket for key, creating it full when it does not exist. s.mu must be held.
+func (s *MemoryStore) bucket(now time.Time, key string, policy Policy) *bucketState {
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok || bucket.policy != policy {
+		// A changed policy starts a fresh bucket rather than carrying tokens over at the old rate
+		bucket = &memoryBucket{
+			state:  bucketState{tokens: float64(policy.Limit), updated: now},
+			policy: policy,
+		}
+		s.buckets[key] = bucket
+	}
+	return &bucket.state
+}
+
+// sweep drops full buckets so idle clients do not hold memory; a new bucket starts full anyway
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if bucket.state.full(now, bucket.policy) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy allows Limit requests per Period. Tokens are refilled continuously, so up to Limit requests
+// can be made in a burst after a quiet period. A zero Limit means unlimited.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParsePolicy parses a policy written "<requests>/<period>", for example "600/1m"
+func ParsePolicy(value string) (Policy, error) {
+	limit, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("invalid rate limit policy %q: expected <requests>/<period>", value)
+	}
+
+	requests, err := strconv.Atoi(limit)
+	if err != nil || requests < 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit policy %q: requests must be a non-negative integer", value)
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit policy %q: period must be a positive duration", value)
+	}
+
+	return Policy{Limit: requests, Period: duration}, nil
+}
+
+func (p Policy) String() string {
+	return fmt.Sprintf("%d/%s", p.Limit, p.Period)
+}
+
+// Unlimited reports whether the policy lets every request through
+func (p Policy) Unlimited() bool {
+	return p.Limit <= 0
+}
+
+// interval is how long the policy takes to refill one token
+func (p Policy) interval() time.Duration {
+	return p.Period / time.Duration(p.Limit)
+}
+
+// Result describes the state of a bucket after a request was charged to it
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token is available; zero when the request was allowed
+	RetryAfter time.Duration
+}
+
+// Store holds token buckets. The in-memory store limits each server instance separately; a store shared
+// between instances enforces one quota across all of them.
+type Store interface {
+	// Take removes a token from the bucket identified by key, creating the bucket full if it does not exist
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+	// Peek reports whether the bucket identified by key has a token left, without removing it
+	Peek(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// bucketState is a token bucket as of a point in time; tokens are fractional so refills are exact
+type bucketState struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket up to now and removes one token if there is one
+func (b *bucketState) take(now time.Time, policy Policy) Result {
+	b.refill(now, policy)
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return b.result(policy, allowed)
+}
+
+// peek refills the bucket up to now and reports whether it has a token, leaving it in place
+func (b *bucketState) peek(now time.Time, policy Policy) Result {
+	b.refill(now, policy)
+	return b.result(policy, b.tokens >= 1)
+}
+
+func (b *bucketState) refill(now time.Time, policy Policy) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(policy.Limit), b.tokens+float64(elapsed)/float64(policy.interval()))
+	}
+	b.updated = now
+}
+
+func (b *bucketState) result(policy Policy, allowed bool) Result {
+	interval := policy.interval()
+	result := Result{Allowed: allowed, Limit: policy.Limit}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(interval))
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((float64(policy.Limit) - b.tokens) * float64(interval))
+	return result
+}
+
+// full reports whether the bucket will have refilled completely by now, so it can be forgotten
+func (b *bucketState) full(now time.Time, policy Policy) bool {
+	return b.tokens+float64(now.Sub(b.updated))/float64(policy.interval()) >= float64(policy.Limit)
+}
@@ -0,0 +1,238 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(" 600/1m ")
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Limit: 600, Period: time.Minute}, policy)
+	assert.Equal(t, "600/1m0s", policy.String())
+
+	unlimited, err := ParsePolicy("0/1s")
+	require.NoError(t, err)
+	assert.True(t, unlimited.Unlimited())
+
+	for _, value := range []string{"", "600", "x/1m", "-1/1m", "10/soon", "10/0s"} {
+		_, err := ParsePolicy(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	store := newTestStore(&now)
+	policy := Policy{Limit: 3, Period: 3 * time.Second}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(ctx, "client", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// Other clients have their own bucket
+	other, err := store.Take(ctx, "other", policy)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed)
+
+	// Tokens refill continuously at Limit per Period
+	now = now.Add(1500 * time.Millisecond)
+	result, err = store.Take(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// The bucket never holds more than Limit tokens
+	now = now.Add(time.Hour)
+	result, err = store.Take(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	store := newTestStore(&now)
+	policy := Policy{Limit: 10, Period: time.Second}
+
+	_, err := store.Take(ctx, "idle", policy)
+	require.NoError(t, err)
+	require.Len(t, store.buckets, 1)
+
+	now = now.Add(2 * memorySweepInterval)
+	_, err = store.Take(ctx, "active", policy)
+	require.NoError(t, err)
+
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "active")
+}
+
+func TestMemoryStore_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewMemoryStore().Take(ctx, "client", Policy{Limit: 1, Period: time.Second})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLimiter_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	limiter := NewLimiter(newTestStore(&now), Policies{
+		Default: Policy{Limit: 5, Period: time.Minute},
+		Keys: map[string]Policy{
+			"simulator": {Limit: 100, Period: time.Minute},
+			"metrics":   {},
+		},
+		Routes: map[string]Policy{
+			"GET /scooters/closest": {Limit: 2, Period: time.Minute},
+		},
+	})
+
+	rider := Client{ID: "user:1"}
+
+	t.Run("route quota is separate and more restrictive", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			result, ok, err := limiter.Take(ctx, rider, "GET /scooters/closest")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 2, result.Limit)
+		}
+
+		result, _, err := limiter.Take(ctx, rider, "GET /scooters/closest")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+
+		// Other routes only draw on the overall quota; the refused request was not charged to it
+		result, _, err = limiter.Take(ctx, rider, "GET /scooters")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 5, result.Limit)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("key policy replaces the default", func(t *testing.T) {
+		simulator := Client{ID: "key:abc", Names: []string{"abc", "simulator"}}
+		result, ok, err := limiter.Take(ctx, simulator, "GET /scooters")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, 100, result.Limit)
+	})
+
+	t.Run("unlimited key is not charged", func(t *testing.T) {
+		_, ok, err := limiter.Take(ctx, Client{ID: "key:metrics", Names: []string{"metrics"}}, "GET /scooters")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestLimiter_AuthFailures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	limiter := NewLimiter(newTestStore(&now), Policies{
+		Default:      Policy{Limit: 100, Period: time.Minute},
+		AuthFailures: Policy{Limit: 2, Period: time.Minute},
+	})
+	guesser := Client{ID: "ip:10.0.0.1"}
+
+	for i := 0; i < 2; i++ {
+		result, ok, err := limiter.CheckAuthFailures(ctx, guesser)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.True(t, result.Allowed, "checking does not charge the bucket")
+		require.NoError(t, limiter.ChargeAuthFailure(ctx, guesser))
+	}
+
+	result, _, err := limiter.CheckAuthFailures(ctx, guesser)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// Failures are counted apart from the request quota
+	result, _, err = limiter.Take(ctx, guesser, "GET /scooters")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(30 * time.Second)
+	result, _, err = limiter.CheckAuthFailures(ctx, guesser)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "failures are forgiven as the bucket refills")
+
+	unlimited := NewLimiter(NewMemoryStore(), Policies{Default: Policy{Limit: 1, Period: time.Second}})
+	_, ok, err := unlimited.CheckAuthFailures(ctx, guesser)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Policy) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func (failingStore) Peek(context.Context, string, Policy) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestLimiter_StoreError(t *testing.T) {
+	limiter := NewLimiter(failingStore{}, Policies{Default: Policy{Limit: 1, Period: time.Second}})
+
+	_, ok, err := limiter.Take(context.Background(), Client{ID: "ip:127.0.0.1"}, "GET /health")
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestNewLimiterFromConfig(t *testing.T) {
+	limiter, err := NewLimiterFromConfig(&config.RateLimitConfig{Enabled: false})
+	require.NoError(t, err)
+	assert.Nil(t, limiter)
+
+	limiter, err = NewLimiterFromConfig(&config.RateLimitConfig{
+		Enabled:      true,
+		Default:      "600/1m",
+		Routes:       "GET /api/v1/scooters/closest=120/1m, POST /api/v1/trips=10/1s",
+		Keys:         "simulator=6000/1m",
+		AuthFailures: "20/1m",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, limiter)
+	assert.Equal(t, Policy{Limit: 600, Period: time.Minute}, limiter.policies.Default)
+	assert.Equal(t, Policy{Limit: 10, Period: time.Second}, limiter.policies.Routes["POST /api/v1/trips"])
+	assert.Equal(t, Policy{Limit: 6000, Period: time.Minute}, limiter.policies.Keys["simulator"])
+	assert.Equal(t, Policy{Limit: 20, Period: time.Minute}, limiter.policies.AuthFailures)
+
+	_, err = NewLimiterFromConfig(&config.RateLimitConfig{Enabled: true, Default: "fast"})
+	assert.Error(t, err)
+
+	_, err = NewLimiterFromConfig(&config.RateLimitConfig{Enabled: true, Default: "1/1s", Routes: "GET /health"})
+	assert.Error(t, err)
+
+	_, err = NewLimiterFromConfig(&config.RateLimitConfig{Enabled: true, Default: "1/1s", AuthFailures: "many"})
+	assert.Error(t, err)
+}
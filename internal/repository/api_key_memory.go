@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryAPIKeyRepository struct {
+	db memoryConn
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	key.SetID()
+	key.SetTimestamps()
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.apiKeys.lock(ctx, key.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.apiKeys.get(key.ID); exists {
+			return fmt.Errorf("%w: api key %s", errMemoryDuplicateKey, key.ID)
+		}
+		for _, row := range tx.apiKeys.all() {
+			if row.KeyHash == key.KeyHash {
+				return fmt.Errorf("%w: api key hash", errMemoryDuplicateKey)
+			}
+		}
+		row := *key
+		row.Scopes = slices.Clone(key.Scopes)
+		tx.apiKeys.put(key.ID, row)
+		return nil
+	})
+}
+
+func (r *memoryAPIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key *models.APIKey
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if row, ok := tx.apiKeys.get(id); ok {
+			key = cloneMemoryAPIKey(row)
+		}
+		return nil
+	})
+	return key, err
+}
+
+func (r *memoryAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key *models.APIKey
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.apiKeys.all() {
+			if row.KeyHash == keyHash {
+				key = cloneMemoryAPIKey(row)
+				break
+			}
+		}
+		return nil
+	})
+	return key, err
+}
+
+func (r *memoryAPIKeyRepository) List(ctx context.Context) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.apiKeys.all() {
+			keys = append(keys, cloneMemoryAPIKey(row))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(keys, func(k *models.APIKey) time.Time { return k.CreatedAt })
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.apiKeys.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.apiKeys.get(id)
+		if !ok || row.RevokedAt != nil {
+			return ErrAPIKeyNotFound
+		}
+		now := time.Now()
+		row.RevokedAt = &now
+		tx.apiKeys.put(id, row)
+		return nil
+	})
+}
+
+func (r *memoryAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.apiKeys.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.apiKeys.get(id)
+		if !ok {
+			return nil
+		}
+		row.LastUsedAt = &at
+		tx.apiKeys.put(id, row)
+		return nil
+	})
+}
+
+// cloneMemoryAPIKey copies a stored key so callers cannot modify its scopes in place
+func cloneMemoryAPIKey(row models.APIKey) *models.APIKey {
+	row.Scopes = slices.Clone(row.Scopes)
+	return &row
+}
@@ -0,0 +1,118 @@
+package repository
+
+import "strings"
+
+// GeohashPrecision is the length of the geohash stored for every scooter (cells of about 5 x 5 metres)
+const GeohashPrecision = 9
+
+// geohashMaxCoverCells bounds how many cells a radius query is expanded into; larger areas use coarser cells
+const geohashMaxCoverCells = 16
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash returns the geohash of a point. It matches the geohash_encode function used by the
+// scooters table, so cells computed here select the rows the database assigned to them.
+func EncodeGeohash(latitude, longitude float64, precision int) string {
+	x, y := geohashCell(latitude, longitude, precision)
+	return geohashFromCell(x, y, precision)
+}
+
+// geohashBits returns how many longitude and latitude bits a geohash of the given length holds
+func geohashBits(precision int) (lngBits, latBits int) {
+	total := 5 * precision
+	return (total + 1) / 2, total / 2
+}
+
+// geohashCell returns the column and row of the cell containing the point, found by the same bisection
+// as the geohash encoding so that points on a cell edge land in the same cell
+func geohashCell(latitude, longitude float64, precision int) (x, y uint64) {
+	lngBits, latBits := geohashBits(precision)
+	return geohashBisect(longitude, -180, 180, lngBits), geohashBisect(latitude, -90, 90, latBits)
+}
+
+func geohashBisect(value, min, max float64, bits int) uint64 {
+	var index uint64
+	for i := 0; i < bits; i++ {
+		mid := (min + max) / 2
+		index <<= 1
+		if value >= mid {
+			index |= 1
+			min = mid
+		} else {
+			max = mid
+		}
+	}
+	return index
+}
+
+// geohashFromCell interleaves the cell's column and row bits, longitude first, into a geohash
+func geohashFromCell(x, y uint64, precision int) string {
+	lngBits, latBits := geohashBits(precision)
+
+	var hash strings.Builder
+	hash.Grow(precision)
+
+	var chunk, chunkBits int
+	for i := 0; i < 5*precision; i++ {
+		var bit uint64
+		if i%2 == 0 {
+			lngBits--
+			bit = (x >> uint(lngBits)) & 1
+		} else {
+			latBits--
+			bit = (y >> uint(latBits)) & 1
+		}
+
+		chunk = chunk<<1 | int(bit)
+		chunkBits++
+		if chunkBits == 5 {
+			hash.WriteByte(geohashAlphabet[chunk])
+			chunk, chunkBits = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// geohashCover returns the cells of the given length that together contain the bounding box.
+// It reports false when the box crosses the antimeridian or a pole, which a cell range cannot express.
+func geohashCover(box BoundingBox, precision int) ([]string, bool) {
+	if box.MinLng < -180 || box.MaxLng > 180 {
+		return nil, false
+	}
+	minLat, maxLat := max(box.MinLat, -90), min(box.MaxLat, 90)
+
+	minX, minY := geohashCell(minLat, box.MinLng, precision)
+	maxX, maxY := geohashCell(maxLat, box.MaxLng, precision)
+
+	cells := make([]string, 0, (maxX-minX+1)*(maxY-minY+1))
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			cells = append(cells, geohashFromCell(x, y, precision))
+		}
+	}
+	return cells, true
+}
+
+// geohashCoverPrecision returns the longest geohash whose cover of the box has at most maxCells cells
+func geohashCoverPrecision(box BoundingBox, maxCells int) int {
+	precision := 1
+	for p := 2; p <= GeohashPrecision; p++ {
+		minX, minY := geohashCell(box.MinLat, box.MinLng, p)
+		maxX, maxY := geohashCell(box.MaxLat, box.MaxLng, p)
+		if (maxX-minX+1)*(maxY-minY+1) > uint64(maxCells) {
+			break
+		}
+		precision = p
+	}
+	return precision
+}
+
+// radiusPrefilter returns the bounding box of a radius in kilometres around a point and the geohash cells
+// covering it. When the box crosses the antimeridian or a pole only its latitude range is usable and
+// ok is false.
+func radiusPrefilter(latitude, longitude, radius float64) (box BoundingBox, cells []string, ok bool) {
+	box = NewBoundingBox(latitude, longitude, radius)
+	cells, ok = geohashCover(box, geohashCoverPrecision(box, geohashMaxCoverCells))
+	return box, cells, ok
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		precision int
+		expected  string
+	}{
+		{name: "Jutland", latitude: 57.64911, longitude: 10.40744, precision: 11, expected: "u4pruydqqvj"},
+		{name: "Spain", latitude: 42.6, longitude: -5.6, precision: 5, expected: "ezs42"},
+		{name: "Ottawa", latitude: 45.4215, longitude: -75.6972, precision: 6, expected: "f244mk"},
+		{name: "origin is on the upper half of both axes", latitude: 0, longitude: 0, precision: 1, expected: "s"},
+		{name: "south west corner", latitude: -90, longitude: -180, precision: 3, expected: "000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EncodeGeohash(tt.latitude, tt.longitude, tt.precision))
+		})
+	}
+}
+
+func TestGeohashCover(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, radius := range []float64{0.1, 1, 5, 50} {
+		box := NewBoundingBox(45.4215, -75.6972, radius)
+		precision := geohashCoverPrecision(box, geohashMaxCoverCells)
+		cells, ok := geohashCover(box, precision)
+		require.True(t, ok)
+		assert.LessOrEqual(t, len(cells), geohashMaxCoverCells)
+
+		// Every point in the box has a geohash starting with one of the cells
+		for i := 0; i < 1000; i++ {
+			lat := box.MinLat + random.Float64()*(box.MaxLat-box.MinLat)
+			lng := box.MinLng + random.Float64()*(box.MaxLng-box.MinLng)
+			hash := EncodeGeohash(lat, lng, GeohashPrecision)
+
+			covered := false
+			for _, cell := range cells {
+				if strings.HasPrefix(hash, cell) {
+					covered = true
+					break
+				}
+			}
+			assert.True(t, covered, "radius %.1f km: %s not covered by %v", radius, hash, cells)
+		}
+	}
+
+	_, ok := geohashCover(NewBoundingBox(0, 179.99, 10), 5)
+	assert.False(t, ok, "a box across the antimeridian cannot be covered")
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"scootin-aboot/internal/models"
 
@@ -16,4 +17,6 @@ type LocationUpdateRepository interface {
 	List(ctx context.Context, limit, offset int) ([]*models.LocationUpdate, error)
 
 	GetByScooterID(ctx context.Context, scooterID uuid.UUID) ([]*models.LocationUpdate, error)
+	// GetByScooterIDBetween returns the scooter's updates timestamped within [from, to], oldest first
+	GetByScooterIDBetween(ctx context.Context, scooterID uuid.UUID, from, to time.Time) ([]*models.LocationUpdate, error)
 }
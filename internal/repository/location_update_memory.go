@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryLocationUpdateRepository struct {
+	db memoryConn
+}
+
+func (r *memoryLocationUpdateRepository) Create(ctx context.Context, update *models.LocationUpdate) error {
+	update.SetID()
+	if err := update.ValidateAndSetTimestamps(); err != nil {
+		return err
+	}
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.locationUpdates.lock(ctx, update.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.locationUpdates.get(update.ID); exists {
+			return fmt.Errorf("%w: location update %s", errMemoryDuplicateKey, update.ID)
+		}
+		tx.locationUpdates.put(update.ID, *update)
+		return nil
+	})
+}
+
+func (r *memoryLocationUpdateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.LocationUpdate, error) {
+	var update *models.LocationUpdate
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if row, ok := tx.locationUpdates.get(id); ok && row.DeletedAt == nil {
+			update = &row
+		}
+		return nil
+	})
+	return update, err
+}
+
+func (r *memoryLocationUpdateRepository) Update(ctx context.Context, update *models.LocationUpdate) error {
+	if err := update.ValidateAndSetTimestamps(); err != nil {
+		return err
+	}
+
+	return r.modify(ctx, update.ID, func(row *models.LocationUpdate) {
+		row.ScooterID = update.ScooterID
+		row.Latitude = update.Latitude
+		row.Longitude = update.Longitude
+		row.Timestamp = update.Timestamp
+		row.DeletedAt = update.DeletedAt
+	})
+}
+
+func (r *memoryLocationUpdateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.modify(ctx, id, func(row *models.LocationUpdate) {
+		now := time.Now()
+		row.DeletedAt = &now
+	})
+}
+
+func (r *memoryLocationUpdateRepository) List(ctx context.Context, limit, offset int) ([]*models.LocationUpdate, error) {
+	updates, err := r.list(ctx, func(row models.LocationUpdate) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(updates, func(u *models.LocationUpdate) time.Time { return u.CreatedAt })
+	return paginate(updates, limit, offset), nil
+}
+
+func (r *memoryLocationUpdateRepository) GetByScooterID(ctx context.Context, scooterID uuid.UUID) ([]*models.LocationUpdate, error) {
+	updates, err := r.list(ctx, func(row models.LocationUpdate) bool {
+		return row.ScooterID == scooterID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(updates, func(u *models.LocationUpdate) time.Time { return u.Timestamp })
+	return updates, nil
+}
+
+func (r *memoryLocationUpdateRepository) GetByScooterIDBetween(ctx context.Context, scooterID uuid.UUID, from, to time.Time) ([]*models.LocationUpdate, error) {
+	updates, err := r.list(ctx, func(row models.LocationUpdate) bool {
+		return row.ScooterID == scooterID && !row.Timestamp.Before(from) && !row.Timestamp.After(to)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(updates, func(i, j int) bool {
+		return updates[i].Timestamp.Before(updates[j].Timestamp)
+	})
+	return updates, nil
+}
+
+// modify locks a live location update and applies change, returning ErrLocationUpdateNotFound when no row matched
+func (r *memoryLocationUpdateRepository) modify(ctx context.Context, id uuid.UUID, change func(row *models.LocationUpdate)) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.locationUpdates.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.locationUpdates.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrLocationUpdateNotFound
+		}
+		change(&row)
+		tx.locationUpdates.put(id, row)
+		return nil
+	})
+}
+
+// list returns live location updates matching filter, unordered
+func (r *memoryLocationUpdateRepository) list(ctx context.Context, filter func(row models.LocationUpdate) bool) ([]*models.LocationUpdate, error) {
+	var updates []*models.LocationUpdate
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.locationUpdates.all() {
+			if row.DeletedAt == nil && filter(row) {
+				update := row
+				updates = append(updates, &update)
+			}
+		}
+		return nil
+	})
+	return updates, err
+}
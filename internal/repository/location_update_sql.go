@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"scootin-aboot/internal/models"
 
@@ -20,8 +21,8 @@ func (r *sqlLocationUpdateRepository) Create(ctx context.Context, update *models
 	}
 
 	query := `
-		INSERT INTO location_updates (id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		INSERT INTO location_updates (id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at, battery_level)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		update.ID,
@@ -31,13 +32,14 @@ func (r *sqlLocationUpdateRepository) Create(ctx context.Context, update *models
 		update.Timestamp,
 		update.CreatedAt,
 		update.DeletedAt,
+		update.BatteryLevel,
 	)
 	return err
 }
 
 func (r *sqlLocationUpdateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.LocationUpdate, error) {
 	query := `
-		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at
+		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at, battery_level
 		FROM location_updates
 		WHERE id = $1 AND deleted_at IS NULL`
 
@@ -50,6 +52,7 @@ func (r *sqlLocationUpdateRepository) GetByID(ctx context.Context, id uuid.UUID)
 		&update.Timestamp,
 		&update.CreatedAt,
 		&update.DeletedAt,
+		&update.BatteryLevel,
 	)
 
 	if err != nil {
@@ -69,7 +72,7 @@ func (r *sqlLocationUpdateRepository) Update(ctx context.Context, update *models
 
 	query := `
 		UPDATE location_updates
-		SET scooter_id = $2, latitude = $3, longitude = $4, timestamp = $5, deleted_at = $6
+		SET scooter_id = $2, latitude = $3, longitude = $4, timestamp = $5, deleted_at = $6, battery_level = $7
 		WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -79,6 +82,7 @@ func (r *sqlLocationUpdateRepository) Update(ctx context.Context, update *models
 		update.Longitude,
 		update.Timestamp,
 		update.DeletedAt,
+		update.BatteryLevel,
 	)
 	if err != nil {
 		return err
@@ -121,7 +125,7 @@ func (r *sqlLocationUpdateRepository) Delete(ctx context.Context, id uuid.UUID)
 
 func (r *sqlLocationUpdateRepository) List(ctx context.Context, limit, offset int) ([]*models.LocationUpdate, error) {
 	query := `
-		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at
+		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at, battery_level
 		FROM location_updates
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC`
@@ -160,6 +164,7 @@ func (r *sqlLocationUpdateRepository) List(ctx context.Context, limit, offset in
 			&update.Timestamp,
 			&update.CreatedAt,
 			&update.DeletedAt,
+			&update.BatteryLevel,
 		)
 		if err != nil {
 			return nil, err
@@ -172,7 +177,7 @@ func (r *sqlLocationUpdateRepository) List(ctx context.Context, limit, offset in
 
 func (r *sqlLocationUpdateRepository) GetByScooterID(ctx context.Context, scooterID uuid.UUID) ([]*models.LocationUpdate, error) {
 	query := `
-		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at
+		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at, battery_level
 		FROM location_updates
 		WHERE scooter_id = $1 AND deleted_at IS NULL
 		ORDER BY timestamp DESC`
@@ -194,6 +199,42 @@ func (r *sqlLocationUpdateRepository) GetByScooterID(ctx context.Context, scoote
 			&update.Timestamp,
 			&update.CreatedAt,
 			&update.DeletedAt,
+			&update.BatteryLevel,
+		)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, update)
+	}
+
+	return updates, rows.Err()
+}
+
+func (r *sqlLocationUpdateRepository) GetByScooterIDBetween(ctx context.Context, scooterID uuid.UUID, from, to time.Time) ([]*models.LocationUpdate, error) {
+	query := `
+		SELECT id, scooter_id, latitude, longitude, timestamp, created_at, deleted_at, battery_level
+		FROM location_updates
+		WHERE scooter_id = $1 AND timestamp BETWEEN $2 AND $3 AND deleted_at IS NULL
+		ORDER BY timestamp ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, scooterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var updates []*models.LocationUpdate
+	for rows.Next() {
+		update := &models.LocationUpdate{}
+		err := rows.Scan(
+			&update.ID,
+			&update.ScooterID,
+			&update.Latitude,
+			&update.Longitude,
+			&update.Timestamp,
+			&update.CreatedAt,
+			&update.DeletedAt,
+			&update.BatteryLevel,
 		)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,55 @@
+package repository
+
+type memoryRepository struct {
+	store      *memoryStore
+	unitOfWork UnitOfWork
+}
+
+// NewMemoryRepository returns an empty Repository that keeps all data in process memory
+func NewMemoryRepository() Repository {
+	store := newMemoryStore()
+	return &memoryRepository{
+		store:      store,
+		unitOfWork: &memoryUnitOfWork{store: store},
+	}
+}
+
+func (r *memoryRepository) Scooter() ScooterRepository {
+	return &memoryScooterRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) Trip() TripRepository {
+	return &memoryTripRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) User() UserRepository {
+	return &memoryUserRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) LocationUpdate() LocationUpdateRepository {
+	return &memoryLocationUpdateRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) ProcessedEvent() ProcessedEventRepository {
+	return &memoryProcessedEventRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) Outbox() OutboxRepository {
+	return &memoryOutboxRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) APIKey() APIKeyRepository {
+	return &memoryAPIKeyRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) Reservation() ReservationRepository {
+	return &memoryReservationRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) ScooterStatusHistory() ScooterStatusHistoryRepository {
+	return &memoryScooterStatusHistoryRepository{db: memoryConn{store: r.store}}
+}
+
+func (r *memoryRepository) UnitOfWork() UnitOfWork {
+	return r.unitOfWork
+}
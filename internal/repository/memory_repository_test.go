@@ -0,0 +1,517 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createMemoryScooter(t *testing.T, repo Repository, status models.ScooterStatus) *models.Scooter {
+	t.Helper()
+	scooter := &models.Scooter{
+		Status:           status,
+		CurrentLatitude:  45.4215,
+		CurrentLongitude: -75.6972,
+	}
+	require.NoError(t, repo.Scooter().Create(context.Background(), scooter))
+	return scooter
+}
+
+func TestMemoryUnitOfWork_CommitAndRollback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes are invisible until commit", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		scooter := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+
+		tx, err := repo.UnitOfWork().Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, tx.ScooterRepository().UpdateStatus(ctx, scooter.ID, models.ScooterStatusOccupied))
+
+		inTx, err := tx.ScooterRepository().GetByID(ctx, scooter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ScooterStatusOccupied, inTx.Status)
+
+		outside, err := repo.Scooter().GetByID(ctx, scooter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ScooterStatusAvailable, outside.Status)
+
+		require.NoError(t, tx.Commit())
+
+		outside, err = repo.Scooter().GetByID(ctx, scooter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ScooterStatusOccupied, outside.Status)
+	})
+
+	t.Run("rollback discards writes", func(t *testing.T) {
+		repo := NewMemoryRepository()
+
+		tx, err := repo.UnitOfWork().Begin(ctx)
+		require.NoError(t, err)
+		user := &models.User{}
+		require.NoError(t, tx.UserRepository().Create(ctx, user))
+		require.NoError(t, tx.Rollback())
+
+		found, err := repo.User().GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("finished transaction rejects further use", func(t *testing.T) {
+		repo := NewMemoryRepository()
+
+		tx, err := repo.UnitOfWork().Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		assert.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+		assert.ErrorIs(t, tx.Rollback(), sql.ErrTxDone)
+		_, err = tx.TripRepository().GetByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, sql.ErrTxDone)
+	})
+}
+
+func TestMemoryUnitOfWork_GetByIDForUpdateLocksRow(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	scooter := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+
+	first, err := repo.UnitOfWork().Begin(ctx)
+	require.NoError(t, err)
+	_, err = first.ScooterRepository().GetByIDForUpdate(ctx, scooter.ID)
+	require.NoError(t, err)
+
+	locked := make(chan *models.Scooter)
+	go func() {
+		second, _ := repo.UnitOfWork().Begin(ctx)
+		defer second.Rollback()
+		row, _ := second.ScooterRepository().GetByIDForUpdate(ctx, scooter.ID)
+		locked <- row
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("second transaction acquired a row lock held by the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, first.ScooterRepository().UpdateStatusWithCheck(ctx, scooter.ID, models.ScooterStatusOccupied, models.ScooterStatusAvailable))
+	require.NoError(t, first.Commit())
+
+	select {
+	case row := <-locked:
+		require.NotNil(t, row)
+		assert.Equal(t, models.ScooterStatusOccupied, row.Status)
+	case <-time.After(time.Second):
+		t.Fatal("second transaction did not acquire the lock after commit")
+	}
+}
+
+func TestMemoryUnitOfWork_LockWaitHonoursContext(t *testing.T) {
+	repo := NewMemoryRepository()
+	scooter := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+
+	first, err := repo.UnitOfWork().Begin(context.Background())
+	require.NoError(t, err)
+	defer first.Rollback()
+	_, err = first.ScooterRepository().GetByIDForUpdate(context.Background(), scooter.ID)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = repo.Scooter().UpdateStatus(ctx, scooter.ID, models.ScooterStatusOccupied)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMemoryRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	kept := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	deleted := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+
+	require.NoError(t, repo.Scooter().Delete(ctx, deleted.ID))
+
+	_, err := repo.Scooter().GetByID(ctx, deleted.ID)
+	assert.ErrorIs(t, err, ErrScooterNotFound)
+	assert.ErrorIs(t, repo.Scooter().Delete(ctx, deleted.ID), ErrScooterNotFound)
+
+	scooters, err := repo.Scooter().List(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, scooters, 1)
+	assert.Equal(t, kept.ID, scooters[0].ID)
+
+	trip := &models.Trip{
+		ScooterID:      kept.ID,
+		UserID:         uuid.New(),
+		StartTime:      time.Now(),
+		StartLatitude:  45.4215,
+		StartLongitude: -75.6972,
+		Status:         models.TripStatusActive,
+	}
+	require.NoError(t, repo.Trip().Create(ctx, trip))
+	require.NoError(t, repo.Trip().Delete(ctx, trip.ID))
+
+	found, err := repo.Trip().GetByID(ctx, trip.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	active, err := repo.Trip().GetActiveByScooterID(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+}
+
+func TestMemoryRepository_UpdateStatusWithCheckEnforcesTransitions(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	scooter := createMemoryScooter(t, repo, models.ScooterStatusRetired)
+
+	err := repo.Scooter().UpdateStatusWithCheck(ctx, scooter.ID, models.ScooterStatusAvailable, models.ScooterStatusRetired)
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+	err = repo.Scooter().UpdateStatusWithCheck(ctx, scooter.ID, "flying", models.ScooterStatusRetired)
+	assert.ErrorIs(t, err, models.ErrInvalidScooterStatus)
+
+	stored, err := repo.Scooter().GetByID(ctx, scooter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusRetired, stored.Status)
+
+	// An allowed pair still fails when the scooter is not in the expected status
+	err = repo.Scooter().UpdateStatusWithCheck(ctx, scooter.ID, models.ScooterStatusOccupied, models.ScooterStatusAvailable)
+	assert.ErrorIs(t, err, ErrScooterNotFound)
+}
+
+func TestMemoryRepository_Queries(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	near := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: 45.4216, CurrentLongitude: -75.6973}
+	far := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: 45.5017, CurrentLongitude: -73.5673}
+	occupied := &models.Scooter{Status: models.ScooterStatusOccupied, CurrentLatitude: 45.4215, CurrentLongitude: -75.6972}
+	for _, scooter := range []*models.Scooter{near, far, occupied} {
+		require.NoError(t, repo.Scooter().Create(ctx, scooter))
+	}
+
+	closest, err := repo.Scooter().GetClosest(ctx, 45.4215, -75.6972, 2)
+	require.NoError(t, err)
+	require.Len(t, closest, 2)
+	assert.Equal(t, occupied.ID, closest[0].ID)
+	assert.Equal(t, near.ID, closest[1].ID)
+
+	available, err := repo.Scooter().GetByStatusInBounds(ctx, models.ScooterStatusAvailable, 45.0, 45.45, -76.0, -75.0)
+	require.NoError(t, err)
+	require.Len(t, available, 1)
+	assert.Equal(t, near.ID, available[0].ID)
+
+	err = repo.Scooter().UpdateStatusWithCheck(ctx, near.ID, models.ScooterStatusAvailable, models.ScooterStatusOccupied)
+	assert.ErrorIs(t, err, ErrScooterNotFound)
+
+	page, err := repo.Scooter().List(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+}
+
+func TestMemoryRepository_Counts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	occupied := createMemoryScooter(t, repo, models.ScooterStatusOccupied)
+	deleted := createMemoryScooter(t, repo, models.ScooterStatusMaintenance)
+	require.NoError(t, repo.Scooter().Delete(ctx, deleted.ID))
+
+	counts, err := repo.Scooter().CountByStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[models.ScooterStatus]int64{
+		models.ScooterStatusAvailable: 2,
+		models.ScooterStatusOccupied:  1,
+	}, counts)
+
+	for _, status := range []models.TripStatus{models.TripStatusActive, models.TripStatusCompleted} {
+		require.NoError(t, repo.Trip().Create(ctx, &models.Trip{
+			ScooterID: occupied.ID,
+			UserID:    uuid.New(),
+			StartTime: time.Now(),
+			Status:    status,
+		}))
+	}
+
+	active, err := repo.Trip().CountActive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), active)
+}
+
+func TestMemoryRepository_MarkProcessed(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	require.NoError(t, repo.ProcessedEvent().MarkProcessed(ctx, &models.ProcessedEvent{EventID: "event-1", EventType: "trip.started"}))
+
+	err := repo.ProcessedEvent().MarkProcessed(ctx, &models.ProcessedEvent{EventID: "event-1", EventType: "trip.started"})
+	assert.ErrorIs(t, err, ErrEventAlreadyProcessed)
+}
+
+func TestMemoryRepository_OutboxMarkFailed(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	poison := &models.OutboxEvent{AggregateID: uuid.New(), EventType: models.OutboxEventTripEnded, Payload: []byte(`{}`)}
+	healthy := &models.OutboxEvent{AggregateID: uuid.New(), EventType: models.OutboxEventTripStarted, Payload: []byte(`{}`)}
+	require.NoError(t, repo.Outbox().Create(ctx, poison))
+	require.NoError(t, repo.Outbox().Create(ctx, healthy))
+
+	require.NoError(t, repo.Outbox().MarkFailed(ctx, poison.ID, "message too large"))
+
+	unpublished, err := repo.Outbox().GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, unpublished, 1, "failed events are skipped")
+	assert.Equal(t, healthy.ID, unpublished[0].ID)
+}
+
+func TestMemoryRepository_DevicePublicKey(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	scooter := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+
+	publicKey, err := repo.Scooter().GetDevicePublicKey(ctx, scooter.ID)
+	require.NoError(t, err)
+	assert.Empty(t, publicKey)
+
+	tx, err := repo.UnitOfWork().Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.ScooterRepository().SetDevicePublicKey(ctx, scooter.ID, "key-1"))
+	require.NoError(t, tx.Rollback())
+
+	publicKey, err = repo.Scooter().GetDevicePublicKey(ctx, scooter.ID)
+	require.NoError(t, err)
+	assert.Empty(t, publicKey)
+
+	require.NoError(t, repo.Scooter().SetDevicePublicKey(ctx, scooter.ID, "key-2"))
+	publicKey, err = repo.Scooter().GetDevicePublicKey(ctx, scooter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", publicKey)
+
+	_, err = repo.Scooter().GetDevicePublicKey(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrScooterNotFound)
+	assert.ErrorIs(t, repo.Scooter().SetDevicePublicKey(ctx, uuid.New(), "key"), ErrScooterNotFound)
+}
+
+func TestMemoryRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var scooters []*models.Scooter
+	for i := 0; i < 5; i++ {
+		scooter := &models.Scooter{
+			Status:           models.ScooterStatusAvailable,
+			CurrentLatitude:  45.42 + float64(i)*0.01,
+			CurrentLongitude: -75.69,
+			CreatedAt:        base.Add(time.Duration(i) * time.Minute),
+			LastSeen:         base.Add(time.Duration(i) * time.Hour),
+		}
+		require.NoError(t, repo.Scooter().Create(ctx, scooter))
+		scooters = append(scooters, scooter)
+	}
+	createMemoryScooter(t, repo, models.ScooterStatusOccupied)
+
+	t.Run("pages through results with a cursor", func(t *testing.T) {
+		filter := ScooterFilter{Status: models.ScooterStatusAvailable, Sort: ScooterSortCreatedAt, Descending: true, Limit: 2}
+
+		var seen []uuid.UUID
+		for {
+			page, err := repo.Scooter().Search(ctx, filter)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), page.Total)
+			for _, scooter := range page.Scooters {
+				seen = append(seen, scooter.ID)
+			}
+			if page.Next == nil {
+				break
+			}
+			filter.After = page.Next
+		}
+
+		assert.Equal(t, []uuid.UUID{scooters[4].ID, scooters[3].ID, scooters[2].ID, scooters[1].ID, scooters[0].ID}, seen)
+	})
+
+	t.Run("filters by last seen window", func(t *testing.T) {
+		page, err := repo.Scooter().Search(ctx, ScooterFilter{
+			Status:         models.ScooterStatusAvailable,
+			LastSeenAfter:  base.Add(time.Hour),
+			LastSeenBefore: base.Add(3 * time.Hour),
+			Sort:           ScooterSortLastSeen,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), page.Total)
+		require.Len(t, page.Scooters, 2)
+		assert.Equal(t, scooters[1].ID, page.Scooters[0].ID)
+		assert.Nil(t, page.Next)
+	})
+
+	t.Run("sorts by distance with offset", func(t *testing.T) {
+		page, err := repo.Scooter().Search(ctx, ScooterFilter{
+			Status:    models.ScooterStatusAvailable,
+			Sort:      ScooterSortDistance,
+			Latitude:  45.46,
+			Longitude: -75.69,
+			Limit:     2,
+			Offset:    1,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Scooters, 2)
+		assert.Equal(t, scooters[3].ID, page.Scooters[0].ID)
+		assert.Equal(t, scooters[2].ID, page.Scooters[1].ID)
+		require.NotNil(t, page.Next)
+		assert.InDelta(t, HaversineDistance(45.46, -75.69, page.Scooters[1].CurrentLatitude, page.Scooters[1].CurrentLongitude), page.Next.Distance, 1e-9)
+	})
+}
+
+func TestMemoryRepository_GetClosestWithRadiusUsesCurrentLocation(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	near := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	moved := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	require.NoError(t, repo.Scooter().UpdateLocation(ctx, moved.ID, 45.5017, -73.5673))
+
+	found, err := repo.Scooter().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, near.ID, found[0].ID)
+
+	// A location written in a transaction is visible to that transaction only
+	tx, err := repo.UnitOfWork().Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.ScooterRepository().UpdateLocation(ctx, moved.ID, 45.4216, -75.6973))
+
+	inTx, err := tx.ScooterRepository().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, string(models.ScooterStatusAvailable), 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, inTx, 2)
+
+	found, err = repo.Scooter().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, "", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	require.NoError(t, tx.Rollback())
+
+	montreal, err := repo.Scooter().GetClosestWithRadius(ctx, 45.5017, -73.5673, 1, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, montreal, 1)
+	assert.Equal(t, moved.ID, montreal[0].ID)
+}
+
+func TestMemoryRepository_GetClosestWithRadiusFiltersLowBattery(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	unknown := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	charged := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	low := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	require.NoError(t, repo.Scooter().UpdateBatteryLevel(ctx, charged.ID, 20))
+	require.NoError(t, repo.Scooter().UpdateBatteryLevel(ctx, low.ID, 19))
+
+	found, err := repo.Scooter().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, "", 20, 10)
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, len(found))
+	for i, scooter := range found {
+		ids[i] = scooter.ID
+	}
+	// Scooters that have never reported their charge are not filtered out
+	assert.ElementsMatch(t, []uuid.UUID{unknown.ID, charged.ID}, ids)
+
+	all, err := repo.Scooter().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, "", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	stored, err := repo.Scooter().GetByID(ctx, low.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.BatteryLevel)
+	assert.Equal(t, 19, *stored.BatteryLevel)
+}
+
+func TestMemoryRepository_GetClosestWithRadiusLeavesOutOutOfService(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	available := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	createMemoryScooter(t, repo, models.ScooterStatusMaintenance)
+	createMemoryScooter(t, repo, models.ScooterStatusRetired)
+
+	found, err := repo.Scooter().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, available.ID, found[0].ID)
+
+	// Out-of-service scooters are still found when asked for by status
+	maintenance, err := repo.Scooter().GetClosestWithRadius(ctx, 45.4215, -75.6972, 1, string(models.ScooterStatusMaintenance), 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, maintenance, 1)
+}
+
+func TestMemoryRepository_ScooterStatusHistoryNewestFirst(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	scooter := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	other := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+
+	now := time.Now()
+	changes := []*models.ScooterStatusChange{
+		{ScooterID: scooter.ID, FromStatus: models.ScooterStatusAvailable, ToStatus: models.ScooterStatusMaintenance, Reason: "brakes", ChangedBy: "ops", CreatedAt: now.Add(-time.Hour)},
+		{ScooterID: scooter.ID, FromStatus: models.ScooterStatusMaintenance, ToStatus: models.ScooterStatusAvailable, Reason: "repaired", ChangedBy: "ops", CreatedAt: now},
+		{ScooterID: other.ID, FromStatus: models.ScooterStatusAvailable, ToStatus: models.ScooterStatusRetired, Reason: "stolen", ChangedBy: "ops", CreatedAt: now},
+	}
+	for _, change := range changes {
+		require.NoError(t, repo.ScooterStatusHistory().Create(ctx, change))
+	}
+
+	history, err := repo.ScooterStatusHistory().ListByScooterID(ctx, scooter.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "repaired", history[0].Reason)
+	assert.Equal(t, "brakes", history[1].Reason)
+
+	page, err := repo.ScooterStatusHistory().ListByScooterID(ctx, scooter.ID, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "brakes", page[0].Reason)
+}
+
+func TestMemoryRepository_ActiveReservationsAreUnique(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	scooter := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	other := createMemoryScooter(t, repo, models.ScooterStatusAvailable)
+	userID, otherUserID := uuid.New(), uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+
+	held := &models.Reservation{ScooterID: scooter.ID, UserID: userID, Status: models.ReservationStatusActive, ExpiresAt: expiresAt}
+	require.NoError(t, repo.Reservation().Create(ctx, held))
+
+	sameScooter := &models.Reservation{ScooterID: scooter.ID, UserID: otherUserID, Status: models.ReservationStatusActive, ExpiresAt: expiresAt}
+	assert.ErrorIs(t, repo.Reservation().Create(ctx, sameScooter), ErrActiveReservationExists)
+
+	sameUser := &models.Reservation{ScooterID: other.ID, UserID: userID, Status: models.ReservationStatusActive, ExpiresAt: expiresAt}
+	assert.ErrorIs(t, repo.Reservation().Create(ctx, sameUser), ErrActiveReservationExists)
+
+	expired, err := repo.Reservation().GetExpired(ctx, expiresAt, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, held.ID, expired[0].ID)
+
+	require.NoError(t, repo.Reservation().End(ctx, held.ID, models.ReservationStatusCancelled))
+	assert.ErrorIs(t, repo.Reservation().End(ctx, held.ID, models.ReservationStatusExpired), ErrReservationNotFound)
+
+	// An ended reservation no longer blocks the scooter or the user
+	sameScooter.ID = uuid.Nil
+	assert.NoError(t, repo.Reservation().Create(ctx, sameScooter))
+	sameUser.ID = uuid.Nil
+	assert.NoError(t, repo.Reservation().Create(ctx, sameUser))
+}
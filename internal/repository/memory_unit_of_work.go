@@ -0,0 +1,428 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// errMemoryDuplicateKey mirrors a primary key violation in the SQL repositories
+var errMemoryDuplicateKey = errors.New("duplicate key value violates unique constraint")
+
+// memoryStore holds the committed rows of every table. Transactions read committed rows plus their own
+// uncommitted writes, like Postgres at READ COMMITTED, and take row locks for every write.
+type memoryStore struct {
+	mu    sync.RWMutex
+	locks *memoryLocks
+
+	scooters        *memoryTable[uuid.UUID, models.Scooter]
+	trips           *memoryTable[uuid.UUID, models.Trip]
+	users           *memoryTable[uuid.UUID, models.User]
+	locationUpdates *memoryTable[uuid.UUID, models.LocationUpdate]
+	processedEvents *memoryTable[string, models.ProcessedEvent]
+	outbox          *memoryTable[uuid.UUID, models.OutboxEvent]
+	apiKeys         *memoryTable[uuid.UUID, models.APIKey]
+	reservations    *memoryTable[uuid.UUID, models.Reservation]
+	statusHistory   *memoryTable[uuid.UUID, models.ScooterStatusChange]
+	// deviceKeys holds the scooters.device_public_key column, which models.Scooter does not carry
+	deviceKeys *memoryTable[uuid.UUID, string]
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		locks:           newMemoryLocks(),
+		scooters:        newMemoryTable[uuid.UUID, models.Scooter]("scooters").withIndex(memoryScooterCell),
+		trips:           newMemoryTable[uuid.UUID, models.Trip]("trips"),
+		users:           newMemoryTable[uuid.UUID, models.User]("users"),
+		locationUpdates: newMemoryTable[uuid.UUID, models.LocationUpdate]("location_updates"),
+		processedEvents: newMemoryTable[string, models.ProcessedEvent]("processed_events"),
+		outbox:          newMemoryTable[uuid.UUID, models.OutboxEvent]("outbox"),
+		apiKeys:         newMemoryTable[uuid.UUID, models.APIKey]("api_keys"),
+		reservations:    newMemoryTable[uuid.UUID, models.Reservation]("reservations"),
+		statusHistory:   newMemoryTable[uuid.UUID, models.ScooterStatusChange]("scooter_status_history"),
+		deviceKeys:      newMemoryTable[uuid.UUID, string]("scooter_device_keys"),
+	}
+}
+
+func (s *memoryStore) begin() *memoryUnitOfWorkTx {
+	tx := &memoryUnitOfWorkTx{store: s}
+	tx.scooters = newMemoryTableTx(tx, s.scooters)
+	tx.trips = newMemoryTableTx(tx, s.trips)
+	tx.users = newMemoryTableTx(tx, s.users)
+	tx.locationUpdates = newMemoryTableTx(tx, s.locationUpdates)
+	tx.processedEvents = newMemoryTableTx(tx, s.processedEvents)
+	tx.outbox = newMemoryTableTx(tx, s.outbox)
+	tx.apiKeys = newMemoryTableTx(tx, s.apiKeys)
+	tx.reservations = newMemoryTableTx(tx, s.reservations)
+	tx.statusHistory = newMemoryTableTx(tx, s.statusHistory)
+	tx.deviceKeys = newMemoryTableTx(tx, s.deviceKeys)
+	return tx
+}
+
+type memoryUnitOfWork struct {
+	store *memoryStore
+}
+
+func (u *memoryUnitOfWork) Begin(ctx context.Context) (UnitOfWorkTx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return u.store.begin(), nil
+}
+
+type memoryUnitOfWorkTx struct {
+	store *memoryStore
+	done  bool
+	held  []memoryLockKey
+
+	scooters        *memoryTableTx[uuid.UUID, models.Scooter]
+	trips           *memoryTableTx[uuid.UUID, models.Trip]
+	users           *memoryTableTx[uuid.UUID, models.User]
+	locationUpdates *memoryTableTx[uuid.UUID, models.LocationUpdate]
+	processedEvents *memoryTableTx[string, models.ProcessedEvent]
+	outbox          *memoryTableTx[uuid.UUID, models.OutboxEvent]
+	apiKeys         *memoryTableTx[uuid.UUID, models.APIKey]
+	reservations    *memoryTableTx[uuid.UUID, models.Reservation]
+	statusHistory   *memoryTableTx[uuid.UUID, models.ScooterStatusChange]
+	deviceKeys      *memoryTableTx[uuid.UUID, string]
+}
+
+func (u *memoryUnitOfWorkTx) ScooterRepository() ScooterRepository {
+	return &memoryScooterRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) TripRepository() TripRepository {
+	return &memoryTripRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) UserRepository() UserRepository {
+	return &memoryUserRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) LocationUpdateRepository() LocationUpdateRepository {
+	return &memoryLocationUpdateRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) ProcessedEventRepository() ProcessedEventRepository {
+	return &memoryProcessedEventRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) OutboxRepository() OutboxRepository {
+	return &memoryOutboxRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) APIKeyRepository() APIKeyRepository {
+	return &memoryAPIKeyRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) ReservationRepository() ReservationRepository {
+	return &memoryReservationRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) ScooterStatusHistoryRepository() ScooterStatusHistoryRepository {
+	return &memoryScooterStatusHistoryRepository{db: memoryConn{tx: u}}
+}
+
+func (u *memoryUnitOfWorkTx) Commit() error {
+	if u.done {
+		return sql.ErrTxDone
+	}
+	u.done = true
+
+	u.store.mu.Lock()
+	u.scooters.commit()
+	u.trips.commit()
+	u.users.commit()
+	u.locationUpdates.commit()
+	u.processedEvents.commit()
+	u.outbox.commit()
+	u.apiKeys.commit()
+	u.reservations.commit()
+	u.statusHistory.commit()
+	u.deviceKeys.commit()
+	u.store.mu.Unlock()
+
+	// Locks are released only after the writes are visible, so a waiting transaction reads the new rows
+	u.store.locks.releaseAll(u)
+	return nil
+}
+
+func (u *memoryUnitOfWorkTx) Rollback() error {
+	if u.done {
+		return sql.ErrTxDone
+	}
+	u.done = true
+
+	u.store.locks.releaseAll(u)
+	return nil
+}
+
+// lock takes the row lock for key, waiting for the transaction holding it to finish
+func (u *memoryUnitOfWorkTx) lock(ctx context.Context, table string, key any) error {
+	return u.store.locks.acquire(ctx, u, memoryLockKey{table: table, key: key})
+}
+
+// memoryConn runs repository operations inside tx, or in a transaction of their own when tx is nil
+type memoryConn struct {
+	store *memoryStore
+	tx    *memoryUnitOfWorkTx
+}
+
+func (c memoryConn) run(ctx context.Context, fn func(tx *memoryUnitOfWorkTx) error) error {
+	if c.tx != nil {
+		if c.tx.done {
+			return sql.ErrTxDone
+		}
+		return fn(c.tx)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := c.store.begin()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// memoryTable holds the committed rows of a table; guarded by memoryStore.mu
+type memoryTable[K comparable, V any] struct {
+	name  string
+	rows  map[K]V
+	index *memoryIndex[K, V]
+}
+
+func newMemoryTable[K comparable, V any](name string) *memoryTable[K, V] {
+	return &memoryTable[K, V]{name: name, rows: make(map[K]V)}
+}
+
+// withIndex adds a secondary index on the value returned by keyOf
+func (t *memoryTable[K, V]) withIndex(keyOf func(V) string) *memoryTable[K, V] {
+	t.index = &memoryIndex[K, V]{keyOf: keyOf, keys: make(map[string]map[K]struct{})}
+	return t
+}
+
+// memoryIndex maps a secondary key to the primary keys of the committed rows that have it
+type memoryIndex[K comparable, V any] struct {
+	keyOf func(V) string
+	keys  map[string]map[K]struct{}
+}
+
+func (i *memoryIndex[K, V]) add(key K, row V) {
+	indexKey := i.keyOf(row)
+	if i.keys[indexKey] == nil {
+		i.keys[indexKey] = make(map[K]struct{})
+	}
+	i.keys[indexKey][key] = struct{}{}
+}
+
+func (i *memoryIndex[K, V]) remove(key K, row V) {
+	indexKey := i.keyOf(row)
+	delete(i.keys[indexKey], key)
+	if len(i.keys[indexKey]) == 0 {
+		delete(i.keys, indexKey)
+	}
+}
+
+// memoryTableTx overlays a transaction's uncommitted writes on a table
+type memoryTableTx[K comparable, V any] struct {
+	tx     *memoryUnitOfWorkTx
+	table  *memoryTable[K, V]
+	writes map[K]V
+}
+
+func newMemoryTableTx[K comparable, V any](tx *memoryUnitOfWorkTx, table *memoryTable[K, V]) *memoryTableTx[K, V] {
+	return &memoryTableTx[K, V]{tx: tx, table: table, writes: make(map[K]V)}
+}
+
+func (t *memoryTableTx[K, V]) get(key K) (V, bool) {
+	if row, ok := t.writes[key]; ok {
+		return row, true
+	}
+
+	t.tx.store.mu.RLock()
+	defer t.tx.store.mu.RUnlock()
+	row, ok := t.table.rows[key]
+	return row, ok
+}
+
+func (t *memoryTableTx[K, V]) put(key K, row V) {
+	t.writes[key] = row
+}
+
+// lock takes the row lock for key in this table
+func (t *memoryTableTx[K, V]) lock(ctx context.Context, key K) error {
+	return t.tx.lock(ctx, t.table.name, key)
+}
+
+// tryLock takes the row lock for key only if no other transaction holds it
+func (t *memoryTableTx[K, V]) tryLock(key K) bool {
+	return t.tx.store.locks.tryAcquire(t.tx, memoryLockKey{table: t.table.name, key: key})
+}
+
+// all returns the committed rows merged with this transaction's writes, in no particular order
+func (t *memoryTableTx[K, V]) all() []V {
+	t.tx.store.mu.RLock()
+	merged := make(map[K]V, len(t.table.rows)+len(t.writes))
+	for key, row := range t.table.rows {
+		merged[key] = row
+	}
+	t.tx.store.mu.RUnlock()
+
+	for key, row := range t.writes {
+		merged[key] = row
+	}
+
+	rows := make([]V, 0, len(merged))
+	for _, row := range merged {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// lookup returns the committed rows merged with this transaction's writes whose index key is one of
+// indexKeys, in no particular order. The table must have an index.
+func (t *memoryTableTx[K, V]) lookup(indexKeys []string) []V {
+	index := t.table.index
+	wanted := make(map[string]bool, len(indexKeys))
+	for _, indexKey := range indexKeys {
+		wanted[indexKey] = true
+	}
+
+	var rows []V
+	t.tx.store.mu.RLock()
+	for _, indexKey := range indexKeys {
+		for key := range index.keys[indexKey] {
+			if _, written := t.writes[key]; !written {
+				rows = append(rows, t.table.rows[key])
+			}
+		}
+	}
+	t.tx.store.mu.RUnlock()
+
+	for _, row := range t.writes {
+		if wanted[index.keyOf(row)] {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// commit applies the transaction's writes; the caller holds memoryStore.mu
+func (t *memoryTableTx[K, V]) commit() {
+	for key, row := range t.writes {
+		if t.table.index != nil {
+			if previous, exists := t.table.rows[key]; exists {
+				t.table.index.remove(key, previous)
+			}
+			t.table.index.add(key, row)
+		}
+		t.table.rows[key] = row
+	}
+}
+
+type memoryLockKey struct {
+	table string
+	key   any
+}
+
+type memoryLock struct {
+	owner    *memoryUnitOfWorkTx
+	released chan struct{}
+}
+
+// memoryLocks implements row locks that are held until the owning transaction commits or rolls back.
+// Unlike Postgres there is no deadlock detection; a deadlocked transaction waits until its context ends.
+type memoryLocks struct {
+	mu    sync.Mutex
+	locks map[memoryLockKey]*memoryLock
+}
+
+func newMemoryLocks() *memoryLocks {
+	return &memoryLocks{locks: make(map[memoryLockKey]*memoryLock)}
+}
+
+func (l *memoryLocks) acquire(ctx context.Context, tx *memoryUnitOfWorkTx, key memoryLockKey) error {
+	for {
+		l.mu.Lock()
+		lock, held := l.locks[key]
+		if !held {
+			l.locks[key] = &memoryLock{owner: tx, released: make(chan struct{})}
+			tx.held = append(tx.held, key)
+			l.mu.Unlock()
+			return nil
+		}
+		if lock.owner == tx {
+			l.mu.Unlock()
+			return nil
+		}
+		released := lock.released
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *memoryLocks) tryAcquire(tx *memoryUnitOfWorkTx, key memoryLockKey) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lock, held := l.locks[key]; held {
+		return lock.owner == tx
+	}
+
+	l.locks[key] = &memoryLock{owner: tx, released: make(chan struct{})}
+	tx.held = append(tx.held, key)
+	return true
+}
+
+func (l *memoryLocks) releaseAll(tx *memoryUnitOfWorkTx) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range tx.held {
+		if lock, held := l.locks[key]; held && lock.owner == tx {
+			close(lock.released)
+			delete(l.locks, key)
+		}
+	}
+	tx.held = nil
+}
+
+// paginate applies LIMIT and OFFSET the way the SQL repositories build them
+func paginate[V any](rows []V, limit, offset int) []V {
+	if limit <= 0 {
+		return rows
+	}
+	if offset > 0 {
+		if offset >= len(rows) {
+			return nil
+		}
+		rows = rows[offset:]
+	}
+	if limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// sortByTimeDesc orders rows newest first, matching ORDER BY <column> DESC
+func sortByTimeDesc[V any](rows []V, column func(V) time.Time) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return column(rows[i]).After(column(rows[j]))
+	})
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"scootin-aboot/internal/models"
 
@@ -46,3 +47,8 @@ func (m *MockLocationUpdateRepository) GetByScooterID(ctx context.Context, scoot
 	args := m.Called(ctx, scooterID)
 	return args.Get(0).([]*models.LocationUpdate), args.Error(1)
 }
+
+func (m *MockLocationUpdateRepository) GetByScooterIDBetween(ctx context.Context, scooterID uuid.UUID, from, to time.Time) ([]*models.LocationUpdate, error) {
+	args := m.Called(ctx, scooterID, from, to)
+	return args.Get(0).([]*models.LocationUpdate), args.Error(1)
+}
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProcessedEventRepository is a mock implementation of repository.ProcessedEventRepository
+type MockProcessedEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockProcessedEventRepository) MarkProcessed(ctx context.Context, event *models.ProcessedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReservationRepository is a mock implementation of repository.ReservationRepository
+type MockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	args := m.Called(ctx, reservation)
+	return args.Error(0)
+}
+
+func (m *MockReservationRepository) GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Reservation, error) {
+	args := m.Called(ctx, scooterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Reservation, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationRepository) GetExpired(ctx context.Context, at time.Time, limit int) ([]*models.Reservation, error) {
+	args := m.Called(ctx, at, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationRepository) End(ctx context.Context, id uuid.UUID, status models.ReservationStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -52,11 +53,24 @@ func (m *MockScooterRepository) UpdateLocation(ctx context.Context, id uuid.UUID
 	return args.Error(0)
 }
 
+func (m *MockScooterRepository) UpdateBatteryLevel(ctx context.Context, id uuid.UUID, level int) error {
+	args := m.Called(ctx, id, level)
+	return args.Error(0)
+}
+
 func (m *MockScooterRepository) GetByStatus(ctx context.Context, status models.ScooterStatus) ([]*models.Scooter, error) {
 	args := m.Called(ctx, status)
 	return args.Get(0).([]*models.Scooter), args.Error(1)
 }
 
+func (m *MockScooterRepository) CountByStatus(ctx context.Context) (map[models.ScooterStatus]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[models.ScooterStatus]int64), args.Error(1)
+}
+
 func (m *MockScooterRepository) GetInBounds(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error) {
 	args := m.Called(ctx, minLat, maxLat, minLng, maxLng)
 	return args.Get(0).([]*models.Scooter), args.Error(1)
@@ -67,8 +81,8 @@ func (m *MockScooterRepository) GetClosest(ctx context.Context, latitude, longit
 	return args.Get(0).([]*models.Scooter), args.Error(1)
 }
 
-func (m *MockScooterRepository) GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, limit int) ([]*models.Scooter, error) {
-	args := m.Called(ctx, latitude, longitude, radius, status, limit)
+func (m *MockScooterRepository) GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, minBattery int, limit int) ([]*models.Scooter, error) {
+	args := m.Called(ctx, latitude, longitude, radius, status, minBattery, limit)
 	return args.Get(0).([]*models.Scooter), args.Error(1)
 }
 
@@ -77,6 +91,14 @@ func (m *MockScooterRepository) GetByStatusInBounds(ctx context.Context, status
 	return args.Get(0).([]*models.Scooter), args.Error(1)
 }
 
+func (m *MockScooterRepository) Search(ctx context.Context, filter repository.ScooterFilter) (*repository.ScooterPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ScooterPage), args.Error(1)
+}
+
 func (m *MockScooterRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Scooter, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -89,3 +111,13 @@ func (m *MockScooterRepository) UpdateStatusWithCheck(ctx context.Context, id uu
 	args := m.Called(ctx, id, newStatus, expectedStatus)
 	return args.Error(0)
 }
+
+func (m *MockScooterRepository) GetDevicePublicKey(ctx context.Context, id uuid.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockScooterRepository) SetDevicePublicKey(ctx context.Context, id uuid.UUID, publicKey string) error {
+	args := m.Called(ctx, id, publicKey)
+	return args.Error(0)
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockScooterStatusHistoryRepository is a mock implementation of repository.ScooterStatusHistoryRepository
+type MockScooterStatusHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockScooterStatusHistoryRepository) Create(ctx context.Context, change *models.ScooterStatusChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockScooterStatusHistoryRepository) ListByScooterID(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error) {
+	args := m.Called(ctx, scooterID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ScooterStatusChange), args.Error(1)
+}
@@ -47,8 +47,8 @@ func (m *MockTripRepository) UpdateStatus(ctx context.Context, id uuid.UUID, sta
 	return args.Error(0)
 }
 
-func (m *MockTripRepository) EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64) error {
-	args := m.Called(ctx, id, endLat, endLng)
+func (m *MockTripRepository) EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64, fare *models.Fare) error {
+	args := m.Called(ctx, id, endLat, endLng, fare)
 	return args.Error(0)
 }
 
@@ -72,3 +72,8 @@ func (m *MockTripRepository) GetActiveByScooterID(ctx context.Context, scooterID
 	}
 	return args.Get(0).(*models.Trip), args.Error(1)
 }
+
+func (m *MockTripRepository) CountActive(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
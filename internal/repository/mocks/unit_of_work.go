@@ -41,6 +41,31 @@ func (m *MockUnitOfWorkTx) LocationUpdateRepository() repository.LocationUpdateR
 	return args.Get(0).(repository.LocationUpdateRepository)
 }
 
+func (m *MockUnitOfWorkTx) ProcessedEventRepository() repository.ProcessedEventRepository {
+	args := m.Called()
+	return args.Get(0).(repository.ProcessedEventRepository)
+}
+
+func (m *MockUnitOfWorkTx) OutboxRepository() repository.OutboxRepository {
+	args := m.Called()
+	return args.Get(0).(repository.OutboxRepository)
+}
+
+func (m *MockUnitOfWorkTx) APIKeyRepository() repository.APIKeyRepository {
+	args := m.Called()
+	return args.Get(0).(repository.APIKeyRepository)
+}
+
+func (m *MockUnitOfWorkTx) ReservationRepository() repository.ReservationRepository {
+	args := m.Called()
+	return args.Get(0).(repository.ReservationRepository)
+}
+
+func (m *MockUnitOfWorkTx) ScooterStatusHistoryRepository() repository.ScooterStatusHistoryRepository {
+	args := m.Called()
+	return args.Get(0).(repository.ScooterStatusHistoryRepository)
+}
+
 func (m *MockUnitOfWorkTx) Commit() error {
 	args := m.Called()
 	return args.Error(0)
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, event *models.OutboxEvent) error
+	// GetUnpublished locks up to limit unpublished events in creation order, skipping rows locked by another
+	// relay and rows marked failed
+	GetUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	RecordFailure(ctx context.Context, id uuid.UUID, errMsg string) error
+	// MarkFailed records a last failed attempt and parks the event, so it is no longer returned by GetUnpublished
+	MarkFailed(ctx context.Context, id uuid.UUID, errMsg string) error
+}
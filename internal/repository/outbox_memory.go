@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryOutboxRepository struct {
+	db memoryConn
+}
+
+func (r *memoryOutboxRepository) Create(ctx context.Context, event *models.OutboxEvent) error {
+	event.SetID()
+	event.SetTimestamps()
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.outbox.lock(ctx, event.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.outbox.get(event.ID); exists {
+			return fmt.Errorf("%w: outbox event %s", errMemoryDuplicateKey, event.ID)
+		}
+		tx.outbox.put(event.ID, *event)
+		return nil
+	})
+}
+
+func (r *memoryOutboxRepository) GetUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	var events []*models.OutboxEvent
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		rows := tx.outbox.all()
+		sort.Slice(rows, func(i, j int) bool {
+			if !rows[i].CreatedAt.Equal(rows[j].CreatedAt) {
+				return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+			}
+			return rows[i].ID.String() < rows[j].ID.String()
+		})
+
+		for _, row := range rows {
+			if len(events) >= limit {
+				break
+			}
+			if row.PublishedAt != nil || row.FailedAt != nil || !tx.outbox.tryLock(row.ID) {
+				continue
+			}
+			event := row
+			events = append(events, &event)
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (r *memoryOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	return r.modify(ctx, id, func(row *models.OutboxEvent) {
+		now := time.Now()
+		row.PublishedAt = &now
+		row.Attempts++
+		row.LastError = nil
+	})
+}
+
+func (r *memoryOutboxRepository) RecordFailure(ctx context.Context, id uuid.UUID, errMsg string) error {
+	return r.modify(ctx, id, func(row *models.OutboxEvent) {
+		row.Attempts++
+		row.LastError = &errMsg
+	})
+}
+
+func (r *memoryOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, errMsg string) error {
+	return r.modify(ctx, id, func(row *models.OutboxEvent) {
+		now := time.Now()
+		row.Attempts++
+		row.LastError = &errMsg
+		row.FailedAt = &now
+	})
+}
+
+// modify locks an outbox event and applies change; like the SQL update, a missing row is not an error
+func (r *memoryOutboxRepository) modify(ctx context.Context, id uuid.UUID, change func(row *models.OutboxEvent)) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.outbox.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.outbox.get(id)
+		if !ok {
+			return nil
+		}
+		change(&row)
+		tx.outbox.put(id, row)
+		return nil
+	})
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type sqlOutboxRepository struct {
+	db SQLExecutor
+}
+
+func (r *sqlOutboxRepository) Create(ctx context.Context, event *models.OutboxEvent) error {
+	event.SetID()
+	event.SetTimestamps()
+
+	query := `
+		INSERT INTO outbox (id, aggregate_id, event_type, payload, attempts, last_error, request_id, created_at, published_at, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID,
+		event.AggregateID,
+		event.EventType,
+		[]byte(event.Payload),
+		event.Attempts,
+		event.LastError,
+		event.RequestID,
+		event.CreatedAt,
+		event.PublishedAt,
+		event.FailedAt,
+	)
+	return err
+}
+
+func (r *sqlOutboxRepository) GetUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `
+		SELECT id, aggregate_id, event_type, payload, attempts, last_error, request_id, created_at, published_at, failed_at
+		FROM outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		event := &models.OutboxEvent{}
+		var payload []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.AggregateID,
+			&event.EventType,
+			&payload,
+			&event.Attempts,
+			&event.LastError,
+			&event.RequestID,
+			&event.CreatedAt,
+			&event.PublishedAt,
+			&event.FailedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *sqlOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE outbox
+		SET published_at = $2, attempts = attempts + 1, last_error = NULL
+		WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, time.Now())
+	return err
+}
+
+func (r *sqlOutboxRepository) RecordFailure(ctx context.Context, id uuid.UUID, errMsg string) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $2
+		WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, errMsg)
+	return err
+}
+
+func (r *sqlOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, errMsg string) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $2, failed_at = $3
+		WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, errMsg, time.Now())
+	return err
+}
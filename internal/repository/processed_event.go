@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+)
+
+type ProcessedEventRepository interface {
+	// MarkProcessed records the event and returns ErrEventAlreadyProcessed if it was recorded before
+	MarkProcessed(ctx context.Context, event *models.ProcessedEvent) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+)
+
+type memoryProcessedEventRepository struct {
+	db memoryConn
+}
+
+func (r *memoryProcessedEventRepository) MarkProcessed(ctx context.Context, event *models.ProcessedEvent) error {
+	event.SetTimestamps()
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		// Like ON CONFLICT, a concurrent delivery of the same event waits here until the first transaction finishes
+		if err := tx.processedEvents.lock(ctx, event.EventID); err != nil {
+			return err
+		}
+		if _, exists := tx.processedEvents.get(event.EventID); exists {
+			return ErrEventAlreadyProcessed
+		}
+		tx.processedEvents.put(event.EventID, *event)
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+)
+
+type sqlProcessedEventRepository struct {
+	db SQLExecutor
+}
+
+func (r *sqlProcessedEventRepository) MarkProcessed(ctx context.Context, event *models.ProcessedEvent) error {
+	event.SetTimestamps()
+
+	// ON CONFLICT blocks on a concurrent insert of the same event until that transaction finishes,
+	// so only one delivery of an event can ever commit its side effects
+	query := `
+		INSERT INTO processed_events (event_id, event_type, processed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, event.EventID, event.EventType, event.ProcessedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrEventAlreadyProcessed
+	}
+
+	return nil
+}
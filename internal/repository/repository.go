@@ -10,6 +10,11 @@ type Repository interface {
 	Trip() TripRepository
 	User() UserRepository
 	LocationUpdate() LocationUpdateRepository
+	ProcessedEvent() ProcessedEventRepository
+	Outbox() OutboxRepository
+	APIKey() APIKeyRepository
+	Reservation() ReservationRepository
+	ScooterStatusHistory() ScooterStatusHistoryRepository
 	UnitOfWork() UnitOfWork
 }
 
@@ -22,6 +27,11 @@ type UnitOfWorkTx interface {
 	TripRepository() TripRepository
 	UserRepository() UserRepository
 	LocationUpdateRepository() LocationUpdateRepository
+	ProcessedEventRepository() ProcessedEventRepository
+	OutboxRepository() OutboxRepository
+	APIKeyRepository() APIKeyRepository
+	ReservationRepository() ReservationRepository
+	ScooterStatusHistoryRepository() ScooterStatusHistoryRepository
 
 	Commit() error
 	Rollback() error
@@ -32,4 +42,9 @@ var (
 	ErrTripNotFound           = errors.New("trip not found")
 	ErrUserNotFound           = errors.New("user not found")
 	ErrLocationUpdateNotFound = errors.New("location update not found")
+	ErrEventAlreadyProcessed  = errors.New("event already processed")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrReservationNotFound    = errors.New("reservation not found")
+	// ErrActiveReservationExists is returned when a scooter or user already has an active reservation
+	ErrActiveReservationExists = errors.New("active reservation already exists")
 )
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type ReservationRepository interface {
+	// Create returns ErrActiveReservationExists when the scooter or the user already has an active reservation
+	Create(ctx context.Context, reservation *models.Reservation) error
+	// GetActiveByScooterID and GetActiveByUserID return nil when there is no active reservation, even an expired one
+	// the sweeper has not ended yet
+	GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Reservation, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Reservation, error)
+	// GetExpired returns up to limit active reservations that expired at or before the given time, oldest first
+	GetExpired(ctx context.Context, at time.Time, limit int) ([]*models.Reservation, error)
+	// End moves an active reservation to status and returns ErrReservationNotFound if it is not active
+	End(ctx context.Context, id uuid.UUID, status models.ReservationStatus) error
+}
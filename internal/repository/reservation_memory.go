@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Lock names standing in for the unique indexes on active reservations
+const (
+	memoryReservationScooterLock = "reservations_active_scooter"
+	memoryReservationUserLock    = "reservations_active_user"
+)
+
+type memoryReservationRepository struct {
+	db memoryConn
+}
+
+func (r *memoryReservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	reservation.SetID()
+	reservation.SetTimestamps()
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.reservations.lock(ctx, reservation.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.reservations.get(reservation.ID); exists {
+			return fmt.Errorf("%w: reservation %s", errMemoryDuplicateKey, reservation.ID)
+		}
+
+		if reservation.IsActive() {
+			// Like a unique index, a concurrent hold on the same scooter or by the same user waits for this
+			// transaction and then sees its row
+			if err := tx.lock(ctx, memoryReservationScooterLock, reservation.ScooterID); err != nil {
+				return err
+			}
+			if err := tx.lock(ctx, memoryReservationUserLock, reservation.UserID); err != nil {
+				return err
+			}
+			for _, row := range tx.reservations.all() {
+				if row.IsActive() && (row.ScooterID == reservation.ScooterID || row.UserID == reservation.UserID) {
+					return ErrActiveReservationExists
+				}
+			}
+		}
+
+		tx.reservations.put(reservation.ID, *reservation)
+		return nil
+	})
+}
+
+func (r *memoryReservationRepository) GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Reservation, error) {
+	return r.first(ctx, func(row models.Reservation) bool {
+		return row.ScooterID == scooterID && row.IsActive()
+	})
+}
+
+func (r *memoryReservationRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Reservation, error) {
+	return r.first(ctx, func(row models.Reservation) bool {
+		return row.UserID == userID && row.IsActive()
+	})
+}
+
+func (r *memoryReservationRepository) first(ctx context.Context, match func(row models.Reservation) bool) (*models.Reservation, error) {
+	var reservation *models.Reservation
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.reservations.all() {
+			if match(row) {
+				reservation = &row
+				break
+			}
+		}
+		return nil
+	})
+	return reservation, err
+}
+
+func (r *memoryReservationRepository) GetExpired(ctx context.Context, at time.Time, limit int) ([]*models.Reservation, error) {
+	var reservations []*models.Reservation
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.reservations.all() {
+			if row.IsExpired(at) {
+				reservations = append(reservations, &row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].ExpiresAt.Before(reservations[j].ExpiresAt)
+	})
+	return paginate(reservations, limit, 0), nil
+}
+
+func (r *memoryReservationRepository) End(ctx context.Context, id uuid.UUID, status models.ReservationStatus) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.reservations.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.reservations.get(id)
+		if !ok || !row.IsActive() {
+			return ErrReservationNotFound
+		}
+		now := time.Now()
+		row.Status = status
+		row.EndedAt = &now
+		tx.reservations.put(id, row)
+		return nil
+	})
+}
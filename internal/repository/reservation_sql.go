@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type sqlReservationRepository struct {
+	db SQLExecutor
+}
+
+const reservationColumns = `id, scooter_id, user_id, status, expires_at, ended_at, created_at`
+
+func (r *sqlReservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	reservation.SetID()
+	reservation.SetTimestamps()
+
+	// The partial unique indexes on active reservations turn a second hold on the scooter or by the
+	// user into a conflict
+	query := `
+		INSERT INTO reservations (` + reservationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query,
+		reservation.ID, reservation.ScooterID, reservation.UserID, reservation.Status,
+		reservation.ExpiresAt, reservation.EndedAt, reservation.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrActiveReservationExists
+	}
+
+	return nil
+}
+
+func (r *sqlReservationRepository) GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations WHERE scooter_id = $1 AND status = 'active'`
+	return r.get(ctx, query, scooterID)
+}
+
+func (r *sqlReservationRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations WHERE user_id = $1 AND status = 'active'`
+	return r.get(ctx, query, userID)
+}
+
+func (r *sqlReservationRepository) get(ctx context.Context, query string, arg interface{}) (*models.Reservation, error) {
+	reservation, err := scanReservation(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *sqlReservationRepository) GetExpired(ctx context.Context, at time.Time, limit int) ([]*models.Reservation, error) {
+	query := `
+		SELECT ` + reservationColumns + `
+		FROM reservations
+		WHERE status = 'active' AND expires_at <= $1
+		ORDER BY expires_at ASC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, at, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*models.Reservation
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+func (r *sqlReservationRepository) End(ctx context.Context, id uuid.UUID, status models.ReservationStatus) error {
+	query := `
+		UPDATE reservations
+		SET status = $2, ended_at = NOW()
+		WHERE id = $1 AND status = 'active'`
+
+	result, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrReservationNotFound
+	}
+
+	return nil
+}
+
+type reservationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReservation(row reservationScanner) (*models.Reservation, error) {
+	reservation := &models.Reservation{}
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.ScooterID,
+		&reservation.UserID,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.EndedAt,
+		&reservation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"scootin-aboot/internal/models"
 
@@ -17,15 +18,88 @@ type ScooterRepository interface {
 
 	UpdateStatus(ctx context.Context, id uuid.UUID, status models.ScooterStatus) error
 	UpdateLocation(ctx context.Context, id uuid.UUID, latitude, longitude float64) error
+	UpdateBatteryLevel(ctx context.Context, id uuid.UUID, level int) error
 
 	GetByStatus(ctx context.Context, status models.ScooterStatus) ([]*models.Scooter, error)
+	// CountByStatus returns how many scooters have each status; statuses with none are left out
+	CountByStatus(ctx context.Context) (map[models.ScooterStatus]int64, error)
 
 	GetInBounds(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error)
 	GetClosest(ctx context.Context, latitude, longitude float64, limit int) ([]*models.Scooter, error)
-	GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, limit int) ([]*models.Scooter, error)
+	// GetClosestWithRadius returns scooters within radius kilometres, nearest first; an empty status matches any
+	// scooter in service, leaving out those in maintenance, charging or retired.
+	// Scooters that reported a battery level below minBattery are left out; a zero minBattery matches any charge.
+	GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, minBattery int, limit int) ([]*models.Scooter, error)
 
 	GetByStatusInBounds(ctx context.Context, status models.ScooterStatus, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error)
 
+	Search(ctx context.Context, filter ScooterFilter) (*ScooterPage, error)
+
 	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Scooter, error)
+	// UpdateStatusWithCheck moves the scooter from expectedStatus to newStatus. It returns an error wrapping
+	// models.ErrInvalidStatusTransition when the scooter state machine does not allow the change, and
+	// ErrScooterNotFound when the scooter does not exist or is no longer in expectedStatus.
 	UpdateStatusWithCheck(ctx context.Context, id uuid.UUID, newStatus models.ScooterStatus, expectedStatus models.ScooterStatus) error
+
+	// GetDevicePublicKey returns the public key of the scooter's device secret, or an empty string when none is provisioned
+	GetDevicePublicKey(ctx context.Context, id uuid.UUID) (string, error)
+	SetDevicePublicKey(ctx context.Context, id uuid.UUID, publicKey string) error
+}
+
+// ScooterSort is the column Search orders by; ties are broken by ID so the order is total
+type ScooterSort string
+
+const (
+	ScooterSortCreatedAt ScooterSort = "created_at"
+	ScooterSortLastSeen  ScooterSort = "last_seen"
+	ScooterSortDistance  ScooterSort = "distance"
+)
+
+// ScooterFilter selects, orders and pages the scooters returned by Search. Zero-valued filters are not applied.
+type ScooterFilter struct {
+	Status         models.ScooterStatus
+	Bounds         *BoundingBox
+	LastSeenAfter  time.Time
+	LastSeenBefore time.Time
+
+	// Sort defaults to ScooterSortCreatedAt. Latitude and Longitude are the reference point for ScooterSortDistance.
+	Sort       ScooterSort
+	Descending bool
+	Latitude   float64
+	Longitude  float64
+
+	// After continues from the cursor of a previous page and takes the place of Offset
+	After  *ScooterCursor
+	Limit  int
+	Offset int
+}
+
+// ScooterCursor is the position of a scooter in a Search ordering. Only the field matching the sort is set.
+type ScooterCursor struct {
+	Time     time.Time
+	Distance float64
+	ID       uuid.UUID
+}
+
+// ScooterPage is one page of Search results
+type ScooterPage struct {
+	Scooters []*models.Scooter
+	// Total counts every scooter matching the filter, regardless of paging
+	Total int64
+	// Next is the cursor after the last scooter, or nil when this is the last page
+	Next *ScooterCursor
+}
+
+// cursorFor returns the position of scooter in the filter's ordering; distance is its distance from the reference point
+func (f ScooterFilter) cursorFor(scooter *models.Scooter, distance float64) *ScooterCursor {
+	cursor := &ScooterCursor{ID: scooter.ID}
+	switch f.Sort {
+	case ScooterSortLastSeen:
+		cursor.Time = scooter.LastSeen
+	case ScooterSortDistance:
+		cursor.Distance = distance
+	default:
+		cursor.Time = scooter.CreatedAt
+	}
+	return cursor
 }
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/models"
+)
+
+const benchmarkScooters = 100_000
+
+// newBenchmarkRepository fills a memory repository with scooters spread over both cities
+func newBenchmarkRepository(b *testing.B) Repository {
+	b.Helper()
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	random := rand.New(rand.NewSource(1))
+
+	centers := [][2]float64{
+		{config.OttawaCenterLat, config.OttawaCenterLng},
+		{config.MontrealCenterLat, config.MontrealCenterLng},
+	}
+	for i := 0; i < benchmarkScooters; i++ {
+		center := centers[i%len(centers)]
+		box := NewBoundingBox(center[0], center[1], config.CityRadiusKm)
+		scooter := &models.Scooter{
+			Status:           models.ScooterStatusAvailable,
+			CurrentLatitude:  box.MinLat + random.Float64()*(box.MaxLat-box.MinLat),
+			CurrentLongitude: box.MinLng + random.Float64()*(box.MaxLng-box.MinLng),
+		}
+		if err := repo.Scooter().Create(ctx, scooter); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func BenchmarkGetClosestWithRadius(b *testing.B) {
+	ctx := context.Background()
+	repo := newBenchmarkRepository(b)
+
+	for _, radius := range []float64{0.5, 2, 10} {
+		b.Run(fmt.Sprintf("radius_%gkm/geohash", radius), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.Scooter().GetClosestWithRadius(ctx, config.OttawaCenterLat, config.OttawaCenterLng, radius, "available", 0, 20); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		// The baseline computes the distance to every scooter, as the query did before the geohash column
+		b.Run(fmt.Sprintf("radius_%gkm/full_scan", radius), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scooters, err := repo.Scooter().GetByStatus(ctx, models.ScooterStatusAvailable)
+				if err != nil {
+					b.Fatal(err)
+				}
+				FilterAndSortByDistance(scooters, config.OttawaCenterLat, config.OttawaCenterLng, radius, 20)
+			}
+		})
+	}
+}
+
+func BenchmarkGeohashCover(b *testing.B) {
+	box := NewBoundingBox(config.OttawaCenterLat, config.OttawaCenterLng, 2)
+	for i := 0; i < b.N; i++ {
+		geohashCover(box, geohashCoverPrecision(box, geohashMaxCoverCells))
+	}
+}
+
+func BenchmarkEncodeGeohash(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		EncodeGeohash(config.OttawaCenterLat, config.OttawaCenterLng, GeohashPrecision)
+	}
+}
+
+// benchmarkDSNEnv names a PostgreSQL database, migrated to the latest version, for BenchmarkGetClosestWithRadius_Postgres
+const benchmarkDSNEnv = "BENCHMARK_DATABASE_DSN"
+
+// haversineClosestSQL is the closest scooter query from before the geohash column: the distance to every
+// scooter with the status is computed before filtering by radius
+const haversineClosestSQL = `
+	SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at,
+	(6371 * acos(cos(radians($1)) * cos(radians(current_latitude)) *
+	cos(radians(current_longitude) - radians($2)) +
+	sin(radians($1)) * sin(radians(current_latitude)))) AS distance
+	FROM scooters
+	WHERE deleted_at IS NULL
+	AND status = $4
+	AND (6371 * acos(cos(radians($1)) * cos(radians(current_latitude)) *
+	cos(radians(current_longitude) - radians($2)) +
+	sin(radians($1)) * sin(radians(current_latitude)))) <= $3
+	ORDER BY distance
+	LIMIT $5`
+
+// BenchmarkGetClosestWithRadius_Postgres compares the geohash query with the Haversine query it replaced on
+// benchmarkScooters scooters. The scooters are inserted in a transaction that is rolled back afterwards.
+func BenchmarkGetClosestWithRadius_Postgres(b *testing.B) {
+	dsn := os.Getenv(benchmarkDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set", benchmarkDSNEnv)
+	}
+	ctx := context.Background()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// The insert trigger sets each scooter's geohash, as it does for scooters created through the API
+	box := NewBoundingBox(config.OttawaCenterLat, config.OttawaCenterLng, config.CityRadiusKm)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO scooters (id, status, current_latitude, current_longitude)
+		SELECT gen_random_uuid(), 'available', $1 + random() * ($2 - $1), $3 + random() * ($4 - $3)
+		FROM generate_series(1, $5)`,
+		box.MinLat, box.MaxLat, box.MinLng, box.MaxLng, benchmarkScooters); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "ANALYZE scooters"); err != nil {
+		b.Fatal(err)
+	}
+	repo := &sqlScooterRepository{db: tx}
+
+	for _, radius := range []float64{0.5, 2, 10} {
+		b.Run(fmt.Sprintf("radius_%gkm/geohash", radius), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetClosestWithRadius(ctx, config.OttawaCenterLat, config.OttawaCenterLng, radius, "available", 0, 20); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("radius_%gkm/haversine", radius), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rows, err := tx.QueryContext(ctx, haversineClosestSQL, config.OttawaCenterLat, config.OttawaCenterLng, radius, "available", 20)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for rows.Next() {
+				}
+				if err := rows.Err(); err != nil {
+					b.Fatal(err)
+				}
+				rows.Close()
+			}
+		})
+	}
+}
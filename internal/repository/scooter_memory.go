@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// The in-memory scooters table is indexed by geohash cells of about 5 x 5 km
+const (
+	memoryScooterCellPrecision = 5
+	memoryScooterMaxCells      = 1024
+)
+
+func memoryScooterCell(scooter models.Scooter) string {
+	return EncodeGeohash(scooter.CurrentLatitude, scooter.CurrentLongitude, memoryScooterCellPrecision)
+}
+
+type memoryScooterRepository struct {
+	db memoryConn
+}
+
+func (r *memoryScooterRepository) Create(ctx context.Context, scooter *models.Scooter) error {
+	scooter.SetID()
+	if err := scooter.ValidateAndSetTimestamps(); err != nil {
+		return err
+	}
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.scooters.lock(ctx, scooter.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.scooters.get(scooter.ID); exists {
+			return fmt.Errorf("%w: scooter %s", errMemoryDuplicateKey, scooter.ID)
+		}
+		tx.scooters.put(scooter.ID, *scooter)
+		return nil
+	})
+}
+
+func (r *memoryScooterRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Scooter, error) {
+	var scooter *models.Scooter
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		row, ok := tx.scooters.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrScooterNotFound
+		}
+		scooter = &row
+		return nil
+	})
+	return scooter, err
+}
+
+func (r *memoryScooterRepository) Update(ctx context.Context, scooter *models.Scooter) error {
+	if err := scooter.ValidateAndSetTimestamps(); err != nil {
+		return err
+	}
+
+	return r.modify(ctx, scooter.ID, func(row *models.Scooter) bool {
+		createdAt := row.CreatedAt
+		*row = *scooter
+		row.CreatedAt = createdAt
+		return true
+	})
+}
+
+func (r *memoryScooterRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.modify(ctx, id, func(row *models.Scooter) bool {
+		now := time.Now()
+		row.DeletedAt = &now
+		return true
+	})
+}
+
+func (r *memoryScooterRepository) List(ctx context.Context, limit, offset int) ([]*models.Scooter, error) {
+	scooters, err := r.list(ctx, func(row models.Scooter) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	return paginate(scooters, limit, offset), nil
+}
+
+func (r *memoryScooterRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.ScooterStatus) error {
+	return r.modify(ctx, id, func(row *models.Scooter) bool {
+		row.Status = status
+		row.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (r *memoryScooterRepository) UpdateLocation(ctx context.Context, id uuid.UUID, latitude, longitude float64) error {
+	return r.modify(ctx, id, func(row *models.Scooter) bool {
+		now := time.Now()
+		row.CurrentLatitude = latitude
+		row.CurrentLongitude = longitude
+		row.LastSeen = now
+		row.UpdatedAt = now
+		return true
+	})
+}
+
+func (r *memoryScooterRepository) UpdateBatteryLevel(ctx context.Context, id uuid.UUID, level int) error {
+	return r.modify(ctx, id, func(row *models.Scooter) bool {
+		row.BatteryLevel = &level
+		row.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (r *memoryScooterRepository) GetByStatus(ctx context.Context, status models.ScooterStatus) ([]*models.Scooter, error) {
+	return r.list(ctx, func(row models.Scooter) bool {
+		return row.Status == status
+	})
+}
+
+func (r *memoryScooterRepository) CountByStatus(ctx context.Context) (map[models.ScooterStatus]int64, error) {
+	scooters, err := r.list(ctx, func(models.Scooter) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.ScooterStatus]int64)
+	for _, scooter := range scooters {
+		counts[scooter.Status]++
+	}
+	return counts, nil
+}
+
+func (r *memoryScooterRepository) GetInBounds(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error) {
+	return r.list(ctx, func(row models.Scooter) bool {
+		return inBounds(row, minLat, maxLat, minLng, maxLng)
+	})
+}
+
+func (r *memoryScooterRepository) GetClosest(ctx context.Context, latitude, longitude float64, limit int) ([]*models.Scooter, error) {
+	scooters, err := r.list(ctx, func(row models.Scooter) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	sortByDistance(scooters, latitude, longitude)
+	return paginate(scooters, limit, 0), nil
+}
+
+func (r *memoryScooterRepository) GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, minBattery int, limit int) ([]*models.Scooter, error) {
+	matches := func(row models.Scooter) bool {
+		return (status == "" && row.Status.InService() || string(row.Status) == status) &&
+			!row.HasBatteryBelow(minBattery) &&
+			HaversineDistance(latitude, longitude, row.CurrentLatitude, row.CurrentLongitude) <= radius
+	}
+
+	var scooters []*models.Scooter
+	var err error
+	// Areas spanning too many index cells, the antimeridian or a pole fall back to a scan
+	cells, ok := geohashCover(NewBoundingBox(latitude, longitude, radius), memoryScooterCellPrecision)
+	if ok && len(cells) <= memoryScooterMaxCells {
+		scooters, err = r.lookup(ctx, cells, matches)
+	} else {
+		scooters, err = r.list(ctx, matches)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sortByDistance(scooters, latitude, longitude)
+	return paginate(scooters, limit, 0), nil
+}
+
+func (r *memoryScooterRepository) GetByStatusInBounds(ctx context.Context, status models.ScooterStatus, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error) {
+	return r.list(ctx, func(row models.Scooter) bool {
+		return row.Status == status && inBounds(row, minLat, maxLat, minLng, maxLng)
+	})
+}
+
+func (r *memoryScooterRepository) Search(ctx context.Context, filter ScooterFilter) (*ScooterPage, error) {
+	scooters, err := r.list(ctx, func(row models.Scooter) bool {
+		return matchesScooterFilter(row, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type positioned struct {
+		scooter *models.Scooter
+		cursor  *ScooterCursor
+	}
+	rows := make([]positioned, len(scooters))
+	for i, scooter := range scooters {
+		var distance float64
+		if filter.Sort == ScooterSortDistance {
+			distance = HaversineDistance(filter.Latitude, filter.Longitude, scooter.CurrentLatitude, scooter.CurrentLongitude)
+		}
+		rows[i] = positioned{scooter: scooter, cursor: filter.cursorFor(scooter, distance)}
+	}
+
+	// compare orders two positions in the requested direction, like ORDER BY <sort key>, id
+	compare := func(a, b *ScooterCursor) int {
+		order := cmp.Compare(a.Distance, b.Distance)
+		if filter.Sort != ScooterSortDistance {
+			order = a.Time.Compare(b.Time)
+		}
+		if order == 0 {
+			order = bytes.Compare(a.ID[:], b.ID[:])
+		}
+		if filter.Descending {
+			return -order
+		}
+		return order
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compare(rows[i].cursor, rows[j].cursor) < 0
+	})
+
+	if filter.After != nil {
+		start := sort.Search(len(rows), func(i int) bool {
+			return compare(rows[i].cursor, filter.After) > 0
+		})
+		rows = rows[start:]
+	} else if filter.Offset > 0 {
+		rows = rows[min(filter.Offset, len(rows)):]
+	}
+
+	page := &ScooterPage{Total: int64(len(scooters))}
+	if filter.Limit > 0 && len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
+		page.Next = rows[filter.Limit-1].cursor
+	}
+	for _, row := range rows {
+		page.Scooters = append(page.Scooters, row.scooter)
+	}
+
+	return page, nil
+}
+
+func (r *memoryScooterRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Scooter, error) {
+	var scooter *models.Scooter
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.scooters.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.scooters.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrScooterNotFound
+		}
+		scooter = &row
+		return nil
+	})
+	return scooter, err
+}
+
+func (r *memoryScooterRepository) UpdateStatusWithCheck(ctx context.Context, id uuid.UUID, newStatus models.ScooterStatus, expectedStatus models.ScooterStatus) error {
+	if err := models.ValidateStatusTransition(expectedStatus, newStatus); err != nil {
+		return err
+	}
+
+	return r.modify(ctx, id, func(row *models.Scooter) bool {
+		if row.Status != expectedStatus {
+			return false
+		}
+		row.Status = newStatus
+		row.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (r *memoryScooterRepository) GetDevicePublicKey(ctx context.Context, id uuid.UUID) (string, error) {
+	var publicKey string
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		row, ok := tx.scooters.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrScooterNotFound
+		}
+		publicKey, _ = tx.deviceKeys.get(id)
+		return nil
+	})
+	return publicKey, err
+}
+
+func (r *memoryScooterRepository) SetDevicePublicKey(ctx context.Context, id uuid.UUID, publicKey string) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.scooters.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.scooters.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrScooterNotFound
+		}
+		row.UpdatedAt = time.Now()
+		tx.scooters.put(id, row)
+		tx.deviceKeys.put(id, publicKey)
+		return nil
+	})
+}
+
+// modify locks a live scooter and applies change, returning ErrScooterNotFound when no row matched
+func (r *memoryScooterRepository) modify(ctx context.Context, id uuid.UUID, change func(row *models.Scooter) bool) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.scooters.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.scooters.get(id)
+		if !ok || row.DeletedAt != nil || !change(&row) {
+			return ErrScooterNotFound
+		}
+		tx.scooters.put(id, row)
+		return nil
+	})
+}
+
+// lookup returns live scooters in the given index cells matching filter, newest first
+func (r *memoryScooterRepository) lookup(ctx context.Context, cells []string, filter func(row models.Scooter) bool) ([]*models.Scooter, error) {
+	var scooters []*models.Scooter
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.scooters.lookup(cells) {
+			if row.DeletedAt == nil && filter(row) {
+				scooter := row
+				scooters = append(scooters, &scooter)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(scooters, func(s *models.Scooter) time.Time { return s.CreatedAt })
+	return scooters, nil
+}
+
+// list returns live scooters matching filter, newest first
+func (r *memoryScooterRepository) list(ctx context.Context, filter func(row models.Scooter) bool) ([]*models.Scooter, error) {
+	var scooters []*models.Scooter
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.scooters.all() {
+			if row.DeletedAt == nil && filter(row) {
+				scooter := row
+				scooters = append(scooters, &scooter)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(scooters, func(s *models.Scooter) time.Time { return s.CreatedAt })
+	return scooters, nil
+}
+
+func matchesScooterFilter(scooter models.Scooter, filter ScooterFilter) bool {
+	if filter.Status != "" && scooter.Status != filter.Status {
+		return false
+	}
+	if filter.Bounds != nil && !inBounds(scooter, filter.Bounds.MinLat, filter.Bounds.MaxLat, filter.Bounds.MinLng, filter.Bounds.MaxLng) {
+		return false
+	}
+	if !filter.LastSeenAfter.IsZero() && scooter.LastSeen.Before(filter.LastSeenAfter) {
+		return false
+	}
+	if !filter.LastSeenBefore.IsZero() && !scooter.LastSeen.Before(filter.LastSeenBefore) {
+		return false
+	}
+	return true
+}
+
+func inBounds(scooter models.Scooter, minLat, maxLat, minLng, maxLng float64) bool {
+	return scooter.CurrentLatitude >= minLat && scooter.CurrentLatitude <= maxLat &&
+		scooter.CurrentLongitude >= minLng && scooter.CurrentLongitude <= maxLng
+}
+
+func sortByDistance(scooters []*models.Scooter, latitude, longitude float64) {
+	sort.SliceStable(scooters, func(i, j int) bool {
+		return HaversineDistance(latitude, longitude, scooters[i].CurrentLatitude, scooters[i].CurrentLongitude) <
+			HaversineDistance(latitude, longitude, scooters[j].CurrentLatitude, scooters[j].CurrentLongitude)
+	})
+}
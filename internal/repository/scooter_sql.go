@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"scootin-aboot/internal/models"
 
@@ -20,8 +22,8 @@ func (r *sqlScooterRepository) Create(ctx context.Context, scooter *models.Scoot
 	}
 
 	query := `
-		INSERT INTO scooters (id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		INSERT INTO scooters (id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		scooter.ID,
@@ -32,13 +34,14 @@ func (r *sqlScooterRepository) Create(ctx context.Context, scooter *models.Scoot
 		scooter.UpdatedAt,
 		scooter.LastSeen,
 		scooter.DeletedAt,
+		scooter.BatteryLevel,
 	)
 	return err
 }
 
 func (r *sqlScooterRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Scooter, error) {
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level
 		FROM scooters
 		WHERE id = $1 AND deleted_at IS NULL`
 
@@ -52,6 +55,7 @@ func (r *sqlScooterRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 		&scooter.UpdatedAt,
 		&scooter.LastSeen,
 		&scooter.DeletedAt,
+		&scooter.BatteryLevel,
 	)
 
 	if err != nil {
@@ -71,7 +75,7 @@ func (r *sqlScooterRepository) Update(ctx context.Context, scooter *models.Scoot
 
 	query := `
 		UPDATE scooters
-		SET status = $2, current_latitude = $3, current_longitude = $4, updated_at = $5, last_seen = $6, deleted_at = $7
+		SET status = $2, current_latitude = $3, current_longitude = $4, updated_at = $5, last_seen = $6, deleted_at = $7, battery_level = $8
 		WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -82,6 +86,7 @@ func (r *sqlScooterRepository) Update(ctx context.Context, scooter *models.Scoot
 		scooter.UpdatedAt,
 		scooter.LastSeen,
 		scooter.DeletedAt,
+		scooter.BatteryLevel,
 	)
 	if err != nil {
 		return err
@@ -124,7 +129,7 @@ func (r *sqlScooterRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *sqlScooterRepository) List(ctx context.Context, limit, offset int) ([]*models.Scooter, error) {
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level
 		FROM scooters
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC`
@@ -164,6 +169,7 @@ func (r *sqlScooterRepository) List(ctx context.Context, limit, offset int) ([]*
 			&scooter.UpdatedAt,
 			&scooter.LastSeen,
 			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
 		)
 		if err != nil {
 			return nil, err
@@ -220,9 +226,32 @@ func (r *sqlScooterRepository) UpdateLocation(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+func (r *sqlScooterRepository) UpdateBatteryLevel(ctx context.Context, id uuid.UUID, level int) error {
+	query := `
+		UPDATE scooters
+		SET battery_level = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, level)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrScooterNotFound
+	}
+
+	return nil
+}
+
 func (r *sqlScooterRepository) GetByStatus(ctx context.Context, status models.ScooterStatus) ([]*models.Scooter, error) {
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level
 		FROM scooters
 		WHERE status = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC`
@@ -245,6 +274,7 @@ func (r *sqlScooterRepository) GetByStatus(ctx context.Context, status models.Sc
 			&scooter.UpdatedAt,
 			&scooter.LastSeen,
 			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
 		)
 		if err != nil {
 			return nil, err
@@ -255,9 +285,35 @@ func (r *sqlScooterRepository) GetByStatus(ctx context.Context, status models.Sc
 	return scooters, rows.Err()
 }
 
+func (r *sqlScooterRepository) CountByStatus(ctx context.Context) (map[models.ScooterStatus]int64, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM scooters
+		WHERE deleted_at IS NULL
+		GROUP BY status`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.ScooterStatus]int64)
+	for rows.Next() {
+		var status models.ScooterStatus
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
 func (r *sqlScooterRepository) GetInBounds(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error) {
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level
 		FROM scooters
 		WHERE current_latitude BETWEEN $1 AND $2
 		AND current_longitude BETWEEN $3 AND $4
@@ -282,6 +338,7 @@ func (r *sqlScooterRepository) GetInBounds(ctx context.Context, minLat, maxLat,
 			&scooter.UpdatedAt,
 			&scooter.LastSeen,
 			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
 		)
 		if err != nil {
 			return nil, err
@@ -295,7 +352,7 @@ func (r *sqlScooterRepository) GetInBounds(ctx context.Context, minLat, maxLat,
 func (r *sqlScooterRepository) GetClosest(ctx context.Context, latitude, longitude float64, limit int) ([]*models.Scooter, error) {
 	// Using Haversine formula for distance calculation
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at,
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level,
 		(6371 * acos(cos(radians($1)) * cos(radians(current_latitude)) * 
 		cos(radians(current_longitude) - radians($2)) + 
 		sin(radians($1)) * sin(radians(current_latitude)))) AS distance
@@ -323,6 +380,7 @@ func (r *sqlScooterRepository) GetClosest(ctx context.Context, latitude, longitu
 			&scooter.UpdatedAt,
 			&scooter.LastSeen,
 			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
 			&distance,
 		)
 		if err != nil {
@@ -334,23 +392,48 @@ func (r *sqlScooterRepository) GetClosest(ctx context.Context, latitude, longitu
 	return scooters, rows.Err()
 }
 
-func (r *sqlScooterRepository) GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, limit int) ([]*models.Scooter, error) {
-	// Using Haversine formula for distance calculation with radius filtering
-	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at,
-		(6371 * acos(cos(radians($1)) * cos(radians(current_latitude)) * 
-		cos(radians(current_longitude) - radians($2)) + 
-		sin(radians($1)) * sin(radians(current_latitude)))) AS distance
+func (r *sqlScooterRepository) GetClosestWithRadius(ctx context.Context, latitude, longitude, radius float64, status string, minBattery int, limit int) ([]*models.Scooter, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	distance := fmt.Sprintf(scooterDistanceSQL, arg(latitude), arg(longitude))
+	conditions := []string{"deleted_at IS NULL"}
+	if status != "" {
+		conditions = append(conditions, "status = "+arg(status))
+	} else {
+		conditions = append(conditions, "status IN ('available', 'occupied', 'reserved')")
+	}
+	if minBattery > 0 {
+		conditions = append(conditions, "(battery_level IS NULL OR battery_level >= "+arg(minBattery)+")")
+	}
+
+	// The geohash cells and bounding box narrow the search to an index range before distances are computed
+	box, cells, ok := radiusPrefilter(latitude, longitude, radius)
+	conditions = append(conditions, fmt.Sprintf("current_latitude BETWEEN %s AND %s", arg(box.MinLat), arg(box.MaxLat)))
+	if ok {
+		ranges := make([]string, len(cells))
+		for i, cell := range cells {
+			ranges[i] = fmt.Sprintf("(geohash >= %s AND geohash < %s)", arg(cell), arg(cell+"~"))
+		}
+		conditions = append(conditions,
+			"("+strings.Join(ranges, " OR ")+")",
+			fmt.Sprintf("current_longitude BETWEEN %s AND %s", arg(box.MinLng), arg(box.MaxLng)),
+		)
+	}
+	conditions = append(conditions, fmt.Sprintf("%s <= %s", distance, arg(radius)))
+
+	query := fmt.Sprintf(`
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level,
+		%s AS distance
 		FROM scooters
-		WHERE deleted_at IS NULL
-		AND status = $4
-		AND (6371 * acos(cos(radians($1)) * cos(radians(current_latitude)) * 
-		cos(radians(current_longitude) - radians($2)) + 
-		sin(radians($1)) * sin(radians(current_latitude)))) <= $3
-		ORDER BY distance
-		LIMIT $5`
+		WHERE %s
+		ORDER BY distance, id
+		LIMIT %s`, distance, strings.Join(conditions, " AND "), arg(limit))
 
-	rows, err := r.db.QueryContext(ctx, query, latitude, longitude, radius, status, limit)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -369,6 +452,7 @@ func (r *sqlScooterRepository) GetClosestWithRadius(ctx context.Context, latitud
 			&scooter.UpdatedAt,
 			&scooter.LastSeen,
 			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
 			&distance,
 		)
 		if err != nil {
@@ -382,7 +466,7 @@ func (r *sqlScooterRepository) GetClosestWithRadius(ctx context.Context, latitud
 
 func (r *sqlScooterRepository) GetByStatusInBounds(ctx context.Context, status models.ScooterStatus, minLat, maxLat, minLng, maxLng float64) ([]*models.Scooter, error) {
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level
 		FROM scooters
 		WHERE status = $1
 		AND current_latitude BETWEEN $2 AND $3
@@ -408,6 +492,7 @@ func (r *sqlScooterRepository) GetByStatusInBounds(ctx context.Context, status m
 			&scooter.UpdatedAt,
 			&scooter.LastSeen,
 			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
 		)
 		if err != nil {
 			return nil, err
@@ -420,7 +505,7 @@ func (r *sqlScooterRepository) GetByStatusInBounds(ctx context.Context, status m
 
 func (r *sqlScooterRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Scooter, error) {
 	query := `
-		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level
 		FROM scooters
 		WHERE id = $1 AND deleted_at IS NULL
 		FOR UPDATE`
@@ -435,6 +520,7 @@ func (r *sqlScooterRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUI
 		&scooter.UpdatedAt,
 		&scooter.LastSeen,
 		&scooter.DeletedAt,
+		&scooter.BatteryLevel,
 	)
 
 	if err != nil {
@@ -448,6 +534,10 @@ func (r *sqlScooterRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUI
 }
 
 func (r *sqlScooterRepository) UpdateStatusWithCheck(ctx context.Context, id uuid.UUID, newStatus models.ScooterStatus, expectedStatus models.ScooterStatus) error {
+	if err := models.ValidateStatusTransition(expectedStatus, newStatus); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE scooters
 		SET status = $2, updated_at = NOW()
@@ -469,3 +559,159 @@ func (r *sqlScooterRepository) UpdateStatusWithCheck(ctx context.Context, id uui
 
 	return nil
 }
+
+// scooterDistanceSQL is the Haversine distance in kilometres from the point given by its two placeholders.
+// The cosine is clamped so rounding at zero distance cannot push acos out of its domain.
+const scooterDistanceSQL = `(6371 * acos(LEAST(1, GREATEST(-1, cos(radians(%[1]s)) * cos(radians(current_latitude)) *
+		cos(radians(current_longitude) - radians(%[2]s)) +
+		sin(radians(%[1]s)) * sin(radians(current_latitude))))))`
+
+func (r *sqlScooterRepository) Search(ctx context.Context, filter ScooterFilter) (*ScooterPage, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+arg(filter.Status))
+	}
+	if filter.Bounds != nil {
+		conditions = append(conditions,
+			fmt.Sprintf("current_latitude BETWEEN %s AND %s", arg(filter.Bounds.MinLat), arg(filter.Bounds.MaxLat)),
+			fmt.Sprintf("current_longitude BETWEEN %s AND %s", arg(filter.Bounds.MinLng), arg(filter.Bounds.MaxLng)),
+		)
+	}
+	if !filter.LastSeenAfter.IsZero() {
+		conditions = append(conditions, "last_seen >= "+arg(filter.LastSeenAfter))
+	}
+	if !filter.LastSeenBefore.IsZero() {
+		conditions = append(conditions, "last_seen < "+arg(filter.LastSeenBefore))
+	}
+
+	page := &ScooterPage{}
+	countQuery := "SELECT COUNT(*) FROM scooters WHERE " + strings.Join(conditions, " AND ")
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	sortKey := "created_at"
+	distance := "0::float8"
+	switch filter.Sort {
+	case ScooterSortLastSeen:
+		sortKey = "last_seen"
+	case ScooterSortDistance:
+		distance = fmt.Sprintf(scooterDistanceSQL, arg(filter.Latitude), arg(filter.Longitude))
+		sortKey = distance
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		var position interface{} = filter.After.Time
+		if filter.Sort == ScooterSortDistance {
+			position = filter.After.Distance
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", sortKey, comparison, arg(position), arg(filter.After.ID)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, status, current_latitude, current_longitude, created_at, updated_at, last_seen, deleted_at, battery_level,
+		%s AS distance
+		FROM scooters
+		WHERE %s
+		ORDER BY %s %s, id %s`, distance, strings.Join(conditions, " AND "), sortKey, direction, direction)
+
+	// One row past the limit tells whether there is a next page
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit+1)
+	}
+	if filter.After == nil && filter.Offset > 0 {
+		query += " OFFSET " + arg(filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var distances []float64
+	for rows.Next() {
+		scooter := &models.Scooter{}
+		var distance float64
+		err := rows.Scan(
+			&scooter.ID,
+			&scooter.Status,
+			&scooter.CurrentLatitude,
+			&scooter.CurrentLongitude,
+			&scooter.CreatedAt,
+			&scooter.UpdatedAt,
+			&scooter.LastSeen,
+			&scooter.DeletedAt,
+			&scooter.BatteryLevel,
+			&distance,
+		)
+		if err != nil {
+			return nil, err
+		}
+		page.Scooters = append(page.Scooters, scooter)
+		distances = append(distances, distance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(page.Scooters) > filter.Limit {
+		page.Scooters = page.Scooters[:filter.Limit]
+		last := page.Scooters[filter.Limit-1]
+		page.Next = filter.cursorFor(last, distances[filter.Limit-1])
+	}
+
+	return page, nil
+}
+
+func (r *sqlScooterRepository) GetDevicePublicKey(ctx context.Context, id uuid.UUID) (string, error) {
+	query := `
+		SELECT COALESCE(device_public_key, '')
+		FROM scooters
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	var publicKey string
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&publicKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrScooterNotFound
+		}
+		return "", err
+	}
+
+	return publicKey, nil
+}
+
+func (r *sqlScooterRepository) SetDevicePublicKey(ctx context.Context, id uuid.UUID, publicKey string) error {
+	query := `
+		UPDATE scooters
+		SET device_public_key = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, publicKey)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrScooterNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type ScooterStatusHistoryRepository interface {
+	Create(ctx context.Context, change *models.ScooterStatusChange) error
+	// ListByScooterID returns the scooter's status changes, newest first
+	ListByScooterID(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryScooterStatusHistoryRepository struct {
+	db memoryConn
+}
+
+func (r *memoryScooterStatusHistoryRepository) Create(ctx context.Context, change *models.ScooterStatusChange) error {
+	change.SetID()
+	change.SetTimestamps()
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.statusHistory.lock(ctx, change.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.statusHistory.get(change.ID); exists {
+			return fmt.Errorf("%w: scooter status change %s", errMemoryDuplicateKey, change.ID)
+		}
+		tx.statusHistory.put(change.ID, *change)
+		return nil
+	})
+}
+
+func (r *memoryScooterStatusHistoryRepository) ListByScooterID(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error) {
+	var changes []*models.ScooterStatusChange
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.statusHistory.all() {
+			if row.ScooterID == scooterID {
+				change := row
+				changes = append(changes, &change)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(changes, func(c *models.ScooterStatusChange) time.Time { return c.CreatedAt })
+	return paginate(changes, limit, offset), nil
+}
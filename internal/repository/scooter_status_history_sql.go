@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type sqlScooterStatusHistoryRepository struct {
+	db SQLExecutor
+}
+
+const scooterStatusChangeColumns = `id, scooter_id, from_status, to_status, reason, changed_by, created_at`
+
+func (r *sqlScooterStatusHistoryRepository) Create(ctx context.Context, change *models.ScooterStatusChange) error {
+	change.SetID()
+	change.SetTimestamps()
+
+	query := `
+		INSERT INTO scooter_status_history (` + scooterStatusChangeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		change.ID, change.ScooterID, change.FromStatus, change.ToStatus,
+		change.Reason, change.ChangedBy, change.CreatedAt)
+	return err
+}
+
+func (r *sqlScooterStatusHistoryRepository) ListByScooterID(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error) {
+	query := `
+		SELECT ` + scooterStatusChangeColumns + `
+		FROM scooter_status_history
+		WHERE scooter_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, scooterID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*models.ScooterStatusChange
+	for rows.Next() {
+		change := &models.ScooterStatusChange{}
+		err := rows.Scan(
+			&change.ID,
+			&change.ScooterID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Reason,
+			&change.ChangedBy,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
@@ -25,6 +25,26 @@ func (r *sqlRepository) LocationUpdate() LocationUpdateRepository {
 	return &sqlLocationUpdateRepository{db: r.db}
 }
 
+func (r *sqlRepository) ProcessedEvent() ProcessedEventRepository {
+	return &sqlProcessedEventRepository{db: r.db}
+}
+
+func (r *sqlRepository) Outbox() OutboxRepository {
+	return &sqlOutboxRepository{db: r.db}
+}
+
+func (r *sqlRepository) APIKey() APIKeyRepository {
+	return &sqlAPIKeyRepository{db: r.db}
+}
+
+func (r *sqlRepository) Reservation() ReservationRepository {
+	return &sqlReservationRepository{db: r.db}
+}
+
+func (r *sqlRepository) ScooterStatusHistory() ScooterStatusHistoryRepository {
+	return &sqlScooterStatusHistoryRepository{db: r.db}
+}
+
 func (r *sqlRepository) UnitOfWork() UnitOfWork {
 	return r.unitOfWork
 }
@@ -44,6 +44,26 @@ func (u *sqlUnitOfWorkTx) LocationUpdateRepository() LocationUpdateRepository {
 	return &sqlLocationUpdateRepository{db: u.tx}
 }
 
+func (u *sqlUnitOfWorkTx) ProcessedEventRepository() ProcessedEventRepository {
+	return &sqlProcessedEventRepository{db: u.tx}
+}
+
+func (u *sqlUnitOfWorkTx) OutboxRepository() OutboxRepository {
+	return &sqlOutboxRepository{db: u.tx}
+}
+
+func (u *sqlUnitOfWorkTx) APIKeyRepository() APIKeyRepository {
+	return &sqlAPIKeyRepository{db: u.tx}
+}
+
+func (u *sqlUnitOfWorkTx) ReservationRepository() ReservationRepository {
+	return &sqlReservationRepository{db: u.tx}
+}
+
+func (u *sqlUnitOfWorkTx) ScooterStatusHistoryRepository() ScooterStatusHistoryRepository {
+	return &sqlScooterStatusHistoryRepository{db: u.tx}
+}
+
 func (u *sqlUnitOfWorkTx) Commit() error {
 	return u.tx.Commit()
 }
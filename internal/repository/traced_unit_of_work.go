@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"scootin-aboot/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "scootin-aboot/internal/repository"
+
+type tracedUnitOfWork struct {
+	unitOfWork UnitOfWork
+}
+
+// NewTracedUnitOfWork traces each transaction of unitOfWork in a UnitOfWork span lasting until it is committed
+// or rolled back, with UnitOfWork.Begin, UnitOfWork.Commit and UnitOfWork.Rollback spans inside it
+func NewTracedUnitOfWork(unitOfWork UnitOfWork) UnitOfWork {
+	return &tracedUnitOfWork{unitOfWork: unitOfWork}
+}
+
+func (u *tracedUnitOfWork) Begin(ctx context.Context) (UnitOfWorkTx, error) {
+	tracer := otel.Tracer(tracerName)
+	txCtx, txSpan := tracer.Start(ctx, "UnitOfWork")
+	_, beginSpan := tracer.Start(txCtx, "UnitOfWork.Begin")
+
+	tx, err := u.unitOfWork.Begin(ctx)
+	tracing.End(beginSpan, err)
+	if err != nil {
+		tracing.End(txSpan, err)
+		return nil, err
+	}
+
+	return &tracedUnitOfWorkTx{UnitOfWorkTx: tx, ctx: txCtx, span: txSpan}, nil
+}
+
+type tracedUnitOfWorkTx struct {
+	UnitOfWorkTx
+	// ctx carries the transaction span the commit or rollback span is started in
+	ctx  context.Context
+	span trace.Span
+	// committed stops the rollback deferred by callers from being traced after a failed commit
+	committed bool
+}
+
+func (u *tracedUnitOfWorkTx) Commit() error {
+	u.committed = true
+	_, span := otel.Tracer(tracerName).Start(u.ctx, "UnitOfWork.Commit")
+	err := u.UnitOfWorkTx.Commit()
+	tracing.End(span, err)
+
+	u.span.SetAttributes(attribute.String("outcome", "committed"))
+	tracing.End(u.span, err)
+	return err
+}
+
+func (u *tracedUnitOfWorkTx) Rollback() error {
+	if u.committed {
+		return u.UnitOfWorkTx.Rollback()
+	}
+
+	_, span := otel.Tracer(tracerName).Start(u.ctx, "UnitOfWork.Rollback")
+	err := u.UnitOfWorkTx.Rollback()
+	tracing.End(span, err)
+
+	u.span.SetAttributes(attribute.String("outcome", "rolled_back"))
+	u.span.End()
+	return err
+}
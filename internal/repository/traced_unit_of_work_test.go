@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/tracing/tracingtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	return names
+}
+
+func TestTracedUnitOfWork(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		recorder := tracingtest.Record(t)
+		repo := NewMemoryRepository()
+		unitOfWork := NewTracedUnitOfWork(repo.UnitOfWork())
+
+		ctx, request := otel.Tracer("test").Start(context.Background(), "request")
+		tx, err := unitOfWork.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, tx.ScooterRepository().Create(ctx, &models.Scooter{Status: models.ScooterStatusAvailable}))
+		require.NoError(t, tx.Commit())
+		tx.Rollback()
+		request.End()
+
+		spans := recorder.Ended()
+		require.Equal(t, []string{"UnitOfWork.Begin", "UnitOfWork.Commit", "UnitOfWork", "request"}, spanNames(spans))
+		transaction := spans[2]
+		assert.Equal(t, request.SpanContext().SpanID(), transaction.Parent().SpanID())
+		assert.Equal(t, transaction.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, transaction.SpanContext().SpanID(), spans[1].Parent().SpanID())
+		assert.Contains(t, transaction.Attributes(), attribute.String("outcome", "committed"))
+
+		scooters, err := repo.Scooter().List(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, scooters, 1, "the wrapped transaction was committed")
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		recorder := tracingtest.Record(t)
+		unitOfWork := NewTracedUnitOfWork(NewMemoryRepository().UnitOfWork())
+
+		tx, err := unitOfWork.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		spans := recorder.Ended()
+		require.Equal(t, []string{"UnitOfWork.Begin", "UnitOfWork.Rollback", "UnitOfWork"}, spanNames(spans))
+		assert.Contains(t, spans[2].Attributes(), attribute.String("outcome", "rolled_back"))
+	})
+}
@@ -16,9 +16,11 @@ type TripRepository interface {
 	List(ctx context.Context, limit, offset int) ([]*models.Trip, error)
 
 	UpdateStatus(ctx context.Context, id uuid.UUID, status models.TripStatus) error
-	EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64) error
+	// EndTrip completes the trip and records its fare, which is nil when pricing is not configured
+	EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64, fare *models.Fare) error
 	CancelTrip(ctx context.Context, id uuid.UUID) error
 
 	GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Trip, error)
 	GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error)
+	CountActive(ctx context.Context) (int64, error)
 }
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryTripRepository struct {
+	db memoryConn
+}
+
+func (r *memoryTripRepository) Create(ctx context.Context, trip *models.Trip) error {
+	trip.SetID()
+	if err := trip.ValidateAndSetTimestamps(); err != nil {
+		return err
+	}
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.trips.lock(ctx, trip.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.trips.get(trip.ID); exists {
+			return fmt.Errorf("%w: trip %s", errMemoryDuplicateKey, trip.ID)
+		}
+		tx.trips.put(trip.ID, *trip)
+		return nil
+	})
+}
+
+func (r *memoryTripRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Trip, error) {
+	var trip *models.Trip
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if row, ok := tx.trips.get(id); ok && row.DeletedAt == nil {
+			trip = &row
+		}
+		return nil
+	})
+	return trip, err
+}
+
+func (r *memoryTripRepository) Update(ctx context.Context, trip *models.Trip) error {
+	if err := trip.ValidateAndSetTimestamps(); err != nil {
+		return err
+	}
+
+	return r.modify(ctx, trip.ID, func(row *models.Trip) {
+		createdAt := row.CreatedAt
+		*row = *trip
+		row.CreatedAt = createdAt
+	})
+}
+
+func (r *memoryTripRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.modify(ctx, id, func(row *models.Trip) {
+		now := time.Now()
+		row.DeletedAt = &now
+	})
+}
+
+func (r *memoryTripRepository) List(ctx context.Context, limit, offset int) ([]*models.Trip, error) {
+	trips, err := r.list(ctx, func(row models.Trip) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	return paginate(trips, limit, offset), nil
+}
+
+func (r *memoryTripRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.TripStatus) error {
+	return r.modify(ctx, id, func(row *models.Trip) {
+		row.Status = status
+		row.UpdatedAt = time.Now()
+	})
+}
+
+func (r *memoryTripRepository) EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64, fare *models.Fare) error {
+	return r.modify(ctx, id, func(row *models.Trip) {
+		now := time.Now()
+		row.EndTime = &now
+		row.EndLatitude = &endLat
+		row.EndLongitude = &endLng
+		row.Status = models.TripStatusCompleted
+		row.Fare = fare
+		row.UpdatedAt = now
+	})
+}
+
+func (r *memoryTripRepository) CancelTrip(ctx context.Context, id uuid.UUID) error {
+	return r.modify(ctx, id, func(row *models.Trip) {
+		row.Status = models.TripStatusCancelled
+		row.UpdatedAt = time.Now()
+	})
+}
+
+func (r *memoryTripRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Trip, error) {
+	return r.first(ctx, func(row models.Trip) bool {
+		return row.UserID == userID && row.Status == models.TripStatusActive
+	})
+}
+
+func (r *memoryTripRepository) GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error) {
+	return r.first(ctx, func(row models.Trip) bool {
+		return row.ScooterID == scooterID && row.Status == models.TripStatusActive
+	})
+}
+
+func (r *memoryTripRepository) CountActive(ctx context.Context) (int64, error) {
+	trips, err := r.list(ctx, func(row models.Trip) bool {
+		return row.Status == models.TripStatusActive
+	})
+	return int64(len(trips)), err
+}
+
+// modify locks a live trip and applies change, returning ErrTripNotFound when no row matched
+func (r *memoryTripRepository) modify(ctx context.Context, id uuid.UUID, change func(row *models.Trip)) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.trips.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.trips.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrTripNotFound
+		}
+		change(&row)
+		tx.trips.put(id, row)
+		return nil
+	})
+}
+
+// list returns live trips matching filter, newest first
+func (r *memoryTripRepository) list(ctx context.Context, filter func(row models.Trip) bool) ([]*models.Trip, error) {
+	var trips []*models.Trip
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.trips.all() {
+			if row.DeletedAt == nil && filter(row) {
+				trip := row
+				trips = append(trips, &trip)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(trips, func(t *models.Trip) time.Time { return t.CreatedAt })
+	return trips, nil
+}
+
+// first returns the newest live trip matching filter, or nil when there is none
+func (r *memoryTripRepository) first(ctx context.Context, filter func(row models.Trip) bool) (*models.Trip, error) {
+	trips, err := r.list(ctx, filter)
+	if err != nil || len(trips) == 0 {
+		return nil, err
+	}
+	return trips[0], nil
+}
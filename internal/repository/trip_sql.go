@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"scootin-aboot/internal/models"
@@ -44,7 +46,7 @@ func (r *sqlTripRepository) Create(ctx context.Context, trip *models.Trip) error
 
 func (r *sqlTripRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Trip, error) {
 	query := `
-		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at
+		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at, fare_breakdown
 		FROM trips
 		WHERE id = $1 AND deleted_at IS NULL`
 
@@ -63,6 +65,7 @@ func (r *sqlTripRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 		&trip.CreatedAt,
 		&trip.UpdatedAt,
 		&trip.DeletedAt,
+		fareColumn{&trip.Fare},
 	)
 
 	if err != nil {
@@ -82,10 +85,16 @@ func (r *sqlTripRepository) Update(ctx context.Context, trip *models.Trip) error
 
 	query := `
 		UPDATE trips
-		SET scooter_id = $2, user_id = $3, start_time = $4, end_time = $5, start_latitude = $6, start_longitude = $7, end_latitude = $8, end_longitude = $9, status = $10, updated_at = $11, deleted_at = $12
+		SET scooter_id = $2, user_id = $3, start_time = $4, end_time = $5, start_latitude = $6, start_longitude = $7, end_latitude = $8, end_longitude = $9, status = $10, updated_at = $11, deleted_at = $12,
+			fare_cents = $13, fare_currency = $14, fare_breakdown = $15
 		WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query,
+	fare, err := fareArgs(trip.Fare)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{
 		trip.ID,
 		trip.ScooterID,
 		trip.UserID,
@@ -98,7 +107,7 @@ func (r *sqlTripRepository) Update(ctx context.Context, trip *models.Trip) error
 		trip.Status,
 		trip.UpdatedAt,
 		trip.DeletedAt,
-	)
+	}, fare...)...)
 	if err != nil {
 		return err
 	}
@@ -140,7 +149,7 @@ func (r *sqlTripRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *sqlTripRepository) List(ctx context.Context, limit, offset int) ([]*models.Trip, error) {
 	query := `
-		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at
+		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at, fare_breakdown
 		FROM trips
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC`
@@ -185,6 +194,7 @@ func (r *sqlTripRepository) List(ctx context.Context, limit, offset int) ([]*mod
 			&trip.CreatedAt,
 			&trip.UpdatedAt,
 			&trip.DeletedAt,
+			fareColumn{&trip.Fare},
 		)
 		if err != nil {
 			return nil, err
@@ -218,14 +228,20 @@ func (r *sqlTripRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 	return nil
 }
 
-func (r *sqlTripRepository) EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64) error {
+func (r *sqlTripRepository) EndTrip(ctx context.Context, id uuid.UUID, endLat, endLng float64, fare *models.Fare) error {
 	now := time.Now()
 	query := `
 		UPDATE trips
-		SET end_time = $2, end_latitude = $3, end_longitude = $4, status = $5, updated_at = NOW()
+		SET end_time = $2, end_latitude = $3, end_longitude = $4, status = $5, updated_at = NOW(),
+			fare_cents = $6, fare_currency = $7, fare_breakdown = $8
 		WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, id, now, endLat, endLng, models.TripStatusCompleted)
+	fareValues, err := fareArgs(fare)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{id, now, endLat, endLng, models.TripStatusCompleted}, fareValues...)...)
 	if err != nil {
 		return err
 	}
@@ -267,7 +283,7 @@ func (r *sqlTripRepository) CancelTrip(ctx context.Context, id uuid.UUID) error
 
 func (r *sqlTripRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) (*models.Trip, error) {
 	query := `
-		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at
+		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at, fare_breakdown
 		FROM trips
 		WHERE user_id = $1 AND status = $2 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -288,6 +304,7 @@ func (r *sqlTripRepository) GetActiveByUserID(ctx context.Context, userID uuid.U
 		&trip.CreatedAt,
 		&trip.UpdatedAt,
 		&trip.DeletedAt,
+		fareColumn{&trip.Fare},
 	)
 
 	if err != nil {
@@ -302,7 +319,7 @@ func (r *sqlTripRepository) GetActiveByUserID(ctx context.Context, userID uuid.U
 
 func (r *sqlTripRepository) GetActiveByScooterID(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error) {
 	query := `
-		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at
+		SELECT id, scooter_id, user_id, start_time, end_time, start_latitude, start_longitude, end_latitude, end_longitude, status, created_at, updated_at, deleted_at, fare_breakdown
 		FROM trips
 		WHERE scooter_id = $1 AND status = $2 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -323,6 +340,7 @@ func (r *sqlTripRepository) GetActiveByScooterID(ctx context.Context, scooterID
 		&trip.CreatedAt,
 		&trip.UpdatedAt,
 		&trip.DeletedAt,
+		fareColumn{&trip.Fare},
 	)
 
 	if err != nil {
@@ -334,3 +352,57 @@ func (r *sqlTripRepository) GetActiveByScooterID(ctx context.Context, scooterID
 
 	return trip, nil
 }
+
+func (r *sqlTripRepository) CountActive(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM trips
+		WHERE status = $1 AND deleted_at IS NULL`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, models.TripStatusActive).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// fareArgs returns the fare_cents, fare_currency and fare_breakdown values of a fare, all NULL without one
+func fareArgs(fare *models.Fare) ([]interface{}, error) {
+	if fare == nil {
+		return []interface{}{nil, nil, nil}, nil
+	}
+
+	breakdown, err := json.Marshal(fare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fare: %w", err)
+	}
+	return []interface{}{fare.Total, fare.Currency, breakdown}, nil
+}
+
+// fareColumn scans fare_breakdown into a trip's fare, leaving it nil when the column is NULL
+type fareColumn struct {
+	fare **models.Fare
+}
+
+func (c fareColumn) Scan(src interface{}) error {
+	*c.fare = nil
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a fare", src)
+	}
+
+	fare := &models.Fare{}
+	if err := json.Unmarshal(data, fare); err != nil {
+		return fmt.Errorf("invalid fare breakdown: %w", err)
+	}
+	*c.fare = fare
+	return nil
+}
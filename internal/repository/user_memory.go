@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryUserRepository struct {
+	db memoryConn
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	user.SetID()
+	user.SetTimestamps()
+
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.users.lock(ctx, user.ID); err != nil {
+			return err
+		}
+		if _, exists := tx.users.get(user.ID); exists {
+			return fmt.Errorf("%w: user %s", errMemoryDuplicateKey, user.ID)
+		}
+		tx.users.put(user.ID, *user)
+		return nil
+	})
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user *models.User
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if row, ok := tx.users.get(id); ok && row.DeletedAt == nil {
+			user = &row
+		}
+		return nil
+	})
+	return user, err
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *models.User) error {
+	user.SetTimestamps()
+
+	return r.modify(ctx, user.ID, func(row *models.User) {
+		row.UpdatedAt = user.UpdatedAt
+		row.DeletedAt = user.DeletedAt
+	})
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.modify(ctx, id, func(row *models.User) {
+		now := time.Now()
+		row.DeletedAt = &now
+	})
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		for _, row := range tx.users.all() {
+			if row.DeletedAt == nil {
+				user := row
+				users = append(users, &user)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByTimeDesc(users, func(u *models.User) time.Time { return u.CreatedAt })
+	return paginate(users, limit, offset), nil
+}
+
+// modify locks a live user and applies change, returning ErrUserNotFound when no row matched
+func (r *memoryUserRepository) modify(ctx context.Context, id uuid.UUID, change func(row *models.User)) error {
+	return r.db.run(ctx, func(tx *memoryUnitOfWorkTx) error {
+		if err := tx.users.lock(ctx, id); err != nil {
+			return err
+		}
+		row, ok := tx.users.get(id)
+		if !ok || row.DeletedAt != nil {
+			return ErrUserNotFound
+		}
+		change(&row)
+		tx.users.put(id, row)
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (*CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+type CreateAPIKeyParams struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey carries the secret of a new key; it is not stored and cannot be retrieved again
+type CreatedAPIKey struct {
+	Key    *models.APIKey
+	Secret string
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (*CreatedAPIKey, error) {
+	if err := s.validateCreateParams(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAPIKeyRequest, err)
+	}
+
+	secret, err := apikey.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	scopes := slices.Clone(params.Scopes)
+	slices.Sort(scopes)
+
+	key := &models.APIKey{
+		Name:      strings.TrimSpace(params.Name),
+		KeyHash:   apikey.HashKey(secret),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: params.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &CreatedAPIKey{Key: key, Secret: secret}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	keys, err := s.apiKeyRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	if err := s.apiKeyRepo.Revoke(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+func (s *apiKeyService) validateCreateParams(params CreateAPIKeyParams) error {
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+
+	if len(params.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range params.Scopes {
+		if !models.IsValidScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/auth/apikey"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/repository/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name          string
+		params        CreateAPIKeyParams
+		expectedError string
+	}{
+		{
+			name:   "valid key",
+			params: CreateAPIKeyParams{Name: " simulator ", Scopes: []string{models.ScopeTripsWrite, models.ScopeScootersRead, models.ScopeTripsWrite}},
+		},
+		{
+			name:          "missing name",
+			params:        CreateAPIKeyParams{Scopes: []string{models.ScopeScootersRead}},
+			expectedError: "name is required",
+		},
+		{
+			name:          "missing scopes",
+			params:        CreateAPIKeyParams{Name: "ops"},
+			expectedError: "at least one scope is required",
+		},
+		{
+			name:          "unknown scope",
+			params:        CreateAPIKeyParams{Name: "ops", Scopes: []string{"scooters:delete"}},
+			expectedError: `unknown scope "scooters:delete"`,
+		},
+		{
+			name:          "expiry in the past",
+			params:        CreateAPIKeyParams{Name: "ops", Scopes: []string{models.ScopeAdmin}, ExpiresAt: &past},
+			expectedError: "expires_at must be in the future",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &mocks.MockAPIKeyRepository{}
+			service := NewAPIKeyService(repo)
+
+			if tc.expectedError == "" {
+				repo.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil)
+			}
+
+			created, err := service.CreateAPIKey(TestContext(), tc.params)
+
+			if tc.expectedError != "" {
+				assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, created)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "simulator", created.Key.Name)
+			assert.Equal(t, []string{models.ScopeScootersRead, models.ScopeTripsWrite}, created.Key.Scopes)
+			assert.Equal(t, apikey.HashKey(created.Secret), created.Key.KeyHash)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("not found", func(t *testing.T) {
+		repo := &mocks.MockAPIKeyRepository{}
+		repo.On("Revoke", mock.Anything, id).Return(repository.ErrAPIKeyNotFound)
+
+		err := NewAPIKeyService(repo).RevokeAPIKey(TestContext(), id)
+		assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		repo := &mocks.MockAPIKeyRepository{}
+		repo.On("Revoke", mock.Anything, id).Return(errors.New("connection reset"))
+
+		err := NewAPIKeyService(repo).RevokeAPIKey(TestContext(), id)
+		assert.ErrorContains(t, err, "failed to revoke API key")
+	})
+}
@@ -0,0 +1,42 @@
+package services
+
+import "errors"
+
+// Trip lifecycle errors that callers can map to client-facing responses
+var (
+	ErrInvalidCoordinates    = errors.New("invalid coordinates")
+	ErrUserHasActiveTrip     = errors.New("user already has an active trip")
+	ErrScooterNotAvailable   = errors.New("scooter is not available")
+	ErrScooterHasActiveTrip  = errors.New("scooter already has an active trip")
+	ErrNoActiveTripOnScooter = errors.New("no active trip found for scooter")
+	ErrTripAlreadyExists     = errors.New("trip already exists")
+	ErrTripMismatch          = errors.New("trip ID does not match active trip")
+	ErrScooterBatteryLow     = errors.New("scooter battery is too low to start a trip")
+	// ErrParkingNotAllowed wraps the geofence rule that stops a trip ending at the requested location
+	ErrParkingNotAllowed = errors.New("trip cannot end here")
+)
+
+// ErrInvalidBatteryLevel wraps validation failures of reported battery levels
+var ErrInvalidBatteryLevel = errors.New("invalid battery level")
+
+// Reservation errors
+var (
+	ErrUserHasReservation  = errors.New("user already has an active reservation")
+	ErrScooterReserved     = errors.New("scooter is reserved by another user")
+	ErrNoActiveReservation = errors.New("no active reservation found for scooter")
+	ErrReservationNotOwned = errors.New("reservation belongs to another user")
+)
+
+// Scooter status errors
+var (
+	// ErrInvalidStatusChange wraps validation failures of status change requests
+	ErrInvalidStatusChange = errors.New("invalid status change")
+	// ErrStatusTransitionNotAllowed is returned when the scooter cannot move from its current status to the requested one
+	ErrStatusTransitionNotAllowed = errors.New("status transition not allowed")
+)
+
+// ErrInvalidQueryParameters wraps validation failures of scooter queries
+var ErrInvalidQueryParameters = errors.New("invalid query parameters")
+
+// ErrInvalidAPIKeyRequest wraps validation failures of API key management requests
+var ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
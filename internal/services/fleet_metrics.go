@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fleetStatuses are reported on every scrape, at zero when no scooter has them, so dashboards see each series
+var fleetStatuses = []models.ScooterStatus{
+	models.ScooterStatusAvailable,
+	models.ScooterStatusOccupied,
+	models.ScooterStatusReserved,
+	models.ScooterStatusMaintenance,
+	models.ScooterStatusCharging,
+	models.ScooterStatusRetired,
+}
+
+// fleetCountTimeout bounds the counting queries of a scrape, so a slow database cannot hold the scraper
+const fleetCountTimeout = 5 * time.Second
+
+// fleetCollector counts scooters by status and active trips from the repositories on each scrape
+type fleetCollector struct {
+	scooterRepo repository.ScooterRepository
+	tripRepo    repository.TripRepository
+	scooters    *prometheus.Desc
+	activeTrips *prometheus.Desc
+}
+
+// RegisterFleetMetrics exposes the number of scooters in each status and of active trips, counted from the
+// repositories whenever the metrics are scraped
+func RegisterFleetMetrics(registerer prometheus.Registerer, scooterRepo repository.ScooterRepository, tripRepo repository.TripRepository) {
+	registerer.MustRegister(&fleetCollector{
+		scooterRepo: scooterRepo,
+		tripRepo:    tripRepo,
+		scooters:    prometheus.NewDesc("scootin_scooters", "Scooters in the fleet, by status.", []string{"status"}, nil),
+		activeTrips: prometheus.NewDesc("scootin_active_trips", "Trips in progress.", nil, nil),
+	})
+}
+
+func (f *fleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- f.scooters
+	ch <- f.activeTrips
+}
+
+// Collect reports each count separately, so a failing count leaves the other in the scrape
+func (f *fleetCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), fleetCountTimeout)
+	defer cancel()
+
+	if counts, err := f.scooterRepo.CountByStatus(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(f.scooters, err)
+	} else {
+		for _, status := range fleetStatuses {
+			ch <- prometheus.MustNewConstMetric(f.scooters, prometheus.GaugeValue, float64(counts[status]), string(status))
+		}
+	}
+
+	if count, err := f.tripRepo.CountActive(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(f.activeTrips, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(f.activeTrips, prometheus.GaugeValue, float64(count))
+	}
+}
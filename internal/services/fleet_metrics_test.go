@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository/mocks"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegisterFleetMetrics(t *testing.T) {
+	t.Run("counts scooters by status and active trips", func(t *testing.T) {
+		scooterRepo := &mocks.MockScooterRepository{}
+		tripRepo := &mocks.MockTripRepository{}
+		scooterRepo.On("CountByStatus", mock.Anything).Return(map[models.ScooterStatus]int64{
+			models.ScooterStatusAvailable: 12,
+			models.ScooterStatusOccupied:  3,
+		}, nil)
+		tripRepo.On("CountActive", mock.Anything).Return(int64(3), nil)
+
+		registry := prometheus.NewRegistry()
+		RegisterFleetMetrics(registry, scooterRepo, tripRepo)
+
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP scootin_active_trips Trips in progress.
+# TYPE scootin_active_trips gauge
+scootin_active_trips 3
+# HELP scootin_scooters Scooters in the fleet, by status.
+# TYPE scootin_scooters gauge
+scootin_scooters{status="available"} 12
+scootin_scooters{status="charging"} 0
+scootin_scooters{status="maintenance"} 0
+scootin_scooters{status="occupied"} 3
+scootin_scooters{status="reserved"} 0
+scootin_scooters{status="retired"} 0
+`)))
+	})
+
+	t.Run("a failing count leaves the others", func(t *testing.T) {
+		scooterRepo := &mocks.MockScooterRepository{}
+		tripRepo := &mocks.MockTripRepository{}
+		scooterRepo.On("CountByStatus", mock.Anything).Return(nil, errors.New("connection refused"))
+		tripRepo.On("CountActive", mock.Anything).Return(int64(1), nil)
+
+		registry := prometheus.NewRegistry()
+		RegisterFleetMetrics(registry, scooterRepo, tripRepo)
+
+		families, err := registry.Gather()
+		assert.ErrorContains(t, err, "connection refused")
+		if assert.Len(t, families, 1) {
+			assert.Equal(t, "scootin_active_trips", families[0].GetName())
+			assert.Equal(t, 1.0, families[0].GetMetric()[0].GetGauge().GetValue())
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/logger"
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TripEventPayload is the outbox payload for trip started, ended and cancelled events
+type TripEventPayload struct {
+	TripID         string     `json:"tripId"`
+	ScooterID      string     `json:"scooterId"`
+	UserID         string     `json:"userId"`
+	Status         string     `json:"status"`
+	StartTime      time.Time  `json:"startTime"`
+	EndTime        *time.Time `json:"endTime,omitempty"`
+	StartLatitude  float64    `json:"startLatitude"`
+	StartLongitude float64    `json:"startLongitude"`
+	EndLatitude    *float64   `json:"endLatitude,omitempty"`
+	EndLongitude   *float64   `json:"endLongitude,omitempty"`
+	// Fare is set on trip.ended events when pricing is configured
+	Fare *FarePayload `json:"fare,omitempty"`
+}
+
+// FarePayload is the fare breakdown of a completed trip, in minor units of the currency
+type FarePayload struct {
+	Currency       string  `json:"currency"`
+	Tariff         string  `json:"tariff"`
+	Minutes        int64   `json:"minutes"`
+	DistanceMeters float64 `json:"distanceMeters"`
+	UnlockFee      int64   `json:"unlockFee"`
+	TimeCharge     int64   `json:"timeCharge"`
+	DistanceCharge int64   `json:"distanceCharge"`
+	Adjustment     int64   `json:"adjustment"`
+	Total          int64   `json:"total"`
+}
+
+// ScooterStatusChangedPayload is the outbox payload for scooter status transitions
+type ScooterStatusChangedPayload struct {
+	ScooterID      string `json:"scooterId"`
+	PreviousStatus string `json:"previousStatus"`
+	Status         string `json:"status"`
+}
+
+// writeTripOutboxEvent records a trip lifecycle event in the outbox, in the same transaction as the trip change
+func writeTripOutboxEvent(ctx context.Context, tx repository.UnitOfWorkTx, eventType string, trip *models.Trip) error {
+	var fare *FarePayload
+	if trip.Fare != nil {
+		fare = &FarePayload{
+			Currency:       trip.Fare.Currency,
+			Tariff:         trip.Fare.Tariff,
+			Minutes:        trip.Fare.Minutes,
+			DistanceMeters: trip.Fare.DistanceMeters,
+			UnlockFee:      trip.Fare.UnlockFee,
+			TimeCharge:     trip.Fare.TimeCharge,
+			DistanceCharge: trip.Fare.DistanceCharge,
+			Adjustment:     trip.Fare.Adjustment,
+			Total:          trip.Fare.Total,
+		}
+	}
+
+	return writeOutboxEvent(ctx, tx, eventType, trip.ScooterID, TripEventPayload{
+		TripID:         trip.ID.String(),
+		ScooterID:      trip.ScooterID.String(),
+		UserID:         trip.UserID.String(),
+		Status:         string(trip.Status),
+		StartTime:      trip.StartTime,
+		EndTime:        trip.EndTime,
+		StartLatitude:  trip.StartLatitude,
+		StartLongitude: trip.StartLongitude,
+		EndLatitude:    trip.EndLatitude,
+		EndLongitude:   trip.EndLongitude,
+		Fare:           fare,
+	})
+}
+
+// writeScooterStatusOutboxEvent records a scooter status transition in the outbox
+func writeScooterStatusOutboxEvent(ctx context.Context, tx repository.UnitOfWorkTx, scooterID uuid.UUID, from, to models.ScooterStatus) error {
+	return writeOutboxEvent(ctx, tx, models.OutboxEventScooterStatusChanged, scooterID, ScooterStatusChangedPayload{
+		ScooterID:      scooterID.String(),
+		PreviousStatus: string(from),
+		Status:         string(to),
+	})
+}
+
+// writeOutboxEvent records an event in the outbox, keeping the request ID attached to ctx for the relay
+func writeOutboxEvent(ctx context.Context, tx repository.UnitOfWorkTx, eventType string, scooterID uuid.UUID, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s outbox event: %w", eventType, err)
+	}
+
+	var requestID *string
+	if id := logger.RequestID(ctx); id != "" {
+		requestID = &id
+	}
+
+	if err := tx.OutboxRepository().Create(ctx, &models.OutboxEvent{
+		AggregateID: scooterID,
+		EventType:   eventType,
+		Payload:     data,
+		RequestID:   requestID,
+	}); err != nil {
+		return fmt.Errorf("failed to write %s outbox event: %w", eventType, err)
+	}
+
+	return nil
+}
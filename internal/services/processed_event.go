@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+)
+
+type processedEventKey struct{}
+
+// WithProcessedEvent marks ctx as applying the given event, so the next transactional service call
+// records it in the processed events ledger and fails with repository.ErrEventAlreadyProcessed on redelivery
+func WithProcessedEvent(ctx context.Context, eventID, eventType string) context.Context {
+	if eventID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, processedEventKey{}, &models.ProcessedEvent{
+		EventID:   eventID,
+		EventType: eventType,
+	})
+}
+
+// ProcessedEventFromContext returns the event attached by WithProcessedEvent
+func ProcessedEventFromContext(ctx context.Context) (*models.ProcessedEvent, bool) {
+	event, ok := ctx.Value(processedEventKey{}).(*models.ProcessedEvent)
+	return event, ok
+}
+
+// recordProcessedEvent writes the event carried by ctx, if any, in the same transaction as the service changes
+func recordProcessedEvent(ctx context.Context, tx repository.UnitOfWorkTx) error {
+	event, ok := ProcessedEventFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if err := tx.ProcessedEventRepository().MarkProcessed(ctx, &models.ProcessedEvent{
+		EventID:   event.EventID,
+		EventType: event.EventType,
+	}); err != nil {
+		return fmt.Errorf("failed to record processed event %s: %w", event.EventID, err)
+	}
+
+	return nil
+}
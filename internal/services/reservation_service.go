@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
+
+	"github.com/google/uuid"
+)
+
+// expireBatchSize bounds how many reservations ExpireReservations releases per call
+const expireBatchSize = 100
+
+type ReservationService interface {
+	// ReserveScooter holds an available scooter for the user until the reservation expires
+	ReserveScooter(ctx context.Context, scooterID, userID uuid.UUID) (*models.Reservation, error)
+	// CancelReservation releases the scooter's active reservation. When userID is not uuid.Nil the
+	// reservation must belong to that user.
+	CancelReservation(ctx context.Context, scooterID, userID uuid.UUID) (*models.Reservation, error)
+	// ExpireReservations releases reservations that ran out by the given time and returns how many were released
+	ExpireReservations(ctx context.Context, at time.Time) (int, error)
+}
+
+type reservationService struct {
+	reservationRepo repository.ReservationRepository
+	unitOfWork      repository.UnitOfWork
+	holdDuration    time.Duration
+	// changes streams committed status changes to live subscribers; nil streams nothing
+	changes *stream.Hub
+}
+
+// NewReservationService returns a service whose reservations hold a scooter for holdDuration
+func NewReservationService(
+	reservationRepo repository.ReservationRepository,
+	unitOfWork repository.UnitOfWork,
+	holdDuration time.Duration,
+	changes *stream.Hub,
+) ReservationService {
+	return &reservationService{
+		reservationRepo: reservationRepo,
+		unitOfWork:      unitOfWork,
+		holdDuration:    holdDuration,
+		changes:         changes,
+	}
+}
+
+func (s *reservationService) ReserveScooter(ctx context.Context, scooterID, userID uuid.UUID) (*models.Reservation, error) {
+	now := time.Now()
+
+	// A reservation the sweeper has not released yet must not block the user. It is released in a
+	// transaction of its own so this one never holds the locks of two scooters.
+	held, err := s.reservationRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user's reservation: %w", err)
+	}
+	if held != nil && held.IsExpired(now) {
+		if _, err := s.expireReservation(ctx, held.ScooterID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var committed bool
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	reservationRepo := tx.ReservationRepository()
+	scooterRepo := tx.ScooterRepository()
+
+	user, err := tx.UserRepository().GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, repository.ErrUserNotFound
+	}
+
+	activeTrip, err := tx.TripRepository().GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user's active trip: %w", err)
+	}
+	if activeTrip != nil {
+		return nil, ErrUserHasActiveTrip
+	}
+
+	held, err = reservationRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user's reservation: %w", err)
+	}
+	if held != nil {
+		return nil, ErrUserHasReservation
+	}
+
+	scooter, err := scooterRepo.GetByIDForUpdate(ctx, scooterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scooter: %w", err)
+	}
+	if scooter == nil {
+		return nil, repository.ErrScooterNotFound
+	}
+
+	if scooter.IsReserved() {
+		existing, err := reservationRepo.GetActiveByScooterID(ctx, scooterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get scooter's reservation: %w", err)
+		}
+		if existing != nil && !existing.IsExpired(now) {
+			return nil, ErrScooterReserved
+		}
+		if err := releaseReservation(ctx, tx, scooter, existing, models.ReservationStatusExpired); err != nil {
+			return nil, err
+		}
+	}
+	if err := models.ValidateStatusTransition(scooter.Status, models.ScooterStatusReserved); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScooterNotAvailable, err)
+	}
+
+	reservation := &models.Reservation{
+		ScooterID: scooterID,
+		UserID:    userID,
+		Status:    models.ReservationStatusActive,
+		ExpiresAt: now.Add(s.holdDuration),
+	}
+
+	if err := reservationRepo.Create(ctx, reservation); err != nil {
+		return nil, fmt.Errorf("failed to create reservation: %w", err)
+	}
+
+	if err := scooterRepo.UpdateStatusWithCheck(ctx, scooterID, models.ScooterStatusReserved, models.ScooterStatusAvailable); err != nil {
+		return nil, fmt.Errorf("failed to update scooter status: %w", err)
+	}
+
+	if err := writeScooterStatusOutboxEvent(ctx, tx, scooterID, models.ScooterStatusAvailable, models.ScooterStatusReserved); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	committed = true
+	scooter.Status = models.ScooterStatusReserved
+	s.changes.Publish(scooterStatusEvent(scooter, models.ScooterStatusAvailable))
+	return reservation, nil
+}
+
+func (s *reservationService) CancelReservation(ctx context.Context, scooterID, userID uuid.UUID) (*models.Reservation, error) {
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var committed bool
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	scooter, err := tx.ScooterRepository().GetByIDForUpdate(ctx, scooterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scooter: %w", err)
+	}
+	if scooter == nil {
+		return nil, repository.ErrScooterNotFound
+	}
+
+	reservation, err := tx.ReservationRepository().GetActiveByScooterID(ctx, scooterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scooter's reservation: %w", err)
+	}
+	if reservation == nil {
+		return nil, ErrNoActiveReservation
+	}
+	if userID != uuid.Nil && reservation.UserID != userID {
+		return nil, ErrReservationNotOwned
+	}
+
+	wasReserved := scooter.IsReserved()
+	if err := releaseReservation(ctx, tx, scooter, reservation, models.ReservationStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	committed = true
+	if wasReserved {
+		s.changes.Publish(scooterStatusEvent(scooter, models.ScooterStatusReserved))
+	}
+	return reservation, nil
+}
+
+func (s *reservationService) ExpireReservations(ctx context.Context, at time.Time) (int, error) {
+	expired, err := s.reservationRepo.GetExpired(ctx, at, expireBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired reservations: %w", err)
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		ok, err := s.expireReservation(ctx, reservation.ScooterID, at)
+		if err != nil {
+			return released, fmt.Errorf("failed to expire reservation %s: %w", reservation.ID, err)
+		}
+		if ok {
+			released++
+		}
+	}
+
+	return released, nil
+}
+
+// expireReservation releases the scooter's active reservation if it ran out by the given time. It reports
+// false when the reservation was fulfilled, cancelled or expired by someone else in the meantime.
+func (s *reservationService) expireReservation(ctx context.Context, scooterID uuid.UUID, at time.Time) (bool, error) {
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var committed bool
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	// A deleted scooter has nothing to release, but its reservation still has to end
+	scooter, err := tx.ScooterRepository().GetByIDForUpdate(ctx, scooterID)
+	if err != nil && !errors.Is(err, repository.ErrScooterNotFound) {
+		return false, fmt.Errorf("failed to get scooter: %w", err)
+	}
+
+	reservation, err := tx.ReservationRepository().GetActiveByScooterID(ctx, scooterID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get scooter's reservation: %w", err)
+	}
+	if reservation == nil || !reservation.IsExpired(at) {
+		return false, nil
+	}
+
+	wasReserved := scooter != nil && scooter.IsReserved()
+	if err := releaseReservation(ctx, tx, scooter, reservation, models.ReservationStatusExpired); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	committed = true
+	if wasReserved {
+		s.changes.Publish(scooterStatusEvent(scooter, models.ScooterStatusReserved))
+	}
+	return true, nil
+}
+
+// releaseReservation ends a reservation with status and makes its scooter available again. The caller holds
+// the scooter's row lock; scooter is nil when it no longer exists and reservation is nil when a reserved
+// scooter has lost its reservation row.
+func releaseReservation(ctx context.Context, tx repository.UnitOfWorkTx, scooter *models.Scooter, reservation *models.Reservation, status models.ReservationStatus) error {
+	if reservation != nil {
+		if err := tx.ReservationRepository().End(ctx, reservation.ID, status); err != nil {
+			return fmt.Errorf("failed to end reservation: %w", err)
+		}
+		endedAt := time.Now()
+		reservation.Status = status
+		reservation.EndedAt = &endedAt
+	}
+
+	if scooter == nil || !scooter.IsReserved() {
+		return nil
+	}
+
+	if err := tx.ScooterRepository().UpdateStatusWithCheck(ctx, scooter.ID, models.ScooterStatusAvailable, models.ScooterStatusReserved); err != nil {
+		return fmt.Errorf("failed to update scooter status: %w", err)
+	}
+	scooter.Status = models.ScooterStatusAvailable
+
+	return writeScooterStatusOutboxEvent(ctx, tx, scooter.ID, models.ScooterStatusReserved, models.ScooterStatusAvailable)
+}
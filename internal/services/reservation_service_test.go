@@ -0,0 +1,171 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reservationFixture struct {
+	repo         repository.Repository
+	reservations ReservationService
+	trips        TripService
+	scooter      *models.Scooter
+	rider        *models.User
+	other        *models.User
+}
+
+func newReservationFixture(t *testing.T) *reservationFixture {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+
+	f := &reservationFixture{
+		repo:         repo,
+		reservations: NewReservationService(repo.Reservation(), repo.UnitOfWork(), 10*time.Minute, nil),
+		trips:        NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, nil, nil),
+		scooter:      &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude},
+		rider:        &models.User{},
+		other:        &models.User{},
+	}
+	require.NoError(t, repo.Scooter().Create(ctx, f.scooter))
+	require.NoError(t, repo.User().Create(ctx, f.rider))
+	require.NoError(t, repo.User().Create(ctx, f.other))
+	return f
+}
+
+func (f *reservationFixture) scooterStatus(t *testing.T) models.ScooterStatus {
+	scooter, err := f.repo.Scooter().GetByID(TestContext(), f.scooter.ID)
+	require.NoError(t, err)
+	return scooter.Status
+}
+
+func TestReservationService_ReserveScooter(t *testing.T) {
+	ctx := TestContext()
+	f := newReservationFixture(t)
+
+	reservation, err := f.reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReservationStatusActive, reservation.Status)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), reservation.ExpiresAt, time.Minute)
+	assert.Equal(t, models.ScooterStatusReserved, f.scooterStatus(t))
+
+	_, err = f.reservations.ReserveScooter(ctx, f.scooter.ID, f.other.ID)
+	assert.ErrorIs(t, err, ErrScooterReserved)
+
+	_, err = f.reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	assert.ErrorIs(t, err, ErrUserHasReservation)
+
+	_, err = f.reservations.ReserveScooter(ctx, uuid.New(), f.other.ID)
+	assert.ErrorIs(t, err, repository.ErrScooterNotFound)
+
+	outboxEvents, err := f.repo.Outbox().GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, outboxEvents, 1)
+	assert.Contains(t, string(outboxEvents[0].Payload), `"status":"reserved"`)
+}
+
+func TestReservationService_StartTripOnReservedScooter(t *testing.T) {
+	ctx := TestContext()
+	f := newReservationFixture(t)
+
+	reservation, err := f.reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+
+	_, err = f.trips.StartTrip(ctx, uuid.Nil, f.scooter.ID, f.other.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+	assert.ErrorIs(t, err, ErrScooterReserved)
+
+	trip, err := f.trips.StartTrip(ctx, uuid.Nil, f.scooter.ID, f.rider.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+	require.NoError(t, err)
+	assert.Equal(t, f.rider.ID, trip.UserID)
+	assert.Equal(t, models.ScooterStatusOccupied, f.scooterStatus(t))
+
+	active, err := f.repo.Reservation().GetActiveByUserID(ctx, f.rider.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active, "reservation %s should be fulfilled", reservation.ID)
+}
+
+func TestReservationService_CancelReservation(t *testing.T) {
+	ctx := TestContext()
+	f := newReservationFixture(t)
+
+	_, err := f.reservations.CancelReservation(ctx, f.scooter.ID, f.rider.ID)
+	assert.ErrorIs(t, err, ErrNoActiveReservation)
+
+	_, err = f.reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+
+	_, err = f.reservations.CancelReservation(ctx, f.scooter.ID, f.other.ID)
+	assert.ErrorIs(t, err, ErrReservationNotOwned)
+
+	cancelled, err := f.reservations.CancelReservation(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReservationStatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.EndedAt)
+	assert.Equal(t, models.ScooterStatusAvailable, f.scooterStatus(t))
+
+	// The scooter can be held again straight away
+	_, err = f.reservations.ReserveScooter(ctx, f.scooter.ID, f.other.ID)
+	assert.NoError(t, err)
+}
+
+func TestReservationService_ExpireReservations(t *testing.T) {
+	ctx := TestContext()
+	f := newReservationFixture(t)
+
+	reservation, err := f.reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+
+	released, err := f.reservations.ExpireReservations(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, released)
+	assert.Equal(t, models.ScooterStatusReserved, f.scooterStatus(t))
+
+	released, err = f.reservations.ExpireReservations(ctx, reservation.ExpiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, models.ScooterStatusAvailable, f.scooterStatus(t))
+
+	active, err := f.repo.Reservation().GetActiveByScooterID(ctx, f.scooter.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+}
+
+func TestReservationService_ExpiredHoldDoesNotBlock(t *testing.T) {
+	ctx := TestContext()
+	f := newReservationFixture(t)
+
+	// A zero hold expires immediately, before any sweep
+	reservations := NewReservationService(f.repo.Reservation(), f.repo.UnitOfWork(), 0, nil)
+	_, err := reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+
+	_, err = f.trips.StartTrip(ctx, uuid.Nil, f.scooter.ID, f.other.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusOccupied, f.scooterStatus(t))
+
+	// The rider's expired hold was ended along the way, so they can reserve another scooter
+	second := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude}
+	require.NoError(t, f.repo.Scooter().Create(ctx, second))
+	_, err = f.reservations.ReserveScooter(ctx, second.ID, f.rider.ID)
+	assert.NoError(t, err)
+}
+
+func TestReservationSweeper_Sweep(t *testing.T) {
+	ctx := TestContext()
+	f := newReservationFixture(t)
+
+	reservations := NewReservationService(f.repo.Reservation(), f.repo.UnitOfWork(), 0, nil)
+	_, err := reservations.ReserveScooter(ctx, f.scooter.ID, f.rider.ID)
+	require.NoError(t, err)
+
+	sweeper := NewReservationSweeper(reservations, time.Hour)
+	assert.Equal(t, 1, sweeper.Sweep(ctx))
+	assert.Equal(t, 0, sweeper.Sweep(ctx))
+	assert.Equal(t, models.ScooterStatusAvailable, f.scooterStatus(t))
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"scootin-aboot/internal/logger"
+)
+
+// ReservationSweeper periodically releases reservations that have run out, making their scooters available again
+type ReservationSweeper struct {
+	reservationService ReservationService
+	interval           time.Duration
+	ctx                context.Context
+	cancel             context.CancelFunc
+	wg                 sync.WaitGroup
+}
+
+func NewReservationSweeper(reservationService ReservationService, interval time.Duration) *ReservationSweeper {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sweeper := &ReservationSweeper{
+		reservationService: reservationService,
+		interval:           interval,
+		ctx:                ctx,
+		cancel:             cancel,
+	}
+	if sweeper.interval <= 0 {
+		sweeper.interval = 15 * time.Second
+	}
+
+	return sweeper
+}
+
+func (s *ReservationSweeper) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.Sweep(s.ctx)
+			}
+		}
+	}()
+
+	logger.Info("Reservation sweeper started", logger.String("interval", s.interval.String()))
+}
+
+func (s *ReservationSweeper) Stop() {
+	logger.Info("Stopping reservation sweeper...")
+	s.cancel()
+	s.wg.Wait()
+	logger.Info("Reservation sweeper stopped")
+}
+
+// Sweep releases every reservation that has run out, in batches, and returns how many were released
+func (s *ReservationSweeper) Sweep(ctx context.Context) int {
+	total := 0
+	for {
+		released, err := s.reservationService.ExpireReservations(ctx, time.Now())
+		total += released
+		if err != nil {
+			logger.Error("Failed to expire reservations", logger.ErrorField(err))
+			break
+		}
+		if released < expireBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info("Expired reservations", logger.Int("count", total))
+	}
+	return total
+}
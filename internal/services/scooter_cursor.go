@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// scooterCursorToken is the content of the opaque next_cursor returned by GetScooters.
+// It carries the ordering it was issued for, and the reference point of a distance sort, so it cannot be
+// replayed against a different sort or measured from a different point.
+type scooterCursorToken struct {
+	Sort       repository.ScooterSort `json:"s"`
+	Descending bool                   `json:"d,omitempty"`
+	Time       *time.Time             `json:"t,omitempty"`
+	Distance   *float64               `json:"x,omitempty"`
+	Latitude   *float64               `json:"lat,omitempty"`
+	Longitude  *float64               `json:"lng,omitempty"`
+	ID         uuid.UUID              `json:"id"`
+}
+
+func encodeScooterCursor(filter repository.ScooterFilter, cursor *repository.ScooterCursor) string {
+	token := scooterCursorToken{
+		Sort:       filter.Sort,
+		Descending: filter.Descending,
+		ID:         cursor.ID,
+	}
+	if filter.Sort == repository.ScooterSortDistance {
+		token.Distance = &cursor.Distance
+		token.Latitude = &filter.Latitude
+		token.Longitude = &filter.Longitude
+	} else {
+		token.Time = &cursor.Time
+	}
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeScooterCursor parses a cursor issued by encodeScooterCursor for the same ordering, and for a distance
+// sort the same reference point, as filter
+func decodeScooterCursor(filter repository.ScooterFilter, value string) (*repository.ScooterCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var token scooterCursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, errInvalidCursor
+	}
+	if token.Sort != filter.Sort || token.Descending != filter.Descending {
+		return nil, errors.New("cursor does not match the requested sort")
+	}
+
+	cursor := &repository.ScooterCursor{ID: token.ID}
+	switch {
+	case filter.Sort == repository.ScooterSortDistance && token.Distance != nil:
+		if token.Latitude == nil || token.Longitude == nil ||
+			*token.Latitude != filter.Latitude || *token.Longitude != filter.Longitude {
+			return nil, fmt.Errorf("%w: it was issued for a different reference point", errInvalidCursor)
+		}
+		cursor.Distance = *token.Distance
+	case filter.Sort != repository.ScooterSortDistance && token.Time != nil:
+		cursor.Time = *token.Time
+	default:
+		return nil, errInvalidCursor
+	}
+
+	return cursor, nil
+}
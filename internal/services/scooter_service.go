@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"time"
 
+	"scootin-aboot/internal/auth/device"
 	"scootin-aboot/internal/models"
 	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
 	"scootin-aboot/internal/validation"
 
 	"github.com/google/uuid"
@@ -17,7 +19,14 @@ type ScooterService interface {
 	GetScooters(ctx context.Context, params ScooterQueryParams) (*ScooterListResult, error)
 	GetScooter(ctx context.Context, id uuid.UUID) (*ScooterDetailsResult, error)
 	GetClosestScooters(ctx context.Context, params ClosestScootersQueryParams) (*ClosestScootersResult, error)
-	UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64) error
+	// UpdateLocation records a telemetry report; batteryLevel is nil when the scooter did not report its charge
+	UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64, batteryLevel *int) error
+
+	// RotateDeviceSecret issues a new device secret for the scooter, replacing any previous one.
+	// The secret is returned once; only its public key is stored.
+	RotateDeviceSecret(ctx context.Context, scooterID uuid.UUID) (string, error)
+	// GetDevicePublicKey returns the key that verifies the scooter's event signatures, or an empty string
+	GetDevicePublicKey(ctx context.Context, scooterID uuid.UUID) (string, error)
 }
 
 type scooterService struct {
@@ -25,6 +34,10 @@ type scooterService struct {
 	tripRepo     repository.TripRepository
 	locationRepo repository.LocationUpdateRepository
 	unitOfWork   repository.UnitOfWork
+	// searchBatteryThreshold keeps scooters reporting a lower charge out of closest scooter searches
+	searchBatteryThreshold int
+	// changes streams committed location reports to live subscribers; nil streams nothing
+	changes *stream.Hub
 }
 
 func NewScooterService(
@@ -32,30 +45,47 @@ func NewScooterService(
 	tripRepo repository.TripRepository,
 	locationRepo repository.LocationUpdateRepository,
 	unitOfWork repository.UnitOfWork,
+	searchBatteryThreshold int,
+	changes *stream.Hub,
 ) ScooterService {
 	return &scooterService{
-		scooterRepo:  scooterRepo,
-		tripRepo:     tripRepo,
-		locationRepo: locationRepo,
-		unitOfWork:   unitOfWork,
+		scooterRepo:            scooterRepo,
+		tripRepo:               tripRepo,
+		locationRepo:           locationRepo,
+		unitOfWork:             unitOfWork,
+		searchBatteryThreshold: searchBatteryThreshold,
+		changes:                changes,
 	}
 }
 
 type ScooterQueryParams struct {
-	Status string
-	MinLat float64
-	MaxLat float64
-	MinLng float64
-	MaxLng float64
+	Status         string
+	MinLat         float64
+	MaxLat         float64
+	MinLng         float64
+	MaxLng         float64
+	LastSeenAfter  time.Time
+	LastSeenBefore time.Time
+
+	// Sort is created_at (default), last_seen or distance; distance is measured from Latitude and Longitude.
+	// Order defaults to desc for timestamps and asc for distance.
+	Sort      string
+	Order     string
+	Latitude  *float64
+	Longitude *float64
+
+	// Cursor is the NextCursor of a previous page and cannot be combined with Offset
+	Cursor string
 	Limit  int
 	Offset int
 }
 
 type ScooterListResult struct {
-	Scooters []*ScooterInfo
-	Total    int64
-	Limit    int
-	Offset   int
+	Scooters   []*ScooterInfo
+	Total      int64
+	Limit      int
+	Offset     int
+	NextCursor string
 }
 
 type ScooterInfo struct {
@@ -63,6 +93,7 @@ type ScooterInfo struct {
 	Status           string    `json:"status"`
 	CurrentLatitude  float64   `json:"current_latitude"`
 	CurrentLongitude float64   `json:"current_longitude"`
+	BatteryLevel     *int      `json:"battery_level,omitempty"`
 	LastSeen         time.Time `json:"last_seen"`
 	CreatedAt        time.Time `json:"created_at"`
 }
@@ -72,6 +103,7 @@ type ScooterDetailsResult struct {
 	Status           string    `json:"status"`
 	CurrentLatitude  float64   `json:"current_latitude"`
 	CurrentLongitude float64   `json:"current_longitude"`
+	BatteryLevel     *int      `json:"battery_level,omitempty"`
 	LastSeen         time.Time `json:"last_seen"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
@@ -112,42 +144,35 @@ type Location struct {
 
 func (s *scooterService) GetScooters(ctx context.Context, params ScooterQueryParams) (*ScooterListResult, error) {
 	if err := s.validateScooterQueryParams(params); err != nil {
-		return nil, fmt.Errorf("invalid query parameters: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQueryParameters, err)
 	}
 
-	scooters, err := s.queryScootersByFilters(ctx, params)
+	filter, err := s.buildScooterFilter(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQueryParameters, err)
+	}
 
+	page, err := s.scooterRepo.Search(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query scooters: %w", err)
 	}
 
-	scooterInfos := make([]*ScooterInfo, len(scooters))
-	for i, scooter := range scooters {
+	scooterInfos := make([]*ScooterInfo, len(page.Scooters))
+	for i, scooter := range page.Scooters {
 		scooterInfos[i] = s.mapScooterToInfo(scooter)
 	}
 
-	total := int64(len(scooterInfos))
-
-	if params.Status == "" && (!s.hasLocationBounds(params)) {
-	} else {
-		start := params.Offset
-		end := start + params.Limit
-		if end > len(scooterInfos) {
-			end = len(scooterInfos)
-		}
-		if start > len(scooterInfos) {
-			scooterInfos = []*ScooterInfo{}
-		} else {
-			scooterInfos = scooterInfos[start:end]
-		}
-	}
-
-	return &ScooterListResult{
+	result := &ScooterListResult{
 		Scooters: scooterInfos,
-		Total:    total,
+		Total:    page.Total,
 		Limit:    params.Limit,
 		Offset:   params.Offset,
-	}, nil
+	}
+	if page.Next != nil {
+		result.NextCursor = encodeScooterCursor(filter, page.Next)
+	}
+
+	return result, nil
 }
 
 func (s *scooterService) GetScooter(ctx context.Context, id uuid.UUID) (*ScooterDetailsResult, error) {
@@ -180,6 +205,7 @@ func (s *scooterService) GetScooter(ctx context.Context, id uuid.UUID) (*Scooter
 		Status:           string(scooter.Status),
 		CurrentLatitude:  scooter.CurrentLatitude,
 		CurrentLongitude: scooter.CurrentLongitude,
+		BatteryLevel:     scooter.BatteryLevel,
 		LastSeen:         scooter.LastSeen,
 		CreatedAt:        scooter.CreatedAt,
 		UpdatedAt:        scooter.UpdatedAt,
@@ -189,10 +215,11 @@ func (s *scooterService) GetScooter(ctx context.Context, id uuid.UUID) (*Scooter
 
 func (s *scooterService) GetClosestScooters(ctx context.Context, params ClosestScootersQueryParams) (*ClosestScootersResult, error) {
 	if err := s.validateClosestScootersParams(params); err != nil {
-		return nil, fmt.Errorf("invalid query parameters: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQueryParameters, err)
 	}
 
-	scooters, err := s.scooterRepo.GetClosestWithRadius(ctx, params.Latitude, params.Longitude, params.Radius, params.Status, params.Limit)
+	// The radius is given in meters and the repository works in kilometres
+	scooters, err := s.scooterRepo.GetClosestWithRadius(ctx, params.Latitude, params.Longitude, params.Radius/1000, params.Status, s.searchBatteryThreshold, params.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query closest scooters: %w", err)
 	}
@@ -218,8 +245,8 @@ func (s *scooterService) GetClosestScooters(ctx context.Context, params ClosestS
 }
 
 func (s *scooterService) validateScooterQueryParams(params ScooterQueryParams) error {
-	if params.Status != "" && params.Status != "available" && params.Status != "occupied" {
-		return errors.New("status must be 'available' or 'occupied'")
+	if params.Status != "" && !models.ScooterStatus(params.Status).IsValid() {
+		return errors.New("status must be 'available', 'occupied', 'reserved', 'maintenance', 'charging' or 'retired'")
 	}
 
 	if err := repository.ValidateGeographicBounds(params.MinLat, params.MaxLat, params.MinLng, params.MaxLng); err != nil {
@@ -236,6 +263,31 @@ func (s *scooterService) validateScooterQueryParams(params ScooterQueryParams) e
 		return errors.New("limit cannot exceed 100")
 	}
 
+	if !params.LastSeenAfter.IsZero() && !params.LastSeenBefore.IsZero() && !params.LastSeenAfter.Before(params.LastSeenBefore) {
+		return errors.New("last_seen_after must be before last_seen_before")
+	}
+
+	switch repository.ScooterSort(params.Sort) {
+	case "", repository.ScooterSortCreatedAt, repository.ScooterSortLastSeen:
+	case repository.ScooterSortDistance:
+		if params.Latitude == nil || params.Longitude == nil {
+			return errors.New("lat and lng are required to sort by distance")
+		}
+		if err := validation.ValidateCoordinates(*params.Latitude, *params.Longitude); err != nil {
+			return err
+		}
+	default:
+		return errors.New("sort must be 'created_at', 'last_seen' or 'distance'")
+	}
+
+	if params.Order != "" && params.Order != "asc" && params.Order != "desc" {
+		return errors.New("order must be 'asc' or 'desc'")
+	}
+
+	if params.Cursor != "" && params.Offset > 0 {
+		return errors.New("cursor cannot be combined with offset")
+	}
+
 	return nil
 }
 
@@ -251,8 +303,8 @@ func (s *scooterService) validateClosestScootersParams(params ClosestScootersQue
 		return errors.New("radius cannot exceed 50000 meters")
 	}
 
-	if params.Status != "" && params.Status != "available" && params.Status != "occupied" {
-		return errors.New("status must be 'available' or 'occupied'")
+	if params.Status != "" && !models.ScooterStatus(params.Status).IsValid() {
+		return errors.New("status must be 'available', 'occupied', 'reserved', 'maintenance', 'charging' or 'retired'")
 	}
 
 	if params.Limit < 0 {
@@ -265,9 +317,14 @@ func (s *scooterService) validateClosestScootersParams(params ClosestScootersQue
 	return nil
 }
 
-func (s *scooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64) error {
+func (s *scooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64, batteryLevel *int) error {
 	if err := validation.ValidateCoordinates(lat, lng); err != nil {
-		return fmt.Errorf("invalid coordinates: %w", err)
+		return fmt.Errorf("%w: %v", ErrInvalidCoordinates, err)
+	}
+	if batteryLevel != nil {
+		if err := validation.ValidateBatteryLevel(*batteryLevel); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBatteryLevel, err)
+		}
 	}
 
 	tx, err := s.unitOfWork.Begin(ctx)
@@ -282,6 +339,10 @@ func (s *scooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID
 		}
 	}()
 
+	if err := recordProcessedEvent(ctx, tx); err != nil {
+		return err
+	}
+
 	scooterRepo := tx.ScooterRepository()
 	locationRepo := tx.LocationUpdateRepository()
 
@@ -294,10 +355,11 @@ func (s *scooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID
 	}
 
 	locationUpdate := &models.LocationUpdate{
-		ScooterID: scooterID,
-		Latitude:  lat,
-		Longitude: lng,
-		Timestamp: time.Now(),
+		ScooterID:    scooterID,
+		Latitude:     lat,
+		Longitude:    lng,
+		BatteryLevel: batteryLevel,
+		Timestamp:    time.Now(),
 	}
 
 	if err := locationRepo.Create(ctx, locationUpdate); err != nil {
@@ -308,44 +370,116 @@ func (s *scooterService) UpdateLocation(ctx context.Context, scooterID uuid.UUID
 		return fmt.Errorf("failed to update scooter location: %w", err)
 	}
 
+	if batteryLevel != nil {
+		if err := scooterRepo.UpdateBatteryLevel(ctx, scooterID, *batteryLevel); err != nil {
+			return fmt.Errorf("failed to update scooter battery level: %w", err)
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	committed = true
+	if batteryLevel == nil {
+		batteryLevel = scooter.BatteryLevel
+	}
+	s.changes.Publish(stream.ScooterEvent{
+		Type:         stream.EventTypeLocation,
+		ScooterID:    scooterID,
+		Status:       scooter.Status,
+		Latitude:     lat,
+		Longitude:    lng,
+		BatteryLevel: batteryLevel,
+		Timestamp:    locationUpdate.Timestamp,
+	})
 	return nil
 }
 
+func (s *scooterService) RotateDeviceSecret(ctx context.Context, scooterID uuid.UUID) (string, error) {
+	secret, err := device.GenerateSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate device secret: %w", err)
+	}
+
+	publicKey, err := device.PublicKey(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive device public key: %w", err)
+	}
+
+	if err := s.scooterRepo.SetDevicePublicKey(ctx, scooterID, publicKey); err != nil {
+		if errors.Is(err, repository.ErrScooterNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to store device key: %w", err)
+	}
+
+	return secret, nil
+}
+
+func (s *scooterService) GetDevicePublicKey(ctx context.Context, scooterID uuid.UUID) (string, error) {
+	publicKey, err := s.scooterRepo.GetDevicePublicKey(ctx, scooterID)
+	if err != nil {
+		if errors.Is(err, repository.ErrScooterNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to get device key: %w", err)
+	}
+	return publicKey, nil
+}
+
 func (s *scooterService) mapScooterToInfo(scooter *models.Scooter) *ScooterInfo {
 	return &ScooterInfo{
 		ID:               scooter.ID,
 		Status:           string(scooter.Status),
 		CurrentLatitude:  scooter.CurrentLatitude,
 		CurrentLongitude: scooter.CurrentLongitude,
+		BatteryLevel:     scooter.BatteryLevel,
 		LastSeen:         scooter.LastSeen,
 		CreatedAt:        scooter.CreatedAt,
 	}
 }
 
-func (s *scooterService) queryScootersByFilters(ctx context.Context, params ScooterQueryParams) ([]*models.Scooter, error) {
-	hasStatusFilter := params.Status != ""
-	hasLocationFilter := s.hasLocationBounds(params)
-
-	switch {
-	case hasStatusFilter && hasLocationFilter:
-		status := models.ScooterStatus(params.Status)
-		return s.scooterRepo.GetByStatusInBounds(ctx, status, params.MinLat, params.MaxLat, params.MinLng, params.MaxLng)
+// buildScooterFilter translates validated query parameters into a repository filter
+func (s *scooterService) buildScooterFilter(params ScooterQueryParams) (repository.ScooterFilter, error) {
+	filter := repository.ScooterFilter{
+		Status:         models.ScooterStatus(params.Status),
+		LastSeenAfter:  params.LastSeenAfter,
+		LastSeenBefore: params.LastSeenBefore,
+		Sort:           repository.ScooterSort(params.Sort),
+		Limit:          params.Limit,
+		Offset:         params.Offset,
+	}
 
-	case hasStatusFilter:
-		status := models.ScooterStatus(params.Status)
-		return s.scooterRepo.GetByStatus(ctx, status)
+	if s.hasLocationBounds(params) {
+		filter.Bounds = &repository.BoundingBox{
+			MinLat: params.MinLat,
+			MaxLat: params.MaxLat,
+			MinLng: params.MinLng,
+			MaxLng: params.MaxLng,
+		}
+	}
 
-	case hasLocationFilter:
-		return s.scooterRepo.GetInBounds(ctx, params.MinLat, params.MaxLat, params.MinLng, params.MaxLng)
+	if filter.Sort == "" {
+		filter.Sort = repository.ScooterSortCreatedAt
+	}
+	if filter.Sort == repository.ScooterSortDistance {
+		filter.Latitude = *params.Latitude
+		filter.Longitude = *params.Longitude
+		filter.Descending = params.Order == "desc"
+	} else {
+		filter.Descending = params.Order != "asc"
+	}
 
-	default:
-		return s.scooterRepo.List(ctx, params.Limit, params.Offset)
+	if params.Cursor != "" {
+		cursor, err := decodeScooterCursor(filter, params.Cursor)
+		if err != nil {
+			return repository.ScooterFilter{}, err
+		}
+		filter.After = cursor
 	}
+
+	return filter, nil
 }
 
 func (s *scooterService) hasLocationBounds(params ScooterQueryParams) bool {
@@ -1,13 +1,18 @@
 package services
 
 import (
+	"strings"
 	"testing"
 
+	"scootin-aboot/internal/auth/device"
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
 	"scootin-aboot/internal/repository/mocks"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewScooterService(t *testing.T) {
@@ -71,8 +76,12 @@ func TestScooterService_GetScooters_Pagination(t *testing.T) {
 		Offset: 10,
 	}
 
-	testScooters := GetTestScooters(15)
-	scooterRepo.On("GetByStatus", mock.Anything, mock.Anything).Return(testScooters, nil)
+	testScooters := GetTestScooters(5)
+	last := testScooters[4]
+	next := &repository.ScooterCursor{Time: last.CreatedAt, ID: last.ID}
+	scooterRepo.On("Search", mock.Anything, mock.MatchedBy(func(filter repository.ScooterFilter) bool {
+		return filter.Limit == 5 && filter.Offset == 10 && filter.After == nil
+	})).Return(&repository.ScooterPage{Scooters: testScooters, Total: 15, Next: next}, nil)
 
 	result, err := service.GetScooters(TestContext(), params)
 
@@ -82,10 +91,54 @@ func TestScooterService_GetScooters_Pagination(t *testing.T) {
 	assert.Equal(t, int64(15), result.Total)
 	assert.Equal(t, 5, result.Limit)
 	assert.Equal(t, 10, result.Offset)
+	assert.NotEmpty(t, result.NextCursor)
+
+	// The cursor resumes after the last scooter of the page
+	scooterRepo.On("Search", mock.Anything, mock.MatchedBy(func(filter repository.ScooterFilter) bool {
+		return filter.After != nil && filter.After.ID == last.ID && filter.After.Time.Equal(last.CreatedAt)
+	})).Return(&repository.ScooterPage{Total: 15}, nil)
+
+	result, err = service.GetScooters(TestContext(), ScooterQueryParams{Status: "available", Limit: 5, Cursor: result.NextCursor})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.NextCursor)
+
+	// A cursor only applies to the ordering it was issued for
+	_, err = service.GetScooters(TestContext(), ScooterQueryParams{Status: "available", Limit: 5, Order: "asc", Cursor: encodeScooterCursor(repository.ScooterFilter{Sort: repository.ScooterSortCreatedAt, Descending: true}, next)})
+	assert.ErrorIs(t, err, ErrInvalidQueryParameters)
 
 	scooterRepo.AssertExpectations(t)
 }
 
+func TestScooterService_GetScooters_DistanceCursorKeepsReferencePoint(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	service := NewScooterService(repo.Scooter(), repo.Trip(), repo.LocationUpdate(), repo.UnitOfWork(), TestSearchBatteryThreshold, nil)
+
+	for i := 1; i <= 3; i++ {
+		scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude + float64(i)*0.001, CurrentLongitude: TestData.ValidLongitude}
+		require.NoError(t, repo.Scooter().Create(ctx, scooter))
+	}
+
+	lat, lng := TestData.ValidLatitude, TestData.ValidLongitude
+	params := ScooterQueryParams{Sort: "distance", Latitude: &lat, Longitude: &lng, Limit: 2}
+	first, err := service.GetScooters(ctx, params)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.NextCursor)
+
+	params.Cursor = first.NextCursor
+	second, err := service.GetScooters(ctx, params)
+	require.NoError(t, err)
+	assert.Len(t, second.Scooters, 1)
+
+	// Distances from another point are not comparable with the one in the cursor
+	otherLat := lat + 0.01
+	params.Latitude = &otherLat
+	_, err = service.GetScooters(ctx, params)
+	assert.ErrorIs(t, err, ErrInvalidQueryParameters)
+	assert.ErrorIs(t, err, errInvalidCursor)
+}
+
 func TestScooterService_GetScooter(t *testing.T) {
 	testCases := &ScooterTestCases{}
 	cases := testCases.GetScooterTestCases()
@@ -146,6 +199,33 @@ func TestScooterService_GetClosestScooters(t *testing.T) {
 	}
 }
 
+// The radius and distances are in meters while the repository searches in kilometres; treating meters as
+// kilometres would return both scooters below
+func TestScooterService_GetClosestScooters_RadiusInMeters(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	service := NewScooterService(repo.Scooter(), repo.Trip(), repo.LocationUpdate(), repo.UnitOfWork(), TestSearchBatteryThreshold, nil)
+
+	// 0.0027 and 0.0108 degrees of latitude are about 300 m and 1.2 km
+	near := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude + 0.0027, CurrentLongitude: TestData.ValidLongitude}
+	far := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude + 0.0108, CurrentLongitude: TestData.ValidLongitude}
+	require.NoError(t, repo.Scooter().Create(ctx, near))
+	require.NoError(t, repo.Scooter().Create(ctx, far))
+
+	result, err := service.GetClosestScooters(ctx, ClosestScootersQueryParams{
+		Latitude:  TestData.ValidLatitude,
+		Longitude: TestData.ValidLongitude,
+		Radius:    1000,
+		Limit:     10,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, result.Scooters, 1)
+	assert.Equal(t, near.ID, result.Scooters[0].ID)
+	assert.InDelta(t, 300, result.Scooters[0].Distance, 5, "distances are reported in meters")
+	assert.Equal(t, 1000.0, result.Radius)
+}
+
 func TestScooterService_GetClosestScooters_InvalidParams(t *testing.T) {
 	invalidParams := GetInvalidClosestScootersQueryParams()
 	mockSetup := &MockSetup{}
@@ -174,7 +254,7 @@ func TestScooterService_UpdateLocation(t *testing.T) {
 
 			tc.SetupMocks(scooterRepo, locationRepo, unitOfWork, mockTx)
 
-			err := service.UpdateLocation(TestContext(), tc.ScooterID, tc.Latitude, tc.Longitude)
+			err := service.UpdateLocation(TestContext(), tc.ScooterID, tc.Latitude, tc.Longitude, tc.BatteryLevel)
 
 			if tc.ExpectedError != "" {
 				assert.Error(t, err)
@@ -191,6 +271,40 @@ func TestScooterService_UpdateLocation(t *testing.T) {
 	}
 }
 
+func TestScooterService_RotateDeviceSecret(t *testing.T) {
+	scooterID := uuid.New()
+
+	t.Run("stores only the public key of the new secret", func(t *testing.T) {
+		mockSetup := &MockSetup{}
+		service, scooterRepo, _, _, _ := mockSetup.CreateTestScooterService()
+
+		var storedKey string
+		scooterRepo.On("SetDevicePublicKey", mock.Anything, scooterID, mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { storedKey = args.String(2) }).
+			Return(nil)
+
+		secret, err := service.RotateDeviceSecret(TestContext(), scooterID)
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(secret, "dev_"))
+		publicKey, err := device.PublicKey(secret)
+		require.NoError(t, err)
+		assert.Equal(t, publicKey, storedKey)
+		assert.NotContains(t, storedKey, strings.TrimPrefix(secret, "dev_"))
+		scooterRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown scooter", func(t *testing.T) {
+		mockSetup := &MockSetup{}
+		service, scooterRepo, _, _, _ := mockSetup.CreateTestScooterService()
+		scooterRepo.On("SetDevicePublicKey", mock.Anything, scooterID, mock.AnythingOfType("string")).Return(repository.ErrScooterNotFound)
+
+		_, err := service.RotateDeviceSecret(TestContext(), scooterID)
+
+		assert.ErrorIs(t, err, repository.ErrScooterNotFound)
+	})
+}
+
 // Validation tests
 func TestScooterService_ValidateScooterQueryParams(t *testing.T) {
 	service := &scooterService{}
@@ -205,7 +319,7 @@ func TestScooterService_ValidateScooterQueryParams(t *testing.T) {
 		params := ScooterQueryParams{Status: "invalid", Limit: 10, Offset: 0}
 		err := service.validateScooterQueryParams(params)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "status must be 'available' or 'occupied'")
+		assert.Contains(t, err.Error(), "status must be 'available', 'occupied', 'reserved', 'maintenance', 'charging' or 'retired'")
 	})
 
 	t.Run("negative limit", func(t *testing.T) {
@@ -285,7 +399,7 @@ func TestScooterService_ValidateClosestScootersParams(t *testing.T) {
 		}
 		err := service.validateClosestScootersParams(params)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "status must be 'available' or 'occupied'")
+		assert.Contains(t, err.Error(), "status must be 'available', 'occupied', 'reserved', 'maintenance', 'charging' or 'retired'")
 	})
 
 	t.Run("excessive limit", func(t *testing.T) {
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
+
+	"github.com/google/uuid"
+)
+
+// maxStatusReasonLength bounds the reason recorded with a status change
+const maxStatusReasonLength = 500
+
+type ScooterStatusService interface {
+	// ChangeStatus moves the scooter to status and records the change with reason and who made it. Only
+	// available, maintenance, charging and retired can be set this way; trips and reservations drive the rest.
+	ChangeStatus(ctx context.Context, scooterID uuid.UUID, status models.ScooterStatus, reason, changedBy string) (*models.ScooterStatusChange, error)
+	// GetStatusHistory returns the scooter's recorded status changes, newest first
+	GetStatusHistory(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error)
+}
+
+type scooterStatusService struct {
+	scooterRepo repository.ScooterRepository
+	historyRepo repository.ScooterStatusHistoryRepository
+	unitOfWork  repository.UnitOfWork
+	// changes streams committed status changes to live subscribers; nil streams nothing
+	changes *stream.Hub
+}
+
+func NewScooterStatusService(
+	scooterRepo repository.ScooterRepository,
+	historyRepo repository.ScooterStatusHistoryRepository,
+	unitOfWork repository.UnitOfWork,
+	changes *stream.Hub,
+) ScooterStatusService {
+	return &scooterStatusService{
+		scooterRepo: scooterRepo,
+		historyRepo: historyRepo,
+		unitOfWork:  unitOfWork,
+		changes:     changes,
+	}
+}
+
+func (s *scooterStatusService) ChangeStatus(ctx context.Context, scooterID uuid.UUID, status models.ScooterStatus, reason, changedBy string) (*models.ScooterStatusChange, error) {
+	reason = strings.TrimSpace(reason)
+	if err := validateStatusChange(status, reason); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStatusChange, err)
+	}
+
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var committed bool
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	scooterRepo := tx.ScooterRepository()
+
+	scooter, err := scooterRepo.GetByIDForUpdate(ctx, scooterID)
+	if err != nil {
+		if errors.Is(err, repository.ErrScooterNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get scooter: %w", err)
+	}
+
+	from := scooter.Status
+	if (from == models.ScooterStatusOccupied || from == models.ScooterStatusReserved) && status == models.ScooterStatusAvailable {
+		// Releasing a ridden or reserved scooter would strand its trip or reservation
+		return nil, fmt.Errorf("%w: scooter is %s", ErrStatusTransitionNotAllowed, from)
+	}
+	if err := scooter.SetStatus(status); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStatusTransitionNotAllowed, err)
+	}
+
+	if err := scooterRepo.UpdateStatusWithCheck(ctx, scooterID, status, from); err != nil {
+		return nil, fmt.Errorf("failed to update scooter status: %w", err)
+	}
+
+	change := &models.ScooterStatusChange{
+		ScooterID:  scooterID,
+		FromStatus: from,
+		ToStatus:   status,
+		Reason:     reason,
+		ChangedBy:  changedBy,
+	}
+	if err := tx.ScooterStatusHistoryRepository().Create(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to record status change: %w", err)
+	}
+
+	if err := writeScooterStatusOutboxEvent(ctx, tx, scooterID, from, status); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	committed = true
+	s.changes.Publish(scooterStatusEvent(scooter, from))
+	return change, nil
+}
+
+func (s *scooterStatusService) GetStatusHistory(ctx context.Context, scooterID uuid.UUID, limit, offset int) ([]*models.ScooterStatusChange, error) {
+	if limit < 0 || limit > 100 {
+		return nil, fmt.Errorf("%w: limit must be between 0 and 100", ErrInvalidQueryParameters)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset must be non-negative", ErrInvalidQueryParameters)
+	}
+
+	if _, err := s.scooterRepo.GetByID(ctx, scooterID); err != nil {
+		if errors.Is(err, repository.ErrScooterNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get scooter: %w", err)
+	}
+
+	changes, err := s.historyRepo.ListByScooterID(ctx, scooterID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+	return changes, nil
+}
+
+func validateStatusChange(status models.ScooterStatus, reason string) error {
+	switch status {
+	case models.ScooterStatusAvailable, models.ScooterStatusMaintenance, models.ScooterStatusCharging, models.ScooterStatusRetired:
+	case models.ScooterStatusOccupied, models.ScooterStatusReserved:
+		return fmt.Errorf("status %s is set by trips and reservations", status)
+	default:
+		return errors.New("status must be 'available', 'maintenance', 'charging' or 'retired'")
+	}
+
+	if reason == "" {
+		return errors.New("reason is required")
+	}
+	if len(reason) > maxStatusReasonLength {
+		return fmt.Errorf("reason cannot exceed %d characters", maxStatusReasonLength)
+	}
+
+	return nil
+}
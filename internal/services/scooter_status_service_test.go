@@ -0,0 +1,141 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatusFixture(t *testing.T, status models.ScooterStatus) (repository.Repository, ScooterStatusService, *models.Scooter) {
+	repo := repository.NewMemoryRepository()
+	scooter := &models.Scooter{Status: status, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude}
+	require.NoError(t, repo.Scooter().Create(TestContext(), scooter))
+
+	return repo, NewScooterStatusService(repo.Scooter(), repo.ScooterStatusHistory(), repo.UnitOfWork(), nil), scooter
+}
+
+func TestScooterStatusService_ChangeStatus(t *testing.T) {
+	ctx := TestContext()
+	repo, service, scooter := newStatusFixture(t, models.ScooterStatusAvailable)
+
+	change, err := service.ChangeStatus(ctx, scooter.ID, models.ScooterStatusMaintenance, "  brake check ", "fleet-ops")
+	require.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusAvailable, change.FromStatus)
+	assert.Equal(t, models.ScooterStatusMaintenance, change.ToStatus)
+	assert.Equal(t, "brake check", change.Reason)
+	assert.Equal(t, "fleet-ops", change.ChangedBy)
+
+	_, err = service.ChangeStatus(ctx, scooter.ID, models.ScooterStatusCharging, "battery swap", "fleet-ops")
+	require.NoError(t, err)
+	_, err = service.ChangeStatus(ctx, scooter.ID, models.ScooterStatusAvailable, "back on the street", "fleet-ops")
+	require.NoError(t, err)
+
+	stored, err := repo.Scooter().GetByID(ctx, scooter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusAvailable, stored.Status)
+
+	history, err := service.GetStatusHistory(ctx, scooter.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, models.ScooterStatusAvailable, history[0].ToStatus)
+	assert.Equal(t, models.ScooterStatusMaintenance, history[2].ToStatus)
+
+	outboxEvents, err := repo.Outbox().GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, outboxEvents, 3)
+}
+
+func TestScooterStatusService_ChangeStatusRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    models.ScooterStatus
+		to      models.ScooterStatus
+		reason  string
+		wantErr error
+	}{
+		{
+			name:    "missing reason",
+			from:    models.ScooterStatusAvailable,
+			to:      models.ScooterStatusMaintenance,
+			reason:  "   ",
+			wantErr: ErrInvalidStatusChange,
+		},
+		{
+			name:    "reason too long",
+			from:    models.ScooterStatusAvailable,
+			to:      models.ScooterStatusMaintenance,
+			reason:  strings.Repeat("a", maxStatusReasonLength+1),
+			wantErr: ErrInvalidStatusChange,
+		},
+		{
+			name:    "status driven by trips",
+			from:    models.ScooterStatusAvailable,
+			to:      models.ScooterStatusOccupied,
+			reason:  "manual",
+			wantErr: ErrInvalidStatusChange,
+		},
+		{
+			name:    "unknown status",
+			from:    models.ScooterStatusAvailable,
+			to:      "broken",
+			reason:  "manual",
+			wantErr: ErrInvalidStatusChange,
+		},
+		{
+			name:    "retired scooters stay retired",
+			from:    models.ScooterStatusRetired,
+			to:      models.ScooterStatusAvailable,
+			reason:  "changed our mind",
+			wantErr: ErrStatusTransitionNotAllowed,
+		},
+		{
+			name:    "scooter on a trip",
+			from:    models.ScooterStatusOccupied,
+			to:      models.ScooterStatusMaintenance,
+			reason:  "flat tyre",
+			wantErr: ErrStatusTransitionNotAllowed,
+		},
+		{
+			name:    "releasing a reserved scooter",
+			from:    models.ScooterStatusReserved,
+			to:      models.ScooterStatusAvailable,
+			reason:  "stuck reservation",
+			wantErr: ErrStatusTransitionNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := TestContext()
+			repo, service, scooter := newStatusFixture(t, tt.from)
+
+			_, err := service.ChangeStatus(ctx, scooter.ID, tt.to, tt.reason, "fleet-ops")
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			stored, err := repo.Scooter().GetByID(ctx, scooter.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.from, stored.Status)
+
+			history, err := service.GetStatusHistory(ctx, scooter.ID, 10, 0)
+			require.NoError(t, err)
+			assert.Empty(t, history)
+		})
+	}
+}
+
+func TestScooterStatusService_UnknownScooter(t *testing.T) {
+	ctx := TestContext()
+	_, service, _ := newStatusFixture(t, models.ScooterStatusAvailable)
+
+	_, err := service.ChangeStatus(ctx, uuid.New(), models.ScooterStatusMaintenance, "brake check", "fleet-ops")
+	assert.ErrorIs(t, err, repository.ErrScooterNotFound)
+
+	_, err = service.GetStatusHistory(ctx, uuid.New(), 10, 0)
+	assert.ErrorIs(t, err, repository.ErrScooterNotFound)
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/stream"
+)
+
+// scooterStatusEvent describes a committed status change of the scooter, which already holds the new status
+func scooterStatusEvent(scooter *models.Scooter, from models.ScooterStatus) stream.ScooterEvent {
+	return stream.ScooterEvent{
+		Type:           stream.EventTypeStatus,
+		ScooterID:      scooter.ID,
+		Status:         scooter.Status,
+		PreviousStatus: from,
+		Latitude:       scooter.CurrentLatitude,
+		Longitude:      scooter.CurrentLongitude,
+		BatteryLevel:   scooter.BatteryLevel,
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain returns the events published so far
+func drain(subscription *stream.Subscription) []stream.ScooterEvent {
+	var events []stream.ScooterEvent
+	for {
+		select {
+		case event := <-subscription.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestServices_PublishScooterChanges(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	hub := stream.NewHub(100, 100)
+	subscription, _, _ := hub.Subscribe("")
+	defer subscription.Close()
+
+	scooters := NewScooterService(repo.Scooter(), repo.Trip(), repo.LocationUpdate(), repo.UnitOfWork(), TestSearchBatteryThreshold, hub)
+	trips := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, nil, hub)
+	reservations := NewReservationService(repo.Reservation(), repo.UnitOfWork(), 10*time.Minute, hub)
+	statuses := NewScooterStatusService(repo.Scooter(), repo.ScooterStatusHistory(), repo.UnitOfWork(), hub)
+
+	battery := 90
+	scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude, BatteryLevel: &battery}
+	require.NoError(t, repo.Scooter().Create(ctx, scooter))
+	user := &models.User{}
+	require.NoError(t, repo.User().Create(ctx, user))
+
+	t.Run("location reports", func(t *testing.T) {
+		require.NoError(t, scooters.UpdateLocation(ctx, scooter.ID, 45.4250, -75.6900, nil))
+
+		events := drain(subscription)
+		require.Len(t, events, 1)
+		assert.Equal(t, stream.EventTypeLocation, events[0].Type)
+		assert.Equal(t, 45.4250, events[0].Latitude)
+		assert.Equal(t, models.ScooterStatusAvailable, events[0].Status)
+		require.NotNil(t, events[0].BatteryLevel, "the stored battery level is kept when the report has none")
+		assert.Equal(t, 90, *events[0].BatteryLevel)
+	})
+
+	t.Run("reservations and trips", func(t *testing.T) {
+		_, err := reservations.ReserveScooter(ctx, scooter.ID, user.ID)
+		require.NoError(t, err)
+		_, err = reservations.CancelReservation(ctx, scooter.ID, user.ID)
+		require.NoError(t, err)
+		_, err = trips.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, 45.4250, -75.6900)
+		require.NoError(t, err)
+		_, err = trips.EndTrip(ctx, uuid.Nil, scooter.ID, 45.4300, -75.6950)
+		require.NoError(t, err)
+
+		events := drain(subscription)
+		require.Len(t, events, 4)
+		transitions := make([][2]models.ScooterStatus, len(events))
+		for i, event := range events {
+			assert.Equal(t, stream.EventTypeStatus, event.Type)
+			transitions[i] = [2]models.ScooterStatus{event.PreviousStatus, event.Status}
+		}
+		assert.Equal(t, [][2]models.ScooterStatus{
+			{models.ScooterStatusAvailable, models.ScooterStatusReserved},
+			{models.ScooterStatusReserved, models.ScooterStatusAvailable},
+			{models.ScooterStatusAvailable, models.ScooterStatusOccupied},
+			{models.ScooterStatusOccupied, models.ScooterStatusAvailable},
+		}, transitions)
+		assert.Equal(t, 45.4300, events[3].Latitude, "a trip's scooter is where the trip ended")
+	})
+
+	t.Run("admin status changes", func(t *testing.T) {
+		_, err := statuses.ChangeStatus(ctx, scooter.ID, models.ScooterStatusMaintenance, "brake check", "fleet-ops")
+		require.NoError(t, err)
+
+		events := drain(subscription)
+		require.Len(t, events, 1)
+		assert.Equal(t, models.ScooterStatusMaintenance, events[0].Status)
+	})
+
+	t.Run("nothing for failed changes", func(t *testing.T) {
+		_, err := trips.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, 45.4250, -75.6900)
+		require.Error(t, err)
+
+		assert.Empty(t, drain(subscription))
+	})
+}
@@ -32,7 +32,13 @@ func (tc *ScooterTestCases) GetScootersTestCases() []struct {
 			Params:        GetValidScooterQueryParams(),
 			ExpectedError: "",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("List", mock.Anything, TestData.ValidLimit, TestData.ValidOffset).Return(GetTestScooters(1), nil)
+				filter := repository.ScooterFilter{
+					Sort:       repository.ScooterSortCreatedAt,
+					Descending: true,
+					Limit:      TestData.ValidLimit,
+					Offset:     TestData.ValidOffset,
+				}
+				repo.On("Search", mock.Anything, filter).Return(&repository.ScooterPage{Scooters: GetTestScooters(1), Total: 1}, nil)
 			},
 		},
 		{
@@ -40,7 +46,9 @@ func (tc *ScooterTestCases) GetScootersTestCases() []struct {
 			Params:        GetValidScooterQueryParamsWithStatus("available"),
 			ExpectedError: "",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("GetByStatus", mock.Anything, models.ScooterStatusAvailable).Return(GetTestScootersWithStatus(1, models.ScooterStatusAvailable), nil)
+				repo.On("Search", mock.Anything, mock.MatchedBy(func(filter repository.ScooterFilter) bool {
+					return filter.Status == models.ScooterStatusAvailable && filter.Bounds == nil
+				})).Return(&repository.ScooterPage{Scooters: GetTestScootersWithStatus(1, models.ScooterStatusAvailable), Total: 1}, nil)
 			},
 		},
 		{
@@ -48,7 +56,9 @@ func (tc *ScooterTestCases) GetScootersTestCases() []struct {
 			Params:        GetValidScooterQueryParamsWithBounds(),
 			ExpectedError: "",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("GetInBounds", mock.Anything, mock.AnythingOfType("float64"), mock.AnythingOfType("float64"), mock.AnythingOfType("float64"), mock.AnythingOfType("float64")).Return(GetTestScooters(1), nil)
+				repo.On("Search", mock.Anything, mock.MatchedBy(func(filter repository.ScooterFilter) bool {
+					return filter.Status == "" && filter.Bounds != nil && filter.Bounds.MinLat == TestData.ValidLatitude-0.1
+				})).Return(&repository.ScooterPage{Scooters: GetTestScooters(1), Total: 1}, nil)
 			},
 		},
 		{
@@ -60,7 +70,26 @@ func (tc *ScooterTestCases) GetScootersTestCases() []struct {
 			}(),
 			ExpectedError: "",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("GetByStatusInBounds", mock.Anything, models.ScooterStatusAvailable, mock.AnythingOfType("float64"), mock.AnythingOfType("float64"), mock.AnythingOfType("float64"), mock.AnythingOfType("float64")).Return(GetTestScooters(1), nil)
+				repo.On("Search", mock.Anything, mock.MatchedBy(func(filter repository.ScooterFilter) bool {
+					return filter.Status == models.ScooterStatusAvailable && filter.Bounds != nil
+				})).Return(&repository.ScooterPage{Scooters: GetTestScooters(1), Total: 1}, nil)
+			},
+		},
+		{
+			Name: "successful get sorted by distance",
+			Params: func() ScooterQueryParams {
+				p := GetValidScooterQueryParams()
+				p.Sort = "distance"
+				p.Latitude = &TestData.ValidLatitude
+				p.Longitude = &TestData.ValidLongitude
+				return p
+			}(),
+			ExpectedError: "",
+			SetupMocks: func(repo *mocks.MockScooterRepository) {
+				repo.On("Search", mock.Anything, mock.MatchedBy(func(filter repository.ScooterFilter) bool {
+					return filter.Sort == repository.ScooterSortDistance && !filter.Descending &&
+						filter.Latitude == TestData.ValidLatitude && filter.Longitude == TestData.ValidLongitude
+				})).Return(&repository.ScooterPage{Scooters: GetTestScooters(1), Total: 1}, nil)
 			},
 		},
 		{
@@ -68,9 +97,19 @@ func (tc *ScooterTestCases) GetScootersTestCases() []struct {
 			Params:        GetValidScooterQueryParams(),
 			ExpectedError: "failed to query scooters",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("List", mock.Anything, TestData.ValidLimit, TestData.ValidOffset).Return([]*models.Scooter(nil), errors.New("database connection failed"))
+				repo.On("Search", mock.Anything, mock.AnythingOfType("repository.ScooterFilter")).Return(nil, errors.New("database connection failed"))
 			},
 		},
+		{
+			Name: "invalid cursor",
+			Params: func() ScooterQueryParams {
+				p := GetValidScooterQueryParams()
+				p.Cursor = "not-a-cursor"
+				return p
+			}(),
+			ExpectedError: "invalid cursor",
+			SetupMocks:    func(repo *mocks.MockScooterRepository) {},
+		},
 	}
 }
 
@@ -155,7 +194,7 @@ func (tc *ScooterTestCases) GetClosestScootersTestCases() []struct {
 			Params:        GetValidClosestScootersQueryParams(),
 			ExpectedError: "",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("GetClosestWithRadius", mock.Anything, TestData.ValidLatitude, TestData.ValidLongitude, TestData.ValidRadius, "", TestData.ValidLimit).Return(GetTestScooters(1), nil)
+				repo.On("GetClosestWithRadius", mock.Anything, TestData.ValidLatitude, TestData.ValidLongitude, TestData.ValidRadius/1000, "", TestSearchBatteryThreshold, TestData.ValidLimit).Return(GetTestScooters(1), nil)
 			},
 		},
 		{
@@ -163,7 +202,7 @@ func (tc *ScooterTestCases) GetClosestScootersTestCases() []struct {
 			Params:        GetValidClosestScootersQueryParamsWithStatus("available"),
 			ExpectedError: "",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("GetClosestWithRadius", mock.Anything, TestData.ValidLatitude, TestData.ValidLongitude, TestData.ValidRadius, "available", TestData.ValidLimit).Return(GetTestScootersWithStatus(1, models.ScooterStatusAvailable), nil)
+				repo.On("GetClosestWithRadius", mock.Anything, TestData.ValidLatitude, TestData.ValidLongitude, TestData.ValidRadius/1000, "available", TestSearchBatteryThreshold, TestData.ValidLimit).Return(GetTestScootersWithStatus(1, models.ScooterStatusAvailable), nil)
 			},
 		},
 		{
@@ -171,7 +210,7 @@ func (tc *ScooterTestCases) GetClosestScootersTestCases() []struct {
 			Params:        GetValidClosestScootersQueryParams(),
 			ExpectedError: "failed to query closest scooters",
 			SetupMocks: func(repo *mocks.MockScooterRepository) {
-				repo.On("GetClosestWithRadius", mock.Anything, TestData.ValidLatitude, TestData.ValidLongitude, TestData.ValidRadius, "", TestData.ValidLimit).Return([]*models.Scooter(nil), errors.New("database connection failed"))
+				repo.On("GetClosestWithRadius", mock.Anything, TestData.ValidLatitude, TestData.ValidLongitude, TestData.ValidRadius/1000, "", TestSearchBatteryThreshold, TestData.ValidLimit).Return([]*models.Scooter(nil), errors.New("database connection failed"))
 			},
 		},
 	}
@@ -183,6 +222,7 @@ func (tc *ScooterTestCases) UpdateLocationTestCases() []struct {
 	ScooterID     uuid.UUID
 	Latitude      float64
 	Longitude     float64
+	BatteryLevel  *int
 	ExpectedError string
 	SetupMocks    func(*mocks.MockScooterRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork, *mocks.MockUnitOfWorkTx)
 } {
@@ -191,6 +231,7 @@ func (tc *ScooterTestCases) UpdateLocationTestCases() []struct {
 		ScooterID     uuid.UUID
 		Latitude      float64
 		Longitude     float64
+		BatteryLevel  *int
 		ExpectedError string
 		SetupMocks    func(*mocks.MockScooterRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork, *mocks.MockUnitOfWorkTx)
 	}{
@@ -211,6 +252,37 @@ func (tc *ScooterTestCases) UpdateLocationTestCases() []struct {
 				scooterRepo.On("UpdateLocation", mock.Anything, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).Return(nil)
 			},
 		},
+		{
+			Name:          "location update with battery level",
+			ScooterID:     TestData.ValidScooterID,
+			Latitude:      TestData.ValidLatitude,
+			Longitude:     TestData.ValidLongitude,
+			BatteryLevel:  &TestData.ValidBatteryLevel,
+			ExpectedError: "",
+			SetupMocks: func(scooterRepo *mocks.MockScooterRepository, locationRepo *mocks.MockLocationUpdateRepository, unitOfWork *mocks.MockUnitOfWork, mockTx *mocks.MockUnitOfWorkTx) {
+				scooter := NewTestScooterBuilder().WithID(TestData.ValidScooterID).Build()
+				unitOfWork.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("ScooterRepository").Return(scooterRepo)
+				mockTx.On("LocationUpdateRepository").Return(locationRepo)
+				mockTx.On("Commit").Return(nil)
+				scooterRepo.On("GetByID", mock.Anything, TestData.ValidScooterID).Return(scooter, nil)
+				locationRepo.On("Create", mock.Anything, mock.MatchedBy(func(update *models.LocationUpdate) bool {
+					return update.BatteryLevel != nil && *update.BatteryLevel == TestData.ValidBatteryLevel
+				})).Return(nil)
+				scooterRepo.On("UpdateLocation", mock.Anything, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).Return(nil)
+				scooterRepo.On("UpdateBatteryLevel", mock.Anything, TestData.ValidScooterID, TestData.ValidBatteryLevel).Return(nil)
+			},
+		},
+		{
+			Name:          "invalid battery level",
+			ScooterID:     TestData.ValidScooterID,
+			Latitude:      TestData.ValidLatitude,
+			Longitude:     TestData.ValidLongitude,
+			BatteryLevel:  &TestData.InvalidBatteryLevel,
+			ExpectedError: "invalid battery level",
+			SetupMocks: func(*mocks.MockScooterRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork, *mocks.MockUnitOfWorkTx) {
+			},
+		},
 		{
 			Name:          "invalid coordinates",
 			ScooterID:     TestData.ValidScooterID,
@@ -29,6 +29,9 @@ var TestData = struct {
 
 	ValidRadius     float64
 	ExcessiveRadius float64
+
+	ValidBatteryLevel   int
+	InvalidBatteryLevel int
 }{
 	ValidScooterID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
 	ValidUserID:    uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
@@ -51,6 +54,9 @@ var TestData = struct {
 
 	ValidRadius:     1000.0,
 	ExcessiveRadius: 60000.0,
+
+	ValidBatteryLevel:   80,
+	InvalidBatteryLevel: 101,
 }
 
 func GetValidScooterQueryParams() ScooterQueryParams {
@@ -127,6 +133,43 @@ func GetInvalidScooterQueryParams() []struct {
 				Offset: TestData.ValidOffset,
 			},
 		},
+		{
+			Name: "invalid sort",
+			Params: ScooterQueryParams{
+				Sort:  "battery",
+				Limit: TestData.ValidLimit,
+			},
+		},
+		{
+			Name: "distance sort without reference point",
+			Params: ScooterQueryParams{
+				Sort:  "distance",
+				Limit: TestData.ValidLimit,
+			},
+		},
+		{
+			Name: "invalid order",
+			Params: ScooterQueryParams{
+				Order: "sideways",
+				Limit: TestData.ValidLimit,
+			},
+		},
+		{
+			Name: "cursor with offset",
+			Params: ScooterQueryParams{
+				Cursor: "eyJzIjoiY3JlYXRlZF9hdCJ9",
+				Limit:  TestData.ValidLimit,
+				Offset: 10,
+			},
+		},
+		{
+			Name: "inverted last seen window",
+			Params: ScooterQueryParams{
+				LastSeenAfter:  TestData.ValidTime,
+				LastSeenBefore: TestData.ValidTime.Add(-time.Hour),
+				Limit:          TestData.ValidLimit,
+			},
+		},
 	}
 }
 
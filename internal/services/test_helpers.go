@@ -11,6 +11,12 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// Battery thresholds the test services are built with
+const (
+	TestSearchBatteryThreshold = 20
+	TestMinTripBattery         = 15
+)
+
 var TestFixtures = struct {
 	ValidCoordinates struct {
 		Latitude  float64
@@ -201,11 +207,19 @@ func (m *MockSetup) SetupBasicUnitOfWork(unitOfWork *mocks.MockUnitOfWork, tripR
 	mockTx.On("TripRepository").Return(tripRepo)
 	mockTx.On("ScooterRepository").Return(scooterRepo)
 	mockTx.On("LocationUpdateRepository").Return(locationRepo)
+	mockTx.On("OutboxRepository").Return(m.SetupOutboxRepository())
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
 	return mockTx
 }
 
+// SetupOutboxRepository returns an outbox repository that accepts any event
+func (m *MockSetup) SetupOutboxRepository() *mocks.MockOutboxRepository {
+	outboxRepo := &mocks.MockOutboxRepository{}
+	outboxRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.OutboxEvent")).Return(nil).Maybe()
+	return outboxRepo
+}
+
 func (m *MockSetup) SetupScooterServiceUnitOfWork(unitOfWork *mocks.MockUnitOfWork, scooterRepo *mocks.MockScooterRepository, locationRepo *mocks.MockLocationUpdateRepository) *mocks.MockUnitOfWorkTx {
 	mockTx := &mocks.MockUnitOfWorkTx{}
 	unitOfWork.On("Begin", mock.Anything).Return(mockTx, nil)
@@ -218,13 +232,13 @@ func (m *MockSetup) SetupScooterServiceUnitOfWork(unitOfWork *mocks.MockUnitOfWo
 
 func (m *MockSetup) CreateTestScooterService() (ScooterService, *mocks.MockScooterRepository, *mocks.MockTripRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork) {
 	scooterRepo, tripRepo, locationRepo, unitOfWork := m.SetupScooterServiceMocks()
-	service := NewScooterService(scooterRepo, tripRepo, locationRepo, unitOfWork)
+	service := NewScooterService(scooterRepo, tripRepo, locationRepo, unitOfWork, TestSearchBatteryThreshold, nil)
 	return service, scooterRepo, tripRepo, locationRepo, unitOfWork
 }
 
 func (m *MockSetup) CreateTestTripService() (TripService, *mocks.MockTripRepository, *mocks.MockScooterRepository, *mocks.MockUserRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork) {
 	tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork := m.SetupTripServiceMocks()
-	service := NewTripService(tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork)
+	service := NewTripService(tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork, TestMinTripBattery, nil, nil, nil)
 	return service, tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork
 }
 
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TripRoute is the path a trip took, rebuilt from the locations its scooter reported while the trip ran
+type TripRoute struct {
+	Trip *models.Trip
+	// Points run from where the trip started to where it ended or, until it ends, to the latest report
+	Points         []RoutePoint
+	DistanceMeters float64
+}
+
+type RoutePoint struct {
+	Latitude  float64
+	Longitude float64
+	Timestamp time.Time
+}
+
+func (s *tripService) GetTripRoute(ctx context.Context, tripID uuid.UUID) (*TripRoute, error) {
+	trip, err := s.tripRepo.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+	if trip == nil {
+		return nil, repository.ErrTripNotFound
+	}
+
+	// Location updates carry no trip ID, so the trip's are the scooter's while the trip ran
+	var end *RoutePoint
+	until := time.Now()
+	switch {
+	case trip.EndTime != nil && trip.EndLatitude != nil && trip.EndLongitude != nil:
+		end = &RoutePoint{Latitude: *trip.EndLatitude, Longitude: *trip.EndLongitude, Timestamp: *trip.EndTime}
+		until = *trip.EndTime
+	case trip.IsCancelled():
+		until = trip.UpdatedAt
+	}
+
+	updates, err := s.locationRepo.GetByScooterIDBetween(ctx, trip.ScooterID, trip.StartTime, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip path: %w", err)
+	}
+
+	return newTripRoute(trip, updates, end), nil
+}
+
+// newTripRoute joins the trip's start, the scooter's updates in between and, once known, its end
+func newTripRoute(trip *models.Trip, updates []*models.LocationUpdate, end *RoutePoint) *TripRoute {
+	points := make([]RoutePoint, 0, len(updates)+2)
+	points = append(points, RoutePoint{Latitude: trip.StartLatitude, Longitude: trip.StartLongitude, Timestamp: trip.StartTime})
+	for _, update := range updates {
+		points = append(points, RoutePoint{Latitude: update.Latitude, Longitude: update.Longitude, Timestamp: update.Timestamp})
+	}
+	if end != nil {
+		points = append(points, *end)
+	}
+
+	var distanceKm float64
+	for i := 1; i < len(points); i++ {
+		distanceKm += repository.HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+
+	return &TripRoute{
+		Trip:           trip,
+		Points:         points,
+		DistanceMeters: distanceKm * 1000,
+	}
+}
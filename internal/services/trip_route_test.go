@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTripService_GetTripRoute(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	service := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, nil, nil)
+
+	scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: 45.4215, CurrentLongitude: -75.6972}
+	require.NoError(t, repo.Scooter().Create(ctx, scooter))
+	other := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: 45.5, CurrentLongitude: -75.5}
+	require.NoError(t, repo.Scooter().Create(ctx, other))
+	user := &models.User{}
+	require.NoError(t, repo.User().Create(ctx, user))
+
+	// Reported before the trip, and by another scooter during it; neither is part of the route
+	require.NoError(t, repo.LocationUpdate().Create(ctx, &models.LocationUpdate{ScooterID: scooter.ID, Latitude: 45.40, Longitude: -75.70, Timestamp: time.Now().Add(-time.Hour)}))
+
+	trip, err := service.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, 45.4215, -75.6972)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.LocationUpdate().Create(ctx, &models.LocationUpdate{ScooterID: scooter.ID, Latitude: 45.4250, Longitude: -75.6972}))
+	require.NoError(t, repo.LocationUpdate().Create(ctx, &models.LocationUpdate{ScooterID: other.ID, Latitude: 45.5, Longitude: -75.5}))
+	require.NoError(t, repo.LocationUpdate().Create(ctx, &models.LocationUpdate{ScooterID: scooter.ID, Latitude: 45.4250, Longitude: -75.6900}))
+
+	t.Run("active trip runs to the latest report", func(t *testing.T) {
+		route, err := service.GetTripRoute(ctx, trip.ID)
+		require.NoError(t, err)
+
+		require.Len(t, route.Points, 3)
+		assert.Equal(t, RoutePoint{Latitude: 45.4215, Longitude: -75.6972, Timestamp: trip.StartTime}, route.Points[0])
+		assert.Equal(t, -75.6900, route.Points[2].Longitude)
+	})
+
+	_, err = service.EndTrip(ctx, uuid.Nil, scooter.ID, 45.4215, -75.6900)
+	require.NoError(t, err)
+
+	t.Run("completed trip ends where it was ended", func(t *testing.T) {
+		route, err := service.GetTripRoute(ctx, trip.ID)
+		require.NoError(t, err)
+
+		require.Len(t, route.Points, 4)
+		assert.Equal(t, 45.4215, route.Points[3].Latitude)
+		assert.Equal(t, -75.6900, route.Points[3].Longitude)
+
+		expectedKm := repository.HaversineDistance(45.4215, -75.6972, 45.4250, -75.6972) +
+			repository.HaversineDistance(45.4250, -75.6972, 45.4250, -75.6900) +
+			repository.HaversineDistance(45.4250, -75.6900, 45.4215, -75.6900)
+		assert.InDelta(t, expectedKm*1000, route.DistanceMeters, 0.001)
+	})
+
+	t.Run("unknown trip", func(t *testing.T) {
+		_, err := service.GetTripRoute(ctx, uuid.New())
+		assert.ErrorIs(t, err, repository.ErrTripNotFound)
+	})
+}
@@ -2,25 +2,31 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
+	"scootin-aboot/internal/geofence"
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/pricing"
 	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/stream"
 	"scootin-aboot/internal/validation"
 
 	"github.com/google/uuid"
 )
 
 type TripService interface {
-	StartTrip(ctx context.Context, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error)
-	EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error)
-	CancelTrip(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error)
+	// StartTrip persists the trip under tripID when provided, or a generated ID when tripID is uuid.Nil
+	StartTrip(ctx context.Context, tripID, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error)
+	// EndTrip and CancelTrip reject the request when tripID is provided and differs from the scooter's active trip
+	EndTrip(ctx context.Context, tripID, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error)
+	CancelTrip(ctx context.Context, tripID, scooterID uuid.UUID) (*models.Trip, error)
 	UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64) error
 	GetActiveTrip(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error)
 	GetActiveTripByUser(ctx context.Context, userID uuid.UUID) (*models.Trip, error)
 	GetTrip(ctx context.Context, tripID uuid.UUID) (*models.Trip, error)
+	// GetTripRoute rebuilds the path of a trip from its scooter's location history
+	GetTripRoute(ctx context.Context, tripID uuid.UUID) (*TripRoute, error)
 }
 
 type tripService struct {
@@ -29,6 +35,14 @@ type tripService struct {
 	userRepo     repository.UserRepository
 	locationRepo repository.LocationUpdateRepository
 	unitOfWork   repository.UnitOfWork
+	// minTripBattery is the lowest reported charge a scooter can start a trip with
+	minTripBattery int
+	// zones decides where trips can end; nil allows ending anywhere
+	zones *geofence.Set
+	// fares prices trips as they end; nil leaves them unpriced
+	fares *pricing.Calculator
+	// changes streams committed scooter changes to live subscribers; nil streams nothing
+	changes *stream.Hub
 }
 
 func NewTripService(
@@ -37,19 +51,27 @@ func NewTripService(
 	userRepo repository.UserRepository,
 	locationRepo repository.LocationUpdateRepository,
 	unitOfWork repository.UnitOfWork,
+	minTripBattery int,
+	zones *geofence.Set,
+	fares *pricing.Calculator,
+	changes *stream.Hub,
 ) TripService {
 	return &tripService{
-		tripRepo:     tripRepo,
-		scooterRepo:  scooterRepo,
-		userRepo:     userRepo,
-		locationRepo: locationRepo,
-		unitOfWork:   unitOfWork,
+		tripRepo:       tripRepo,
+		scooterRepo:    scooterRepo,
+		userRepo:       userRepo,
+		locationRepo:   locationRepo,
+		unitOfWork:     unitOfWork,
+		minTripBattery: minTripBattery,
+		zones:          zones,
+		fares:          fares,
+		changes:        changes,
 	}
 }
 
-func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error) {
+func (s *tripService) StartTrip(ctx context.Context, tripID, scooterID, userID uuid.UUID, lat, lng float64) (*models.Trip, error) {
 	if err := validation.ValidateCoordinates(lat, lng); err != nil {
-		return nil, fmt.Errorf("invalid coordinates: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCoordinates, err)
 	}
 
 	tx, err := s.unitOfWork.Begin(ctx)
@@ -64,6 +86,10 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		}
 	}()
 
+	if err := recordProcessedEvent(ctx, tx); err != nil {
+		return nil, err
+	}
+
 	userRepo := tx.UserRepository()
 	tripRepo := tx.TripRepository()
 	scooterRepo := tx.ScooterRepository()
@@ -73,7 +99,7 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, repository.ErrUserNotFound
 	}
 
 	activeTrip, err := tripRepo.GetActiveByUserID(ctx, userID)
@@ -81,7 +107,7 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		return nil, fmt.Errorf("failed to check user's active trip: %w", err)
 	}
 	if activeTrip != nil {
-		return nil, errors.New("user already has an active trip")
+		return nil, ErrUserHasActiveTrip
 	}
 
 	scooter, err := scooterRepo.GetByIDForUpdate(ctx, scooterID)
@@ -89,10 +115,38 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		return nil, fmt.Errorf("failed to get scooter: %w", err)
 	}
 	if scooter == nil {
-		return nil, errors.New("scooter not found")
+		return nil, repository.ErrScooterNotFound
 	}
-	if !scooter.IsAvailable() {
-		return nil, errors.New("scooter is not available")
+
+	// A reserved scooter can only be taken by the rider holding it, or by anyone once the hold has run out
+	fromStatus := models.ScooterStatusAvailable
+	if scooter.IsReserved() {
+		reservation, err := tx.ReservationRepository().GetActiveByScooterID(ctx, scooterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get scooter's reservation: %w", err)
+		}
+
+		now := time.Now()
+		switch {
+		case reservation == nil:
+			return nil, ErrScooterNotAvailable
+		case reservation.UserID == userID && !reservation.IsExpired(now):
+			err = tx.ReservationRepository().End(ctx, reservation.ID, models.ReservationStatusFulfilled)
+		case reservation.IsExpired(now):
+			err = tx.ReservationRepository().End(ctx, reservation.ID, models.ReservationStatusExpired)
+		default:
+			return nil, ErrScooterReserved
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to end reservation: %w", err)
+		}
+		fromStatus = models.ScooterStatusReserved
+	} else if err := models.ValidateStatusTransition(scooter.Status, models.ScooterStatusOccupied); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScooterNotAvailable, err)
+	}
+
+	if scooter.HasBatteryBelow(s.minTripBattery) {
+		return nil, ErrScooterBatteryLow
 	}
 
 	activeScooterTrip, err := tripRepo.GetActiveByScooterID(ctx, scooterID)
@@ -100,10 +154,21 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		return nil, fmt.Errorf("failed to check scooter's active trip: %w", err)
 	}
 	if activeScooterTrip != nil {
-		return nil, errors.New("scooter already has an active trip")
+		return nil, ErrScooterHasActiveTrip
+	}
+
+	if tripID != uuid.Nil {
+		existingTrip, err := tripRepo.GetByID(ctx, tripID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check trip ID: %w", err)
+		}
+		if existingTrip != nil {
+			return nil, ErrTripAlreadyExists
+		}
 	}
 
 	trip := &models.Trip{
+		ID:             tripID,
 		ScooterID:      scooterID,
 		UserID:         userID,
 		StartTime:      time.Now(),
@@ -116,7 +181,7 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		return nil, fmt.Errorf("failed to create trip: %w", err)
 	}
 
-	if err := scooterRepo.UpdateStatusWithCheck(ctx, scooterID, models.ScooterStatusOccupied, models.ScooterStatusAvailable); err != nil {
+	if err := scooterRepo.UpdateStatusWithCheck(ctx, scooterID, models.ScooterStatusOccupied, fromStatus); err != nil {
 		return nil, fmt.Errorf("failed to update scooter status: %w", err)
 	}
 
@@ -124,17 +189,26 @@ func (s *tripService) StartTrip(ctx context.Context, scooterID, userID uuid.UUID
 		// Location will be updated with first location update
 	}
 
+	if err := writeTripOutboxEvent(ctx, tx, models.OutboxEventTripStarted, trip); err != nil {
+		return nil, err
+	}
+
+	if err := writeScooterStatusOutboxEvent(ctx, tx, scooterID, fromStatus, models.ScooterStatusOccupied); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	committed = true
+	s.publishStatusChange(ctx, scooterID, fromStatus)
 	return trip, nil
 }
 
-func (s *tripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error) {
+func (s *tripService) EndTrip(ctx context.Context, tripID, scooterID uuid.UUID, lat, lng float64) (*models.Trip, error) {
 	if err := validation.ValidateCoordinates(lat, lng); err != nil {
-		return nil, fmt.Errorf("invalid coordinates: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCoordinates, err)
 	}
 
 	tx, err := s.unitOfWork.Begin(ctx)
@@ -149,6 +223,10 @@ func (s *tripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng
 		}
 	}()
 
+	if err := recordProcessedEvent(ctx, tx); err != nil {
+		return nil, err
+	}
+
 	tripRepo := tx.TripRepository()
 	scooterRepo := tx.ScooterRepository()
 
@@ -157,12 +235,24 @@ func (s *tripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng
 		return nil, fmt.Errorf("failed to get active trip: %w", err)
 	}
 	if trip == nil {
-		return nil, errors.New("no active trip found for scooter")
+		return nil, ErrNoActiveTripOnScooter
+	}
+	if tripID != uuid.Nil && trip.ID != tripID {
+		return nil, fmt.Errorf("%w: expected %s, active trip is %s", ErrTripMismatch, tripID, trip.ID)
+	}
+
+	if err := s.zones.CheckParking(lat, lng); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParkingNotAllowed, err)
 	}
 
 	endTime := time.Now()
 
-	if err := tripRepo.EndTrip(ctx, trip.ID, lat, lng); err != nil {
+	fare, err := s.priceTrip(ctx, tx, trip, endTime, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tripRepo.EndTrip(ctx, trip.ID, lat, lng, fare); err != nil {
 		return nil, fmt.Errorf("failed to end trip: %w", err)
 	}
 
@@ -178,16 +268,56 @@ func (s *tripService) EndTrip(ctx context.Context, scooterID uuid.UUID, lat, lng
 	trip.EndLatitude = &lat
 	trip.EndLongitude = &lng
 	trip.Status = models.TripStatusCompleted
+	trip.Fare = fare
+
+	if err := writeTripOutboxEvent(ctx, tx, models.OutboxEventTripEnded, trip); err != nil {
+		return nil, err
+	}
+
+	if err := writeScooterStatusOutboxEvent(ctx, tx, scooterID, models.ScooterStatusOccupied, models.ScooterStatusAvailable); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	committed = true
+	s.publishStatusChange(ctx, scooterID, models.ScooterStatusOccupied)
 	return trip, nil
 }
 
-func (s *tripService) CancelTrip(ctx context.Context, scooterID uuid.UUID) (*models.Trip, error) {
+// priceTrip prices a trip ending at the point, measuring its distance along the locations the scooter
+// reported on the way
+func (s *tripService) priceTrip(ctx context.Context, tx repository.UnitOfWorkTx, trip *models.Trip, endTime time.Time, lat, lng float64) (*models.Fare, error) {
+	if s.fares == nil {
+		return nil, nil
+	}
+
+	updates, err := tx.LocationUpdateRepository().GetByScooterIDBetween(ctx, trip.ScooterID, trip.StartTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip path: %w", err)
+	}
+	route := newTripRoute(trip, updates, &RoutePoint{Latitude: lat, Longitude: lng, Timestamp: endTime})
+
+	return s.fares.Fare(trip.StartLatitude, trip.StartLongitude, endTime.Sub(trip.StartTime), route.DistanceMeters), nil
+}
+
+// publishStatusChange streams a committed status change of the scooter. The scooter is read back so that the
+// event carries its current position and battery level; a failed read only costs subscribers the event.
+func (s *tripService) publishStatusChange(ctx context.Context, scooterID uuid.UUID, from models.ScooterStatus) {
+	if s.changes == nil {
+		return
+	}
+
+	scooter, err := s.scooterRepo.GetByID(ctx, scooterID)
+	if err != nil || scooter == nil {
+		return
+	}
+	s.changes.Publish(scooterStatusEvent(scooter, from))
+}
+
+func (s *tripService) CancelTrip(ctx context.Context, tripID, scooterID uuid.UUID) (*models.Trip, error) {
 	tx, err := s.unitOfWork.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -200,6 +330,10 @@ func (s *tripService) CancelTrip(ctx context.Context, scooterID uuid.UUID) (*mod
 		}
 	}()
 
+	if err := recordProcessedEvent(ctx, tx); err != nil {
+		return nil, err
+	}
+
 	tripRepo := tx.TripRepository()
 	scooterRepo := tx.ScooterRepository()
 
@@ -208,7 +342,10 @@ func (s *tripService) CancelTrip(ctx context.Context, scooterID uuid.UUID) (*mod
 		return nil, fmt.Errorf("failed to get active trip: %w", err)
 	}
 	if trip == nil {
-		return nil, errors.New("no active trip found for scooter")
+		return nil, ErrNoActiveTripOnScooter
+	}
+	if tripID != uuid.Nil && trip.ID != tripID {
+		return nil, fmt.Errorf("%w: expected %s, active trip is %s", ErrTripMismatch, tripID, trip.ID)
 	}
 
 	if err := tripRepo.CancelTrip(ctx, trip.ID); err != nil {
@@ -221,17 +358,26 @@ func (s *tripService) CancelTrip(ctx context.Context, scooterID uuid.UUID) (*mod
 
 	trip.Status = models.TripStatusCancelled
 
+	if err := writeTripOutboxEvent(ctx, tx, models.OutboxEventTripCancelled, trip); err != nil {
+		return nil, err
+	}
+
+	if err := writeScooterStatusOutboxEvent(ctx, tx, scooterID, models.ScooterStatusOccupied, models.ScooterStatusAvailable); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	committed = true
+	s.publishStatusChange(ctx, scooterID, models.ScooterStatusOccupied)
 	return trip, nil
 }
 
 func (s *tripService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng float64) error {
 	if err := validation.ValidateCoordinates(lat, lng); err != nil {
-		return fmt.Errorf("invalid coordinates: %w", err)
+		return fmt.Errorf("%w: %v", ErrInvalidCoordinates, err)
 	}
 
 	tx, err := s.unitOfWork.Begin(ctx)
@@ -246,6 +392,10 @@ func (s *tripService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, l
 		}
 	}()
 
+	if err := recordProcessedEvent(ctx, tx); err != nil {
+		return err
+	}
+
 	tripRepo := tx.TripRepository()
 	locationRepo := tx.LocationUpdateRepository()
 	scooterRepo := tx.ScooterRepository()
@@ -255,7 +405,7 @@ func (s *tripService) UpdateLocation(ctx context.Context, scooterID uuid.UUID, l
 		return fmt.Errorf("failed to get active trip: %w", err)
 	}
 	if trip == nil {
-		return errors.New("no active trip found for scooter")
+		return ErrNoActiveTripOnScooter
 	}
 
 	locationUpdate := &models.LocationUpdate{
@@ -303,7 +453,7 @@ func (s *tripService) GetTrip(ctx context.Context, tripID uuid.UUID) (*models.Tr
 		return nil, fmt.Errorf("failed to get trip: %w", err)
 	}
 	if trip == nil {
-		return nil, errors.New("trip not found")
+		return nil, repository.ErrTripNotFound
 	}
 	return trip, nil
 }
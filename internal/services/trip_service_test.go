@@ -1,11 +1,21 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
+	"scootin-aboot/internal/geofence"
+	"scootin-aboot/internal/logger"
 	"scootin-aboot/internal/models"
+	"scootin-aboot/internal/pricing"
+	"scootin-aboot/internal/repository"
+	"scootin-aboot/internal/repository/mocks"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTripService(t *testing.T) {
@@ -26,7 +36,7 @@ func TestTripService_StartTrip(t *testing.T) {
 
 			tc.SetupMocks(tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork)
 
-			trip, err := service.StartTrip(TestContext(), tc.ScooterID, tc.UserID, tc.Latitude, tc.Longitude)
+			trip, err := service.StartTrip(TestContext(), tc.TripID, tc.ScooterID, tc.UserID, tc.Latitude, tc.Longitude)
 
 			if tc.ExpectedError != "" {
 				assert.Error(t, err)
@@ -40,6 +50,9 @@ func TestTripService_StartTrip(t *testing.T) {
 				assert.Equal(t, tc.Latitude, trip.StartLatitude)
 				assert.Equal(t, tc.Longitude, trip.StartLongitude)
 				assert.Equal(t, models.TripStatusActive, trip.Status)
+				if tc.TripID != uuid.Nil {
+					assert.Equal(t, tc.TripID, trip.ID)
+				}
 			}
 
 			tripRepo.AssertExpectations(t)
@@ -62,7 +75,7 @@ func TestTripService_EndTrip(t *testing.T) {
 
 			tc.SetupMocks(tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork)
 
-			trip, err := service.EndTrip(TestContext(), tc.ScooterID, tc.Latitude, tc.Longitude)
+			trip, err := service.EndTrip(TestContext(), tc.TripID, tc.ScooterID, tc.Latitude, tc.Longitude)
 
 			if tc.ExpectedError != "" {
 				assert.Error(t, err)
@@ -126,7 +139,7 @@ func TestTripService_CancelTrip(t *testing.T) {
 
 			tc.SetupMocks(tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork)
 
-			trip, err := service.CancelTrip(TestContext(), tc.ScooterID)
+			trip, err := service.CancelTrip(TestContext(), tc.TripID, tc.ScooterID)
 
 			if tc.ExpectedError != "" {
 				assert.Error(t, err)
@@ -254,3 +267,299 @@ func TestTripService_GetTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestTripService_UpdateLocation_ProcessedEvent(t *testing.T) {
+	t.Run("first delivery is recorded with the location update", func(t *testing.T) {
+		mockSetup := &MockSetup{}
+		service, tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork := mockSetup.CreateTestTripService()
+		mockTx := mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+		processedEventRepo := &mocks.MockProcessedEventRepository{}
+		mockTx.On("ProcessedEventRepository").Return(processedEventRepo)
+
+		processedEventRepo.On("MarkProcessed", mock.Anything, mock.MatchedBy(func(event *models.ProcessedEvent) bool {
+			return event.EventID == "event-1" && event.EventType == "location.updated"
+		})).Return(nil)
+		trip := NewTestTripBuilder().WithScooterID(TestData.ValidScooterID).WithStatus(models.TripStatusActive).Build()
+		tripRepo.On("GetActiveByScooterID", mock.Anything, TestData.ValidScooterID).Return(trip, nil)
+		locationRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.LocationUpdate")).Return(nil)
+		scooterRepo.On("UpdateLocation", mock.Anything, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).Return(nil)
+
+		ctx := WithProcessedEvent(TestContext(), "event-1", "location.updated")
+		err := service.UpdateLocation(ctx, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude)
+
+		assert.NoError(t, err)
+		processedEventRepo.AssertExpectations(t)
+		locationRepo.AssertExpectations(t)
+		mockTx.AssertCalled(t, "Commit")
+	})
+
+	t.Run("redelivered event is rejected before any changes", func(t *testing.T) {
+		mockSetup := &MockSetup{}
+		service, tripRepo, scooterRepo, userRepo, locationRepo, unitOfWork := mockSetup.CreateTestTripService()
+		mockTx := mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+		processedEventRepo := &mocks.MockProcessedEventRepository{}
+		mockTx.On("ProcessedEventRepository").Return(processedEventRepo)
+
+		processedEventRepo.On("MarkProcessed", mock.Anything, mock.Anything).Return(repository.ErrEventAlreadyProcessed)
+
+		ctx := WithProcessedEvent(TestContext(), "event-1", "location.updated")
+		err := service.UpdateLocation(ctx, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude)
+
+		assert.ErrorIs(t, err, repository.ErrEventAlreadyProcessed)
+		locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockTx.AssertNotCalled(t, "Commit")
+		mockTx.AssertCalled(t, "Rollback")
+	})
+}
+
+func TestTripService_EndTrip_WritesOutboxEvents(t *testing.T) {
+	mockSetup := &MockSetup{}
+	service, tripRepo, scooterRepo, _, _, unitOfWork := mockSetup.CreateTestTripService()
+	outboxRepo := &mocks.MockOutboxRepository{}
+	mockTx := &mocks.MockUnitOfWorkTx{}
+	unitOfWork.On("Begin", mock.Anything).Return(mockTx, nil)
+	mockTx.On("TripRepository").Return(tripRepo)
+	mockTx.On("ScooterRepository").Return(scooterRepo)
+	mockTx.On("OutboxRepository").Return(outboxRepo)
+	mockTx.On("Commit").Return(nil)
+
+	trip := NewTestTripBuilder().WithScooterID(TestData.ValidScooterID).WithStatus(models.TripStatusActive).Build()
+	tripRepo.On("GetActiveByScooterID", mock.Anything, TestData.ValidScooterID).Return(trip, nil)
+	tripRepo.On("EndTrip", mock.Anything, trip.ID, TestData.ValidLatitude, TestData.ValidLongitude, (*models.Fare)(nil)).Return(nil)
+	scooterRepo.On("UpdateStatusWithCheck", mock.Anything, TestData.ValidScooterID, models.ScooterStatusAvailable, models.ScooterStatusOccupied).Return(nil)
+	scooterRepo.On("UpdateLocation", mock.Anything, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).Return(nil)
+
+	var written []*models.OutboxEvent
+	outboxRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.OutboxEvent")).Run(func(args mock.Arguments) {
+		written = append(written, args.Get(1).(*models.OutboxEvent))
+	}).Return(nil)
+
+	ctx := logger.WithRequestID(TestContext(), "req-42")
+	_, err := service.EndTrip(ctx, uuid.Nil, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude)
+
+	assert.NoError(t, err)
+	assert.Len(t, written, 2)
+	assert.Equal(t, models.OutboxEventTripEnded, written[0].EventType)
+	assert.Equal(t, TestData.ValidScooterID, written[0].AggregateID)
+	if assert.NotNil(t, written[0].RequestID) {
+		assert.Equal(t, "req-42", *written[0].RequestID)
+	}
+	assert.Contains(t, string(written[0].Payload), `"status":"completed"`)
+	assert.Equal(t, models.OutboxEventScooterStatusChanged, written[1].EventType)
+	assert.Contains(t, string(written[1].Payload), `"previousStatus":"occupied","status":"available"`)
+	mockTx.AssertCalled(t, "Commit")
+}
+
+func TestTripService_EndTrip_OutboxFailureRollsBack(t *testing.T) {
+	mockSetup := &MockSetup{}
+	service, tripRepo, scooterRepo, _, _, unitOfWork := mockSetup.CreateTestTripService()
+	outboxRepo := &mocks.MockOutboxRepository{}
+	mockTx := &mocks.MockUnitOfWorkTx{}
+	unitOfWork.On("Begin", mock.Anything).Return(mockTx, nil)
+	mockTx.On("TripRepository").Return(tripRepo)
+	mockTx.On("ScooterRepository").Return(scooterRepo)
+	mockTx.On("OutboxRepository").Return(outboxRepo)
+	mockTx.On("Rollback").Return(nil)
+
+	trip := NewTestTripBuilder().WithScooterID(TestData.ValidScooterID).WithStatus(models.TripStatusActive).Build()
+	tripRepo.On("GetActiveByScooterID", mock.Anything, TestData.ValidScooterID).Return(trip, nil)
+	tripRepo.On("EndTrip", mock.Anything, trip.ID, TestData.ValidLatitude, TestData.ValidLongitude, (*models.Fare)(nil)).Return(nil)
+	scooterRepo.On("UpdateStatusWithCheck", mock.Anything, TestData.ValidScooterID, models.ScooterStatusAvailable, models.ScooterStatusOccupied).Return(nil)
+	scooterRepo.On("UpdateLocation", mock.Anything, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).Return(nil)
+	outboxRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	trip, err := service.EndTrip(TestContext(), uuid.Nil, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write trip.ended outbox event")
+	assert.Nil(t, trip)
+	mockTx.AssertNotCalled(t, "Commit")
+	mockTx.AssertCalled(t, "Rollback")
+}
+
+func TestTripService_WithMemoryRepository(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	service := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, nil, nil)
+
+	scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude}
+	assert.NoError(t, repo.Scooter().Create(ctx, scooter))
+	users := []*models.User{{}, {}}
+	for _, user := range users {
+		assert.NoError(t, repo.User().Create(ctx, user))
+	}
+
+	// Two users race for the same scooter; the row lock lets exactly one of them start a trip
+	results := make(chan error, len(users))
+	for _, user := range users {
+		go func(userID uuid.UUID) {
+			_, err := service.StartTrip(ctx, uuid.Nil, scooter.ID, userID, TestData.ValidLatitude, TestData.ValidLongitude)
+			results <- err
+		}(user.ID)
+	}
+
+	var started, rejected int
+	for range users {
+		if err := <-results; err == nil {
+			started++
+		} else {
+			assert.ErrorIs(t, err, ErrScooterNotAvailable)
+			rejected++
+		}
+	}
+	assert.Equal(t, 1, started)
+	assert.Equal(t, 1, rejected)
+
+	occupied, err := repo.Scooter().GetByID(ctx, scooter.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusOccupied, occupied.Status)
+
+	trip, err := service.EndTrip(ctx, uuid.Nil, scooter.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TripStatusCompleted, trip.Status)
+
+	available, err := repo.Scooter().GetByID(ctx, scooter.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusAvailable, available.Status)
+
+	outboxEvents, err := repo.Outbox().GetUnpublished(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, outboxEvents, 4)
+}
+
+func TestTripService_StartTripRefusesLowBattery(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	service := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, nil, nil)
+
+	scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude}
+	assert.NoError(t, repo.Scooter().Create(ctx, scooter))
+	user := &models.User{}
+	assert.NoError(t, repo.User().Create(ctx, user))
+
+	assert.NoError(t, repo.Scooter().UpdateBatteryLevel(ctx, scooter.ID, TestMinTripBattery-1))
+	_, err := service.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+	assert.ErrorIs(t, err, ErrScooterBatteryLow)
+
+	stored, err := repo.Scooter().GetByID(ctx, scooter.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusAvailable, stored.Status)
+
+	assert.NoError(t, repo.Scooter().UpdateBatteryLevel(ctx, scooter.ID, TestMinTripBattery))
+	_, err = service.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+	assert.NoError(t, err)
+}
+
+func TestTripService_StartTripEnforcesStatusTransitions(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+	service := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, nil, nil)
+
+	user := &models.User{}
+	require.NoError(t, repo.User().Create(ctx, user))
+
+	for _, status := range []models.ScooterStatus{models.ScooterStatusMaintenance, models.ScooterStatusCharging, models.ScooterStatusRetired} {
+		t.Run(string(status), func(t *testing.T) {
+			scooter := &models.Scooter{Status: status, CurrentLatitude: TestData.ValidLatitude, CurrentLongitude: TestData.ValidLongitude}
+			require.NoError(t, repo.Scooter().Create(ctx, scooter))
+
+			trip, err := service.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, TestData.ValidLatitude, TestData.ValidLongitude)
+			assert.ErrorIs(t, err, ErrScooterNotAvailable)
+			assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+			assert.Nil(t, trip)
+
+			stored, err := repo.Scooter().GetByID(ctx, scooter.ID)
+			require.NoError(t, err)
+			assert.Equal(t, status, stored.Status)
+		})
+	}
+}
+
+func TestTripService_EndTripRespectsGeofences(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+
+	square := func(minLat, minLng, maxLat, maxLng float64) []geofence.Polygon {
+		return []geofence.Polygon{{{{minLng, minLat}, {maxLng, minLat}, {maxLng, maxLat}, {minLng, maxLat}, {minLng, minLat}}}}
+	}
+	zones, err := geofence.NewSet([]*geofence.Zone{
+		{ID: "city", Name: "City", Type: geofence.ZoneTypeServiceArea, Polygons: square(45, -76, 46, -75)},
+		{ID: "plaza", Name: "Plaza", Type: geofence.ZoneTypeNoParking, Polygons: square(45.5, -75.5, 45.6, -75.4)},
+	})
+	require.NoError(t, err)
+
+	service := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, zones, nil, nil)
+
+	scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: 45.4215, CurrentLongitude: -75.6972}
+	require.NoError(t, repo.Scooter().Create(ctx, scooter))
+	user := &models.User{}
+	require.NoError(t, repo.User().Create(ctx, user))
+
+	_, err = service.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, 45.4215, -75.6972)
+	require.NoError(t, err)
+
+	_, err = service.EndTrip(ctx, uuid.Nil, scooter.ID, 43.6532, -79.3832)
+	assert.ErrorIs(t, err, ErrParkingNotAllowed)
+	assert.ErrorIs(t, err, geofence.ErrOutsideServiceArea)
+
+	_, err = service.EndTrip(ctx, uuid.Nil, scooter.ID, 45.55, -75.45)
+	assert.ErrorIs(t, err, ErrParkingNotAllowed)
+	assert.ErrorIs(t, err, geofence.ErrNoParkingZone)
+
+	stored, err := repo.Scooter().GetByID(ctx, scooter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScooterStatusOccupied, stored.Status, "a refused end leaves the trip running")
+
+	trip, err := service.EndTrip(ctx, uuid.Nil, scooter.ID, 45.4289, -75.6920)
+	require.NoError(t, err)
+	assert.Equal(t, models.TripStatusCompleted, trip.Status)
+}
+
+func TestTripService_EndTripRecordsFare(t *testing.T) {
+	ctx := TestContext()
+	repo := repository.NewMemoryRepository()
+
+	fares, err := pricing.NewCalculator(pricing.Config{
+		Currency: "CAD",
+		Default:  pricing.Tariff{UnlockFee: 100, PerMinute: 30, PerKm: 100},
+	}, nil)
+	require.NoError(t, err)
+
+	service := NewTripService(repo.Trip(), repo.Scooter(), repo.User(), repo.LocationUpdate(), repo.UnitOfWork(), TestMinTripBattery, nil, fares, nil)
+
+	scooter := &models.Scooter{Status: models.ScooterStatusAvailable, CurrentLatitude: 45.4215, CurrentLongitude: -75.6972}
+	require.NoError(t, repo.Scooter().Create(ctx, scooter))
+	user := &models.User{}
+	require.NoError(t, repo.User().Create(ctx, user))
+
+	started, err := service.StartTrip(ctx, uuid.Nil, scooter.ID, user.ID, 45.4215, -75.6972)
+	require.NoError(t, err)
+
+	// An out-and-back ride ends where it started, so only the reported path gives it any distance
+	require.NoError(t, repo.LocationUpdate().Create(ctx, &models.LocationUpdate{ScooterID: scooter.ID, Latitude: 45.4300, Longitude: -75.6972}))
+
+	trip, err := service.EndTrip(ctx, uuid.Nil, scooter.ID, 45.4215, -75.6972)
+	require.NoError(t, err)
+	require.NotNil(t, trip.Fare)
+
+	expectedMeters := 2 * 1000 * repository.HaversineDistance(45.4215, -75.6972, 45.4300, -75.6972)
+	assert.InDelta(t, expectedMeters, trip.Fare.DistanceMeters, 1)
+	assert.Equal(t, int64(1), trip.Fare.Minutes)
+	assert.Equal(t, int64(100+30)+trip.Fare.DistanceCharge, trip.Fare.Total)
+	assert.InDelta(t, 189, trip.Fare.DistanceCharge, 1)
+
+	stored, err := repo.Trip().GetByID(ctx, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, trip.Fare, stored.Fare)
+
+	outboxEvents, err := repo.Outbox().GetUnpublished(ctx, 10)
+	require.NoError(t, err)
+	var payload TripEventPayload
+	for _, event := range outboxEvents {
+		if event.EventType == models.OutboxEventTripEnded {
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		}
+	}
+	require.NotNil(t, payload.Fare)
+	assert.Equal(t, trip.Fare.Total, payload.Fare.Total)
+}
@@ -15,6 +15,7 @@ type TripTestCases struct{}
 
 func (tc *TripTestCases) StartTripTestCases() []struct {
 	Name          string
+	TripID        uuid.UUID
 	ScooterID     uuid.UUID
 	UserID        uuid.UUID
 	Latitude      float64
@@ -24,6 +25,7 @@ func (tc *TripTestCases) StartTripTestCases() []struct {
 } {
 	return []struct {
 		Name          string
+		TripID        uuid.UUID
 		ScooterID     uuid.UUID
 		UserID        uuid.UUID
 		Latitude      float64
@@ -136,11 +138,69 @@ func (tc *TripTestCases) StartTripTestCases() []struct {
 				scooterRepo.On("GetByIDForUpdate", mock.Anything, TestData.ValidScooterID).Return(scooter, nil)
 			},
 		},
+		{
+			Name:          "successful trip start with client supplied trip ID",
+			TripID:        TestData.ValidTripID,
+			ScooterID:     TestData.ValidScooterID,
+			UserID:        TestData.ValidUserID,
+			Latitude:      TestData.ValidLatitude,
+			Longitude:     TestData.ValidLongitude,
+			ExpectedError: "",
+			SetupMocks: func(tripRepo *mocks.MockTripRepository, scooterRepo *mocks.MockScooterRepository, userRepo *mocks.MockUserRepository, locationRepo *mocks.MockLocationUpdateRepository, unitOfWork *mocks.MockUnitOfWork) {
+				mockSetup := &MockSetup{}
+				mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+
+				user := NewTestUserBuilder().WithID(TestData.ValidUserID).Build()
+				userRepo.On("GetByID", mock.Anything, TestData.ValidUserID).Return(user, nil)
+				tripRepo.On("GetActiveByUserID", mock.Anything, TestData.ValidUserID).Return(nil, nil)
+
+				scooter := NewTestScooterBuilder().
+					WithID(TestData.ValidScooterID).
+					WithStatus(models.ScooterStatusAvailable).
+					WithLocation(TestData.ValidLatitude, TestData.ValidLongitude).
+					Build()
+				scooterRepo.On("GetByIDForUpdate", mock.Anything, TestData.ValidScooterID).Return(scooter, nil)
+				tripRepo.On("GetActiveByScooterID", mock.Anything, mock.Anything).Return(nil, nil)
+				tripRepo.On("GetByID", mock.Anything, TestData.ValidTripID).Return(nil, nil)
+				tripRepo.On("Create", mock.Anything, mock.MatchedBy(func(trip *models.Trip) bool {
+					return trip.ID == TestData.ValidTripID
+				})).Return(nil)
+				scooterRepo.On("UpdateStatusWithCheck", mock.Anything, TestData.ValidScooterID, models.ScooterStatusOccupied, models.ScooterStatusAvailable).Return(nil)
+				scooterRepo.On("UpdateLocation", mock.Anything, TestData.ValidScooterID, TestData.ValidLatitude, TestData.ValidLongitude).Return(nil)
+			},
+		},
+		{
+			Name:          "client supplied trip ID already exists",
+			TripID:        TestData.ValidTripID,
+			ScooterID:     TestData.ValidScooterID,
+			UserID:        TestData.ValidUserID,
+			Latitude:      TestData.ValidLatitude,
+			Longitude:     TestData.ValidLongitude,
+			ExpectedError: "trip already exists",
+			SetupMocks: func(tripRepo *mocks.MockTripRepository, scooterRepo *mocks.MockScooterRepository, userRepo *mocks.MockUserRepository, locationRepo *mocks.MockLocationUpdateRepository, unitOfWork *mocks.MockUnitOfWork) {
+				mockSetup := &MockSetup{}
+				mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+
+				user := NewTestUserBuilder().WithID(TestData.ValidUserID).Build()
+				userRepo.On("GetByID", mock.Anything, TestData.ValidUserID).Return(user, nil)
+				tripRepo.On("GetActiveByUserID", mock.Anything, TestData.ValidUserID).Return(nil, nil)
+
+				scooter := NewTestScooterBuilder().
+					WithID(TestData.ValidScooterID).
+					WithStatus(models.ScooterStatusAvailable).
+					Build()
+				scooterRepo.On("GetByIDForUpdate", mock.Anything, TestData.ValidScooterID).Return(scooter, nil)
+				tripRepo.On("GetActiveByScooterID", mock.Anything, mock.Anything).Return(nil, nil)
+				existing := NewTestTripBuilder().WithID(TestData.ValidTripID).WithStatus(models.TripStatusCompleted).Build()
+				tripRepo.On("GetByID", mock.Anything, TestData.ValidTripID).Return(existing, nil)
+			},
+		},
 	}
 }
 
 func (tc *TripTestCases) EndTripTestCases() []struct {
 	Name          string
+	TripID        uuid.UUID
 	ScooterID     uuid.UUID
 	Latitude      float64
 	Longitude     float64
@@ -149,6 +209,7 @@ func (tc *TripTestCases) EndTripTestCases() []struct {
 } {
 	return []struct {
 		Name          string
+		TripID        uuid.UUID
 		ScooterID     uuid.UUID
 		Latitude      float64
 		Longitude     float64
@@ -173,7 +234,7 @@ func (tc *TripTestCases) EndTripTestCases() []struct {
 					WithStartLocation(TestData.ValidLatitude, TestData.ValidLongitude).
 					Build()
 				tripRepo.On("GetActiveByScooterID", mock.Anything, mock.Anything).Return(trip, nil)
-				tripRepo.On("EndTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				tripRepo.On("EndTrip", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				scooterRepo.On("UpdateStatusWithCheck", mock.Anything, mock.Anything, models.ScooterStatusAvailable, models.ScooterStatusOccupied).Return(nil)
 				scooterRepo.On("UpdateLocation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
@@ -200,6 +261,46 @@ func (tc *TripTestCases) EndTripTestCases() []struct {
 				// No mocks needed for validation errors
 			},
 		},
+		{
+			Name:          "successful trip end with matching trip ID",
+			TripID:        TestData.ValidTripID,
+			ScooterID:     TestData.ValidScooterID,
+			Latitude:      TestData.ValidLatitude + 0.001,
+			Longitude:     TestData.ValidLongitude + 0.001,
+			ExpectedError: "",
+			SetupMocks: func(tripRepo *mocks.MockTripRepository, scooterRepo *mocks.MockScooterRepository, userRepo *mocks.MockUserRepository, locationRepo *mocks.MockLocationUpdateRepository, unitOfWork *mocks.MockUnitOfWork) {
+				mockSetup := &MockSetup{}
+				mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+
+				trip := NewTestTripBuilder().
+					WithID(TestData.ValidTripID).
+					WithScooterID(TestData.ValidScooterID).
+					WithStatus(models.TripStatusActive).
+					Build()
+				tripRepo.On("GetActiveByScooterID", mock.Anything, mock.Anything).Return(trip, nil)
+				tripRepo.On("EndTrip", mock.Anything, TestData.ValidTripID, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				scooterRepo.On("UpdateStatusWithCheck", mock.Anything, mock.Anything, models.ScooterStatusAvailable, models.ScooterStatusOccupied).Return(nil)
+				scooterRepo.On("UpdateLocation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			Name:          "trip ID does not match active trip",
+			TripID:        TestData.ValidTripID,
+			ScooterID:     TestData.ValidScooterID,
+			Latitude:      TestData.ValidLatitude,
+			Longitude:     TestData.ValidLongitude,
+			ExpectedError: "trip ID does not match active trip",
+			SetupMocks: func(tripRepo *mocks.MockTripRepository, scooterRepo *mocks.MockScooterRepository, userRepo *mocks.MockUserRepository, locationRepo *mocks.MockLocationUpdateRepository, unitOfWork *mocks.MockUnitOfWork) {
+				mockSetup := &MockSetup{}
+				mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+
+				trip := NewTestTripBuilder().
+					WithScooterID(TestData.ValidScooterID).
+					WithStatus(models.TripStatusActive).
+					Build()
+				tripRepo.On("GetActiveByScooterID", mock.Anything, mock.Anything).Return(trip, nil)
+			},
+		},
 	}
 }
 
@@ -266,12 +367,14 @@ func (tc *TripTestCases) UpdateLocationTestCases() []struct {
 
 func (tc *TripTestCases) CancelTripTestCases() []struct {
 	Name          string
+	TripID        uuid.UUID
 	ScooterID     uuid.UUID
 	ExpectedError string
 	SetupMocks    func(*mocks.MockTripRepository, *mocks.MockScooterRepository, *mocks.MockUserRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork)
 } {
 	return []struct {
 		Name          string
+		TripID        uuid.UUID
 		ScooterID     uuid.UUID
 		ExpectedError string
 		SetupMocks    func(*mocks.MockTripRepository, *mocks.MockScooterRepository, *mocks.MockUserRepository, *mocks.MockLocationUpdateRepository, *mocks.MockUnitOfWork)
@@ -349,6 +452,22 @@ func (tc *TripTestCases) CancelTripTestCases() []struct {
 				scooterRepo.On("UpdateStatusWithCheck", mock.Anything, mock.Anything, models.ScooterStatusAvailable, models.ScooterStatusOccupied).Return(errors.New("database error"))
 			},
 		},
+		{
+			Name:          "trip ID does not match active trip",
+			TripID:        TestData.ValidTripID,
+			ScooterID:     TestData.ValidScooterID,
+			ExpectedError: "trip ID does not match active trip",
+			SetupMocks: func(tripRepo *mocks.MockTripRepository, scooterRepo *mocks.MockScooterRepository, userRepo *mocks.MockUserRepository, locationRepo *mocks.MockLocationUpdateRepository, unitOfWork *mocks.MockUnitOfWork) {
+				mockSetup := &MockSetup{}
+				mockSetup.SetupBasicUnitOfWork(unitOfWork, tripRepo, scooterRepo, userRepo, locationRepo)
+
+				trip := NewTestTripBuilder().
+					WithScooterID(TestData.ValidScooterID).
+					WithStatus(models.TripStatusActive).
+					Build()
+				tripRepo.On("GetActiveByScooterID", mock.Anything, mock.Anything).Return(trip, nil)
+			},
+		},
 	}
 }
 
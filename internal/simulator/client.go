@@ -30,6 +30,8 @@ type APIScooter struct {
 	Status    string  `json:"status"`
 	Latitude  float64 `json:"current_latitude"`
 	Longitude float64 `json:"current_longitude"`
+	// BatteryLevel is nil for scooters that have not reported a charge yet
+	BatteryLevel *int `json:"battery_level,omitempty"`
 }
 
 type ScootersResponse struct {
@@ -173,3 +175,39 @@ func (c *APIClient) GetScootersInBounds(ctx context.Context, minLat, maxLat, min
 
 	return response.Scooters, nil
 }
+
+type DeviceSecretResponse struct {
+	ScooterID string `json:"scooter_id"`
+	Secret    string `json:"secret"`
+}
+
+// RotateDeviceSecret issues a new device secret for the scooter; it requires an admin API key
+func (c *APIClient) RotateDeviceSecret(ctx context.Context, scooterID string) (string, error) {
+	url := fmt.Sprintf("%s/api/v1/admin/scooters/%s/device-secret", c.baseURL, scooterID)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var response DeviceSecretResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Secret, nil
+}
@@ -11,7 +11,7 @@ import (
 type EventPublisher interface {
 	PublishTripStarted(ctx context.Context, tripID, scooterID, userID string, lat, lng float64) error
 	PublishTripEnded(ctx context.Context, tripID, scooterID, userID string, lat, lng float64, startTime time.Time) error
-	PublishLocationUpdated(ctx context.Context, scooterID, tripID string, lat, lng, heading, speed float64) error
+	PublishLocationUpdated(ctx context.Context, scooterID, tripID string, lat, lng, heading, speed float64, batteryLevel int) error
 	Close() error
 }
 
@@ -40,8 +40,8 @@ func (p *KafkaEventPublisher) PublishTripEnded(ctx context.Context, tripID, scoo
 }
 
 // PublishLocationUpdated publishes a location updated event
-func (p *KafkaEventPublisher) PublishLocationUpdated(ctx context.Context, scooterID, tripID string, lat, lng, heading, speed float64) error {
-	event := events.NewLocationUpdatedEvent(scooterID, tripID, lat, lng, heading, speed)
+func (p *KafkaEventPublisher) PublishLocationUpdated(ctx context.Context, scooterID, tripID string, lat, lng, heading, speed float64, batteryLevel int) error {
+	event := events.NewLocationUpdatedEvent(scooterID, tripID, lat, lng, heading, speed, &batteryLevel)
 	return p.producer.PublishLocationUpdated(ctx, event)
 }
 
@@ -6,15 +6,22 @@ import (
 	"time"
 
 	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/geofence"
 )
 
+// randomLocationAttempts bounds the points tried when placing a location inside a service area
+const randomLocationAttempts = 100
+
 type Movement struct {
 	config *config.Config
+	// zones are the server's geofences; nil falls back to the city circles and places no restrictions
+	zones *geofence.Set
 }
 
-func NewMovement(cfg *config.Config) *Movement {
+func NewMovement(cfg *config.Config, zones *geofence.Set) *Movement {
 	return &Movement{
 		config: cfg,
+		zones:  zones,
 	}
 }
 
@@ -67,15 +74,47 @@ func (m *Movement) GetRandomLocationInCity(city City) Location {
 	}
 }
 
+// GetRandomLocation picks a parking spot in a service area, or anywhere in a city when there are none
 func (m *Movement) GetRandomLocation() Location {
+	if areas := m.zones.Zones(geofence.ZoneTypeServiceArea); len(areas) > 0 {
+		if location, ok := m.randomLocationInZone(areas[rand.Intn(len(areas))]); ok {
+			return location
+		}
+	}
+
 	cities := m.GetCities()
 	city := cities[rand.Intn(len(cities))]
 	return m.GetRandomLocationInCity(city)
 }
 
+func (m *Movement) randomLocationInZone(zone *geofence.Zone) (Location, bool) {
+	minLat, minLng, maxLat, maxLng := zone.Bounds()
+	for range randomLocationAttempts {
+		location := Location{
+			Latitude:  minLat + rand.Float64()*(maxLat-minLat),
+			Longitude: minLng + rand.Float64()*(maxLng-minLng),
+		}
+		if zone.Contains(location.Latitude, location.Longitude) && m.CheckParking(location) == nil {
+			return location, true
+		}
+	}
+	return Location{}, false
+}
+
+// CheckParking returns the reason the server would refuse to end a trip at location, if any
+func (m *Movement) CheckParking(location Location) error {
+	return m.zones.CheckParking(location.Latitude, location.Longitude)
+}
+
 func (m *Movement) CalculateMovement(start Location, direction float64, duration time.Duration) Location {
+	// Riders keep to the limit of any slow zone they start the step in
+	speedKmh := float64(m.config.SimulatorSpeed)
+	if limit, ok := m.zones.SpeedLimitAt(start.Latitude, start.Longitude); ok {
+		speedKmh = min(speedKmh, limit)
+	}
+
 	// Convert speed from km/h to m/s
-	speedMs := float64(m.config.SimulatorSpeed) * 1000.0 / 3600.0
+	speedMs := speedKmh * 1000.0 / 3600.0
 
 	// Calculate distance traveled in meters
 	distanceM := speedMs * duration.Seconds()
@@ -2,15 +2,25 @@ package simulator
 
 import (
 	"context"
+	"errors"
+	"math"
 	"math/rand"
 	"time"
 
 	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/geofence"
 	"scootin-aboot/internal/logger"
 
 	"github.com/google/uuid"
 )
 
+// Battery drain and recharge per 3 second tick, in percentage points. Idle scooters recharge to stand
+// in for the field team swapping batteries.
+const (
+	batteryDrainPerTick    = 2
+	batteryRechargePerTick = 1
+)
+
 type UserTracker interface {
 	IsUserActive(userID string) bool
 	MarkUserActive(userID string)
@@ -33,6 +43,7 @@ type Scooter struct {
 	CurrentTrip       *Trip
 	Location          Location
 	Status            string
+	BatteryLevel      int
 	LastSeen          time.Time
 	UserTracker       UserTracker
 	StatisticsUpdater StatisticsUpdater
@@ -45,14 +56,20 @@ type Trip struct {
 	Direction float64
 }
 
-func NewScooterFromAPI(ctx context.Context, publisher EventPublisher, apiScooter APIScooter, cfg *config.Config, userTracker UserTracker, statsUpdater StatisticsUpdater) (*Scooter, error) {
-	movement := NewMovement(cfg)
+func NewScooterFromAPI(ctx context.Context, publisher EventPublisher, apiScooter APIScooter, cfg *config.Config, zones *geofence.Set, userTracker UserTracker, statsUpdater StatisticsUpdater) (*Scooter, error) {
+	movement := NewMovement(cfg, zones)
 
 	location := Location{
 		Latitude:  apiScooter.Latitude,
 		Longitude: apiScooter.Longitude,
 	}
 
+	// Scooters that have never reported a charge start somewhere between half and fully charged
+	batteryLevel := 50 + rand.Intn(51)
+	if apiScooter.BatteryLevel != nil {
+		batteryLevel = *apiScooter.BatteryLevel
+	}
+
 	return &Scooter{
 		ID:                0,
 		APIScooterID:      apiScooter.ID,
@@ -62,6 +79,7 @@ func NewScooterFromAPI(ctx context.Context, publisher EventPublisher, apiScooter
 		Movement:          movement,
 		Location:          location,
 		Status:            apiScooter.Status,
+		BatteryLevel:      batteryLevel,
 		LastSeen:          time.Now(),
 		UserTracker:       userTracker,
 		StatisticsUpdater: statsUpdater,
@@ -115,12 +133,14 @@ func (s *Scooter) updateLocation() {
 		heading = s.CurrentTrip.Direction
 		speed = 15.0 // Mock speed for occupied scooters
 		tripID = s.CurrentTrip.ID
+		s.BatteryLevel = max(s.BatteryLevel-batteryDrainPerTick, 0)
 	} else {
 		// Scooter is available - keep stationary
 		newLocation = s.Location // No movement for available scooters
 		heading = 0.0            // No heading for stationary scooters
 		speed = 0.0              // Available scooters are stationary
 		tripID = ""              // No trip ID for available scooters
+		s.BatteryLevel = min(s.BatteryLevel+batteryRechargePerTick, 100)
 	}
 
 	s.Location = newLocation
@@ -134,9 +154,10 @@ func (s *Scooter) updateLocation() {
 		logger.Float64("lng", s.Location.Longitude),
 		logger.Float64("heading", heading),
 		logger.Float64("speed", speed),
+		logger.Int("battery_level", s.BatteryLevel),
 	)
 
-	if err := s.Publisher.PublishLocationUpdated(s.Ctx, s.getScooterID(), tripID, s.Location.Latitude, s.Location.Longitude, heading, speed); err != nil {
+	if err := s.Publisher.PublishLocationUpdated(s.Ctx, s.getScooterID(), tripID, s.Location.Latitude, s.Location.Longitude, heading, speed, s.BatteryLevel); err != nil {
 		logger.Error("Failed to publish location update event",
 			logger.Int("scooter_id", s.ID),
 			logger.String("trip_id", tripID),
@@ -208,6 +229,11 @@ func (s *Scooter) GetLastSeen() time.Time {
 }
 
 func (s *Scooter) shouldStartTrip() bool {
+	// The server refuses trips on scooters below the minimum charge
+	if s.BatteryLevel < s.Config.BatteryConfig.MinTripLevel {
+		return false
+	}
+
 	// 60% chance every 3 seconds to start a trip when available
 	return rand.Float64() < 0.6
 }
@@ -221,13 +247,23 @@ func (s *Scooter) shouldEndTrip() bool {
 	minDuration := 5 * time.Second
 	maxDuration := 15 * time.Second
 
-	if tripDuration > minDuration {
-		return rand.Float64() < 0.2 || tripDuration > maxDuration
+	if tripDuration > minDuration && (rand.Float64() < 0.2 || tripDuration > maxDuration) {
+		// The server refuses to end trips outside the service area or in no-parking zones, so ride on
+		return s.canPark()
 	}
 
 	return false
 }
 
+func (s *Scooter) canPark() bool {
+	err := s.Movement.CheckParking(s.Location)
+	if errors.Is(err, geofence.ErrOutsideServiceArea) {
+		// Head back the way the trip came
+		s.CurrentTrip.Direction = math.Mod(s.CurrentTrip.Direction+180, 360)
+	}
+	return err == nil
+}
+
 func (s *Scooter) startRandomTrip() {
 	availableUsers := s.UserTracker.GetAvailableUsers()
 	if len(availableUsers) == 0 {
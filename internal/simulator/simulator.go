@@ -6,8 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"scootin-aboot/internal/auth/device"
 	"scootin-aboot/internal/config"
 	"scootin-aboot/internal/events"
+	"scootin-aboot/internal/geofence"
 	"scootin-aboot/internal/logger"
 )
 
@@ -30,6 +32,8 @@ type Simulator struct {
 	config        *config.Config
 	client        *APIClient
 	publisher     EventPublisher
+	signer        *device.Signer
+	zones         *geofence.Set
 	users         []*User
 	scooters      []*Scooter
 	ctx           context.Context
@@ -61,13 +65,27 @@ func NewSimulator(cfg *config.Config) (*Simulator, error) {
 		cancel()
 		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
 	}
+	// Every event is signed with the secret of the scooter it is about
+	signer := device.NewSigner()
+	kafkaProducer.SetSigner(signer)
 	publisher := NewKafkaEventPublisher(kafkaProducer)
-	logger.Info("Using Kafka event publisher")
+	logger.Info("Using Kafka event publisher", logger.String("transport", cfg.KafkaConfig.Transport))
+
+	// Riders follow the server's zones so their trips are not refused; without them they ride anywhere
+	var zones *geofence.Set
+	if cfg.GeofenceConfig.File != "" {
+		zones, err = geofence.LoadFile(cfg.GeofenceConfig.File)
+		if err != nil {
+			logger.Warn("Failed to load geofences; riding without zones", logger.ErrorField(err))
+		}
+	}
 
 	return &Simulator{
 		config:      cfg,
 		client:      client,
 		publisher:   publisher,
+		signer:      signer,
+		zones:       zones,
 		ctx:         ctx,
 		cancel:      cancel,
 		activeUsers: make(map[string]bool),
@@ -194,7 +212,16 @@ func (s *Simulator) initializeScooters() error {
 
 	for i := 0; i < maxScooters; i++ {
 		apiScooter := apiScooters[i]
-		scooter, err := NewScooterFromAPI(s.ctx, s.publisher, apiScooter, s.config, s, s)
+
+		secret, err := s.client.RotateDeviceSecret(s.ctx, apiScooter.ID)
+		if err != nil {
+			return fmt.Errorf("failed to provision device secret for scooter %s: %w", apiScooter.ID, err)
+		}
+		if err := s.signer.SetSecret(apiScooter.ID, secret); err != nil {
+			return fmt.Errorf("invalid device secret for scooter %s: %w", apiScooter.ID, err)
+		}
+
+		scooter, err := NewScooterFromAPI(s.ctx, s.publisher, apiScooter, s.config, s.zones, s, s)
 		if err != nil {
 			return fmt.Errorf("failed to create scooter %s: %w", apiScooter.ID, err)
 		}
@@ -223,7 +250,7 @@ func (s *Simulator) initializeUsers() error {
 	s.users = make([]*User, maxUsers)
 
 	for i := 0; i < maxUsers; i++ {
-		user, err := NewUserWithID(s.ctx, s.client, i+1, SeededUserIDs[i], s.config)
+		user, err := NewUserWithID(s.ctx, s.client, i+1, SeededUserIDs[i], s.config, s.zones)
 		if err != nil {
 			return fmt.Errorf("failed to create user %s: %w", SeededUserIDs[i], err)
 		}
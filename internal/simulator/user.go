@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/geofence"
 	"scootin-aboot/internal/logger"
 )
 
@@ -23,8 +24,8 @@ type User struct {
 	SearchCount  int      // Number of searches performed
 }
 
-func NewUserWithID(ctx context.Context, client *APIClient, id int, userID string, cfg *config.Config) (*User, error) {
-	movement := NewMovement(cfg)
+func NewUserWithID(ctx context.Context, client *APIClient, id int, userID string, cfg *config.Config, zones *geofence.Set) (*User, error) {
+	movement := NewMovement(cfg, zones)
 	return &User{
 		ID:           id,
 		UserID:       userID,
@@ -0,0 +1,254 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// EventType says what changed about a scooter
+type EventType string
+
+const (
+	// EventTypeLocation is a position and battery report
+	EventTypeLocation EventType = "location"
+	// EventTypeStatus is a status transition
+	EventTypeStatus EventType = "status"
+)
+
+// ScooterEvent is a change to a scooter, carrying the scooter's state after it
+type ScooterEvent struct {
+	// ID is assigned by the hub and increases with every event it publishes
+	ID        uint64
+	Type      EventType
+	ScooterID uuid.UUID
+	Status    models.ScooterStatus
+	// PreviousStatus is set on status events
+	PreviousStatus models.ScooterStatus
+	Latitude       float64
+	Longitude      float64
+	BatteryLevel   *int
+	Timestamp      time.Time
+}
+
+// Hub fans scooter changes out to subscribers and keeps the latest of them so that clients can resume after
+// reconnecting. Subscribers that fall too far behind are dropped rather than slowing down publishers.
+type Hub struct {
+	// epoch tells this hub's event IDs apart from those of an earlier process
+	epoch            int64
+	subscriberBuffer int
+
+	mu sync.Mutex
+	// buffer is a ring of the latest events; the oldest is at start
+	buffer      []ScooterEvent
+	start       int
+	count       int
+	lastID      uint64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+
+	// active counts subscriptions not yet closed by their subscriber, so that shutdown can wait for them
+	active sync.WaitGroup
+}
+
+// NewHub returns a hub that keeps bufferSize events for resuming clients and queues up to subscriberBuffer
+// events per subscriber
+func NewHub(bufferSize, subscriberBuffer int) *Hub {
+	return &Hub{
+		epoch:            time.Now().UnixNano(),
+		subscriberBuffer: subscriberBuffer,
+		buffer:           make([]ScooterEvent, bufferSize),
+		subscribers:      make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event its ID and delivers it to every subscriber. A nil Hub discards events.
+func (h *Hub) Publish(event ScooterEvent) {
+	if h == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	event.ID = h.lastID
+	if len(h.buffer) > 0 {
+		if h.count < len(h.buffer) {
+			h.buffer[(h.start+h.count)%len(h.buffer)] = event
+			h.count++
+		} else {
+			h.buffer[h.start] = event
+			h.start = (h.start + 1) % len(h.buffer)
+		}
+	}
+
+	for subscription := range h.subscribers {
+		if !subscription.wants(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			h.drop(subscription)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. Given the ID of the last event a client saw, the events it missed are
+// returned for replay; complete is false when some of them are no longer buffered, or the ID is not one
+// of this hub's, and the client has to reload the scooters instead. Given scooter IDs, only the events of
+// those scooters are delivered and replayed.
+func (h *Hub) Subscribe(lastEventID string, scooterIDs ...uuid.UUID) (subscription *Subscription, replay []ScooterEvent, complete bool) {
+	subscription = &Subscription{
+		hub:    h,
+		events: make(chan ScooterEvent, h.subscriberBuffer),
+		done:   make(chan struct{}),
+	}
+	if len(scooterIDs) > 0 {
+		subscription.scooters = make(map[uuid.UUID]struct{}, len(scooterIDs))
+		for _, id := range scooterIDs {
+			subscription.scooters[id] = struct{}{}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		subscription.close()
+		subscription.released.Do(func() {})
+		return subscription, nil, true
+	}
+	h.subscribers[subscription] = struct{}{}
+	h.active.Add(1)
+
+	if lastEventID == "" {
+		return subscription, nil, true
+	}
+
+	epoch, seen, ok := h.parseEventID(lastEventID)
+	if !ok || epoch != h.epoch || seen > h.lastID {
+		return subscription, nil, false
+	}
+
+	oldest := h.lastID - uint64(h.count) + 1
+	if seen+1 < oldest {
+		return subscription, nil, false
+	}
+	for i := 0; i < h.count; i++ {
+		event := h.buffer[(h.start+i)%len(h.buffer)]
+		if event.ID > seen && subscription.wants(event) {
+			replay = append(replay, event)
+		}
+	}
+	return subscription, replay, true
+}
+
+// EventID formats an event's ID for the SSE id field
+func (h *Hub) EventID(event ScooterEvent) string {
+	return fmt.Sprintf("%d-%d", h.epoch, event.ID)
+}
+
+func (h *Hub) parseEventID(id string) (epoch int64, seq uint64, ok bool) {
+	if _, err := fmt.Sscanf(id, "%d-%d", &epoch, &seq); err != nil {
+		return 0, 0, false
+	}
+	return epoch, seq, true
+}
+
+// Close ends every subscription, letting streaming responses finish before the server shuts down
+func (h *Hub) Close() {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for subscription := range h.subscribers {
+		h.drop(subscription)
+	}
+}
+
+// Wait blocks until every subscriber has closed its subscription after Close, or ctx is done. Streaming
+// connections are hijacked or long-lived, so the HTTP server's shutdown does not wait for them itself.
+func (h *Hub) Wait(ctx context.Context) error {
+	if h == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drop ends a subscription; the caller holds h.mu
+func (h *Hub) drop(subscription *Subscription) {
+	delete(h.subscribers, subscription)
+	subscription.close()
+}
+
+// Subscription receives the events published after it was made
+type Subscription struct {
+	hub    *Hub
+	events chan ScooterEvent
+	done   chan struct{}
+	// scooters limits the subscription to these scooters' events; nil is every scooter
+	scooters map[uuid.UUID]struct{}
+	once     sync.Once
+	// released makes Close count once towards Wait
+	released sync.Once
+}
+
+// Events delivers published events in order
+func (s *Subscription) Events() <-chan ScooterEvent {
+	return s.events
+}
+
+// Done is closed when the hub drops the subscriber for falling behind or shuts down
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close unsubscribes; subscribers call it once they are done with the subscription
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	s.hub.drop(s)
+	s.hub.mu.Unlock()
+
+	s.released.Do(s.hub.active.Done)
+}
+
+func (s *Subscription) wants(event ScooterEvent) bool {
+	if s.scooters == nil {
+		return true
+	}
+	_, ok := s.scooters[event.ScooterID]
+	return ok
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.done) })
+}
@@ -0,0 +1,153 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scootin-aboot/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publish(hub *Hub, n int) {
+	for range n {
+		hub.Publish(ScooterEvent{Type: EventTypeLocation, ScooterID: uuid.New(), Status: models.ScooterStatusAvailable})
+	}
+}
+
+func TestHub_PublishDelivers(t *testing.T) {
+	hub := NewHub(10, 10)
+	subscription, replay, complete := hub.Subscribe("")
+	defer subscription.Close()
+	assert.Empty(t, replay)
+	assert.True(t, complete)
+
+	publish(hub, 2)
+
+	first := <-subscription.Events()
+	second := <-subscription.Events()
+	assert.Equal(t, uint64(1), first.ID)
+	assert.Equal(t, uint64(2), second.ID)
+	assert.False(t, first.Timestamp.IsZero())
+}
+
+func TestHub_SubscribeResumes(t *testing.T) {
+	hub := NewHub(3, 10)
+	observer, _, _ := hub.Subscribe("")
+	publish(hub, 2)
+	<-observer.Events()
+	seen := <-observer.Events()
+	observer.Close()
+
+	publish(hub, 2)
+
+	t.Run("replays what was missed", func(t *testing.T) {
+		subscription, replay, complete := hub.Subscribe(hub.EventID(seen))
+		defer subscription.Close()
+		assert.True(t, complete)
+		require.Len(t, replay, 2)
+		assert.Equal(t, uint64(3), replay[0].ID)
+		assert.Equal(t, uint64(4), replay[1].ID)
+	})
+
+	t.Run("events that left the buffer", func(t *testing.T) {
+		subscription, replay, complete := hub.Subscribe(hub.EventID(ScooterEvent{ID: 0}))
+		defer subscription.Close()
+		assert.False(t, complete)
+		assert.Empty(t, replay)
+	})
+
+	t.Run("another process's ID", func(t *testing.T) {
+		subscription, _, complete := hub.Subscribe("1-2")
+		defer subscription.Close()
+		assert.False(t, complete)
+	})
+
+	t.Run("malformed ID", func(t *testing.T) {
+		subscription, _, complete := hub.Subscribe("latest")
+		defer subscription.Close()
+		assert.False(t, complete)
+	})
+}
+
+func TestHub_SubscribeToScooter(t *testing.T) {
+	hub := NewHub(10, 10)
+	scooterID := uuid.New()
+	publish(hub, 1)
+	hub.Publish(ScooterEvent{Type: EventTypeLocation, ScooterID: scooterID})
+
+	subscription, replay, complete := hub.Subscribe(hub.EventID(ScooterEvent{ID: 0}), scooterID)
+	defer subscription.Close()
+	assert.True(t, complete)
+	require.Len(t, replay, 1, "only the scooter's events are replayed")
+	assert.Equal(t, scooterID, replay[0].ScooterID)
+
+	publish(hub, 1)
+	hub.Publish(ScooterEvent{Type: EventTypeStatus, ScooterID: scooterID, Status: models.ScooterStatusAvailable})
+
+	event := <-subscription.Events()
+	assert.Equal(t, scooterID, event.ScooterID)
+	assert.Equal(t, EventTypeStatus, event.Type)
+	assert.Empty(t, subscription.Events(), "other scooters' events are not delivered")
+}
+
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	hub := NewHub(10, 2)
+	slow, _, _ := hub.Subscribe("")
+	fast, _, _ := hub.Subscribe("")
+	defer fast.Close()
+
+	publish(hub, 2)
+	<-fast.Events()
+	<-fast.Events()
+	publish(hub, 1)
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("a subscriber with a full queue should be dropped")
+	}
+	select {
+	case <-fast.Done():
+		t.Fatal("a subscriber keeping up should not be dropped")
+	default:
+	}
+	assert.Equal(t, uint64(3), (<-fast.Events()).ID)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(10, 10)
+	subscription, _, _ := hub.Subscribe("")
+
+	hub.Close()
+	<-subscription.Done()
+	subscription.Close()
+
+	late, _, _ := hub.Subscribe("")
+	<-late.Done()
+
+	var nilHub *Hub
+	nilHub.Publish(ScooterEvent{})
+	nilHub.Close()
+}
+
+func TestHub_Wait(t *testing.T) {
+	hub := NewHub(10, 10)
+	subscription, _, _ := hub.Subscribe("")
+	dropped, _, _ := hub.Subscribe("")
+	dropped.Close()
+
+	hub.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, hub.Wait(ctx), context.DeadlineExceeded, "a subscriber is still streaming")
+
+	subscription.Close()
+	subscription.Close()
+	late, _, _ := hub.Subscribe("")
+	late.Close()
+	assert.NoError(t, hub.Wait(context.Background()))
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"scootin-aboot/internal/config"
+	"scootin-aboot/internal/tracing/tracingtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestSetup(t *testing.T) {
+	tracingtest.Record(t)
+
+	t.Run("none still propagates trace context", func(t *testing.T) {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+		shutdown, err := Setup(context.Background(), &config.TracingConfig{Exporter: config.TracingExporterNone}, "test")
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+		assert.Equal(t, []string{"traceparent", "tracestate"}, otel.GetTextMapPropagator().Fields())
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), &config.TracingConfig{Exporter: config.TracingExporterStdout, SampleRatio: 1}, "test")
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("otlp", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), &config.TracingConfig{Exporter: config.TracingExporterOTLP, OTLPEndpoint: "http://localhost:4318", SampleRatio: 1}, "test")
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()), "nothing was buffered, so nothing is sent")
+	})
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	_, ok := otel.Tracer("test").Start(context.Background(), "ok")
+	End(ok, nil)
+	_, failed := otel.Tracer("test").Start(context.Background(), "failed")
+	End(failed, errors.New("boom"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+}
@@ -0,0 +1,12 @@
+package validation
+
+import "errors"
+
+// ValidateBatteryLevel checks that a battery level is a percentage
+func ValidateBatteryLevel(level int) error {
+	if level < 0 || level > 100 {
+		return errors.New("invalid battery level: must be between 0 and 100")
+	}
+
+	return nil
+}
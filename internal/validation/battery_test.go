@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"testing"
+)
+
+func TestValidateBatteryLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		level       int
+		expectError bool
+	}{
+		{name: "empty battery", level: 0},
+		{name: "partial charge", level: 57},
+		{name: "full charge", level: 100},
+		{name: "negative level", level: -1, expectError: true},
+		{name: "above full", level: 101, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBatteryLevel(tt.level)
+			if tt.expectError && err == nil {
+				t.Errorf("ValidateBatteryLevel(%d) expected error but got none", tt.level)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("ValidateBatteryLevel(%d) unexpected error = %v", tt.level, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+-- Drop processed_events table and related objects
+DROP INDEX IF EXISTS idx_processed_events_processed_at;
+DROP TABLE IF EXISTS processed_events;
@@ -0,0 +1,9 @@
+-- Create processed_events table used to deduplicate redelivered events
+CREATE TABLE processed_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_processed_events_processed_at ON processed_events(processed_at);
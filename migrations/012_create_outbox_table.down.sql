@@ -0,0 +1,4 @@
+-- Drop outbox table and related objects
+DROP INDEX IF EXISTS idx_outbox_aggregate_id;
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+DROP TABLE IF EXISTS outbox;
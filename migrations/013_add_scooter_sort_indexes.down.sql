@@ -0,0 +1,3 @@
+-- Drop keyset pagination indexes
+DROP INDEX IF EXISTS idx_scooters_last_seen_id;
+DROP INDEX IF EXISTS idx_scooters_created_at_id;
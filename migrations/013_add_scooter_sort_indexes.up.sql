@@ -0,0 +1,3 @@
+-- Create indexes backing keyset pagination of the scooter list
+CREATE INDEX idx_scooters_created_at_id ON scooters(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_scooters_last_seen_id ON scooters(last_seen, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,6 @@
+-- Drop geohash column and related objects
+DROP INDEX IF EXISTS idx_scooters_geohash;
+DROP TRIGGER IF EXISTS set_scooters_geohash ON scooters;
+DROP FUNCTION IF EXISTS set_scooter_geohash();
+ALTER TABLE scooters DROP COLUMN IF EXISTS geohash;
+DROP FUNCTION IF EXISTS geohash_encode(DOUBLE PRECISION, DOUBLE PRECISION, INTEGER);
@@ -0,0 +1,70 @@
+-- Geohash of a point, matching repository.EncodeGeohash
+CREATE OR REPLACE FUNCTION geohash_encode(lat DOUBLE PRECISION, lng DOUBLE PRECISION, hash_length INTEGER)
+RETURNS TEXT AS $$
+DECLARE
+    alphabet CONSTANT TEXT := '0123456789bcdefghjkmnpqrstuvwxyz';
+    lat_min DOUBLE PRECISION := -90;
+    lat_max DOUBLE PRECISION := 90;
+    lng_min DOUBLE PRECISION := -180;
+    lng_max DOUBLE PRECISION := 180;
+    mid DOUBLE PRECISION;
+    hash TEXT := '';
+    chunk INTEGER := 0;
+    chunk_bits INTEGER := 0;
+    is_lng BOOLEAN := TRUE;
+BEGIN
+    WHILE length(hash) < hash_length LOOP
+        chunk := chunk * 2;
+        IF is_lng THEN
+            mid := (lng_min + lng_max) / 2;
+            IF lng >= mid THEN
+                chunk := chunk + 1;
+                lng_min := mid;
+            ELSE
+                lng_max := mid;
+            END IF;
+        ELSE
+            mid := (lat_min + lat_max) / 2;
+            IF lat >= mid THEN
+                chunk := chunk + 1;
+                lat_min := mid;
+            ELSE
+                lat_max := mid;
+            END IF;
+        END IF;
+        is_lng := NOT is_lng;
+
+        chunk_bits := chunk_bits + 1;
+        IF chunk_bits = 5 THEN
+            hash := hash || substr(alphabet, chunk + 1, 1);
+            chunk := 0;
+            chunk_bits := 0;
+        END IF;
+    END LOOP;
+    RETURN hash;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE STRICT;
+
+-- Add geohash column; the C collation makes prefix ranges follow the geohash alphabet
+ALTER TABLE scooters ADD COLUMN geohash VARCHAR(12) COLLATE "C";
+
+UPDATE scooters SET geohash = geohash_encode(current_latitude, current_longitude, 9);
+
+ALTER TABLE scooters ALTER COLUMN geohash SET NOT NULL;
+
+-- Keep the geohash in step with the scooter's location on every write
+CREATE OR REPLACE FUNCTION set_scooter_geohash()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.geohash := geohash_encode(NEW.current_latitude, NEW.current_longitude, 9);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_scooters_geohash
+    BEFORE INSERT OR UPDATE OF current_latitude, current_longitude ON scooters
+    FOR EACH ROW
+    EXECUTE FUNCTION set_scooter_geohash();
+
+-- Create index for radius queries
+CREATE INDEX idx_scooters_geohash ON scooters(geohash) WHERE deleted_at IS NULL;
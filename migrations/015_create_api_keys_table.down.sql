@@ -0,0 +1,4 @@
+-- Drop api_keys table and related objects
+DROP INDEX IF EXISTS idx_api_keys_created_at;
+DROP INDEX IF EXISTS idx_api_keys_key_hash;
+DROP TABLE IF EXISTS api_keys;
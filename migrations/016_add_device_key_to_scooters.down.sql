@@ -0,0 +1,2 @@
+-- Remove the per-scooter device credential
+ALTER TABLE scooters DROP COLUMN IF EXISTS device_key_hash;
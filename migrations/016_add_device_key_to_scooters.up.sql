@@ -0,0 +1,2 @@
+-- Add the per-scooter device credential; only the SHA-256 hash of the secret is stored
+ALTER TABLE scooters ADD COLUMN device_key_hash CHAR(64);
@@ -0,0 +1,7 @@
+-- Drop reservations table
+DROP TABLE IF EXISTS reservations;
+
+-- Release held scooters before restoring the original status constraint
+UPDATE scooters SET status = 'available' WHERE status = 'reserved';
+ALTER TABLE scooters DROP CONSTRAINT IF EXISTS scooters_status_check;
+ALTER TABLE scooters ADD CONSTRAINT scooters_status_check CHECK (status IN ('available', 'occupied'));
@@ -0,0 +1,21 @@
+-- Allow scooters to be held for a rider
+ALTER TABLE scooters DROP CONSTRAINT IF EXISTS scooters_status_check;
+ALTER TABLE scooters ADD CONSTRAINT scooters_status_check CHECK (status IN ('available', 'occupied', 'reserved'));
+
+-- Create reservations table
+CREATE TABLE reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scooter_id UUID NOT NULL REFERENCES scooters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'fulfilled', 'cancelled', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A scooter is held for at most one rider, and a rider holds at most one scooter
+CREATE UNIQUE INDEX idx_reservations_active_scooter ON reservations(scooter_id) WHERE status = 'active';
+CREATE UNIQUE INDEX idx_reservations_active_user ON reservations(user_id) WHERE status = 'active';
+
+-- The sweeper looks up active reservations by expiry
+CREATE INDEX idx_reservations_active_expires_at ON reservations(expires_at) WHERE status = 'active';
@@ -0,0 +1,3 @@
+-- Remove battery level tracking
+ALTER TABLE location_updates DROP COLUMN IF EXISTS battery_level;
+ALTER TABLE scooters DROP COLUMN IF EXISTS battery_level;
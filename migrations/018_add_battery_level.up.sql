@@ -0,0 +1,3 @@
+-- Track the battery charge reported by scooter telemetry as a percentage; NULL until a scooter reports it
+ALTER TABLE scooters ADD COLUMN battery_level SMALLINT CHECK (battery_level BETWEEN 0 AND 100);
+ALTER TABLE location_updates ADD COLUMN battery_level SMALLINT CHECK (battery_level BETWEEN 0 AND 100);
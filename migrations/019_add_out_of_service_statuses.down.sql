@@ -0,0 +1,8 @@
+-- Drop scooter_status_history table
+DROP TABLE IF EXISTS scooter_status_history;
+
+-- Retired scooters are removed; scooters in maintenance or charging return to service
+UPDATE scooters SET status = 'available', deleted_at = COALESCE(deleted_at, NOW()) WHERE status = 'retired';
+UPDATE scooters SET status = 'available' WHERE status IN ('maintenance', 'charging');
+ALTER TABLE scooters DROP CONSTRAINT IF EXISTS scooters_status_check;
+ALTER TABLE scooters ADD CONSTRAINT scooters_status_check CHECK (status IN ('available', 'occupied', 'reserved'));
@@ -0,0 +1,16 @@
+-- Allow scooters to be taken out of service
+ALTER TABLE scooters DROP CONSTRAINT IF EXISTS scooters_status_check;
+ALTER TABLE scooters ADD CONSTRAINT scooters_status_check CHECK (status IN ('available', 'occupied', 'reserved', 'maintenance', 'charging', 'retired'));
+
+-- Audit trail of manual status changes
+CREATE TABLE scooter_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scooter_id UUID NOT NULL REFERENCES scooters(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_scooter_status_history_scooter_created_at ON scooter_status_history(scooter_id, created_at DESC);
@@ -0,0 +1,4 @@
+-- Remove trip fares
+ALTER TABLE trips DROP COLUMN IF EXISTS fare_breakdown;
+ALTER TABLE trips DROP COLUMN IF EXISTS fare_currency;
+ALTER TABLE trips DROP COLUMN IF EXISTS fare_cents;
@@ -0,0 +1,4 @@
+-- Fares are priced when a trip ends; the breakdown keeps the tariff and charges it was made of
+ALTER TABLE trips ADD COLUMN fare_cents INTEGER CHECK (fare_cents >= 0);
+ALTER TABLE trips ADD COLUMN fare_currency VARCHAR(3);
+ALTER TABLE trips ADD COLUMN fare_breakdown JSONB;
@@ -0,0 +1,2 @@
+-- Remove outbox request IDs
+ALTER TABLE outbox DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,2 @@
+-- Keep the ID of the request that caused each server event, so the relay can pass it on to consumers
+ALTER TABLE outbox ADD COLUMN request_id VARCHAR(128);
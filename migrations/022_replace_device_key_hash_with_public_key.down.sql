@@ -0,0 +1,3 @@
+-- Devices have to be issued a new secret after rolling back
+ALTER TABLE scooters DROP COLUMN IF EXISTS device_public_key;
+ALTER TABLE scooters ADD COLUMN device_key_hash CHAR(64);
@@ -0,0 +1,4 @@
+-- Device signatures are Ed25519; only the public key of the device secret is stored. Devices provisioned
+-- under the old HMAC scheme have to be issued a new secret.
+ALTER TABLE scooters DROP COLUMN IF EXISTS device_key_hash;
+ALTER TABLE scooters ADD COLUMN device_public_key VARCHAR(64);
@@ -0,0 +1,5 @@
+-- Remove parked outbox rows' failure time; parked rows are picked up by the relay again
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox(created_at) WHERE published_at IS NULL;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS failed_at;
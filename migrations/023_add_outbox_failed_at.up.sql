@@ -0,0 +1,5 @@
+-- Park outbox rows that keep failing to publish, so they stop holding up the rows behind them
+ALTER TABLE outbox ADD COLUMN failed_at TIMESTAMP WITH TIME ZONE;
+
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox(created_at) WHERE published_at IS NULL AND failed_at IS NULL;